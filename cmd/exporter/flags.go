@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/config"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors"
+)
+
+const (
+	collectorFlagPrefix   = "collector."
+	noCollectorFlagPrefix = "no-collector."
+)
+
+// registerCollectorFlags defines a --collector.<name> and a --no-collector.<name> flag
+// for every registered collector, like node_exporter. Must be called before flag.Parse.
+func registerCollectorFlags() {
+	for _, registration := range surrealcollectors.Registrations() {
+		flag.Bool(collectorFlagPrefix+registration.Name, false,
+			fmt.Sprintf("Enable the %s collector, overriding the configuration file", registration.Name))
+		flag.Bool(noCollectorFlagPrefix+registration.Name, false,
+			fmt.Sprintf("Disable the %s collector, overriding the configuration file", registration.Name))
+	}
+}
+
+// collectorOverrides returns the collectors enabled or disabled on the command line.
+// Flags given with =false invert their meaning. Always enabled collectors accept only
+// --collector.<name>, which has no effect.
+func collectorOverrides() (map[string]bool, error) {
+	alwaysEnabled := make(map[string]bool)
+	for _, registration := range surrealcollectors.Registrations() {
+		alwaysEnabled[registration.Name] = registration.AlwaysEnabled
+	}
+
+	overrides := make(map[string]bool)
+	var err error
+
+	flag.Visit(func(f *flag.Flag) {
+		var name string
+		var value bool
+		switch {
+		case strings.HasPrefix(f.Name, collectorFlagPrefix):
+			name = strings.TrimPrefix(f.Name, collectorFlagPrefix)
+			value = f.Value.(flag.Getter).Get().(bool)
+		case strings.HasPrefix(f.Name, noCollectorFlagPrefix):
+			name = strings.TrimPrefix(f.Name, noCollectorFlagPrefix)
+			value = !f.Value.(flag.Getter).Get().(bool)
+		default:
+			return
+		}
+
+		if alwaysEnabled[name] {
+			if !value && err == nil {
+				err = fmt.Errorf("collector %s is always enabled and cannot be disabled", name)
+			}
+			return
+		}
+
+		overrides[name] = value
+	})
+
+	return overrides, err
+}
+
+// commandLineOverrides returns the settings given on the command line, leaving the
+// settings of flags that were not given unset.
+func commandLineOverrides() (config.Overrides, error) {
+	collectors, err := collectorOverrides()
+	if err != nil {
+		return config.Overrides{}, err
+	}
+
+	overrides := config.Overrides{Collectors: collectors}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "telemetry.port":
+			overrides.TelemetryPort = telemetryPort
+		case "telemetry.constant-labels":
+			overrides.TelemetryConstantLabels = telemetryConstantLabels
+		}
+	})
+
+	return overrides, nil
+}
+
+// configPath returns the configuration file to read. With an inline configuration, a
+// missing default file is skipped, so minimal deployments need no mounted file.
+func configPath() string {
+	if *configInline == "" && os.Getenv(config.InlineConfigEnv) == "" {
+		return *configFile
+	}
+
+	explicit := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "config.file" {
+			explicit = true
+		}
+	})
+
+	if _, err := os.Stat(*configFile); !explicit && errors.Is(err, fs.ErrNotExist) {
+		return ""
+	}
+
+	return *configFile
+}
@@ -2,9 +2,12 @@ package main //nolint:cyclop
 
 import (
 	"context"
+	"errors"
 	"flag"
+	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,109 +16,245 @@ import (
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/api"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/config"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/converter"
-	"github.com/asaphin/surrealdb-prometheus-exporter/internal/engine"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/exporter"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/generator"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/logger"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/processor"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/registry"
-	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealdb"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/tracing"
 	"github.com/prometheus/client_golang/prometheus"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
 )
 
-var configFile = flag.String("config.file", "./config.yaml", "Path to configuration file")
+const (
+	healthcheckCommand = "healthcheck"
+	healthcheckTimeout = 5 * time.Second
+
+	// serverShutdownTimeout bounds the wait for scrapes in flight on shutdown.
+	serverShutdownTimeout = 30 * time.Second
+)
+
+var (
+	configFile   = flag.String("config.file", "./config.yaml", "Path to configuration file")
+	configInline = flag.String("config.inline", "",
+		"YAML configuration applied on top of the configuration file (default $"+config.InlineConfigEnv+")")
+	configStrict = flag.Bool("config.strict", false,
+		"Fail on unknown configuration keys and on values that would otherwise be corrected with a warning")
+	generateDashboard = flag.Bool("generate-dashboard", false,
+		"Print a Grafana dashboard for the enabled collectors to stdout and exit")
+	generateAlerts = flag.Bool("generate-alerts", false,
+		"Print Prometheus alerting rules for the enabled collectors to stdout and exit")
+	checkConfig = flag.Bool("check-config", false,
+		"Validate the configuration, test connectivity to SurrealDB and exit")
+	dumpConfig = flag.Bool("dump-config", false,
+		"Print the effective configuration with secrets redacted and exit")
+	printConfigSchema = flag.Bool("print-config-schema", false,
+		"Print a JSON Schema of the configuration file and exit")
+	telemetryPort = flag.Int("telemetry.port", 0,
+		"Serve the exporter's own metrics (go, process) and the debug endpoints on this port, overriding exporter.telemetry_port")
+	telemetryConstantLabels = flag.Bool("telemetry.constant-labels", true,
+		"Add the cluster labels to the exporter's own metrics, overriding exporter.telemetry_constant_labels")
+)
 
 func main() {
+	registerCollectorFlags()
 	flag.Parse()
 
-	cfg, err := config.Load(*configFile)
-	if err != nil {
-		slog.Error("Failed to load configuration", "error", err)
-		os.Exit(1)
+	if *printConfigSchema {
+		if err := config.WriteSchema(os.Stdout); err != nil {
+			slog.Error("Failed to print configuration schema", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	logger.Configure(cfg)
-
-	dbConnManager := surrealdb.NewMultiConnectionManager(cfg)
+	// "healthcheck" is a subcommand, so flags may also follow it.
+	healthcheck := flag.Arg(0) == healthcheckCommand
+	if healthcheck {
+		if err := flag.CommandLine.Parse(flag.Args()[1:]); err != nil {
+			os.Exit(2)
+		}
+	}
 
-	versionReader, err := surrealdb.NewVersionReader(dbConnManager)
+	overrides, err := commandLineOverrides()
 	if err != nil {
-		slog.Error("Failed to initialize version reader", "error", err)
+		slog.Error("Invalid command line flags", "error", err)
 		os.Exit(1)
 	}
 
-	infoReader, err := surrealdb.NewInfoReader(cfg, dbConnManager)
+	cfg, err := config.Load(configPath(), *configInline, *configStrict, overrides)
 	if err != nil {
-		slog.Error("Failed to create surrealdb metrics reader", "error", err)
+		slog.Error("Failed to load configuration", "error", err)
 		os.Exit(1)
 	}
 
-	recordCountReader, err := surrealdb.NewRecordCountReader(dbConnManager)
-	if err != nil {
-		slog.Error("Failed to create surrealdb record count reader", "error", err)
-		os.Exit(1)
+	if healthcheck {
+		if err := checkHealth(cfg); err != nil {
+			slog.Error("Health check failed", "error", err)
+			os.Exit(1)
+		}
+		return
 	}
 
-	tableFilter := engine.NewTableFilter(cfg.LiveQueryIncludePatterns(), cfg.LiveQueryExcludePatterns())
-	liveQueryProvider := surrealdb.NewLiveQueryManager(
-		dbConnManager,
-		cfg.LiveQueryReconnectDelay(),
-		cfg.LiveQueryMaxReconnectAttempts(),
-	)
+	if *dumpConfig {
+		if err := cfg.Dump(os.Stdout); err != nil {
+			slog.Error("Failed to dump configuration", "error", err)
+			os.Exit(1)
+		}
+	}
 
-	statsTableFilter := engine.NewTableFilter(cfg.StatsTableIncludePatterns(), cfg.StatsTableExcludePatterns())
-	statsTableProvider := surrealdb.NewStatsTableManager(
-		dbConnManager,
-		cfg.StatsTableRemoveOrphanTables(),
-		cfg.StatsTableNamePrefix(),
-	)
+	if *checkConfig {
+		if err := checkConnectivity(cfg); err != nil {
+			slog.Error("Configuration check failed", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *dumpConfig {
+		return
+	}
 
-	recordCountFilter := engine.NewTableFilter(cfg.RecordCountIncludePatterns(), cfg.RecordCountExcludePatterns())
-
-	// Pre-warm the table cache
-	if cfg.StatsTableEnabled() || cfg.LiveQueryEnabled() || cfg.RecordCountCollectorEnabled() {
-		ctx, cancel := context.WithTimeout(context.Background(), cfg.SurrealTimeout())
-		info, err := infoReader.Info(ctx)
-		cancel()
-		if err != nil {
-			slog.Warn("Failed to pre-warm table cache", "error", err)
-		} else {
-			surrealcollectors.PrewarmTableCache(info.AllTables())
-			slog.Info("Table cache pre-warmed", "table_count", len(info.AllTables()))
+	if *generateDashboard || *generateAlerts {
+		if err := generate(cfg); err != nil {
+			slog.Error("Failed to generate observability artifacts", "error", err)
+			os.Exit(1)
 		}
+		return
 	}
 
-	metricsRegistry, err := registry.New(
-		cfg,
-		versionReader,
-		infoReader,
-		recordCountReader,
-		liveQueryProvider,
-		statsTableProvider,
-		tableFilter,
-		statsTableFilter,
-		recordCountFilter,
-	)
+	logCloser, err := logger.Configure(cfg)
+	if err != nil {
+		slog.Error("Failed to configure logging", "error", err)
+		os.Exit(1)
+	}
+
+	tracingShutdown, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		slog.Error("Failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	surrealdb.ConfigureQueryTracing(cfg.TraceQueries(), cfg.SlowQueryThreshold(), logger.Component("query"))
+
+	exp, err := exporter.New(cfg)
 	if err != nil {
-		slog.Error("Failed to initialize registry", "error", err)
+		slog.Error("Failed to initialize exporter", "error", err)
 		os.Exit(1)
 	}
 
-	gatherers := prometheus.Gatherers{metricsRegistry}
+	gatherers := prometheus.Gatherers{exp.Metrics}
+	if cfg.TelemetryPort() == 0 {
+		gatherers = append(gatherers, exp.Telemetry)
+	}
 
+	otlpStatus := api.OTLPStatus{Enabled: cfg.OTLPReceiverEnabled()}
 	var otlpShutdown func()
 	if cfg.OTLPReceiverEnabled() {
 		var otlpRegistry *prometheus.Registry
-		otlpRegistry, otlpShutdown = startOTLPReceiver(cfg)
+		otlpRegistry, otlpStatus.Listening, otlpShutdown = startOTLPReceiver(cfg, exp.MetricNames)
+		otlpStatus.Endpoint = cfg.OTLPGRPCEndpoint()
+		otlpStatus.Logs = cfg.OTLPLogsEnabled()
+		otlpStatus.Traces = cfg.OTLPTracesEnabled()
 		gatherers = append(gatherers, otlpRegistry)
 	}
 
-	serverErrChan := make(chan error, 1)
-	go func() {
-		if err := api.StartPrometheusServer(cfg, gatherers); err != nil {
-			serverErrChan <- err
+	gatherer := registry.WithScrapeStats(cfg,
+		registry.WithRenames(cfg, registry.WithLabelSanitization(cfg, gatherers)))
+
+	pushCtx, pushCancel := context.WithCancel(context.Background())
+	pushDone := make(chan struct{})
+	if cfg.PushEnabled() {
+		go func() {
+			defer close(pushDone)
+			api.StartPusher(pushCtx, cfg, gatherer)
+		}()
+	} else {
+		close(pushDone)
+	}
+
+	var routes []api.Route
+	if cfg.JSONAPIEnabled() {
+		routes = append(routes, api.Route{
+			Pattern:       api.JSONMetricsPath,
+			Handler:       api.NewJSONMetricsHandler(exp.JSONSources(), cfg.SurrealTimeout()),
+			AllNamespaces: true,
+		})
+	}
+
+	if cfg.StatusAPIEnabled() {
+		sources := exp.StatusSources()
+		sources.OTLP = otlpStatus
+		if scrapes, ok := gatherer.(api.ScrapeStatusProvider); ok {
+			sources.Scrapes = scrapes
 		}
-	}()
+
+		routes = append(routes, api.Route{
+			Pattern:       api.StatusPath,
+			Handler:       api.NewStatusHandler(sources, cfg.SurrealTimeout()),
+			AllNamespaces: true,
+		})
+	}
+
+	if exp.Pauses != nil {
+		routes = append(routes, api.Route{
+			Pattern: api.AdminCollectorPattern,
+			Handler: api.NewAdminCollectorHandler(exp.Pauses, cfg.AdminToken()),
+		})
+	}
+
+	if cfg.NamespaceEndpointsEnabled() {
+		routes = append(routes, api.Route{
+			Pattern: api.NamespaceMetricsPattern(cfg.MetricsPath()),
+			Handler: api.NewNamespaceMetricsHandler(
+				cfg,
+				cfg.NamespaceEndpointPatterns(),
+				exp.NamespaceExists,
+				exp.NamespaceGatherer,
+			),
+		})
+	}
+
+	if cfg.StatsTableEnabled() && cfg.StatsTableDryRun() {
+		routes = append(routes, api.Route{
+			Pattern:       api.StatsTablePlanPath,
+			Handler:       api.NewStatsTablePlanHandler(exp.StatsTable),
+			AllNamespaces: true,
+		})
+	}
+
+	if cfg.TelemetryPort() != 0 {
+		go func() {
+			if err := api.StartTelemetryServer(cfg, registry.WithRenames(cfg, exp.Telemetry)); err != nil {
+				slog.Error("Telemetry server failed", "error", err)
+			}
+		}()
+	} else if cfg.DebugPprofEnabled() {
+		go func() {
+			if err := api.StartDebugServer(cfg); err != nil {
+				slog.Error("Debug server failed", "error", err)
+			}
+		}()
+	}
+
+	server, err := api.NewPrometheusServer(cfg, gatherer, routes...)
+	if err != nil {
+		slog.Error("Failed to create HTTP server", "error", err)
+		os.Exit(1)
+	}
+
+	serverErrChan := make(chan error, 1)
+	if !cfg.PushOnly() {
+		slog.Info("Starting SurrealDB exporter", "address", server.Addr, "metrics_path", cfg.MetricsPath())
+
+		go func() {
+			if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				serverErrChan <- err
+			}
+		}()
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
@@ -127,36 +266,165 @@ func main() {
 		slog.Info("Received shutdown signal", "signal", sig)
 	}
 
+	// Scrapes in flight are drained before the live queries are stopped and the
+	// connections closed, so they finish against open connections.
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), serverShutdownTimeout)
+	if err := server.Shutdown(drainCtx); err != nil {
+		slog.Error("Failed to drain HTTP server", "error", err)
+	}
+	drainCancel()
+
+	pushCancel()
+	<-pushDone
+
+	exp.Close()
+
 	if otlpShutdown != nil {
 		otlpShutdown()
 	}
 
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.SurrealTimeout())
+	if err := tracingShutdown(shutdownCtx); err != nil {
+		slog.Error("Failed to flush traces", "error", err)
+	}
+	shutdownCancel()
+
 	slog.Info("Exporter shutdown complete")
+
+	if err := logCloser.Close(); err != nil {
+		slog.Error("Failed to close log output", "error", err)
+	}
 }
 
-// startOTLPReceiver starts the OTLP gRPC receiver and returns the registry.
-func startOTLPReceiver(cfg config.Config) (*prometheus.Registry, func()) {
-	slog.Info("Starting OpenTelemetry collector")
+// checkConnectivity connects and authenticates to SurrealDB using the loaded configuration.
+func checkConnectivity(cfg surrealdb.Config) error {
+	versionReader, err := surrealdb.NewVersionReader(surrealdb.NewMultiConnectionManager(cfg, nil))
+	if err != nil {
+		return err
+	}
 
-	otlpRegistry := prometheus.NewRegistry()
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.SurrealTimeout())
+	defer cancel()
 
-	conv := converter.NewConverter(cfg, otlpRegistry)
+	version, err := versionReader.Version(ctx)
+	if err != nil {
+		return err
+	}
 
-	var proc processor.Processor
-	if cfg.OTLPBatchingEnabled() {
-		batchTimeout := time.Duration(cfg.OTLPBatchTimeoutMs()) * time.Millisecond
-		proc = processor.NewBatchProcessor(conv, cfg.OTLPBatchSize(), batchTimeout)
-	} else {
-		proc = processor.NewDirectProcessor(conv)
+	slog.Info("Configuration is valid and SurrealDB is reachable",
+		"url", cfg.SurrealURL(),
+		"version", version,
+	)
+
+	return nil
+}
+
+// checkHealth checks the /healthz endpoint of the exporter running on this host: on the
+// metrics port, or, when the exporter only pushes, on the telemetry port. Exporters only
+// pushing without a telemetry port serve no HTTP, so SurrealDB connectivity is checked.
+func checkHealth(cfg interface {
+	api.Config
+	surrealdb.Config
+	PushOnly() bool
+	TelemetryPort() int
+}) error {
+	port := cfg.Port()
+	if cfg.PushOnly() {
+		if cfg.TelemetryPort() == 0 {
+			return checkConnectivity(cfg)
+		}
+		port = cfg.TelemetryPort()
+	}
+
+	client := http.Client{Timeout: healthcheckTimeout}
+
+	resp, err := client.Get(fmt.Sprintf("http://127.0.0.1:%d%s", port, api.HealthzPath))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", api.HealthzPath, resp.Status)
+	}
+
+	return nil
+}
+
+// generate writes the requested dashboard and alerting rules to stdout.
+func generate(cfg generator.Config) error {
+	if *generateDashboard {
+		if err := generator.WriteDashboard(os.Stdout, cfg); err != nil {
+			return err
+		}
 	}
 
-	grpcServer := grpc.NewServer(
-		grpc.MaxRecvMsgSize(cfg.OTLPMaxRecvSize() * 1024 * 1024),
+	if *generateAlerts {
+		if err := generator.WriteAlerts(os.Stdout, cfg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// startOTLPReceiver starts the OTLP gRPC receiver and returns the registry and whether the
+// receiver is listening. OTLP metrics colliding with reserved names are skipped.
+func startOTLPReceiver(cfg config.Config, reserved converter.ReservedNames) (*prometheus.Registry, bool, func()) {
+	slog.Info("Starting OpenTelemetry collector")
+
+	otlpRegistry := prometheus.NewRegistry()
+	otlpLogger := logger.Component("otlp")
+
+	conv := converter.NewConverter(cfg, otlpRegistry, reserved, otlpLogger)
+
+	proc, err := processor.NewPipeline(
+		conv,
+		cfg.OTLPPipeline(),
+		cfg.OTLPBatchSize(),
+		time.Duration(cfg.OTLPBatchTimeoutMs())*time.Millisecond,
+		cfg.OTLPQueueLimits(),
+		otlpRegistry,
+		prometheus.Labels{
+			"cluster":         cfg.ClusterName(),
+			"storage_engine":  cfg.StorageEngine(),
+			"deployment_mode": cfg.DeploymentMode(),
+		},
+		otlpLogger,
 	)
-	otlpGRPC := api.NewOTELGRPCServer(proc)
+	if err != nil {
+		slog.Error("Failed to build OTLP processing pipeline", "error", err)
+		os.Exit(1)
+	}
+
+	grpcOptions, err := api.GRPCServerOptions(cfg)
+	if err != nil {
+		slog.Error("Failed to configure gRPC receiver", "error", err)
+		os.Exit(1)
+	}
+
+	grpcServer := grpc.NewServer(grpcOptions...)
+	otlpGRPC := api.NewOTELGRPCServer(proc, otlpLogger)
 	otlpGRPC.RegisterWith(grpcServer)
 
+	if cfg.OTLPLogsEnabled() {
+		logMetrics := converter.NewLogMetrics(cfg, otlpRegistry,
+			cfg.OTLPSlowQueryLogPattern(), cfg.OTLPAuthFailureLogPattern())
+		api.NewOTELLogsGRPCServer(logMetrics, otlpLogger).RegisterWith(grpcServer)
+	}
+
+	if cfg.OTLPTracesEnabled() {
+		traceMetrics := converter.NewTraceMetrics(cfg, otlpRegistry,
+			cfg.OTLPTraceMethodAttributes(), cfg.OTLPTraceDurationBuckets())
+		api.NewOTELTracesGRPCServer(traceMetrics, otlpLogger).RegisterWith(grpcServer)
+	}
+
+	if cfg.OTLPGRPCReflection() {
+		reflection.Register(grpcServer)
+	}
+
 	lis, err := net.Listen("tcp", cfg.OTLPGRPCEndpoint())
+	listening := err == nil
 	if err != nil {
 		slog.Error("Failed to listen on gRPC endpoint", "error", err, "endpoint", cfg.OTLPGRPCEndpoint())
 	} else {
@@ -168,15 +436,15 @@ func startOTLPReceiver(cfg config.Config) (*prometheus.Registry, func()) {
 		}()
 	}
 
-	return otlpRegistry, func() {
+	return otlpRegistry, listening, func() {
 		slog.Info("Shutting down OpenTelemetry collector")
 
+		// GracefulStop waits for in-flight exports, so every accepted batch is queued
+		// before the processor is stopped and flushed.
 		grpcServer.GracefulStop()
 
-		if batchProc, ok := proc.(*processor.BatchProcessor); ok {
-			if err := batchProc.Flush(); err != nil {
-				slog.Error("Error flushing batch processor", "error", err)
-			}
+		if err := proc.Stop(); err != nil {
+			slog.Error("Error flushing batch processor", "error", err)
 		}
 
 		slog.Info("OpenTelemetry collector shutdown complete")
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// AdminCollectorPattern is the pattern of the endpoints pausing and resuming a collector,
+// e.g. POST /api/v1/admin/collectors/record_count/pause.
+const AdminCollectorPattern = "/api/v1/admin/collectors/{name}/{action}"
+
+// CollectorPauser pauses and resumes collectors by name.
+type CollectorPauser interface {
+	Pause(name string) bool
+	Resume(name string) bool
+	PausedCollectors() []string
+}
+
+// AdminCollectorResponse is the body returned by the admin collector endpoints.
+type AdminCollectorResponse struct {
+	Collector        string   `json:"collector"`
+	Paused           bool     `json:"paused"`
+	PausedCollectors []string `json:"paused_collectors"`
+}
+
+// AdminCollectorHandler pauses and resumes collectors for requests presenting the admin
+// token, so heavy collectors can be stopped during an incident without a restart.
+type AdminCollectorHandler struct {
+	pauser CollectorPauser
+	token  string
+}
+
+// NewAdminCollectorHandler creates a handler accepting requests with token as bearer
+// token.
+func NewAdminCollectorHandler(pauser CollectorPauser, token string) *AdminCollectorHandler {
+	return &AdminCollectorHandler{
+		pauser: pauser,
+		token:  token,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *AdminCollectorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		slog.Warn("Admin request with missing or invalid token", "remote_addr", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	name := r.PathValue("name")
+
+	var found bool
+	switch r.PathValue("action") {
+	case "pause":
+		found = h.pauser.Pause(name)
+	case "resume":
+		found = h.pauser.Resume(name)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if !found {
+		http.Error(w, "unknown or unpausable collector", http.StatusNotFound)
+		return
+	}
+
+	paused := h.pauser.PausedCollectors()
+	slog.Warn("Collector state changed through the admin endpoint",
+		"collector", name,
+		"action", r.PathValue("action"),
+		"paused_collectors", paused,
+		"remote_addr", r.RemoteAddr)
+
+	resp := AdminCollectorResponse{
+		Collector:        name,
+		Paused:           slices.Contains(paused, name),
+		PausedCollectors: paused,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("failed to encode admin response", "error", err)
+	}
+}
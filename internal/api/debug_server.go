@@ -0,0 +1,75 @@
+package api
+
+import (
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type DebugConfig interface {
+	DebugPort() int
+}
+
+type TelemetryConfig interface {
+	TelemetryPort() int
+	MetricsPath() string
+	DebugPprofEnabled() bool
+	Compression() bool
+}
+
+// StartDebugServer serves net/http/pprof profiles and expvar variables on a separate port,
+// so they are never exposed next to the metrics endpoint.
+func StartDebugServer(cfg DebugConfig) error {
+	mux := http.NewServeMux()
+	handleDebug(mux)
+
+	listenAddress := fmt.Sprintf(":%d", cfg.DebugPort())
+
+	slog.Info("Starting debug server", "address", listenAddress)
+
+	return http.ListenAndServe(listenAddress, mux)
+}
+
+// StartTelemetryServer serves the exporter's own metrics from gatherer, the health check,
+// and the debug endpoints when pprof is enabled, on the telemetry port.
+func StartTelemetryServer(cfg TelemetryConfig, gatherer prometheus.Gatherer) error {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(HealthzPath, serveHealthz)
+
+	mux.Handle(cfg.MetricsPath(), promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		ErrorHandling:       promhttp.ContinueOnError,
+		ErrorLog:            slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+		DisableCompression:  !cfg.Compression(),
+		OfferedCompressions: []promhttp.Compression{promhttp.Identity, promhttp.Gzip},
+	}))
+
+	if cfg.DebugPprofEnabled() {
+		handleDebug(mux)
+	}
+
+	listenAddress := fmt.Sprintf(":%d", cfg.TelemetryPort())
+
+	slog.Info("Starting telemetry server",
+		"address", listenAddress,
+		"metrics_path", cfg.MetricsPath(),
+		"debug", cfg.DebugPprofEnabled(),
+	)
+
+	return http.ListenAndServe(listenAddress, mux)
+}
+
+// handleDebug registers the net/http/pprof and expvar handlers on mux.
+func handleDebug(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/klauspost/compress/zstd"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/keepalive"
+)
+
+type GRPCConfig interface {
+	OTLPMaxRecvSize() int
+	OTLPGRPCMaxConcurrentStreams() uint32
+	OTLPGRPCCompression() []string
+	OTLPGRPCKeepalive() domain.GRPCKeepalive
+}
+
+// GRPCServerOptions returns the OTLP receiver server options and registers the
+// configured request compressors. It must be called before the server is created.
+// gzip is registered by gRPC itself and always accepted.
+func GRPCServerOptions(cfg GRPCConfig) ([]grpc.ServerOption, error) {
+	for _, name := range cfg.OTLPGRPCCompression() {
+		switch name {
+		case gzip.Name:
+		case "zstd":
+			encoding.RegisterCompressor(&zstdCompressor{})
+		default:
+			return nil, fmt.Errorf("unsupported gRPC compression %q", name)
+		}
+	}
+
+	ka := cfg.OTLPGRPCKeepalive()
+
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.OTLPMaxRecvSize() * 1024 * 1024),
+		grpc.KeepaliveParams(keepalive.ServerParameters{
+			Time:                  ka.Time,
+			Timeout:               ka.Timeout,
+			MaxConnectionIdle:     ka.MaxConnectionIdle,
+			MaxConnectionAge:      ka.MaxConnectionAge,
+			MaxConnectionAgeGrace: ka.MaxConnectionAgeGrace,
+		}),
+		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+			MinTime:             ka.MinTime,
+			PermitWithoutStream: ka.PermitWithoutStream,
+		}),
+	}
+
+	if streams := cfg.OTLPGRPCMaxConcurrentStreams(); streams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(streams))
+	}
+
+	return opts, nil
+}
+
+// zstdCompressor implements the gRPC zstd encoding with pooled encoders and decoders.
+type zstdCompressor struct {
+	encoders sync.Pool
+	decoders sync.Pool
+}
+
+func (c *zstdCompressor) Name() string { return "zstd" }
+
+func (c *zstdCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	if enc, ok := c.encoders.Get().(*zstd.Encoder); ok {
+		enc.Reset(w)
+		return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+	}
+
+	enc, err := zstd.NewWriter(w, zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+
+	return &zstdWriter{Encoder: enc, pool: &c.encoders}, nil
+}
+
+func (c *zstdCompressor) Decompress(r io.Reader) (io.Reader, error) {
+	dec, ok := c.decoders.Get().(*zstd.Decoder)
+	if !ok {
+		var err error
+		dec, err = zstd.NewReader(r, zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, err
+		}
+	} else if err := dec.Reset(r); err != nil {
+		c.decoders.Put(dec)
+		return nil, err
+	}
+
+	return &zstdReader{Decoder: dec, pool: &c.decoders}, nil
+}
+
+// zstdWriter returns its encoder to the pool once closed.
+type zstdWriter struct {
+	*zstd.Encoder
+	pool *sync.Pool
+}
+
+func (w *zstdWriter) Close() error {
+	err := w.Encoder.Close()
+	w.pool.Put(w.Encoder)
+
+	return err
+}
+
+// zstdReader returns its decoder to the pool once the stream is fully read.
+type zstdReader struct {
+	*zstd.Decoder
+	pool *sync.Pool
+}
+
+func (r *zstdReader) Read(p []byte) (int, error) {
+	if r.Decoder == nil {
+		return 0, io.EOF
+	}
+
+	n, err := r.Decoder.Read(p)
+	if errors.Is(err, io.EOF) {
+		r.pool.Put(r.Decoder)
+		r.Decoder = nil
+	}
+
+	return n, err
+}
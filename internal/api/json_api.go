@@ -0,0 +1,188 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// JSONMetricsPath is the path of the structured JSON metrics endpoint.
+const JSONMetricsPath = "/api/v1/metrics"
+
+type InfoReader interface {
+	Info(ctx context.Context) (*domain.SurrealDBInfo, error)
+}
+
+type RecordCountReader interface {
+	RecordCount(ctx context.Context, tables []*domain.TableInfo) (*domain.RecordCountMetrics, error)
+}
+
+type LiveQueryTotalsProvider interface {
+	LiveQueryTotals() []*domain.TableOperationMetrics
+}
+
+type StatsTableInfoProvider interface {
+	StatsTableInfo(tableIDs []domain.TableIdentifier) ([]*domain.StatsTableData, error)
+}
+
+type TableFilter interface {
+	FilterTables(tables []*domain.TableInfo) []domain.TableIdentifier
+}
+
+// JSONSources holds the readers used by the JSON metrics endpoint.
+// Readers of disabled collectors are left nil and their sections are omitted.
+type JSONSources struct {
+	Info InfoReader
+
+	RecordCount       RecordCountReader
+	RecordCountFilter TableFilter
+
+	LiveQuery LiveQueryTotalsProvider
+
+	StatsTable       StatsTableInfoProvider
+	StatsTableFilter TableFilter
+}
+
+// JSONMetricsResponse is the body returned by the JSON metrics endpoint.
+type JSONMetricsResponse struct {
+	CollectedAt     time.Time                       `json:"collected_at"`
+	DurationSeconds float64                         `json:"duration_seconds"`
+	Info            *domain.SurrealDBInfo           `json:"info,omitempty"`
+	RecordCounts    []*domain.TableRecordCount      `json:"record_counts,omitempty"`
+	LiveQuery       []*domain.TableOperationMetrics `json:"live_query,omitempty"`
+	StatsTables     []*domain.StatsTableData        `json:"stats_tables,omitempty"`
+	Errors          map[string]string               `json:"errors,omitempty"`
+}
+
+// JSONMetricsHandler serves domain-level metrics as structured JSON so that
+// non-Prometheus consumers can reuse the exporter's collection logic.
+type JSONMetricsHandler struct {
+	sources JSONSources
+	timeout time.Duration
+}
+
+// NewJSONMetricsHandler creates a new JSON metrics handler.
+func NewJSONMetricsHandler(sources JSONSources, timeout time.Duration) *JSONMetricsHandler {
+	return &JSONMetricsHandler{
+		sources: sources,
+		timeout: timeout,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *JSONMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	resp := h.collect(ctx)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	status := http.StatusOK
+	if resp.Info == nil {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("failed to encode JSON metrics response", "error", err)
+	}
+}
+
+// collect gathers all enabled sections. Failures of individual sections are
+// reported in the Errors map instead of failing the whole response.
+func (h *JSONMetricsHandler) collect(ctx context.Context) *JSONMetricsResponse {
+	start := time.Now()
+
+	resp := &JSONMetricsResponse{
+		CollectedAt: start,
+		Errors:      make(map[string]string),
+	}
+
+	info, err := h.sources.Info.Info(ctx)
+	if err != nil {
+		resp.Errors["info"] = err.Error()
+	} else {
+		resp.Info = info
+	}
+
+	var tables []*domain.TableInfo
+	if info != nil {
+		tables = info.AllTables()
+	}
+
+	if h.sources.RecordCount != nil && len(tables) > 0 {
+		counts, err := h.sources.RecordCount.RecordCount(ctx, filterTableInfos(tables, h.sources.RecordCountFilter))
+		if err != nil {
+			resp.Errors["record_counts"] = err.Error()
+		} else {
+			resp.RecordCounts = counts.Tables
+		}
+	}
+
+	if h.sources.LiveQuery != nil {
+		resp.LiveQuery = h.sources.LiveQuery.LiveQueryTotals()
+	}
+
+	if h.sources.StatsTable != nil && len(tables) > 0 {
+		tableIDs := filterTableIDs(tables, h.sources.StatsTableFilter)
+
+		stats, err := h.sources.StatsTable.StatsTableInfo(tableIDs)
+		if err != nil {
+			resp.Errors["stats_tables"] = err.Error()
+		} else {
+			resp.StatsTables = stats
+		}
+	}
+
+	resp.DurationSeconds = time.Since(start).Seconds()
+
+	return resp
+}
+
+// filterTableIDs returns identifiers of tables accepted by filter, or of all tables when filter is nil.
+func filterTableIDs(tables []*domain.TableInfo, filter TableFilter) []domain.TableIdentifier {
+	if filter != nil {
+		return filter.FilterTables(tables)
+	}
+
+	ids := make([]domain.TableIdentifier, 0, len(tables))
+	for _, t := range tables {
+		ids = append(ids, domain.TableIdentifier{Namespace: t.Namespace, Database: t.Database, Table: t.Name})
+	}
+
+	return ids
+}
+
+// filterTableInfos returns the tables accepted by filter, or all tables when filter is nil.
+func filterTableInfos(tables []*domain.TableInfo, filter TableFilter) []*domain.TableInfo {
+	if filter == nil {
+		return tables
+	}
+
+	accepted := make(map[string]struct{})
+	for _, id := range filter.FilterTables(tables) {
+		accepted[id.String()] = struct{}{}
+	}
+
+	result := make([]*domain.TableInfo, 0, len(accepted))
+	for _, t := range tables {
+		id := domain.TableIdentifier{Namespace: t.Namespace, Database: t.Database, Table: t.Name}
+		if _, ok := accepted[id.String()]; ok {
+			result = append(result, t)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NamespaceMetricsPattern returns the mux pattern of the per-namespace metrics endpoints
+// below metricsPath.
+func NamespaceMetricsPattern(metricsPath string) string {
+	return strings.TrimSuffix(metricsPath, "/") + "/namespace/{ns}"
+}
+
+// namespaceMetricsHandler serves the metrics of the namespace named in the request path
+// from a gatherer of its own, so each namespace can be scraped on its own interval.
+type namespaceMetricsHandler struct {
+	cfg         Config
+	patterns    []string
+	exists      func(ctx context.Context, namespace string) (bool, error)
+	newGatherer func(namespace string) (prometheus.Gatherer, error)
+
+	mu       sync.Mutex
+	handlers map[string]http.Handler
+}
+
+// NewNamespaceMetricsHandler returns the handler of NamespaceMetricsPattern. A namespace
+// matching one of patterns, for which exists reports true, gets a gatherer built with
+// newGatherer on its first request; other namespaces get 404.
+func NewNamespaceMetricsHandler(
+	cfg Config,
+	patterns []string,
+	exists func(ctx context.Context, namespace string) (bool, error),
+	newGatherer func(namespace string) (prometheus.Gatherer, error),
+) http.Handler {
+	return scrapeHandler(&namespaceMetricsHandler{
+		cfg:         cfg,
+		patterns:    patterns,
+		exists:      exists,
+		newGatherer: newGatherer,
+		handlers:    make(map[string]http.Handler),
+	})
+}
+
+// ServeHTTP implements http.Handler.
+func (h *namespaceMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	namespace := r.PathValue("ns")
+	if !h.allowed(namespace) {
+		http.NotFound(w, r)
+		return
+	}
+
+	handler, ok := h.handler(namespace)
+	if !ok {
+		exists, err := h.exists(r.Context(), namespace)
+		if err != nil {
+			slog.Error("Failed to look up namespace", "namespace", namespace, "error", err)
+			http.Error(w, "failed to look up namespace", http.StatusServiceUnavailable)
+			return
+		}
+
+		if !exists {
+			http.NotFound(w, r)
+			return
+		}
+
+		handler, err = h.addHandler(namespace)
+		if err != nil {
+			slog.Error("Failed to create namespace metrics", "namespace", namespace, "error", err)
+			http.Error(w, "failed to create namespace metrics", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// allowed reports whether namespace matches one of the patterns.
+func (h *namespaceMetricsHandler) allowed(namespace string) bool {
+	if namespace == "" {
+		return false
+	}
+
+	for _, pattern := range h.patterns {
+		if matched, _ := path.Match(pattern, namespace); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handler returns the metrics handler created for namespace.
+func (h *namespaceMetricsHandler) handler(namespace string) (http.Handler, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	handler, ok := h.handlers[namespace]
+	return handler, ok
+}
+
+// addHandler creates the metrics handler of namespace. The gatherer is built without
+// holding the lock, so a slow namespace does not hold up the others; when concurrent
+// requests both build one, the first stored wins.
+func (h *namespaceMetricsHandler) addHandler(namespace string) (http.Handler, error) {
+	gatherer, err := h.newGatherer(namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if handler, ok := h.handlers[namespace]; ok {
+		return handler, nil
+	}
+
+	handler := newScopedMetricsHandler(h.cfg, gatherer)
+	h.handlers[namespace] = handler
+
+	slog.Info("Serving namespace metrics", "namespace", namespace)
+
+	return handler, nil
+}
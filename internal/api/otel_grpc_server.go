@@ -2,23 +2,28 @@ package api
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/processor"
 	"go.opentelemetry.io/collector/pdata/pmetric/pmetricotlp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // OTELGRPCServer implements the OTLP metrics service over gRPC.
 type OTELGRPCServer struct {
 	pmetricotlp.UnimplementedGRPCServer
 	processor processor.Processor
+	logger    *slog.Logger
 }
 
 // NewOTELGRPCServer creates a new gRPC server for OTLP metrics.
-func NewOTELGRPCServer(processor processor.Processor) *OTELGRPCServer {
+func NewOTELGRPCServer(processor processor.Processor, logger *slog.Logger) *OTELGRPCServer {
 	return &OTELGRPCServer{
 		processor: processor,
+		logger:    logger,
 	}
 }
 
@@ -31,12 +36,17 @@ func (s *OTELGRPCServer) Export(
 
 	batch := ConvertPmetricToDomain(metrics)
 
-	slog.Debug("received OTLP metrics via gRPC",
+	s.logger.Debug("received OTLP metrics via gRPC",
 		"metric_count", batch.Count(),
 		"resource_attrs", len(batch.ResourceAttrs))
 
 	if err := s.processor.Process(ctx, batch); err != nil {
-		slog.Error("failed to consume metrics", "error", err)
+		if errors.Is(err, processor.ErrQueueFull) {
+			s.logger.Warn("rejecting OTLP metrics, queue is full", "metric_count", batch.Count())
+			return pmetricotlp.NewExportResponse(), status.Error(codes.Unavailable, err.Error())
+		}
+
+		s.logger.Error("failed to consume metrics", "error", err)
 		return pmetricotlp.NewExportResponse(), err
 	}
 
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"go.opentelemetry.io/collector/pdata/plog/plogotlp"
+	"google.golang.org/grpc"
+)
+
+// LogRecorder derives metrics from log records.
+type LogRecorder interface {
+	Record(records []domain.LogRecord)
+}
+
+// OTELLogsGRPCServer implements the OTLP logs service over gRPC.
+type OTELLogsGRPCServer struct {
+	plogotlp.UnimplementedGRPCServer
+	recorder LogRecorder
+	logger   *slog.Logger
+}
+
+// NewOTELLogsGRPCServer creates a new gRPC server for OTLP logs.
+func NewOTELLogsGRPCServer(recorder LogRecorder, logger *slog.Logger) *OTELLogsGRPCServer {
+	return &OTELLogsGRPCServer{
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+// Export handles the gRPC export request for logs.
+func (s *OTELLogsGRPCServer) Export(
+	_ context.Context,
+	req plogotlp.ExportRequest,
+) (plogotlp.ExportResponse, error) {
+	records := ConvertPlogToDomain(req.Logs())
+
+	s.logger.Debug("received OTLP logs via gRPC", "record_count", len(records))
+
+	s.recorder.Record(records)
+
+	return plogotlp.NewExportResponse(), nil
+}
+
+func (s *OTELLogsGRPCServer) RegisterWith(server *grpc.Server) {
+	plogotlp.RegisterGRPCServer(server, s)
+}
@@ -2,12 +2,16 @@ package api
 
 import (
 	"fmt"
+	"maps"
 	"math"
+	"strings"
 	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 )
 
 // ConvertPmetricToDomain converts OTLP pmetric.Metrics to domain.MetricBatch.
@@ -23,10 +27,9 @@ func ConvertPmetricToDomain(md pmetric.Metrics) domain.MetricBatch {
 		rm := rms.At(i)
 		resource := rm.Resource()
 
-		resource.Attributes().Range(func(k string, v pcommon.Value) bool {
-			batch.ResourceAttrs[k] = v.AsString()
-			return true
-		})
+		resourceAttrs := extractLabels(resource.Attributes())
+		maps.Copy(batch.ResourceAttrs, resourceAttrs)
+		first := len(batch.Metrics)
 
 		ilms := rm.ScopeMetrics()
 		for j := 0; j < ilms.Len(); j++ {
@@ -43,11 +46,17 @@ func ConvertPmetricToDomain(md pmetric.Metrics) domain.MetricBatch {
 					batch.Metrics = append(batch.Metrics, convertSum(metric)...)
 				case pmetric.MetricTypeHistogram:
 					batch.Metrics = append(batch.Metrics, convertHistogram(metric)...)
+				case pmetric.MetricTypeExponentialHistogram:
+					batch.Metrics = append(batch.Metrics, convertExponentialHistogram(metric)...)
 				case pmetric.MetricTypeSummary:
 					batch.Metrics = append(batch.Metrics, convertSummary(metric)...)
 				}
 			}
 		}
+
+		for j := first; j < len(batch.Metrics); j++ {
+			batch.Metrics[j].Resource = resourceAttrs
+		}
 	}
 
 	return batch
@@ -171,6 +180,67 @@ func convertHistogram(metric pmetric.Metric) []domain.Metric {
 	return metrics
 }
 
+// convertExponentialHistogram converts OTLP exponential histogram metrics to domain metrics.
+// Classic buckets are derived from the positive bucket bounds; negative and zero
+// observations fall into every bucket.
+func convertExponentialHistogram(metric pmetric.Metric) []domain.Metric {
+	var metrics []domain.Metric
+	hist := metric.ExponentialHistogram()
+
+	for i := 0; i < hist.DataPoints().Len(); i++ {
+		dp := hist.DataPoints().At(i)
+
+		exp := &domain.ExponentialHistogramData{
+			Scale:          dp.Scale(),
+			ZeroCount:      dp.ZeroCount(),
+			ZeroThreshold:  dp.ZeroThreshold(),
+			PositiveOffset: dp.Positive().Offset(),
+			PositiveCounts: dp.Positive().BucketCounts().AsRaw(),
+			NegativeOffset: dp.Negative().Offset(),
+			NegativeCounts: dp.Negative().BucketCounts().AsRaw(),
+		}
+
+		histData := &domain.HistogramData{
+			Count:       dp.Count(),
+			Sum:         dp.Sum(),
+			Buckets:     make([]domain.HistogramBucket, 0, len(exp.PositiveCounts)+1),
+			CreatedTime: dp.StartTimestamp().AsTime(),
+			Exponential: exp,
+		}
+
+		cumulativeCount := exp.ZeroCount
+		for _, count := range exp.NegativeCounts {
+			cumulativeCount += count
+		}
+
+		base := math.Exp2(math.Exp2(-float64(exp.Scale)))
+		for j, count := range exp.PositiveCounts {
+			cumulativeCount += count
+			histData.Buckets = append(histData.Buckets, domain.HistogramBucket{
+				UpperBound: math.Pow(base, float64(int(exp.PositiveOffset)+j+1)),
+				Count:      cumulativeCount,
+			})
+		}
+
+		histData.Buckets = append(histData.Buckets, domain.HistogramBucket{
+			UpperBound: math.Inf(1),
+			Count:      dp.Count(),
+		})
+
+		metrics = append(metrics, domain.Metric{
+			Name:          metric.Name(),
+			Type:          domain.MetricTypeHistogram,
+			Description:   metric.Description(),
+			Unit:          metric.Unit(),
+			Labels:        extractLabels(dp.Attributes()),
+			Timestamp:     dp.Timestamp().AsTime(),
+			HistogramData: histData,
+		})
+	}
+
+	return metrics
+}
+
 // convertSummary converts OTLP summary metrics to domain metrics.
 func convertSummary(metric pmetric.Metric) []domain.Metric {
 	var metrics []domain.Metric
@@ -223,6 +293,97 @@ func convertSummary(metric pmetric.Metric) []domain.Metric {
 	return metrics
 }
 
+// ConvertPlogToDomain converts OTLP plog.Logs to domain log records.
+// The target is the instrumentation scope name, or the "target" attribute when the scope
+// is unnamed; the level is the severity text, or derived from the severity number.
+func ConvertPlogToDomain(ld plog.Logs) []domain.LogRecord {
+	var records []domain.LogRecord
+
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			sl := sls.At(j)
+
+			lrs := sl.LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+
+				target := sl.Scope().Name()
+				if target == "" {
+					if v, ok := lr.Attributes().Get("target"); ok {
+						target = v.AsString()
+					}
+				}
+
+				records = append(records, domain.LogRecord{
+					Level:      logLevel(lr),
+					Target:     target,
+					Body:       lr.Body().AsString(),
+					Attributes: extractLabels(lr.Attributes()),
+				})
+			}
+		}
+	}
+
+	return records
+}
+
+// ConvertPtraceToDomain converts OTLP ptrace.Traces to span records.
+func ConvertPtraceToDomain(td ptrace.Traces) []domain.SpanRecord {
+	var spans []domain.SpanRecord
+
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			ss := sss.At(j).Spans()
+			for k := 0; k < ss.Len(); k++ {
+				span := ss.At(k)
+
+				var duration time.Duration
+				if end, start := span.EndTimestamp(), span.StartTimestamp(); end > start {
+					duration = end.AsTime().Sub(start.AsTime())
+				}
+
+				spans = append(spans, domain.SpanRecord{
+					Name:       span.Name(),
+					Kind:       strings.ToLower(span.Kind().String()),
+					Error:      span.Status().Code() == ptrace.StatusCodeError,
+					Duration:   duration,
+					Attributes: extractLabels(span.Attributes()),
+				})
+			}
+		}
+	}
+
+	return spans
+}
+
+// logLevel returns the lowercase level of a log record.
+func logLevel(lr plog.LogRecord) string {
+	if text := lr.SeverityText(); text != "" {
+		return strings.ToLower(text)
+	}
+
+	switch n := lr.SeverityNumber(); {
+	case n >= plog.SeverityNumberFatal:
+		return "fatal"
+	case n >= plog.SeverityNumberError:
+		return "error"
+	case n >= plog.SeverityNumberWarn:
+		return "warn"
+	case n >= plog.SeverityNumberInfo:
+		return "info"
+	case n >= plog.SeverityNumberDebug:
+		return "debug"
+	case n >= plog.SeverityNumberTrace:
+		return "trace"
+	default:
+		return "unknown"
+	}
+}
+
 // extractLabels extracts labels from OTLP attributes.
 func extractLabels(attrs pcommon.Map) map[string]string {
 	labels := make(map[string]string)
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"go.opentelemetry.io/collector/pdata/ptrace/ptraceotlp"
+	"google.golang.org/grpc"
+)
+
+// SpanRecorder derives metrics from spans.
+type SpanRecorder interface {
+	Record(spans []domain.SpanRecord)
+}
+
+// OTELTracesGRPCServer implements the OTLP traces service over gRPC.
+type OTELTracesGRPCServer struct {
+	ptraceotlp.UnimplementedGRPCServer
+	recorder SpanRecorder
+	logger   *slog.Logger
+}
+
+// NewOTELTracesGRPCServer creates a new gRPC server for OTLP traces.
+func NewOTELTracesGRPCServer(recorder SpanRecorder, logger *slog.Logger) *OTELTracesGRPCServer {
+	return &OTELTracesGRPCServer{
+		recorder: recorder,
+		logger:   logger,
+	}
+}
+
+// Export handles the gRPC export request for traces.
+func (s *OTELTracesGRPCServer) Export(
+	_ context.Context,
+	req ptraceotlp.ExportRequest,
+) (ptraceotlp.ExportResponse, error) {
+	spans := ConvertPtraceToDomain(req.Traces())
+
+	s.logger.Debug("received OTLP traces via gRPC", "span_count", len(spans))
+
+	s.recorder.Record(spans)
+
+	return ptraceotlp.NewExportResponse(), nil
+}
+
+func (s *OTELTracesGRPCServer) RegisterWith(server *grpc.Server) {
+	ptraceotlp.RegisterGRPCServer(server, s)
+}
@@ -5,15 +5,41 @@ import (
 	"html/template"
 	"log/slog"
 	"net/http"
+	"time"
 
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 	"github.com/asaphin/surrealdb-prometheus-exporter/static"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// HealthzPath serves 200 OK while the exporter is running.
+const HealthzPath = "/healthz"
+
 type Config interface {
 	Port() int
 	MetricsPath() string
+	Compression() bool
+	CacheTTL() time.Duration
+	Tenants() []domain.Tenant
+	TenantsAllowAnonymous() bool
+	AdminToken() string
+	OverlappingScrapes() string
+}
+
+// responseObserver is implemented by gatherers that record the size of the metrics
+// responses sent for them.
+type responseObserver interface {
+	ObserveResponse(encoding string, size int)
+}
+
+// Route is an additional HTTP handler served next to the metrics endpoint.
+type Route struct {
+	Pattern string
+	Handler http.Handler
+	// AllNamespaces marks handlers whose responses cover every namespace. When tenants
+	// are configured, they are only served to requests presenting the admin token.
+	AllNamespaces bool
 }
 
 type PageData struct {
@@ -21,19 +47,28 @@ type PageData struct {
 	EnabledCollectorsHTML template.HTML
 }
 
-func StartPrometheusServer(cfg Config, registry prometheus.Gatherer) error {
+// NewPrometheusServer creates the HTTP server of the metrics endpoint, the health check and
+// routes on the configured port. Shutting it down drains the scrapes in flight.
+func NewPrometheusServer(cfg Config, registry prometheus.Gatherer, routes ...Route) (*http.Server, error) {
 	indexTmpl, err := template.ParseFS(static.Files, "index.html")
 	if err != nil {
 		slog.Error("unable to parse templates", "error", err)
-		return fmt.Errorf("parse template: %w", err)
+		return nil, fmt.Errorf("parse template: %w", err)
 	}
 
 	mux := http.NewServeMux()
 
-	mux.Handle(cfg.MetricsPath(), promhttp.HandlerFor(registry, promhttp.HandlerOpts{
-		ErrorHandling: promhttp.ContinueOnError,
-		ErrorLog:      slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
-	}))
+	mux.Handle(cfg.MetricsPath(), traceHandler("scrape", scrapeHandler(newScopedMetricsHandler(cfg, registry))))
+
+	mux.HandleFunc(HealthzPath, serveHealthz)
+
+	for _, route := range routes {
+		handler := route.Handler
+		if route.AllNamespaces && len(cfg.Tenants()) > 0 {
+			handler = newTenantAdminGuard(handler, cfg.AdminToken(), cfg.TenantsAllowAnonymous())
+		}
+		mux.Handle(route.Pattern, traceHandler(route.Pattern, handler))
+	}
 
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html; charset=utf-8")
@@ -50,13 +85,77 @@ func StartPrometheusServer(cfg Config, registry prometheus.Gatherer) error {
 		}
 	})
 
-	listenAddress := fmt.Sprintf(":%d", cfg.Port())
+	return &http.Server{Addr: fmt.Sprintf(":%d", cfg.Port()), Handler: mux}, nil
+}
+
+// serveHealthz answers the health check of a running exporter.
+func serveHealthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = w.Write([]byte("ok\n"))
+}
+
+// newScopedMetricsHandler serves the metrics of gatherer, scoped to the namespaces of the
+// tenant whose token a request presents when tenants are configured. Requests overlapping
+// a running collection are handled according to the overlapping scrapes setting.
+func newScopedMetricsHandler(cfg Config, gatherer prometheus.Gatherer) http.Handler {
+	newHandler := func(gatherer prometheus.Gatherer) http.Handler {
+		return newMetricsHandler(cfg, gatherer)
+	}
+
+	handler := newHandler(gatherer)
+	if tenants := cfg.Tenants(); len(tenants) > 0 {
+		var anonymous http.Handler
+		if cfg.TenantsAllowAnonymous() {
+			anonymous = handler
+		}
+		handler = newTenantHandler(tenants, gatherer, anonymous, newHandler)
+	}
+
+	return newScrapeGuard(handler, cfg.OverlappingScrapes())
+}
+
+// newMetricsHandler serves the metrics of gatherer, cached for the configured TTL.
+func newMetricsHandler(cfg Config, gatherer prometheus.Gatherer) http.Handler {
+	var handler http.Handler = promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		ErrorHandling:       promhttp.ContinueOnError,
+		ErrorLog:            slog.NewLogLogger(slog.Default().Handler(), slog.LevelError),
+		DisableCompression:  !cfg.Compression(),
+		OfferedCompressions: []promhttp.Compression{promhttp.Identity, promhttp.Gzip},
+	})
+	if observer, ok := gatherer.(responseObserver); ok {
+		handler = observeResponseSize(handler, observer)
+	}
+	if ttl := cfg.CacheTTL(); ttl > 0 {
+		handler = newResponseCache(handler, ttl)
+	}
+
+	return handler
+}
+
+// observeResponseSize reports the number of bytes next writes per response to observer.
+func observeResponseSize(next http.Handler, observer responseObserver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter := &countingResponseWriter{ResponseWriter: w}
+		next.ServeHTTP(counter, r)
+
+		encoding := w.Header().Get("Content-Encoding")
+		if encoding == "" {
+			encoding = string(promhttp.Identity)
+		}
 
-	slog.Info("Starting SurrealDB exporter",
-		"address", listenAddress,
-		"metrics_path", cfg.MetricsPath(),
-		"enabled_collectors", 1,
-	)
+		observer.ObserveResponse(encoding, counter.written)
+	})
+}
+
+// countingResponseWriter counts the body bytes written through it.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	written int
+}
 
-	return http.ListenAndServe(listenAddress, mux)
+// Write implements http.ResponseWriter.
+func (w *countingResponseWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.written += n
+	return n, err
 }
@@ -0,0 +1,70 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/push"
+)
+
+type PushConfig interface {
+	PushURL() string
+	PushJob() string
+	PushInterval() time.Duration
+	PushGrouping() map[string]string
+	PushUsername() string
+	PushPassword() string
+	PushDeleteOnShutdown() bool
+}
+
+// StartPusher periodically pushes gathered metrics to a Prometheus Pushgateway
+// (or any compatible aggregation gateway) until ctx is canceled.
+func StartPusher(ctx context.Context, cfg PushConfig, gatherer prometheus.Gatherer) {
+	pusher := push.New(cfg.PushURL(), cfg.PushJob()).Gatherer(gatherer)
+
+	for name, value := range cfg.PushGrouping() {
+		pusher = pusher.Grouping(name, value)
+	}
+
+	if cfg.PushUsername() != "" {
+		pusher = pusher.BasicAuth(cfg.PushUsername(), cfg.PushPassword())
+	}
+
+	slog.Info("Starting metrics push",
+		"url", cfg.PushURL(),
+		"job", cfg.PushJob(),
+		"interval", cfg.PushInterval(),
+	)
+
+	ticker := time.NewTicker(cfg.PushInterval())
+	defer ticker.Stop()
+
+	pushOnce(ctx, pusher)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if cfg.PushDeleteOnShutdown() {
+				if err := pusher.Delete(); err != nil {
+					slog.Warn("Failed to delete pushed metrics", "error", err)
+				}
+			}
+
+			slog.Info("Metrics push stopped")
+			return
+		case <-ticker.C:
+			pushOnce(ctx, pusher)
+		}
+	}
+}
+
+func pushOnce(ctx context.Context, pusher *push.Pusher) {
+	if err := pusher.PushContext(ctx); err != nil && ctx.Err() == nil {
+		slog.Error("Failed to push metrics", "error", err)
+		return
+	}
+
+	slog.Debug("Metrics pushed")
+}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cachedResponse is a rendered metrics response.
+type cachedResponse struct {
+	header  http.Header
+	body    []byte
+	etag    string
+	created time.Time
+}
+
+// responseCache serves rendered metrics responses for ttl, so repeated requests do not
+// trigger a collection, and answers If-None-Match requests with 304 Not Modified.
+// Responses are cached per Accept and Accept-Encoding header, since both change the
+// rendered body.
+type responseCache struct {
+	next http.Handler
+	ttl  time.Duration
+
+	mu        sync.Mutex
+	responses map[string]*cachedResponse
+	// renders serializes the renders of each key, so requests for other keys and fresh
+	// entries are not held up by a render.
+	renders map[string]*sync.Mutex
+}
+
+// newResponseCache caches the successful responses of next for ttl.
+func newResponseCache(next http.Handler, ttl time.Duration) *responseCache {
+	return &responseCache{
+		next:      next,
+		ttl:       ttl,
+		responses: make(map[string]*cachedResponse),
+		renders:   make(map[string]*sync.Mutex),
+	}
+}
+
+// ServeHTTP implements http.Handler. Concurrent requests for an expired entry wait for a
+// single render.
+func (c *responseCache) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := r.Header.Get("Accept") + "\n" + r.Header.Get("Accept-Encoding")
+
+	response, ok := c.fresh(key)
+	if !ok {
+		render := c.render(key)
+		render.Lock()
+
+		// The request holding the lock before may have rendered the entry.
+		response, ok = c.fresh(key)
+		if !ok {
+			recorder := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+			c.next.ServeHTTP(recorder, r)
+
+			if recorder.status != http.StatusOK {
+				render.Unlock()
+
+				// Failed responses are passed on without caching.
+				maps.Copy(w.Header(), recorder.header)
+				w.WriteHeader(recorder.status)
+				_, _ = w.Write(recorder.body.Bytes())
+				return
+			}
+
+			response = newCachedResponse(recorder)
+
+			c.mu.Lock()
+			c.responses[key] = response
+			c.mu.Unlock()
+		}
+
+		render.Unlock()
+	}
+
+	maps.Copy(w.Header(), response.header)
+	w.Header().Set("ETag", response.etag)
+	w.Header().Set("Cache-Control",
+		"max-age="+strconv.Itoa(int((c.ttl-time.Since(response.created)).Seconds())))
+
+	if etagMatches(r.Header.Get("If-None-Match"), response.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	_, _ = w.Write(response.body)
+}
+
+// fresh returns the cached response of key if it is younger than the TTL.
+func (c *responseCache) fresh(key string) (*cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	response, ok := c.responses[key]
+	if !ok || time.Since(response.created) >= c.ttl {
+		return nil, false
+	}
+
+	return response, true
+}
+
+// render returns the lock serializing the renders of key.
+func (c *responseCache) render(key string) *sync.Mutex {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	render, ok := c.renders[key]
+	if !ok {
+		render = &sync.Mutex{}
+		c.renders[key] = render
+	}
+
+	return render
+}
+
+// newCachedResponse creates a cached response from a recorded one, tagged with a hash of
+// its body.
+func newCachedResponse(recorder *bufferedResponseWriter) *cachedResponse {
+	sum := sha256.Sum256(recorder.body.Bytes())
+
+	return &cachedResponse{
+		header:  recorder.header,
+		body:    recorder.body.Bytes(),
+		etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+		created: time.Now(),
+	}
+}
+
+// etagMatches reports whether the If-None-Match header value matches etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for candidate := range strings.SplitSeq(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// bufferedResponseWriter records a response in memory.
+type bufferedResponseWriter struct {
+	header http.Header
+	body   bytes.Buffer
+	status int
+}
+
+// Header implements http.ResponseWriter.
+func (w *bufferedResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write implements http.ResponseWriter.
+func (w *bufferedResponseWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *bufferedResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"crypto/sha256"
+	"log/slog"
+	"maps"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// scrapeGuard keeps metrics requests from starting a collection while the previous one is
+// still running, so a scrape timeout shorter than the collection does not pile up queries
+// against SurrealDB. Overlapping requests are rejected with 429, or in serve_last mode
+// answered with the last successful response for the same Accept, Accept-Encoding and
+// Authorization headers.
+type scrapeGuard struct {
+	next      http.Handler
+	serveLast bool
+
+	running atomic.Bool
+
+	mu        sync.Mutex
+	responses map[[sha256.Size]byte]*cachedResponse
+}
+
+// newScrapeGuard guards next according to mode, one of the domain.OverlappingScrapes*
+// values. Overlapping requests are allowed through for domain.OverlappingScrapesAllow.
+func newScrapeGuard(next http.Handler, mode string) http.Handler {
+	switch mode {
+	case domain.OverlappingScrapesReject:
+		return &scrapeGuard{next: next}
+	case domain.OverlappingScrapesServeLast:
+		return &scrapeGuard{
+			next:      next,
+			serveLast: true,
+			responses: make(map[[sha256.Size]byte]*cachedResponse),
+		}
+	default:
+		return next
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (g *scrapeGuard) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := responseKey(r)
+
+	if !g.running.CompareAndSwap(false, true) {
+		if response := g.lastResponse(key); response != nil {
+			maps.Copy(w.Header(), response.header)
+			_, _ = w.Write(response.body)
+			return
+		}
+
+		slog.Warn("Rejected metrics request overlapping a running collection",
+			"remote_addr", r.RemoteAddr)
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "previous collection still running", http.StatusTooManyRequests)
+		return
+	}
+	defer g.running.Store(false)
+
+	if !g.serveLast {
+		g.next.ServeHTTP(w, r)
+		return
+	}
+
+	recorder := &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+	g.next.ServeHTTP(recorder, r)
+
+	if recorder.status == http.StatusOK {
+		g.mu.Lock()
+		g.responses[key] = newCachedResponse(recorder)
+		g.mu.Unlock()
+	}
+
+	maps.Copy(w.Header(), recorder.header)
+	w.WriteHeader(recorder.status)
+	_, _ = w.Write(recorder.body.Bytes())
+}
+
+// lastResponse returns the last successful response for key in serve_last mode.
+func (g *scrapeGuard) lastResponse(key [sha256.Size]byte) *cachedResponse {
+	if !g.serveLast {
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.responses[key]
+}
+
+// responseKey hashes the request headers changing the rendered metrics, so tenant tokens
+// are not kept in memory.
+func responseKey(r *http.Request) [sha256.Size]byte {
+	return sha256.Sum256([]byte(r.Header.Get("Accept") + "\n" +
+		r.Header.Get("Accept-Encoding") + "\n" +
+		r.Header.Get("Authorization")))
+}
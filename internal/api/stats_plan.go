@@ -0,0 +1,39 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// StatsTablePlanPath is the path of the stats table dry run plan endpoint.
+const StatsTablePlanPath = "/api/v1/stats-table/plan"
+
+type StatsTablePlanner interface {
+	StatsTablePlan() []*domain.StatsTableAction
+}
+
+// StatsTablePlanResponse is the body returned by the stats table plan endpoint.
+type StatsTablePlanResponse struct {
+	Actions []*domain.StatsTableAction `json:"actions"`
+}
+
+// NewStatsTablePlanHandler serves the stats table changes a dry run reconciliation would
+// make, so they can be reviewed before enabling writes.
+func NewStatsTablePlanHandler(planner StatsTablePlanner) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := json.NewEncoder(w).Encode(StatsTablePlanResponse{Actions: planner.StatsTablePlan()}); err != nil {
+			slog.Error("failed to encode stats table plan response", "error", err)
+		}
+	})
+}
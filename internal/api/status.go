@@ -0,0 +1,203 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// StatusPath is the path of the JSON status endpoint.
+const StatusPath = "/api/v1/status"
+
+type VersionReader interface {
+	Version(ctx context.Context) (string, error)
+}
+
+type ConnectionStatusProvider interface {
+	ConnectionStatus() []domain.ConnectionStatus
+}
+
+type LiveQueryStatusProvider interface {
+	LiveQueryStatus() []*domain.LiveQueryStatus
+}
+
+type ManagedStatsTablesProvider interface {
+	ManagedStatsTables() []*domain.ManagedStatsTable
+}
+
+type TableCacheAge interface {
+	UpdatedAt() time.Time
+}
+
+type ScrapeStatusProvider interface {
+	LastScrape() (domain.ScrapeStatus, bool)
+}
+
+type PausedCollectorsProvider interface {
+	PausedCollectors() []string
+}
+
+// OTLPStatus describes the OTLP receiver.
+type OTLPStatus struct {
+	Enabled   bool   `json:"enabled"`
+	Endpoint  string `json:"endpoint,omitempty"`
+	Listening bool   `json:"listening"`
+	Logs      bool   `json:"logs"`
+	Traces    bool   `json:"traces"`
+}
+
+// StatusSources holds the subsystems described by the status endpoint.
+// Subsystems that are disabled are left nil and their sections are omitted.
+type StatusSources struct {
+	URL     string
+	Version VersionReader
+
+	Connections ConnectionStatusProvider
+	// LiveQueryConnections lists the connections of the live queries when they do not
+	// share the connections of the collectors.
+	LiveQueryConnections ConnectionStatusProvider
+
+	LiveQuery  LiveQueryStatusProvider
+	StatsTable ManagedStatsTablesProvider
+	TableCache TableCacheAge
+	Scrapes    ScrapeStatusProvider
+	Pauses     PausedCollectorsProvider
+
+	OTLP OTLPStatus
+}
+
+// SurrealDBStatus describes the monitored SurrealDB and the exporter's connections to it.
+type SurrealDBStatus struct {
+	URL                  string                    `json:"url"`
+	Version              string                    `json:"version,omitempty"`
+	Connections          []domain.ConnectionStatus `json:"connections"`
+	LiveQueryConnections []domain.ConnectionStatus `json:"live_query_connections,omitempty"`
+}
+
+// TableCacheStatus describes the tables cached for the table collectors.
+type TableCacheStatus struct {
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+	AgeSeconds *float64   `json:"age_seconds,omitempty"`
+}
+
+// StatusResponse is the body returned by the status endpoint.
+type StatusResponse struct {
+	GeneratedAt      time.Time                   `json:"generated_at"`
+	SurrealDB        SurrealDBStatus             `json:"surrealdb"`
+	LiveQueries      []*domain.LiveQueryStatus   `json:"live_queries,omitempty"`
+	StatsTables      []*domain.ManagedStatsTable `json:"stats_tables,omitempty"`
+	OTLP             OTLPStatus                  `json:"otlp"`
+	TableCache       *TableCacheStatus           `json:"table_cache,omitempty"`
+	LastScrape       *domain.ScrapeStatus        `json:"last_scrape,omitempty"`
+	PausedCollectors []string                    `json:"paused_collectors,omitempty"`
+	Errors           map[string]string           `json:"errors,omitempty"`
+}
+
+// StatusHandler serves the state of the exporter's subsystems as JSON, for debugging an
+// exporter without reading its logs.
+type StatusHandler struct {
+	sources StatusSources
+	timeout time.Duration
+}
+
+// NewStatusHandler creates a new status handler. timeout bounds the version query.
+func NewStatusHandler(sources StatusSources, timeout time.Duration) *StatusHandler {
+	return &StatusHandler{
+		sources: sources,
+		timeout: timeout,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *StatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	resp := h.status(ctx)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		slog.Error("failed to encode status response", "error", err)
+	}
+}
+
+// status describes all subsystems. An unreachable SurrealDB is reported in the Errors map
+// instead of failing the response, as the status is most useful exactly then.
+func (h *StatusHandler) status(ctx context.Context) *StatusResponse {
+	now := time.Now()
+
+	resp := &StatusResponse{
+		GeneratedAt: now,
+		SurrealDB:   SurrealDBStatus{URL: redactURL(h.sources.URL)},
+		OTLP:        h.sources.OTLP,
+		Errors:      make(map[string]string),
+	}
+
+	if h.sources.Version != nil {
+		version, err := h.sources.Version.Version(ctx)
+		if err != nil {
+			resp.Errors["version"] = err.Error()
+		} else {
+			resp.SurrealDB.Version = version
+		}
+	}
+
+	if h.sources.Connections != nil {
+		resp.SurrealDB.Connections = h.sources.Connections.ConnectionStatus()
+	}
+
+	if h.sources.LiveQueryConnections != nil {
+		resp.SurrealDB.LiveQueryConnections = h.sources.LiveQueryConnections.ConnectionStatus()
+	}
+
+	if h.sources.LiveQuery != nil {
+		resp.LiveQueries = h.sources.LiveQuery.LiveQueryStatus()
+	}
+
+	if h.sources.StatsTable != nil {
+		resp.StatsTables = h.sources.StatsTable.ManagedStatsTables()
+	}
+
+	if h.sources.TableCache != nil {
+		resp.TableCache = &TableCacheStatus{}
+		if updatedAt := h.sources.TableCache.UpdatedAt(); !updatedAt.IsZero() {
+			age := now.Sub(updatedAt).Seconds()
+			resp.TableCache.UpdatedAt = &updatedAt
+			resp.TableCache.AgeSeconds = &age
+		}
+	}
+
+	if h.sources.Pauses != nil {
+		resp.PausedCollectors = h.sources.Pauses.PausedCollectors()
+	}
+
+	if h.sources.Scrapes != nil {
+		if last, ok := h.sources.Scrapes.LastScrape(); ok {
+			resp.LastScrape = &last
+		}
+	}
+
+	return resp
+}
+
+// redactURL returns rawURL with the password of its user info, if any, replaced.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return u.Redacted()
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// tenantHandler serves the metrics of the tenant whose bearer token a request presents,
+// and all metrics to requests without a token when anonymous is set.
+type tenantHandler struct {
+	tenants   []tenantMetrics
+	anonymous http.Handler
+}
+
+// tenantMetrics is the metrics handler of a tenant.
+type tenantMetrics struct {
+	tenant  domain.Tenant
+	handler http.Handler
+}
+
+// newTenantHandler returns a handler serving each tenant the series of its namespaces,
+// building the handlers with newHandler. anonymous serves requests without a token, nil
+// answers them with 401.
+func newTenantHandler(
+	tenants []domain.Tenant,
+	gatherer prometheus.Gatherer,
+	anonymous http.Handler,
+	newHandler func(prometheus.Gatherer) http.Handler,
+) http.Handler {
+	h := &tenantHandler{anonymous: anonymous}
+	for _, tenant := range tenants {
+		h.tenants = append(h.tenants, tenantMetrics{
+			tenant:  tenant,
+			handler: newHandler(&namespaceFilter{gatherer: gatherer, patterns: tenant.Namespaces}),
+		})
+	}
+
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *tenantHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		if h.anonymous == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="metrics"`)
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		h.anonymous.ServeHTTP(w, r)
+		return
+	}
+
+	var match *tenantMetrics
+	for i := range h.tenants {
+		// Compare every token, so the response time does not tell which one matched.
+		if subtle.ConstantTimeCompare([]byte(token), []byte(h.tenants[i].tenant.Token)) == 1 {
+			match = &h.tenants[i]
+		}
+	}
+
+	if match == nil {
+		slog.Warn("Metrics request with unknown tenant token", "remote_addr", r.RemoteAddr)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="metrics", error="invalid_token"`)
+		http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+		return
+	}
+
+	match.handler.ServeHTTP(w, r)
+}
+
+// newTenantAdminGuard serves next only to requests presenting adminToken, as tenant tokens
+// must not reveal the namespaces of other tenants. Requests without a token are served
+// when anonymous is set, as they get all series from the metrics endpoint then too.
+func newTenantAdminGuard(next http.Handler, adminToken string, anonymous bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			if anonymous {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		if adminToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			slog.Warn("Request for all namespaces without the admin token", "path", r.URL.Path, "remote_addr", r.RemoteAddr)
+			http.Error(w, "the admin token is required while tenants are configured", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// namespaceFilter gathers only the series whose namespace label matches one of patterns.
+// Series without a namespace label, such as server-wide or exporter metrics, are dropped.
+type namespaceFilter struct {
+	gatherer prometheus.Gatherer
+	patterns []string
+}
+
+// Gather implements prometheus.Gatherer.
+func (f *namespaceFilter) Gather() ([]*dto.MetricFamily, error) {
+	families, err := f.gatherer.Gather()
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		var metrics []*dto.Metric
+		for _, metric := range family.GetMetric() {
+			if f.allowed(metric) {
+				metrics = append(metrics, metric)
+			}
+		}
+
+		if len(metrics) == 0 {
+			continue
+		}
+
+		result = append(result, &dto.MetricFamily{
+			Name:   family.Name,
+			Help:   family.Help,
+			Type:   family.Type,
+			Unit:   family.Unit,
+			Metric: metrics,
+		})
+	}
+
+	return result, err
+}
+
+// allowed reports whether the namespace label of metric matches one of the patterns.
+func (f *namespaceFilter) allowed(metric *dto.Metric) bool {
+	for _, pair := range metric.GetLabel() {
+		if pair.GetName() != "namespace" {
+			continue
+		}
+
+		for _, pattern := range f.patterns {
+			if matched, _ := path.Match(pattern, pair.GetValue()); matched {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return false
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/asaphin/surrealdb-prometheus-exporter/internal/api")
+
+// traceHandler wraps h in a server span named spanName, continuing a trace propagated by the caller.
+func traceHandler(spanName string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+			),
+		)
+		defer span.End()
+
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// scrapeHandler records requests to h as scrapes in flight, so the collections they
+// trigger are traced as part of their span.
+func scrapeHandler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer tracing.StartScrape(r.Context())()
+
+		h.ServeHTTP(w, r)
+	})
+}
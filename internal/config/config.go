@@ -1,15 +1,23 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
+	"math"
 	"net/url"
 	"os"
+	"path"
+	"reflect"
 	"regexp"
 	"slices"
 	"strings"
 	"time"
 
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 	"gopkg.in/yaml.v3"
 )
 
@@ -18,24 +26,152 @@ const (
 	DefaultStorageEngine  = "memory"
 	DefaultDeploymentMode = "single"
 
+	DefaultLogOutput             = "stdout"
+	DefaultSlowQueryThreshold    = 1 * time.Second
+	DefaultSlowQueryLogPattern   = `(?i)slow query`
+	DefaultAuthFailureLogPattern = `(?i)(problem with authentication|authentication failed|invalid credentials|signin failed)`
+	DefaultOTLPMetricPrefix      = "surrealdb_otel_"
+
+	DefaultExternalCollectorTimeout = 10 * time.Second
+	DefaultProxyTimeout             = 5 * time.Second
+	DefaultProxyPrefix              = "surrealdb_server_"
+	logOutputFile                   = "file"
+
+	DefaultDerivedLargeTableRecords       = 100000
+	DefaultDerivedIndexBuildingStuckAfter = 1 * time.Hour
+
 	DefaultPort        = 9224
 	DefaultMetricsPath = "/metrics"
 
+	DefaultDebugPort = 6060
+
+	DefaultLabelMaxLength = 64
+	MinLabelMaxLength     = 16
+
+	DefaultPushJob      = "surrealdb"
+	DefaultPushInterval = 30 * time.Second
+	MinPushInterval     = 1 * time.Second
+
+	DefaultLiveQueryMaxTables = 100
+
+	DefaultRecordCountInterval  = 1 * time.Minute
+	DefaultPartitionConcurrency = 4
+	DefaultTopNInterval         = 10 * time.Minute
+	DefaultRecordCountMode      = RecordCountModeScan
+	DefaultReconcileInterval    = 1 * time.Hour
+	RecordCountModeScan         = "scan"
+	RecordCountModeIncremental  = "incremental"
+
+	DefaultStatsTableStrategy = StatsTableStrategyCounter
+	StatsTableStrategyCounter = "counter"
+	StatsTableStrategyDelta   = "delta"
+
 	MinTimeout = 1 * time.Second
 	MaxTimeout = 5 * time.Minute
 
+	DefaultConnectionWriteTimeout = 10 * time.Second
+	DefaultConnectionIdleTimeout  = 10 * time.Minute
+
+	DefaultRateLimitBurst = 10
+
+	DefaultInfoRootBudget      = 0.25
+	DefaultInfoNamespaceBudget = 0.9
+	DefaultInfoTableBudget     = 0.5
+
+	DefaultTableListTTL = 5 * time.Minute
+
+	// InlineConfigEnv holds YAML configuration applied on top of the configuration file,
+	// for deployments without a mounted file.
+	InlineConfigEnv = "SURREALDB_EXPORTER_CONFIG_YAML"
+
+	// AdminTokenEnv holds the token of the admin endpoints, so it need not be stored in
+	// the configuration file.
+	AdminTokenEnv = "SURREALDB_EXPORTER_ADMIN_TOKEN"
+
+	ValidationLenient = "lenient"
+	ValidationStrict  = "strict"
+
 	MinPort        = 1
 	MaxPort        = 65535
 	PrivilegedPort = 1024
 )
 
 var (
-	AllowedStorageEngines  = []string{"memory", "rocksdb", "tikv"}
-	AllowedDeploymentModes = []string{"single", "distributed", "cloud"}
+	defaultSanitizedLabels = []string{"namespace", "database", "table", "index"}
+
+	AllowedStorageEngines   = []string{"memory", "rocksdb", "tikv"}
+	AllowedDeploymentModes  = []string{"single", "distributed", "cloud"}
+	AllowedLogOutputs       = []string{"stdout", "stderr", logOutputFile}
+	AllowedRecordCountModes = []string{RecordCountModeScan, RecordCountModeIncremental}
+	AllowedStatsStrategies  = []string{StatsTableStrategyCounter, StatsTableStrategyDelta}
+	AllowedGRPCCompressions = []string{"gzip", "zstd"}
+	AllowedValidationModes  = []string{ValidationLenient, ValidationStrict}
+	AllowedSchemes          = []string{"ws", "wss", "http", "https"}
+
+	AllowedTranslationStrategies = []string{"UnderscoreEscapingWithSuffixes", "NoTranslation"}
+	AllowedPipelineStages        = []string{
+		domain.PipelineStageFilter,
+		domain.PipelineStageRelabel,
+		domain.PipelineStageRateLimit,
+		domain.PipelineStageBatch,
+	}
+	AllowedLoadAverages = []string{
+		domain.LoadAveragePeriods,
+		domain.LoadAverageMax,
+		domain.LoadAverageAvg,
+	}
+	AllowedTableLists = []string{
+		domain.TableListShared,
+		domain.TableListTTL,
+		domain.TableListUncached,
+	}
+	DefaultTraceMethodAttributes = []string{"rpc.method", "db.operation.name"}
+	DefaultTraceDurationBuckets  = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+	AllowedOverlappingScrapes = []string{
+		domain.OverlappingScrapesAllow,
+		domain.OverlappingScrapesReject,
+		domain.OverlappingScrapesServeLast,
+	}
+	AllowedQueueOverflows = []string{
+		domain.QueueOverflowReject,
+		domain.QueueOverflowDropOldest,
+	}
+	AllowedResourceLabels = []string{
+		domain.ResourceLabelCluster,
+		domain.ResourceLabelStorageEngine,
+		domain.ResourceLabelDeploymentMode,
+		domain.ResourceLabelNode,
+	}
+	AllowedAuthLevels = []string{
+		domain.AuthLevelNamespace,
+		domain.AuthLevelDatabase,
+	}
+	AllowedUserLevels = []string{
+		domain.AuthLevelRoot,
+		domain.AuthLevelNamespace,
+		domain.AuthLevelDatabase,
+	}
+	AllowedRelabelActions = []string{
+		domain.RelabelActionSet,
+		domain.RelabelActionDrop,
+		domain.RelabelActionRename,
+		domain.RelabelActionReplace,
+	}
+
+	metricsPathRegex  = regexp.MustCompile(`^/[a-zA-Z0-9_\-/]*$`)
+	metricPrefixRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+	tableFilterPatternRegex  = regexp.MustCompile(`^[a-zA-Z0-9_*]+:[a-zA-Z0-9_*]+:[a-zA-Z0-9_*]+$`)
+	databasePatternRegex     = regexp.MustCompile(`^[a-zA-Z0-9_*]+:[a-zA-Z0-9_*]+$`)
+	scopeEntryRegex          = regexp.MustCompile(`^[a-zA-Z0-9_]+(:[a-zA-Z0-9_]+)?$`)
+	indexSampleDatabaseRegex = regexp.MustCompile(`^[a-zA-Z0-9_]+:[a-zA-Z0-9_]+$`)
 
-	metricsPathRegex = regexp.MustCompile(`^/[a-zA-Z0-9_\-/]*$`)
+	fieldNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+	labelNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 
-	tableFilterPatternRegex = regexp.MustCompile(`^[a-zA-Z0-9_*]+:[a-zA-Z0-9_*]+:[a-zA-Z0-9_*]+$`)
+	defaultSlowQueryLogRegex   = regexp.MustCompile(DefaultSlowQueryLogPattern)
+	defaultAuthFailureLogRegex = regexp.MustCompile(DefaultAuthFailureLogPattern)
 )
 
 // Config interface for external packages.
@@ -43,9 +179,25 @@ type Config interface {
 	OTLPBatchingEnabled() bool
 	OTLPBatchTimeoutMs() int
 	OTLPBatchSize() int
+	OTLPMetricPrefix() string
+	OTLPResourceLabels() map[string][]string
+	OTLPHistogramBuckets() []domain.HistogramBucketLayout
+	OTLPQueueLimits() domain.QueueLimits
 	OTLPGRPCEndpoint() string
 	OTLPMaxRecvSize() int
 	OTLPTranslationStrategy() string
+	OTLPNativeHistograms() bool
+	OTLPGRPCMaxConcurrentStreams() uint32
+	OTLPGRPCCompression() []string
+	OTLPGRPCReflection() bool
+	OTLPGRPCKeepalive() domain.GRPCKeepalive
+	OTLPLogsEnabled() bool
+	OTLPSlowQueryLogPattern() *regexp.Regexp
+	OTLPAuthFailureLogPattern() *regexp.Regexp
+	OTLPTracesEnabled() bool
+	OTLPTraceMethodAttributes() []string
+	OTLPTraceDurationBuckets() []float64
+	OTLPPipeline() []domain.PipelineStage
 	ClusterName() string
 	StorageEngine() string
 	DeploymentMode() string
@@ -53,114 +205,568 @@ type Config interface {
 
 // unexported root config type.
 type config struct {
-	Exporter   exporterConfig   `yaml:"exporter"`
-	SurrealDB  surrealDBConfig  `yaml:"surrealdb"`
-	Collectors collectorsConfig `yaml:"collectors"`
-	Logging    loggingConfig    `yaml:"logging"`
+	Validation string           `yaml:"validation" description:"lenient corrects invalid values with warnings; strict fails startup on unknown keys and invalid values"`
+	Exporter   exporterConfig   `yaml:"exporter" description:"HTTP server, push and response settings"`
+	SurrealDB  surrealDBConfig  `yaml:"surrealdb" description:"Connection to the monitored SurrealDB"`
+	Collectors collectorsConfig `yaml:"collectors" description:"Collector settings"`
+	Logging    loggingConfig    `yaml:"logging" description:"Exporter logs"`
+	Tracing    tracingConfig    `yaml:"tracing" description:"OpenTelemetry tracing of scrapes, collections and SurrealDB queries, configured with OTEL_* environment variables"`
+
+	// configured holds the topology settings set in the file or inline configuration,
+	// which auto detection must not override.
+	configured struct {
+		storageEngine  bool
+		deploymentMode bool
+	}
+}
+
+type tracingConfig struct {
+	Enabled bool `yaml:"enabled" description:"Export spans of scrapes, collections and queries"`
 }
 
 type exporterConfig struct {
-	Port        int    `yaml:"port"`
-	MetricsPath string `yaml:"metrics_path"`
+	Port                    int             `yaml:"port" description:"Port of the metrics HTTP server"`
+	MetricsPath             string          `yaml:"metrics_path" description:"Path the metrics are served on"`
+	ScrapeBudget            time.Duration   `yaml:"scrape_budget" description:"Skip low-priority collectors (record_count, relation_edges, index_usage) when the rest of the scrape took longer, and the table and index INFO queries of databases reached after it, 0 disables"`
+	TelemetryPort           int             `yaml:"telemetry_port" description:"Serve the exporter's own metrics and the debug endpoints on this port instead, 0 keeps them on port"`
+	TelemetryConstantLabels bool            `yaml:"telemetry_constant_labels" description:"Add the cluster, storage_engine and deployment_mode labels to the exporter's own metrics (go, process)"`
+	Compression             bool            `yaml:"compression" description:"Gzip /metrics responses for scrapers that accept it"`
+	CacheTTL                time.Duration   `yaml:"cache_ttl" description:"Serve repeated /metrics requests from a cached response for this long, 0 disables"`
+	Push                    pushConfig      `yaml:"push" description:"Push gathered metrics to a Prometheus Pushgateway"`
+	JSONAPI                 jsonAPIConfig   `yaml:"json_api" description:"Structured JSON view of the collected data at /api/v1/metrics"`
+	StatusAPI               statusAPIConfig `yaml:"status_api" description:"JSON view of the exporter's connections, live queries, stats tables, OTLP receiver and scrapes at /api/v1/status"`
+	Admin                   adminConfig     `yaml:"admin" description:"Endpoints pausing and resuming collectors at runtime, e.g. during incident mitigation"`
+	Debug                   debugConfig     `yaml:"debug" description:"net/http/pprof and expvar on a separate port"`
+
+	LabelSanitization labelSanitizationConfig `yaml:"label_sanitization" description:"Rewrite SurrealDB identifiers in label values to [a-zA-Z0-9_.:-] and cap their length"`
+	Tenants           tenantsConfig           `yaml:"tenants" description:"Bearer tokens scoping the metrics of a request to the namespaces of a tenant"`
+	Renames           renamesConfig           `yaml:"renames" description:"Export metrics and labels under the names of an earlier exporter, e.g. while migrating dashboards"`
+
+	OverlappingScrapes string                   `yaml:"overlapping_scrapes" description:"Metrics requests arriving while a collection is still running: allow, reject (429) or serve_last (previous response, 429 when there is none)"`
+	NamespaceEndpoints namespaceEndpointsConfig `yaml:"namespace_endpoints" description:"Serve the metrics of each namespace at <metrics_path>/namespace/<ns>, collected separately"`
+}
+
+// namespaceEndpointsConfig holds the settings of the per-namespace metrics endpoints.
+type namespaceEndpointsConfig struct {
+	Enabled    bool     `yaml:"enabled" description:"Serve <metrics_path>/namespace/<ns>"`
+	Namespaces []string `yaml:"namespaces" description:"Namespace patterns with an endpoint, wildcards (*) allowed"`
+}
+
+// tenantsConfig holds the tokens of tenants allowed to scrape the series of their namespaces.
+type tenantsConfig struct {
+	AllowAnonymous bool           `yaml:"allow_anonymous" description:"Serve all series to requests without a token; false answers them with 401"`
+	Tokens         []tenantConfig `yaml:"tokens" description:"Tenants and their tokens"`
+}
+
+type tenantConfig struct {
+	Name       string   `yaml:"name" description:"Name of the tenant in logs"`
+	Token      string   `yaml:"token" description:"Bearer token presented in the Authorization header" secret:"true"`
+	Namespaces []string `yaml:"namespaces" description:"Namespace patterns whose series the tenant gets, wildcards (*) allowed"`
+}
+
+// renamesConfig maps current metric and label names to the names they are exported as.
+type renamesConfig struct {
+	Metrics      map[string]string `yaml:"metrics" description:"Metric names mapped to the names they are exported as"`
+	Labels       map[string]string `yaml:"labels" description:"Label names mapped to the names they are exported as"`
+	KeepOriginal bool              `yaml:"keep_original" description:"Export renamed metrics in addition to the originals, labels are only renamed on the copies"`
+}
+
+type labelSanitizationConfig struct {
+	Enabled    bool     `yaml:"enabled" description:"Sanitize label values"`
+	Labels     []string `yaml:"labels" description:"Labels whose values are sanitized"`
+	MaxLength  int      `yaml:"max_length" description:"Maximum length of sanitized values, minimum 16"`
+	HashSuffix bool     `yaml:"hash_suffix" description:"Append a hash of the original to truncated values"`
+}
+
+type debugConfig struct {
+	Pprof bool `yaml:"pprof" description:"Serve pprof and expvar (/debug/vars)"`
+	Port  int  `yaml:"port" description:"Port of the debug server, unused when exporter.telemetry_port is set"`
+}
+
+type jsonAPIConfig struct {
+	Enabled bool `yaml:"enabled" description:"Serve /api/v1/metrics"`
+}
+
+type statusAPIConfig struct {
+	Enabled bool `yaml:"enabled" description:"Serve /api/v1/status"`
+}
+
+type adminConfig struct {
+	Enabled bool   `yaml:"enabled" description:"Serve /api/v1/admin/collectors/{name}/pause and /resume"`
+	Token   string `yaml:"token" description:"Bearer token the admin endpoints require (default $SURREALDB_EXPORTER_ADMIN_TOKEN)" secret:"true"`
+}
+
+type pushConfig struct {
+	Enabled          bool              `yaml:"enabled" description:"Push metrics on interval"`
+	PushOnly         bool              `yaml:"push_only" description:"Disable the HTTP /metrics server"`
+	URL              string            `yaml:"url" description:"Pushgateway URL"`
+	Job              string            `yaml:"job" description:"Job name metrics are pushed under"`
+	Interval         time.Duration     `yaml:"interval" description:"Push interval, minimum 1s"`
+	Grouping         map[string]string `yaml:"grouping" description:"Additional grouping labels"`
+	Username         string            `yaml:"username" description:"Basic auth username"`
+	Password         string            `yaml:"password" description:"Basic auth password" secret:"true"`
+	DeleteOnShutdown bool              `yaml:"delete_on_shutdown" description:"Delete the pushed group when the exporter stops"`
 }
 
 type surrealDBConfig struct {
-	Scheme         string        `yaml:"scheme"`
-	Host           string        `yaml:"host"`
-	Port           string        `yaml:"port"`
-	Username       string        `yaml:"username"`
-	Password       string        `yaml:"password"`
-	Timeout        time.Duration `yaml:"timeout"`
-	ClusterName    string        `yaml:"cluster_name"`
-	StorageEngine  string        `yaml:"storage_engine"`
-	DeploymentMode string        `yaml:"deployment_mode"`
+	Scheme         string        `yaml:"scheme" description:"Connection scheme (ws, wss, http, https)"`
+	Host           string        `yaml:"host" description:"SurrealDB host"`
+	Port           string        `yaml:"port" description:"SurrealDB port"`
+	Username       string        `yaml:"username" description:"Root user"`
+	Password       string        `yaml:"password" description:"Password of the root user" secret:"true"`
+	Level          string        `yaml:"level" description:"Level of the user above: root, or namespace or database for users without root access, which require scope"`
+	Scope          []string      `yaml:"scope" description:"Namespaces (namespace) and databases (namespace:database) monitored instead of the whole server, skipping INFO FOR ROOT and the system metrics"`
+	Timeout        time.Duration `yaml:"timeout" description:"Timeout of SurrealDB requests, between 1s and 5m"`
+	ClusterName    string        `yaml:"cluster_name" description:"Value of the cluster label"`
+	StorageEngine  string        `yaml:"storage_engine" description:"Value of the storage_engine label"`
+	DeploymentMode string        `yaml:"deployment_mode" description:"Value of the deployment_mode label; live_query requires single"`
+	AutoDetect     bool          `yaml:"auto_detect" description:"Detect deployment_mode from the server and use it when deployment_mode is not configured"`
+
+	Connection  connectionConfig   `yaml:"connection" description:"WebSocket keep-alive and reconnection of the SDK connections"`
+	Credentials []credentialConfig `yaml:"credentials" description:"Credentials used instead of the root user for matching databases, first match wins"`
+	RateLimit   rateLimitConfig    `yaml:"rate_limit" description:"Cap the queries the exporter sends to SurrealDB, e.g. to an agreed budget against production databases"`
+}
+
+// rateLimitConfig holds the token bucket shared by every query of the exporter.
+type rateLimitConfig struct {
+	QueriesPerSecond float64 `yaml:"queries_per_second" description:"Queries per second sent to SurrealDB, 0 disables the limit"`
+	Burst            int     `yaml:"burst" description:"Queries sent at once before the limit applies, at least 1"`
+}
+
+// connectionConfig holds the keep-alive settings of the WebSocket connections, which
+// otherwise stay at the SDK defaults.
+type connectionConfig struct {
+	PingInterval  time.Duration `yaml:"ping_interval" description:"Send WebSocket pings this often, keeping idle connections open behind load balancers, 0 disables"`
+	ReadTimeout   time.Duration `yaml:"read_timeout" description:"Close the connection when no pong arrived for this long, greater than ping_interval, 0 disables"`
+	WriteTimeout  time.Duration `yaml:"write_timeout" description:"Close the connection when a ping cannot be written within this time"`
+	AutoReconnect bool          `yaml:"auto_reconnect" description:"Re-establish closed connections and restart their live queries"`
+	Prewarm       bool          `yaml:"prewarm" description:"Connect to every database found at startup, so the first scrape does not wait for dozens of sign-ins"`
+	IdleTimeout   time.Duration `yaml:"idle_timeout" description:"Close database connections unused for this long, e.g. of deleted databases, 0 keeps them open"`
+}
+
+// credentialConfig holds the credentials of a namespace or database level user, used
+// instead of the root credentials for databases matching Pattern (namespace:database).
+type credentialConfig struct {
+	Pattern  string `yaml:"pattern" description:"namespace:database, wildcards (*) allowed"`
+	Username string `yaml:"username" description:"Namespace or database user"`
+	Password string `yaml:"password" description:"Password of the user" secret:"true"`
+	Level    string `yaml:"level" description:"Level the user is defined on, default database"`
 }
 
 type collectorsConfig struct {
-	LiveQuery     liveQueryConfig     `yaml:"live_query"`
-	RecordCount   recordCountConfig   `yaml:"record_count"`
-	StatsTable    statsTableConfig    `yaml:"stats_table"`
-	OpenTelemetry openTelemetryConfig `yaml:"open_telemetry"`
-	Go            collectorConfig     `yaml:"go"`
-	Process       collectorConfig     `yaml:"process"`
+	Info             infoConfig          `yaml:"info" description:"Info collector, always active"`
+	LiveQuery        liveQueryConfig     `yaml:"live_query" description:"Operation counts from live queries, only available for deployment_mode single"`
+	RecordCount      recordCountConfig   `yaml:"record_count" description:"Record counts per table"`
+	StatsTable       statsTableConfig    `yaml:"stats_table" description:"Operation counts from side tables maintained by events"`
+	OpenTelemetry    openTelemetryConfig `yaml:"open_telemetry" description:"OTLP/gRPC metrics receiver converting SurrealDB metrics to Prometheus format"`
+	Go               collectorConfig     `yaml:"go" description:"Go runtime metrics of the exporter"`
+	Process          collectorConfig     `yaml:"process" description:"Process metrics of the exporter"`
+	RelationEdges    collectorConfig     `yaml:"relation_edges" description:"Edge counts of relation tables by the tables they connect, one aggregate query per relation table"`
+	ConsistencyAudit collectorConfig     `yaml:"consistency_audit" description:"Divergence between the operations counted by live_query and stats_table for the tables both track; needs both collectors"`
+	Derived          derivedConfig       `yaml:"derived" description:"Boolean gauges for alerting: empty databases, large unindexed tables, stuck index builds, namespaces without users"`
+	IndexUsage       indexUsageConfig    `yaml:"index_usage" description:"Indexes used by the EXPLAIN plans of sample queries, to find unused indexes, and the time indexes last finished building"`
+
+	OperationClassification operationClassificationConfig `yaml:"operation_classification" description:"Operation type classification shared by live_query and stats_table, first matching rule wins"`
+	External                []externalCollectorConfig     `yaml:"external" description:"Commands run on every scrape whose stdout (Prometheus text format) is merged into /metrics"`
+	Textfile                textfileConfig                `yaml:"textfile" description:"Expose *.prom files (Prometheus text format) written by sidecars"`
+	Proxy                   proxyConfig                   `yaml:"proxy" description:"Scrape a Prometheus endpoint of SurrealDB itself and merge its metrics into /metrics"`
+}
+
+type infoConfig struct {
+	Cache                  infoCacheConfig  `yaml:"cache" description:"Per-level caching of INFO results; root and system info is always fresh"`
+	DetailedSchema         bool             `yaml:"detailed_schema" description:"Export one info series per function, param, analyzer and HTTP API of every database"`
+	TimeoutBudget          infoBudgetConfig `yaml:"timeout_budget" description:"Shares of surrealdb.timeout each INFO level may use, so one slow table cannot use up the whole scrape"`
+	LoadAverage            string           `yaml:"load_average" description:"System load average export: periods (1m, 5m and 15m series), max or avg (one series)"`
+	LegacyMemoryUsageRatio bool             `yaml:"legacy_memory_usage_ratio" description:"Keep exporting the deprecated surrealdb_system_memory_usage_ratio, replaced by memory_usage_ratio_of_allocated"`
+}
+
+// infoBudgetConfig holds the shares of surrealdb.timeout INFO queries get per level. A
+// level that runs out of its share is recorded as an INFO error of that level while the
+// rest of the hierarchy is still fetched.
+type infoBudgetConfig struct {
+	Root      float64 `yaml:"root" description:"Share of INFO FOR ROOT, between 0 and 1"`
+	Namespace float64 `yaml:"namespace" description:"Share of one namespace with its databases and tables, between 0 and 1"`
+	Table     float64 `yaml:"table" description:"Share of the tables and indexes of one database, between 0 and 1"`
+}
+
+// infoCacheConfig holds per-level TTLs for cached INFO results. Root and system
+// information is always fetched fresh; a zero TTL disables caching for that level.
+type infoCacheConfig struct {
+	NamespaceTTL        time.Duration `yaml:"namespace_ttl" description:"Cache TTL of namespace info, 0 disables caching"`
+	DatabaseTTL         time.Duration `yaml:"database_ttl" description:"Cache TTL of database info, 0 disables caching"`
+	TableTTL            time.Duration `yaml:"table_ttl" description:"Cache TTL of table info, 0 disables caching"`
+	IndexTTL            time.Duration `yaml:"index_ttl" description:"Cache TTL of index info including building progress, 0 disables caching"`
+	IncrementalTableTTL time.Duration `yaml:"incremental_table_ttl" description:"Reuse table info while its DEFINE TABLE statement in INFO FOR DB is unchanged, for at most this long, so field, event and index changes show up within it; 0 disables"`
+
+	TableList    string        `yaml:"table_list" description:"Tables shared with the table collectors: shared keeps them until the next info scrape, ttl forgets them after table_list_ttl, uncached runs INFO whenever a table collector needs them"`
+	TableListTTL time.Duration `yaml:"table_list_ttl" description:"Age after which the tables are forgotten in ttl mode, e.g. while info scrapes fail"`
 }
 
 type collectorConfig struct {
-	Enabled bool `yaml:"enabled"`
+	Enabled bool `yaml:"enabled" description:"Run the collector"`
+}
+
+// textfileConfig configures reading *.prom files written by sidecars.
+type textfileConfig struct {
+	Enabled   bool   `yaml:"enabled" description:"Read *.prom files"`
+	Directory string `yaml:"directory" description:"Directory the *.prom files are read from"`
+}
+
+// derivedConfig configures the derived alert metrics.
+type derivedConfig struct {
+	Enabled                 bool          `yaml:"enabled" description:"Run the collector"`
+	LargeTableRecords       int           `yaml:"large_table_records" description:"Records above which a table without indexes is reported, needs the record_count collector"`
+	IndexBuildingStuckAfter time.Duration `yaml:"index_building_stuck_after" description:"Time an index may be seen building before it is reported as stuck"`
+}
+
+// indexUsageConfig configures the index usage collector.
+type indexUsageConfig struct {
+	Enabled bool                     `yaml:"enabled" description:"Run the collector"`
+	Queries []indexSampleQueryConfig `yaml:"queries" description:"Representative SELECT statements explained on every scrape; indexes none of them uses are candidates for dropping"`
+}
+
+// indexSampleQueryConfig is a sample query of the index usage collector.
+type indexSampleQueryConfig struct {
+	Name     string `yaml:"name" description:"Value of the query label, unique"`
+	Database string `yaml:"database" description:"Database the query runs in (namespace:database)"`
+	Query    string `yaml:"query" description:"A single SELECT statement, without EXPLAIN"`
+}
+
+// proxyConfig configures scraping the metrics SurrealDB exposes itself, so one scrape
+// target covers both.
+type proxyConfig struct {
+	Enabled bool          `yaml:"enabled" description:"Scrape and merge the SurrealDB metrics endpoint"`
+	URL     string        `yaml:"url" description:"URL of the metrics endpoint in the Prometheus text format, e.g. http://surrealdb:8000/metrics"`
+	Prefix  string        `yaml:"prefix" description:"Prefix added to the merged metric names that do not already have it"`
+	Timeout time.Duration `yaml:"timeout" description:"Time a scrape of the endpoint may take"`
+}
+
+// externalCollectorConfig configures a command whose output is merged into the metrics.
+type externalCollectorConfig struct {
+	Name    string        `yaml:"name" description:"Name of the command in logs and metrics"`
+	Command []string      `yaml:"command" description:"Command and arguments"`
+	Timeout time.Duration `yaml:"timeout" description:"Time the command may run, default 10s"`
 }
 
 type recordCountConfig struct {
-	Enabled bool        `yaml:"enabled"`
-	Tables  tableConfig `yaml:"tables"`
+	Enabled   bool                        `yaml:"enabled" description:"Run the collector"`
+	Mode      string                      `yaml:"mode" description:"scan counts with full COUNT queries; incremental adjusts counts by live_query creates and deletes between full reconciliations"`
+	Tables    tableConfig                 `yaml:"tables" description:"Tables to count"`
+	Interval  time.Duration               `yaml:"interval" description:"Refresh counts in the background on this interval, 0 counts on every scrape"`
+	Overrides []recordCountOverrideConfig `yaml:"overrides" description:"Per-table refresh intervals, first matching pattern wins"`
+
+	ReconcileInterval time.Duration `yaml:"reconcile_interval" description:"Interval incremental counts are fully counted again on, replacing interval and overrides in incremental mode"`
+
+	Partitions []recordCountPartitionConfig `yaml:"partitions" description:"Count very large tables as record ID ranges, first matching pattern wins"`
+
+	TopN         int           `yaml:"top_n" description:"Export only the N largest tables and aggregate the rest, 0 exports all"`
+	TopNInterval time.Duration `yaml:"top_n_interval" description:"Interval the largest tables are selected again on"`
+}
+
+// recordCountPartitionConfig splits the count of matching tables into record ID ranges.
+type recordCountPartitionConfig struct {
+	Table       string `yaml:"table" description:"namespace:database:table pattern, wildcards (*) allowed"`
+	Boundaries  []any  `yaml:"boundaries" description:"Ascending integer or string record IDs the table is split at"`
+	Concurrency int    `yaml:"concurrency" description:"Range queries run at the same time"`
+}
+
+type recordCountOverrideConfig struct {
+	Table    string        `yaml:"table" description:"namespace:database:table pattern, wildcards (*) allowed"`
+	Interval time.Duration `yaml:"interval" description:"Refresh interval of matching tables"`
 }
 
 type liveQueryConfig struct {
-	Enabled              bool          `yaml:"enabled"`
-	Tables               tableConfig   `yaml:"tables"`
-	ReconnectDelay       time.Duration `yaml:"reconnect_delay"`
-	MaxReconnectAttempts int           `yaml:"max_reconnect_attempts"`
+	Enabled              bool          `yaml:"enabled" description:"Run the collector"`
+	Tables               tableConfig   `yaml:"tables" description:"Tables to open live queries on"`
+	ReconnectDelay       time.Duration `yaml:"reconnect_delay" description:"Delay before a failed live query is opened again"`
+	MaxReconnectAttempts int           `yaml:"max_reconnect_attempts" description:"Attempts to open a failed live query again"`
+	DetectOperationType  bool          `yaml:"detect_operation_type" description:"Classify operations by record shape; when false, DIFF notifications are used and the operation_type label is dropped"`
+	SchemaPollInterval   time.Duration `yaml:"schema_poll_interval" description:"Poll the databases matching the include patterns for new tables on this interval, 0 waits for the next info scrape"`
+	DedicatedConnections bool          `yaml:"dedicated_connections" description:"Run live queries on their own connections instead of sharing those of scrape queries"`
+	MaxTables            int           `yaml:"max_tables" description:"Open live queries on at most this many tables, preferring tables matching earlier include patterns; 0 is unlimited"`
+
+	Sampling []liveQuerySamplingConfig `yaml:"sampling" description:"Classify only a fraction of the notifications of busy tables, first matching pattern wins"`
+}
+
+// liveQuerySamplingConfig classifies only a fraction of the notifications of matching tables.
+type liveQuerySamplingConfig struct {
+	Table      string  `yaml:"table" description:"namespace:database:table pattern, wildcards (*) allowed"`
+	SampleRate float64 `yaml:"sample_rate" description:"Fraction of notifications decoded and classified, the others count under the operation type last classified for their action"`
 }
 
 type statsTableConfig struct {
-	Enabled             bool        `yaml:"enabled"`
-	Tables              tableConfig `yaml:"tables"`
-	RemoveOrphanTables  bool        `yaml:"remove_orphan_tables"`
-	SideTableNamePrefix string      `yaml:"side_table_name_prefix"`
+	Enabled             bool        `yaml:"enabled" description:"Run the collector"`
+	Tables              tableConfig `yaml:"tables" description:"Tables to maintain side tables for"`
+	RemoveOrphanTables  bool        `yaml:"remove_orphan_tables" description:"Remove side tables whose table no longer exists or is no longer monitored"`
+	SideTableNamePrefix string      `yaml:"side_table_name_prefix" description:"Prefix of side table names"`
+	Strategy            string      `yaml:"strategy" description:"counter increments one stats record per table; delta appends one record per change, folded on every scrape"`
+	DryRun              bool        `yaml:"dry_run" description:"Only log and serve (GET /api/v1/stats-table/plan) the planned side table changes"`
+}
+
+type operationClassificationConfig struct {
+	Default   string                         `yaml:"default" description:"Operation type of records no rule matches"`
+	Rules     []classificationRuleConfig     `yaml:"rules" description:"Rules evaluated in order, field counts exclude the record id"`
+	Overrides []classificationOverrideConfig `yaml:"overrides" description:"Fixed operation types of matching tables"`
+}
+
+type classificationRuleConfig struct {
+	Type             string   `yaml:"type" description:"Operation type of matching records"`
+	HasFields        []string `yaml:"has_fields" description:"Fields the record must have"`
+	MinFields        *int     `yaml:"min_fields" description:"Minimum number of fields"`
+	MaxFields        *int     `yaml:"max_fields" description:"Maximum number of fields"`
+	MinScalarFields  *int     `yaml:"min_scalar_fields" description:"Minimum number of scalar fields"`
+	MaxComplexFields *int     `yaml:"max_complex_fields" description:"Maximum number of object and array fields"`
+}
+
+type classificationOverrideConfig struct {
+	Table          string `yaml:"table" description:"namespace:database:table pattern, wildcards (*) allowed"`
+	Classification string `yaml:"classification" description:"Operation type of the table"`
 }
 
 type tableConfig struct {
-	Include []string `yaml:"include"`
-	Exclude []string `yaml:"exclude"`
+	Include []string `yaml:"include" description:"namespace:database:table patterns of included tables, wildcards (*) allowed"`
+	Exclude []string `yaml:"exclude" description:"namespace:database:table patterns of excluded tables, wildcards (*) allowed"`
 }
 
 type openTelemetryConfig struct {
-	Enabled             bool   `yaml:"enabled"`
-	GRPCEndpoint        string `yaml:"grpc_endpoint"`
-	MaxRecvSize         int    `yaml:"max_recv_size"` // in MB
-	TranslationStrategy string `yaml:"translation_strategy"`
-	EnableBatching      bool   `yaml:"enable_batching"`
-	BatchSize           int    `yaml:"batch_size"`
-	BatchTimeoutMs      int    `yaml:"batch_timeout_ms"`
+	Enabled             bool                  `yaml:"enabled" description:"Run the receiver"`
+	GRPCEndpoint        string                `yaml:"grpc_endpoint" description:"Listen address of the OTLP/gRPC receiver"`
+	MaxRecvSize         int                   `yaml:"max_recv_size" description:"Maximum receive size in MB"` // in MB
+	TranslationStrategy string                `yaml:"translation_strategy" description:"Translation of OTLP metric names"`
+	MetricPrefix        string                `yaml:"metric_prefix" description:"Prefix of converted OTLP metric names"`
+	ResourceLabels      map[string][]string   `yaml:"resource_labels" description:"Labels taken from the first present OTLP resource attribute, falling back to the surrealdb settings"`
+	EnableBatching      bool                  `yaml:"enable_batching" description:"Batch metrics when pipeline is empty"`
+	BatchSize           int                   `yaml:"batch_size" description:"Metrics per batch"`
+	BatchTimeoutMs      int                   `yaml:"batch_timeout_ms" description:"Batch timeout in milliseconds"`
+	NativeHistograms    bool                  `yaml:"native_histograms" description:"Export OTLP histograms as Prometheus native histograms"`
+	HistogramBuckets    []histogramBuckets    `yaml:"histogram_buckets" description:"Re-aggregate histograms matching an OTLP metric name glob onto fixed buckets"`
+	GRPC                grpcConfig            `yaml:"grpc" description:"gRPC receiver tuning, zero values keep the gRPC defaults"`
+	Logs                otlpLogsConfig        `yaml:"logs" description:"Derive metrics from OTLP logs"`
+	Traces              otlpTracesConfig      `yaml:"traces" description:"Derive request rate, error and duration metrics per SurrealDB RPC method from OTLP spans"`
+	Pipeline            []pipelineStageConfig `yaml:"pipeline" description:"Ordered processing stages; batch must be last"`
+	Queue               queueConfig           `yaml:"queue" description:"Limits on metrics buffered by the batch stage, 0 is unlimited"`
+}
+
+// queueConfig bounds the metrics buffered by the batch stage. Zero limits are unlimited.
+type queueConfig struct {
+	MaxMetrics int    `yaml:"max_metrics" description:"Maximum buffered metrics"`
+	MaxBytes   int    `yaml:"max_bytes" description:"Maximum estimated in-memory size of buffered metrics"`
+	Overflow   string `yaml:"overflow" description:"reject answers with a retryable gRPC error and drops the metrics of a single export beyond the limits; drop_oldest discards buffered metrics"`
+}
+
+// histogramBuckets re-aggregates histograms whose OTLP name matches Pattern onto Buckets.
+type histogramBuckets struct {
+	Pattern string    `yaml:"pattern" description:"OTLP metric name glob"`
+	Buckets []float64 `yaml:"buckets" description:"Bucket upper bounds in exported units"`
+}
+
+// pipelineStageConfig configures one OTLP processing stage. An empty pipeline batches
+// according to enable_batching.
+type pipelineStageConfig struct {
+	Type             string              `yaml:"type" description:"Stage type"`
+	Include          []string            `yaml:"include" description:"OTLP metric name globs kept by a filter stage"`
+	Exclude          []string            `yaml:"exclude" description:"OTLP metric name globs dropped by a filter stage"`
+	Rules            []relabelRuleConfig `yaml:"rules" description:"Rules of a relabel stage"`
+	MetricsPerSecond float64             `yaml:"metrics_per_second" description:"Rate of a rate_limit stage"`
+	Burst            int                 `yaml:"burst" description:"Burst of a rate_limit stage"`
+}
+
+type relabelRuleConfig struct {
+	Action      string `yaml:"action" description:"Relabel action"`
+	SourceLabel string `yaml:"source_label" description:"Label read by the rule"`
+	TargetLabel string `yaml:"target_label" description:"Label written by the rule"`
+	Regex       string `yaml:"regex" description:"Regular expression matched against the source label by replace"`
+	Replacement string `yaml:"replacement" description:"Value written by set and replace"`
+}
+
+// otlpLogsConfig controls deriving metrics from OTLP log records.
+type otlpLogsConfig struct {
+	Enabled            bool   `yaml:"enabled" description:"Derive log record, slow query and authentication failure metrics"`
+	SlowQueryPattern   string `yaml:"slow_query_pattern" description:"Log bodies matching this regular expression count as slow queries"`
+	AuthFailurePattern string `yaml:"auth_failure_pattern" description:"Log bodies matching this regular expression count as authentication failures; named groups namespace and access set the labels"`
+}
+
+// otlpTracesConfig controls deriving RED metrics from OTLP spans.
+type otlpTracesConfig struct {
+	Enabled          bool      `yaml:"enabled" description:"Accept OTLP traces and count the spans carrying an RPC method"`
+	MethodAttributes []string  `yaml:"method_attributes" description:"Span attributes holding the RPC method, the first present is used; spans with none are ignored"`
+	Buckets          []float64 `yaml:"buckets" description:"Upper bounds in seconds of the span duration histogram buckets"`
+}
+
+// grpcConfig holds OTLP gRPC receiver tuning. Zero values keep the gRPC defaults.
+type grpcConfig struct {
+	MaxConcurrentStreams uint32          `yaml:"max_concurrent_streams" description:"Per-connection stream limit"`
+	Compression          []string        `yaml:"compression" description:"Accepted request compressions, gzip is always accepted"`
+	Reflection           bool            `yaml:"reflection" description:"Enable gRPC server reflection"`
+	Keepalive            keepaliveConfig `yaml:"keepalive" description:"Keepalive enforcement and pings"`
+}
+
+type keepaliveConfig struct {
+	Time                  time.Duration `yaml:"time" description:"Ping idle clients after this long"`
+	Timeout               time.Duration `yaml:"timeout" description:"Close connections not answering pings after this long"`
+	MaxConnectionIdle     time.Duration `yaml:"max_connection_idle" description:"Close connections idle for this long"`
+	MaxConnectionAge      time.Duration `yaml:"max_connection_age" description:"Close connections after this long"`
+	MaxConnectionAgeGrace time.Duration `yaml:"max_connection_age_grace" description:"Time to finish requests after max_connection_age"`
+	MinTime               time.Duration `yaml:"min_time" description:"Minimum client ping interval before GOAWAY"`
+	PermitWithoutStream   bool          `yaml:"permit_without_stream" description:"Allow client pings without active streams"`
 }
 
 type loggingConfig struct {
-	Format           string         `yaml:"format"`
-	Level            string         `yaml:"level"`
-	CustomAttributes map[string]any `yaml:"custom_attributes"`
+	Format           string            `yaml:"format" description:"Log format (json or text)"`
+	Level            string            `yaml:"level" description:"Minimum log level (debug, info, warn, error)"`
+	CustomAttributes map[string]any    `yaml:"custom_attributes" description:"Attributes added to every log record"`
+	Output           string            `yaml:"output" description:"Log destination"`
+	File             loggingFileConfig `yaml:"file" description:"Log file used when output is file"`
+	TraceQueries     bool              `yaml:"trace_queries" description:"Log every SurrealQL query with its target and duration at debug level"`
+	SlowQuery        time.Duration     `yaml:"slow_query_threshold" description:"Warn about queries slower than this, 0 disables"`
+}
+
+type loggingFileConfig struct {
+	Path       string        `yaml:"path" description:"Log file path"`
+	MaxSizeMB  int           `yaml:"max_size_mb" description:"Rotate when the file exceeds this size, 0 disables"`
+	MaxAge     time.Duration `yaml:"max_age" description:"Rotate when the file is older than this, 0 disables"`
+	MaxBackups int           `yaml:"max_backups" description:"Rotated files to keep, 0 keeps all"`
 }
 
-func Load(path string) (*config, error) {
+// Overrides holds settings given on the command line, which take precedence over the
+// configuration file, the inline configuration and the environment. Nil fields are unset.
+type Overrides struct {
+	// Collectors enables or disables collectors by name.
+	Collectors              map[string]bool
+	TelemetryPort           *int
+	TelemetryConstantLabels *bool
+}
+
+// Load reads the configuration file and the inline YAML configuration, which overrides
+// the settings it contains, applies environment overrides and the command line
+// overrides, and validates the result. An empty path or inline is
+// skipped; an empty inline falls back to the InlineConfigEnv environment variable.
+// In strict mode, enabled by the strict argument or by `validation: strict` in either,
+// unknown keys and any value that would otherwise be corrected with a warning are errors.
+func Load(path, inline string, strict bool, overrides Overrides) (*config, error) {
 	cfg := defaultConfig()
 
+	if inline == "" {
+		inline = os.Getenv(InlineConfigEnv)
+	}
+
+	var documents [][]byte
 	if path != "" {
 		data, err := os.ReadFile(path)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read Config file: %w", err)
 		}
 
+		documents = append(documents, data)
+	}
+
+	if inline != "" {
+		documents = append(documents, []byte(inline))
+	}
+
+	for _, data := range documents {
 		if err := yaml.Unmarshal(data, cfg); err != nil {
 			return nil, fmt.Errorf("failed to parse Config: %w", err)
 		}
+
+		var topology struct {
+			SurrealDB struct {
+				StorageEngine  *string `yaml:"storage_engine"`
+				DeploymentMode *string `yaml:"deployment_mode"`
+			} `yaml:"surrealdb"`
+		}
+		if err := yaml.Unmarshal(data, &topology); err == nil {
+			cfg.configured.storageEngine = cfg.configured.storageEngine || topology.SurrealDB.StorageEngine != nil
+			cfg.configured.deploymentMode = cfg.configured.deploymentMode || topology.SurrealDB.DeploymentMode != nil
+		}
+	}
+
+	strict = strict || cfg.Validation == ValidationStrict
+
+	if strict {
+		for _, data := range documents {
+			if err := checkKnownFields(data); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	applyEnvironmentOverrides(cfg)
 
-	validateAndFix(cfg)
+	for name, enabled := range overrides.Collectors {
+		if err := cfg.setCollectorEnabled(name, enabled); err != nil {
+			return nil, err
+		}
+	}
+	if overrides.TelemetryPort != nil {
+		cfg.Exporter.TelemetryPort = *overrides.TelemetryPort
+	}
+	if overrides.TelemetryConstantLabels != nil {
+		cfg.Exporter.TelemetryConstantLabels = *overrides.TelemetryConstantLabels
+	}
+
+	fixes := validateAndFix(cfg)
+
+	if strict && len(fixes) > 0 {
+		return nil, fmt.Errorf("invalid Config in strict mode: %w", errors.Join(fixes...))
+	}
 
 	return cfg, nil
 }
 
+// checkKnownFields fails when the configuration file contains keys that do not map to any setting.
+func checkKnownFields(data []byte) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(defaultConfig()); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("invalid Config in strict mode: %w", err)
+	}
+
+	return nil
+}
+
+// validator collects configuration corrections made during validation.
+type validator struct {
+	fixes []error
+}
+
+// fix logs a correction warning and records it as a validation problem.
+func (v *validator) fix(msg string, args ...any) {
+	slog.Warn(msg, args...)
+
+	var details strings.Builder
+	for i := 0; i+1 < len(args); i += 2 {
+		fmt.Fprintf(&details, " %v=%v", args[i], args[i+1])
+	}
+
+	v.fixes = append(v.fixes, errors.New(msg+details.String()))
+}
+
 // validateAndFix validates configuration and fixes misconfigurations with warnings.
-func validateAndFix(cfg *config) {
-	validateExporterConfig(cfg)
-	validateSurrealDBConfig(cfg)
-	validateCollectorsConfig(cfg)
+// It returns all corrections that were made.
+func validateAndFix(cfg *config) []error {
+	v := &validator{}
+
+	if cfg.Validation != "" && cfg.Validation != ValidationLenient && cfg.Validation != ValidationStrict {
+		v.fix("validation has invalid value, using lenient validation",
+			"provided", cfg.Validation,
+			"allowed_values", []string{ValidationLenient, ValidationStrict})
+		cfg.Validation = ValidationLenient
+	}
+
+	v.validateExporterConfig(cfg)
+	v.validateSurrealDBConfig(cfg)
+	v.validateCollectorsConfig(cfg)
+	v.validateLoggingConfig(cfg)
+
+	return v.fixes
 }
 
 // validateExporterConfig validates exporter settings.
-func validateExporterConfig(cfg *config) {
+func (v *validator) validateExporterConfig(cfg *config) {
 	if cfg.Exporter.Port < MinPort || cfg.Exporter.Port > MaxPort {
-		slog.Warn("exporter port is out of valid range, using default",
+		v.fix("exporter port is out of valid range, using default",
 			"provided", cfg.Exporter.Port,
 			"valid_range", fmt.Sprintf("%d-%d", MinPort, MaxPort),
 			"default", DefaultPort)
@@ -172,40 +778,223 @@ func validateExporterConfig(cfg *config) {
 	}
 
 	if cfg.Exporter.MetricsPath == "" {
-		slog.Warn("metrics_path is empty, using default",
+		v.fix("metrics_path is empty, using default",
 			"default", DefaultMetricsPath)
 		cfg.Exporter.MetricsPath = DefaultMetricsPath
 	} else if !strings.HasPrefix(cfg.Exporter.MetricsPath, "/") {
-		slog.Warn("metrics_path must start with '/', adding prefix",
+		v.fix("metrics_path must start with '/', adding prefix",
 			"provided", cfg.Exporter.MetricsPath,
 			"corrected", "/"+cfg.Exporter.MetricsPath)
 		cfg.Exporter.MetricsPath = "/" + cfg.Exporter.MetricsPath
 	}
 
 	if !metricsPathRegex.MatchString(cfg.Exporter.MetricsPath) {
-		slog.Warn("metrics_path contains invalid characters, using default",
+		v.fix("metrics_path contains invalid characters, using default",
 			"provided", cfg.Exporter.MetricsPath,
 			"allowed_pattern", "^/[a-zA-Z0-9_\\-/]*$",
 			"default", DefaultMetricsPath)
 		cfg.Exporter.MetricsPath = DefaultMetricsPath
 	}
+
+	if !slices.Contains(AllowedOverlappingScrapes, cfg.Exporter.OverlappingScrapes) {
+		v.fix("overlapping_scrapes has invalid value, allowing overlapping scrapes",
+			"provided", cfg.Exporter.OverlappingScrapes,
+			"allowed_values", AllowedOverlappingScrapes)
+		cfg.Exporter.OverlappingScrapes = domain.OverlappingScrapesAllow
+	}
+
+	if cfg.Exporter.CacheTTL < 0 {
+		v.fix("cache_ttl cannot be negative, disabling response caching",
+			"provided", cfg.Exporter.CacheTTL)
+		cfg.Exporter.CacheTTL = 0
+	}
+
+	if cfg.Exporter.ScrapeBudget < 0 {
+		v.fix("scrape_budget cannot be negative, disabling it",
+			"provided", cfg.Exporter.ScrapeBudget)
+		cfg.Exporter.ScrapeBudget = 0
+	}
+
+	if t := cfg.Exporter.TelemetryPort; t != 0 && (t < MinPort || t > MaxPort || t == cfg.Exporter.Port) {
+		v.fix("telemetry_port is out of valid range or equal to exporter port, serving telemetry on the exporter port",
+			"provided", t)
+		cfg.Exporter.TelemetryPort = 0
+	}
+
+	v.validatePushConfig(cfg)
+	v.validateDebugConfig(cfg)
+	v.validateLabelSanitizationConfig(cfg)
+	v.validateRenamesConfig(cfg)
+	v.validateTenantsConfig(cfg)
+	v.validateNamespaceEndpointsConfig(cfg)
+
+	if cfg.Exporter.Admin.Enabled && cfg.Exporter.Admin.Token == "" {
+		v.fix("admin endpoints need a token, disabling them")
+		cfg.Exporter.Admin.Enabled = false
+	}
+}
+
+// validateNamespaceEndpointsConfig validates the namespace patterns of the per-namespace
+// metrics endpoints.
+func (v *validator) validateNamespaceEndpointsConfig(cfg *config) {
+	n := &cfg.Exporter.NamespaceEndpoints
+	if !n.Enabled {
+		return
+	}
+
+	if len(n.Namespaces) == 0 || !validNamespacePatterns(n.Namespaces) {
+		v.fix("invalid namespace_endpoints namespaces, serving every namespace",
+			"provided", n.Namespaces,
+			"expected", "namespace patterns (wildcards allowed: *)")
+		n.Namespaces = []string{"*"}
+	}
+}
+
+// validateTenantsConfig removes tenants without a name, token or valid namespace
+// patterns, and tenants reusing the token of an earlier tenant.
+func (v *validator) validateTenantsConfig(cfg *config) {
+	t := &cfg.Exporter.Tenants
+
+	tokens := make(map[string]bool, len(t.Tokens))
+	valid := make([]tenantConfig, 0, len(t.Tokens))
+	for _, tenant := range t.Tokens {
+		if tenant.Name == "" || tenant.Token == "" || len(tenant.Namespaces) == 0 ||
+			!validNamespacePatterns(tenant.Namespaces) {
+			v.fix("invalid tenant, removing it",
+				"name", tenant.Name,
+				"namespaces", tenant.Namespaces,
+				"expected", "name, token and namespace patterns (wildcards allowed: *)")
+			continue
+		}
+
+		if tokens[tenant.Token] {
+			v.fix("tenant token is already used by another tenant, removing the tenant",
+				"name", tenant.Name)
+			continue
+		}
+
+		tokens[tenant.Token] = true
+		valid = append(valid, tenant)
+	}
+	t.Tokens = valid
+}
+
+// validNamespacePatterns reports whether every pattern is a valid path.Match pattern.
+func validNamespacePatterns(patterns []string) bool {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); pattern == "" || err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateLabelSanitizationConfig validates label sanitization settings.
+func (v *validator) validateLabelSanitizationConfig(cfg *config) {
+	l := &cfg.Exporter.LabelSanitization
+	if !l.Enabled {
+		return
+	}
+
+	if len(l.Labels) == 0 {
+		v.fix("label_sanitization has no labels, using defaults",
+			"default", defaultSanitizedLabels)
+		l.Labels = slices.Clone(defaultSanitizedLabels)
+	}
+
+	if l.MaxLength < MinLabelMaxLength {
+		v.fix("label_sanitization max_length is too small, using default",
+			"provided", l.MaxLength,
+			"minimum", MinLabelMaxLength,
+			"default", DefaultLabelMaxLength)
+		l.MaxLength = DefaultLabelMaxLength
+	}
+}
+
+// validateRenamesConfig removes renames from or to invalid metric and label names.
+func (v *validator) validateRenamesConfig(cfg *config) {
+	r := &cfg.Exporter.Renames
+
+	for from, to := range r.Metrics {
+		if !metricPrefixRegex.MatchString(from) || !metricPrefixRegex.MatchString(to) {
+			v.fix("renames has an invalid metric name, removing the rename", "from", from, "to", to)
+			delete(r.Metrics, from)
+		}
+	}
+
+	for from, to := range r.Labels {
+		if !labelNameRegex.MatchString(from) || !labelNameRegex.MatchString(to) || strings.HasPrefix(to, "__") {
+			v.fix("renames has an invalid label name, removing the rename", "from", from, "to", to)
+			delete(r.Labels, from)
+		}
+	}
+}
+
+// validateDebugConfig validates debug server settings.
+func (v *validator) validateDebugConfig(cfg *config) {
+	d := &cfg.Exporter.Debug
+	if !d.Pprof || cfg.Exporter.TelemetryPort != 0 {
+		return
+	}
+
+	if d.Port < MinPort || d.Port > MaxPort || d.Port == cfg.Exporter.Port {
+		v.fix("debug port is out of valid range or equal to exporter port, using default",
+			"provided", d.Port,
+			"default", DefaultDebugPort)
+		d.Port = DefaultDebugPort
+	}
+}
+
+// validatePushConfig validates push mode settings.
+func (v *validator) validatePushConfig(cfg *config) {
+	p := &cfg.Exporter.Push
+
+	if !p.Enabled {
+		if p.PushOnly {
+			v.fix("push_only is set but push is disabled, serving metrics over HTTP")
+			p.PushOnly = false
+		}
+		return
+	}
+
+	if _, err := url.ParseRequestURI(p.URL); err != nil || p.URL == "" {
+		v.fix("push is enabled but url is empty or invalid, disabling push",
+			"provided", p.URL)
+		p.Enabled = false
+		p.PushOnly = false
+		return
+	}
+
+	if strings.TrimSpace(p.Job) == "" {
+		v.fix("push job is empty, using default",
+			"default", DefaultPushJob)
+		p.Job = DefaultPushJob
+	}
+
+	if p.Interval < MinPushInterval {
+		v.fix("push interval is too short, using minimum value",
+			"provided", p.Interval,
+			"minimum", MinPushInterval)
+		p.Interval = MinPushInterval
+	}
 }
 
 // validateSurrealDBConfig validates SurrealDB connection settings.
-func validateSurrealDBConfig(cfg *config) {
+func (v *validator) validateSurrealDBConfig(cfg *config) {
 	if strings.TrimSpace(cfg.SurrealDB.ClusterName) == "" {
-		slog.Warn("cluster_name is empty, using default value",
+		v.fix("cluster_name is empty, using default value",
 			"default", DefaultClusterName)
 		cfg.SurrealDB.ClusterName = DefaultClusterName
 	}
 
 	if strings.TrimSpace(cfg.SurrealDB.StorageEngine) == "" {
-		slog.Warn("storage_engine is empty, using default value",
+		v.fix("storage_engine is empty, using default value",
 			"default", DefaultStorageEngine,
 			"allowed_values", AllowedStorageEngines)
 		cfg.SurrealDB.StorageEngine = DefaultStorageEngine
 	} else if !slices.Contains(AllowedStorageEngines, cfg.SurrealDB.StorageEngine) {
-		slog.Warn("storage_engine has invalid value, using default value",
+		v.fix("storage_engine has invalid value, using default value",
 			"provided", cfg.SurrealDB.StorageEngine,
 			"default", DefaultStorageEngine,
 			"allowed_values", AllowedStorageEngines)
@@ -213,12 +1002,12 @@ func validateSurrealDBConfig(cfg *config) {
 	}
 
 	if strings.TrimSpace(cfg.SurrealDB.DeploymentMode) == "" {
-		slog.Warn("deployment_mode is empty, using default value",
+		v.fix("deployment_mode is empty, using default value",
 			"default", DefaultDeploymentMode,
 			"allowed_values", AllowedDeploymentModes)
 		cfg.SurrealDB.DeploymentMode = DefaultDeploymentMode
 	} else if !slices.Contains(AllowedDeploymentModes, cfg.SurrealDB.DeploymentMode) {
-		slog.Warn("deployment_mode has invalid value, using default value",
+		v.fix("deployment_mode has invalid value, using default value",
 			"provided", cfg.SurrealDB.DeploymentMode,
 			"default", DefaultDeploymentMode,
 			"allowed_values", AllowedDeploymentModes)
@@ -226,101 +1015,841 @@ func validateSurrealDBConfig(cfg *config) {
 	}
 
 	if cfg.SurrealDB.Timeout < MinTimeout {
-		slog.Warn("surrealdb timeout is too short, using minimum value",
+		v.fix("surrealdb timeout is too short, using minimum value",
 			"provided", cfg.SurrealDB.Timeout,
 			"minimum", MinTimeout)
 		cfg.SurrealDB.Timeout = MinTimeout
 	} else if cfg.SurrealDB.Timeout > MaxTimeout {
-		slog.Warn("surrealdb timeout is too long, using maximum value",
+		v.fix("surrealdb timeout is too long, using maximum value",
 			"provided", cfg.SurrealDB.Timeout,
 			"maximum", MaxTimeout)
 		cfg.SurrealDB.Timeout = MaxTimeout
 	}
-}
 
-// validateCollectorsConfig validates collectors settings.
-func validateCollectorsConfig(cfg *config) {
-	if cfg.Collectors.LiveQuery.Enabled && cfg.SurrealDB.DeploymentMode != "single" {
-		slog.Warn("live_query collector is only available for 'single' deployment_mode, disabling it",
-			"current_deployment_mode", cfg.SurrealDB.DeploymentMode)
-		cfg.Collectors.LiveQuery.Enabled = false
+	v.validateConnectionConfig(cfg)
+
+	if r := &cfg.SurrealDB.RateLimit; r.QueriesPerSecond < 0 {
+		v.fix("rate_limit queries_per_second cannot be negative, disabling the limit",
+			"provided", r.QueriesPerSecond)
+		r.QueriesPerSecond = 0
+	} else if r.QueriesPerSecond > 0 && r.Burst < 1 {
+		v.fix("rate_limit burst must be at least 1, using default value",
+			"provided", r.Burst,
+			"default", DefaultRateLimitBurst)
+		r.Burst = DefaultRateLimitBurst
 	}
 
-	validateTablePatterns("live_query.tables.include", &cfg.Collectors.LiveQuery.Tables.Include)
-	validateTablePatterns("live_query.tables.exclude", &cfg.Collectors.LiveQuery.Tables.Exclude)
+	validCredentials := make([]credentialConfig, 0, len(cfg.SurrealDB.Credentials))
+	for _, credential := range cfg.SurrealDB.Credentials {
+		if credential.Level == "" {
+			credential.Level = domain.AuthLevelDatabase
+		}
 
-	validateTablePatterns("stats_table.tables.include", &cfg.Collectors.StatsTable.Tables.Include)
-	validateTablePatterns("stats_table.tables.exclude", &cfg.Collectors.StatsTable.Tables.Exclude)
+		if !databasePatternRegex.MatchString(credential.Pattern) || credential.Username == "" ||
+			!slices.Contains(AllowedAuthLevels, credential.Level) {
+			v.fix("invalid surrealdb credentials override, removing it",
+				"pattern", credential.Pattern,
+				"username", credential.Username,
+				"level", credential.Level,
+				"expected_format", "namespace:database (wildcards allowed: *)",
+				"allowed_levels", AllowedAuthLevels)
+			continue
+		}
 
-	validateTablePatterns("record_count.tables.include", &cfg.Collectors.RecordCount.Tables.Include)
-	validateTablePatterns("record_count.tables.exclude", &cfg.Collectors.RecordCount.Tables.Exclude)
+		validCredentials = append(validCredentials, credential)
+	}
+	cfg.SurrealDB.Credentials = validCredentials
 
-	validateOpenTelemetryConfig(cfg)
+	v.validateScopeConfig(cfg)
 }
 
-// validateTablePatterns validates and filters invalid table patterns.
-func validateTablePatterns(fieldName string, patterns *[]string) {
-	if patterns == nil || len(*patterns) == 0 {
-		return
+// validateScopeConfig validates the level of the SurrealDB user and the scope it
+// monitors. Users without root access can only monitor a scope, and database users only
+// single databases.
+func (v *validator) validateScopeConfig(cfg *config) {
+	c := &cfg.SurrealDB
+
+	if c.Level == "" {
+		c.Level = domain.AuthLevelRoot
+	} else if !slices.Contains(AllowedUserLevels, c.Level) {
+		v.fix("invalid surrealdb level, using root",
+			"provided", c.Level,
+			"allowed_values", AllowedUserLevels)
+		c.Level = domain.AuthLevelRoot
 	}
 
-	validPatterns := make([]string, 0, len(*patterns))
-	for _, pattern := range *patterns {
-		if tableFilterPatternRegex.MatchString(pattern) {
-			validPatterns = append(validPatterns, pattern)
-		} else {
-			slog.Warn("invalid table filter pattern, removing from list",
-				"field", fieldName,
-				"pattern", pattern,
-				"expected_format", "namespace:database:table (wildcards allowed: *)")
+	validScope := make([]string, 0, len(c.Scope))
+	for _, entry := range c.Scope {
+		if !scopeEntryRegex.MatchString(entry) {
+			v.fix("invalid surrealdb scope entry, removing it",
+				"entry", entry,
+				"expected_format", "namespace or namespace:database")
+			continue
+		}
+
+		if c.Level == domain.AuthLevelDatabase && !strings.Contains(entry, ":") {
+			v.fix("database users cannot monitor a whole namespace, removing scope entry",
+				"entry", entry)
+			continue
+		}
+
+		if slices.Contains(validScope, entry) {
+			continue
 		}
+
+		validScope = append(validScope, entry)
+	}
+	c.Scope = validScope
+
+	if c.Level != domain.AuthLevelRoot && len(c.Scope) == 0 {
+		v.fix("surrealdb users without root access require a scope, using root level",
+			"level", c.Level)
+		c.Level = domain.AuthLevelRoot
 	}
-	*patterns = validPatterns
 }
 
-// validateOpenTelemetryConfig validates OpenTelemetry collector settings.
-func validateOpenTelemetryConfig(cfg *config) {
-	otel := &cfg.Collectors.OpenTelemetry
+// validateConnectionConfig validates the WebSocket keep-alive settings.
+func (v *validator) validateConnectionConfig(cfg *config) {
+	c := &cfg.SurrealDB.Connection
 
-	if otel.Enabled && otel.GRPCEndpoint == "" {
-		slog.Warn("open_telemetry is enabled but grpc_endpoint is empty, using default",
-			"default", ":4317")
-		otel.GRPCEndpoint = ":4317"
+	if c.PingInterval < 0 {
+		v.fix("connection ping_interval cannot be negative, disabling pings",
+			"provided", c.PingInterval)
+		c.PingInterval = 0
 	}
 
-	if otel.BatchSize <= 0 {
-		slog.Warn("open_telemetry batch_size must be positive, using default",
-			"provided", otel.BatchSize,
-			"default", 100)
-		otel.BatchSize = 100
+	if c.IdleTimeout < 0 {
+		v.fix("connection idle_timeout cannot be negative, keeping idle connections open",
+			"provided", c.IdleTimeout)
+		c.IdleTimeout = 0
 	}
 
-	if otel.BatchTimeoutMs <= 0 {
-		slog.Warn("open_telemetry batch_timeout_ms must be positive, using default",
-			"provided", otel.BatchTimeoutMs,
-			"default", 1000)
-		otel.BatchTimeoutMs = 1000
+	if c.WriteTimeout <= 0 {
+		v.fix("connection write_timeout must be positive, using default value",
+			"provided", c.WriteTimeout,
+			"default", DefaultConnectionWriteTimeout)
+		c.WriteTimeout = DefaultConnectionWriteTimeout
 	}
 
-	if otel.MaxRecvSize <= 0 {
-		slog.Warn("open_telemetry max_recv_size must be positive, using default",
-			"provided", otel.MaxRecvSize,
-			"default", 4)
-		otel.MaxRecvSize = 4
+	if c.ReadTimeout < 0 || (c.ReadTimeout > 0 && c.PingInterval == 0) {
+		v.fix("connection read_timeout needs a ping_interval and cannot be negative, disabling it",
+			"provided", c.ReadTimeout,
+			"ping_interval", c.PingInterval)
+		c.ReadTimeout = 0
+	} else if c.ReadTimeout > 0 && c.ReadTimeout <= c.PingInterval {
+		v.fix("connection read_timeout must be greater than ping_interval, using twice the ping_interval",
+			"provided", c.ReadTimeout,
+			"ping_interval", c.PingInterval)
+		c.ReadTimeout = 2 * c.PingInterval
 	}
+}
 
-	validStrategies := []string{"UnderscoreEscapingWithSuffixes", "NoTranslation"}
-	if otel.TranslationStrategy == "" {
-		slog.Warn("open_telemetry translation_strategy is empty, using default",
-			"default", "UnderscoreEscapingWithSuffixes")
+// validateCollectorsConfig validates collectors settings.
+func (v *validator) validateCollectorsConfig(cfg *config) {
+	v.validateTopologyCollectors(cfg)
+
+	v.validateInfoCacheConfig(cfg)
+	v.validateInfoBudgetConfig(cfg)
+
+	if !slices.Contains(AllowedLoadAverages, cfg.Collectors.Info.LoadAverage) {
+		v.fix("invalid info load average mode, using default value",
+			"provided", cfg.Collectors.Info.LoadAverage,
+			"default", domain.LoadAveragePeriods,
+			"allowed_values", AllowedLoadAverages)
+		cfg.Collectors.Info.LoadAverage = domain.LoadAveragePeriods
+	}
+
+	v.validateRecordCountConfig(cfg)
+
+	if cfg.Collectors.LiveQuery.SchemaPollInterval < 0 {
+		v.fix("live_query schema_poll_interval cannot be negative, disabling schema watch",
+			"provided", cfg.Collectors.LiveQuery.SchemaPollInterval)
+		cfg.Collectors.LiveQuery.SchemaPollInterval = 0
+	}
+
+	if cfg.Collectors.LiveQuery.MaxTables < 0 {
+		v.fix("live_query max_tables cannot be negative, using default",
+			"provided", cfg.Collectors.LiveQuery.MaxTables,
+			"default", DefaultLiveQueryMaxTables)
+		cfg.Collectors.LiveQuery.MaxTables = DefaultLiveQueryMaxTables
+	}
+
+	validSampling := make([]liveQuerySamplingConfig, 0, len(cfg.Collectors.LiveQuery.Sampling))
+	for _, sampling := range cfg.Collectors.LiveQuery.Sampling {
+		if !tableFilterPatternRegex.MatchString(sampling.Table) || sampling.SampleRate <= 0 || sampling.SampleRate > 1 {
+			v.fix("invalid live_query sampling, removing it",
+				"table", sampling.Table,
+				"sample_rate", sampling.SampleRate,
+				"expected", "namespace:database:table pattern and sample_rate in (0, 1]")
+			continue
+		}
+
+		validSampling = append(validSampling, sampling)
+	}
+	cfg.Collectors.LiveQuery.Sampling = validSampling
+
+	v.validateTablePatterns("live_query.tables.include", &cfg.Collectors.LiveQuery.Tables.Include)
+	v.validateTablePatterns("live_query.tables.exclude", &cfg.Collectors.LiveQuery.Tables.Exclude)
+
+	if !slices.Contains(AllowedStatsStrategies, cfg.Collectors.StatsTable.Strategy) {
+		v.fix("stats_table strategy has invalid value, using default",
+			"provided", cfg.Collectors.StatsTable.Strategy,
+			"allowed_values", AllowedStatsStrategies,
+			"default", DefaultStatsTableStrategy)
+		cfg.Collectors.StatsTable.Strategy = DefaultStatsTableStrategy
+	}
+
+	v.validateTablePatterns("stats_table.tables.include", &cfg.Collectors.StatsTable.Tables.Include)
+	v.validateTablePatterns("stats_table.tables.exclude", &cfg.Collectors.StatsTable.Tables.Exclude)
+
+	v.validateTablePatterns("record_count.tables.include", &cfg.Collectors.RecordCount.Tables.Include)
+	v.validateTablePatterns("record_count.tables.exclude", &cfg.Collectors.RecordCount.Tables.Exclude)
+
+	v.validateOpenTelemetryConfig(cfg)
+	v.validateOperationClassificationConfig(cfg)
+	v.validateExternalCollectors(cfg)
+
+	if cfg.Collectors.Textfile.Enabled && cfg.Collectors.Textfile.Directory == "" {
+		v.fix("textfile collector is enabled but directory is empty, disabling it")
+		cfg.Collectors.Textfile.Enabled = false
+	}
+
+	v.validateProxyConfig(cfg)
+	v.validateIndexUsageConfig(cfg)
+
+	if cfg.Collectors.Derived.LargeTableRecords < 0 {
+		v.fix("derived large_table_records must not be negative, using default",
+			"provided", cfg.Collectors.Derived.LargeTableRecords,
+			"default", DefaultDerivedLargeTableRecords)
+		cfg.Collectors.Derived.LargeTableRecords = DefaultDerivedLargeTableRecords
+	}
+
+	if cfg.Collectors.Derived.IndexBuildingStuckAfter <= 0 {
+		v.fix("derived index_building_stuck_after must be positive, using default",
+			"provided", cfg.Collectors.Derived.IndexBuildingStuckAfter,
+			"default", DefaultDerivedIndexBuildingStuckAfter)
+		cfg.Collectors.Derived.IndexBuildingStuckAfter = DefaultDerivedIndexBuildingStuckAfter
+	}
+}
+
+// validateTopologyCollectors disables the collectors the deployment_mode does not
+// support. It runs again when a detected topology changes the deployment_mode.
+func (v *validator) validateTopologyCollectors(cfg *config) {
+	if cfg.Collectors.LiveQuery.Enabled && cfg.SurrealDB.DeploymentMode != "single" {
+		v.fix("live_query collector is only available for 'single' deployment_mode, disabling it",
+			"current_deployment_mode", cfg.SurrealDB.DeploymentMode)
+		cfg.Collectors.LiveQuery.Enabled = false
+	}
+
+	if cfg.Collectors.ConsistencyAudit.Enabled && (!cfg.Collectors.LiveQuery.Enabled || !cfg.Collectors.StatsTable.Enabled) {
+		v.fix("consistency_audit collector needs the live_query and stats_table collectors, disabling it",
+			"live_query_enabled", cfg.Collectors.LiveQuery.Enabled,
+			"stats_table_enabled", cfg.Collectors.StatsTable.Enabled)
+		cfg.Collectors.ConsistencyAudit.Enabled = false
+	}
+}
+
+// validateIndexUsageConfig removes the sample queries without a unique name, a database
+// or a single SELECT statement.
+func (v *validator) validateIndexUsageConfig(cfg *config) {
+	names := make(map[string]bool)
+	validQueries := make([]indexSampleQueryConfig, 0, len(cfg.Collectors.IndexUsage.Queries))
+	for _, query := range cfg.Collectors.IndexUsage.Queries {
+		statement := strings.TrimSuffix(strings.TrimSpace(query.Query), ";")
+		fields := strings.Fields(statement)
+
+		if query.Name == "" || names[query.Name] || !indexSampleDatabaseRegex.MatchString(query.Database) ||
+			len(fields) == 0 || !strings.EqualFold(fields[0], "SELECT") || strings.Contains(statement, ";") {
+			v.fix("invalid index_usage sample query, removing it",
+				"name", query.Name,
+				"database", query.Database,
+				"query", query.Query,
+				"expected", "unique name, namespace:database and a single SELECT statement")
+			continue
+		}
+
+		names[query.Name] = true
+		validQueries = append(validQueries, query)
+	}
+	cfg.Collectors.IndexUsage.Queries = validQueries
+}
+
+// validateProxyConfig disables the proxy without a valid URL and defaults its timeout.
+func (v *validator) validateProxyConfig(cfg *config) {
+	proxy := &cfg.Collectors.Proxy
+	if !proxy.Enabled {
+		return
+	}
+
+	if u, err := url.Parse(proxy.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.fix("proxy collector needs an http or https url, disabling it", "url", proxy.URL)
+		proxy.Enabled = false
+		return
+	}
+
+	if proxy.Timeout <= 0 {
+		v.fix("proxy timeout must be positive, using default",
+			"provided", proxy.Timeout,
+			"default", DefaultProxyTimeout)
+		proxy.Timeout = DefaultProxyTimeout
+	}
+}
+
+// validateExternalCollectors removes external collectors without a unique name or a
+// command and defaults their timeouts.
+func (v *validator) validateExternalCollectors(cfg *config) {
+	seen := make(map[string]struct{})
+	valid := make([]externalCollectorConfig, 0, len(cfg.Collectors.External))
+
+	for i, external := range cfg.Collectors.External {
+		if _, duplicate := seen[external.Name]; external.Name == "" || duplicate || len(external.Command) == 0 {
+			v.fix("external collector needs a unique name and a command, removing it",
+				"index", i,
+				"name", external.Name,
+				"command", external.Command)
+			continue
+		}
+		seen[external.Name] = struct{}{}
+
+		if external.Timeout <= 0 {
+			external.Timeout = DefaultExternalCollectorTimeout
+		}
+
+		valid = append(valid, external)
+	}
+
+	cfg.Collectors.External = valid
+}
+
+// validateInfoCacheConfig validates INFO cache TTLs.
+func (v *validator) validateInfoCacheConfig(cfg *config) {
+	ttls := map[string]*time.Duration{
+		"info.cache.namespace_ttl": &cfg.Collectors.Info.Cache.NamespaceTTL,
+		"info.cache.database_ttl":  &cfg.Collectors.Info.Cache.DatabaseTTL,
+		"info.cache.table_ttl":     &cfg.Collectors.Info.Cache.TableTTL,
+		"info.cache.index_ttl":     &cfg.Collectors.Info.Cache.IndexTTL,
+
+		"info.cache.incremental_table_ttl": &cfg.Collectors.Info.Cache.IncrementalTableTTL,
+	}
+
+	for field, ttl := range ttls {
+		if *ttl < 0 {
+			v.fix("cache ttl cannot be negative, disabling cache for this level",
+				"field", field,
+				"provided", *ttl)
+			*ttl = 0
+		}
+	}
+
+	cache := &cfg.Collectors.Info.Cache
+	if !slices.Contains(AllowedTableLists, cache.TableList) {
+		v.fix("invalid info table_list mode, using default value",
+			"provided", cache.TableList,
+			"default", domain.TableListShared,
+			"allowed_values", AllowedTableLists)
+		cache.TableList = domain.TableListShared
+	}
+
+	if cache.TableList == domain.TableListTTL && cache.TableListTTL <= 0 {
+		v.fix("info table_list_ttl must be positive in ttl mode, using default value",
+			"provided", cache.TableListTTL,
+			"default", DefaultTableListTTL)
+		cache.TableListTTL = DefaultTableListTTL
+	}
+}
+
+// validateInfoBudgetConfig validates the INFO timeout shares.
+func (v *validator) validateInfoBudgetConfig(cfg *config) {
+	shares := []struct {
+		field        string
+		share        *float64
+		defaultValue float64
+	}{
+		{"info.timeout_budget.root", &cfg.Collectors.Info.TimeoutBudget.Root, DefaultInfoRootBudget},
+		{"info.timeout_budget.namespace", &cfg.Collectors.Info.TimeoutBudget.Namespace, DefaultInfoNamespaceBudget},
+		{"info.timeout_budget.table", &cfg.Collectors.Info.TimeoutBudget.Table, DefaultInfoTableBudget},
+	}
+
+	for _, s := range shares {
+		if *s.share <= 0 || *s.share > 1 {
+			v.fix("timeout budget share must be greater than 0 and at most 1, using default value",
+				"field", s.field,
+				"provided", *s.share,
+				"default", s.defaultValue)
+			*s.share = s.defaultValue
+		}
+	}
+}
+
+// validateRecordCountConfig validates record count refresh intervals.
+func (v *validator) validateRecordCountConfig(cfg *config) {
+	rc := &cfg.Collectors.RecordCount
+
+	if rc.Interval < 0 {
+		v.fix("record_count interval cannot be negative, counting on every scrape",
+			"provided", rc.Interval)
+		rc.Interval = 0
+	}
+
+	validOverrides := make([]recordCountOverrideConfig, 0, len(rc.Overrides))
+	for _, override := range rc.Overrides {
+		if !tableFilterPatternRegex.MatchString(override.Table) || override.Interval <= 0 {
+			v.fix("invalid record_count override, removing it",
+				"table", override.Table,
+				"interval", override.Interval,
+				"expected_table_format", "namespace:database:table (wildcards allowed: *)")
+			continue
+		}
+
+		validOverrides = append(validOverrides, override)
+	}
+	rc.Overrides = validOverrides
+
+	if rc.TopN < 0 {
+		v.fix("record_count top_n cannot be negative, exporting all tables",
+			"provided", rc.TopN)
+		rc.TopN = 0
+	}
+
+	if rc.TopN > 0 && rc.TopNInterval <= 0 {
+		v.fix("record_count top_n_interval must be positive, using default",
+			"provided", rc.TopNInterval,
+			"default", DefaultTopNInterval)
+		rc.TopNInterval = DefaultTopNInterval
+	}
+
+	validPartitions := make([]recordCountPartitionConfig, 0, len(rc.Partitions))
+	for _, partition := range rc.Partitions {
+		if !tableFilterPatternRegex.MatchString(partition.Table) || !validBoundaries(partition.Boundaries) {
+			v.fix("invalid record_count partitions, removing them",
+				"table", partition.Table,
+				"boundaries", partition.Boundaries,
+				"expected", "namespace:database:table (wildcards allowed: *) with ascending integer or string boundaries")
+			continue
+		}
+
+		if partition.Concurrency <= 0 {
+			v.fix("record_count partitions concurrency must be positive, using default",
+				"table", partition.Table,
+				"provided", partition.Concurrency,
+				"default", DefaultPartitionConcurrency)
+			partition.Concurrency = DefaultPartitionConcurrency
+		}
+
+		validPartitions = append(validPartitions, partition)
+	}
+	rc.Partitions = validPartitions
+
+	if len(rc.Overrides) > 0 && rc.Interval == 0 {
+		v.fix("record_count overrides require background counting, using default interval",
+			"default", DefaultRecordCountInterval)
+		rc.Interval = DefaultRecordCountInterval
+	}
+
+	if !slices.Contains(AllowedRecordCountModes, rc.Mode) {
+		v.fix("record_count mode has invalid value, using default",
+			"provided", rc.Mode,
+			"allowed_values", AllowedRecordCountModes,
+			"default", DefaultRecordCountMode)
+		rc.Mode = DefaultRecordCountMode
+	}
+
+	if rc.Mode == RecordCountModeIncremental && !cfg.Collectors.LiveQuery.Enabled {
+		v.fix("record_count incremental mode requires the live_query collector, using scan mode")
+		rc.Mode = RecordCountModeScan
+	}
+
+	if rc.ReconcileInterval <= 0 {
+		v.fix("record_count reconcile_interval must be positive, using default",
+			"provided", rc.ReconcileInterval,
+			"default", DefaultReconcileInterval)
+		rc.ReconcileInterval = DefaultReconcileInterval
+	}
+
+	if rc.Mode == RecordCountModeIncremental && len(rc.Overrides) > 0 {
+		v.fix("record_count overrides do not apply in incremental mode, counts are reconciled every reconcile_interval",
+			"reconcile_interval", rc.ReconcileInterval)
+		rc.Overrides = nil
+	}
+}
+
+// validateTablePatterns validates and filters invalid table patterns.
+func (v *validator) validateTablePatterns(fieldName string, patterns *[]string) {
+	if patterns == nil || len(*patterns) == 0 {
+		return
+	}
+
+	validPatterns := make([]string, 0, len(*patterns))
+	for _, pattern := range *patterns {
+		if tableFilterPatternRegex.MatchString(pattern) {
+			validPatterns = append(validPatterns, pattern)
+		} else {
+			v.fix("invalid table filter pattern, removing from list",
+				"field", fieldName,
+				"pattern", pattern,
+				"expected_format", "namespace:database:table (wildcards allowed: *)")
+		}
+	}
+	*patterns = validPatterns
+}
+
+// validateOpenTelemetryConfig validates OpenTelemetry collector settings.
+func (v *validator) validateOpenTelemetryConfig(cfg *config) {
+	otel := &cfg.Collectors.OpenTelemetry
+
+	if otel.Enabled && otel.GRPCEndpoint == "" {
+		v.fix("open_telemetry is enabled but grpc_endpoint is empty, using default",
+			"default", ":4317")
+		otel.GRPCEndpoint = ":4317"
+	}
+
+	if otel.BatchSize <= 0 {
+		v.fix("open_telemetry batch_size must be positive, using default",
+			"provided", otel.BatchSize,
+			"default", 100)
+		otel.BatchSize = 100
+	}
+
+	if otel.BatchTimeoutMs <= 0 {
+		v.fix("open_telemetry batch_timeout_ms must be positive, using default",
+			"provided", otel.BatchTimeoutMs,
+			"default", 1000)
+		otel.BatchTimeoutMs = 1000
+	}
+
+	if otel.MaxRecvSize <= 0 {
+		v.fix("open_telemetry max_recv_size must be positive, using default",
+			"provided", otel.MaxRecvSize,
+			"default", 4)
+		otel.MaxRecvSize = 4
+	}
+
+	if otel.TranslationStrategy == "" {
+		v.fix("open_telemetry translation_strategy is empty, using default",
+			"default", "UnderscoreEscapingWithSuffixes")
 		otel.TranslationStrategy = "UnderscoreEscapingWithSuffixes"
-	} else if !slices.Contains(validStrategies, otel.TranslationStrategy) {
-		slog.Warn("open_telemetry translation_strategy has invalid value, using default",
+	} else if !slices.Contains(AllowedTranslationStrategies, otel.TranslationStrategy) {
+		v.fix("open_telemetry translation_strategy has invalid value, using default",
 			"provided", otel.TranslationStrategy,
-			"allowed_values", validStrategies,
+			"allowed_values", AllowedTranslationStrategies,
 			"default", "UnderscoreEscapingWithSuffixes")
 		otel.TranslationStrategy = "UnderscoreEscapingWithSuffixes"
 	}
+
+	if otel.MetricPrefix != "" && !metricPrefixRegex.MatchString(otel.MetricPrefix) {
+		v.fix("open_telemetry metric_prefix is not a valid metric name prefix, using default",
+			"provided", otel.MetricPrefix,
+			"default", DefaultOTLPMetricPrefix)
+		otel.MetricPrefix = DefaultOTLPMetricPrefix
+	}
+
+	for label := range otel.ResourceLabels {
+		if !slices.Contains(AllowedResourceLabels, label) {
+			v.fix("open_telemetry resource_labels has an invalid label, removing it",
+				"provided", label,
+				"allowed_values", AllowedResourceLabels)
+			delete(otel.ResourceLabels, label)
+		}
+	}
+
+	validCompressions := make([]string, 0, len(otel.GRPC.Compression))
+	for _, compression := range otel.GRPC.Compression {
+		if !slices.Contains(AllowedGRPCCompressions, compression) {
+			v.fix("open_telemetry grpc compression has invalid value, removing it",
+				"provided", compression,
+				"allowed_values", AllowedGRPCCompressions)
+			continue
+		}
+
+		validCompressions = append(validCompressions, compression)
+	}
+	otel.GRPC.Compression = validCompressions
+
+	if _, err := regexp.Compile(otel.Logs.SlowQueryPattern); err != nil {
+		v.fix("open_telemetry logs slow_query_pattern is not a valid regular expression, using default",
+			"provided", otel.Logs.SlowQueryPattern,
+			"error", err,
+			"default", DefaultSlowQueryLogPattern)
+		otel.Logs.SlowQueryPattern = DefaultSlowQueryLogPattern
+	}
+
+	if _, err := regexp.Compile(otel.Logs.AuthFailurePattern); err != nil {
+		v.fix("open_telemetry logs auth_failure_pattern is not a valid regular expression, using default",
+			"provided", otel.Logs.AuthFailurePattern,
+			"error", err,
+			"default", DefaultAuthFailureLogPattern)
+		otel.Logs.AuthFailurePattern = DefaultAuthFailureLogPattern
+	}
+
+	if len(otel.Traces.MethodAttributes) == 0 || slices.Contains(otel.Traces.MethodAttributes, "") {
+		v.fix("open_telemetry traces method_attributes must be non-empty attribute names, using default",
+			"provided", otel.Traces.MethodAttributes,
+			"default", DefaultTraceMethodAttributes)
+		otel.Traces.MethodAttributes = DefaultTraceMethodAttributes
+	}
+
+	buckets := otel.Traces.Buckets
+	if len(buckets) == 0 || buckets[0] <= 0 || !slices.IsSorted(buckets) ||
+		len(slices.Compact(slices.Clone(buckets))) != len(buckets) {
+		v.fix("open_telemetry traces buckets must be positive and ascending, using default",
+			"provided", otel.Traces.Buckets,
+			"default", DefaultTraceDurationBuckets)
+		otel.Traces.Buckets = DefaultTraceDurationBuckets
+	}
+
+	v.validatePipelineConfig(otel)
+	v.validateHistogramBuckets(otel)
+
+	if otel.Queue.MaxMetrics < 0 || otel.Queue.MaxBytes < 0 {
+		v.fix("open_telemetry queue limits cannot be negative, disabling them",
+			"max_metrics", otel.Queue.MaxMetrics,
+			"max_bytes", otel.Queue.MaxBytes)
+		otel.Queue.MaxMetrics = max(otel.Queue.MaxMetrics, 0)
+		otel.Queue.MaxBytes = max(otel.Queue.MaxBytes, 0)
+	}
+
+	if !slices.Contains(AllowedQueueOverflows, otel.Queue.Overflow) {
+		v.fix("open_telemetry queue overflow has invalid value, using default",
+			"provided", otel.Queue.Overflow,
+			"allowed_values", AllowedQueueOverflows,
+			"default", domain.QueueOverflowReject)
+		otel.Queue.Overflow = domain.QueueOverflowReject
+	}
+
+	ka := &otel.GRPC.Keepalive
+	if ka.Time < 0 || ka.Timeout < 0 || ka.MaxConnectionIdle < 0 || ka.MaxConnectionAge < 0 ||
+		ka.MaxConnectionAgeGrace < 0 || ka.MinTime < 0 {
+		v.fix("open_telemetry grpc keepalive durations cannot be negative, using gRPC defaults",
+			"time", ka.Time,
+			"timeout", ka.Timeout,
+			"max_connection_idle", ka.MaxConnectionIdle,
+			"max_connection_age", ka.MaxConnectionAge,
+			"max_connection_age_grace", ka.MaxConnectionAgeGrace,
+			"min_time", ka.MinTime)
+		*ka = keepaliveConfig{PermitWithoutStream: ka.PermitWithoutStream}
+	}
+}
+
+// validatePipelineConfig validates the OTLP processing pipeline stages.
+func (v *validator) validatePipelineConfig(otel *openTelemetryConfig) {
+	validStages := make([]pipelineStageConfig, 0, len(otel.Pipeline))
+	for i, stage := range otel.Pipeline {
+		switch {
+		case !slices.Contains(AllowedPipelineStages, stage.Type):
+			v.fix("open_telemetry pipeline stage has invalid type, removing it",
+				"stage", i,
+				"provided", stage.Type,
+				"allowed_values", AllowedPipelineStages)
+			continue
+		case stage.Type == domain.PipelineStageBatch && i != len(otel.Pipeline)-1:
+			v.fix("open_telemetry pipeline batch stage must be the last stage, removing it",
+				"stage", i)
+			continue
+		case stage.Type == domain.PipelineStageRateLimit && stage.MetricsPerSecond <= 0:
+			v.fix("open_telemetry pipeline rate_limit stage needs a positive metrics_per_second, removing it",
+				"stage", i,
+				"provided", stage.MetricsPerSecond)
+			continue
+		}
+
+		if stage.Type == domain.PipelineStageRateLimit && stage.Burst <= 0 {
+			stage.Burst = max(int(stage.MetricsPerSecond), 1)
+		}
+
+		stage.Include = v.validMetricPatterns(i, stage.Include)
+		stage.Exclude = v.validMetricPatterns(i, stage.Exclude)
+
+		validRules := make([]relabelRuleConfig, 0, len(stage.Rules))
+		for _, rule := range stage.Rules {
+			if _, err := regexp.Compile(rule.Regex); err != nil || !slices.Contains(AllowedRelabelActions, rule.Action) {
+				v.fix("invalid open_telemetry pipeline relabel rule, removing it",
+					"stage", i,
+					"action", rule.Action,
+					"regex", rule.Regex,
+					"allowed_actions", AllowedRelabelActions)
+				continue
+			}
+
+			validRules = append(validRules, rule)
+		}
+		stage.Rules = validRules
+
+		validStages = append(validStages, stage)
+	}
+	otel.Pipeline = validStages
+}
+
+// validateHistogramBuckets removes invalid histogram bucket layouts and sorts the
+// bucket bounds of the remaining ones.
+func (v *validator) validateHistogramBuckets(otel *openTelemetryConfig) {
+	validLayouts := make([]histogramBuckets, 0, len(otel.HistogramBuckets))
+	for _, layout := range otel.HistogramBuckets {
+		if _, err := path.Match(layout.Pattern, ""); err != nil || layout.Pattern == "" {
+			v.fix("open_telemetry histogram_buckets has an invalid pattern, removing it",
+				"pattern", layout.Pattern)
+			continue
+		}
+
+		buckets := make([]float64, 0, len(layout.Buckets))
+		for _, bound := range layout.Buckets {
+			if !math.IsNaN(bound) && !math.IsInf(bound, 0) {
+				buckets = append(buckets, bound)
+			}
+		}
+		slices.Sort(buckets)
+		buckets = slices.Compact(buckets)
+
+		if len(buckets) == 0 {
+			v.fix("open_telemetry histogram_buckets needs at least one finite bucket, removing it",
+				"pattern", layout.Pattern)
+			continue
+		}
+
+		layout.Buckets = buckets
+		validLayouts = append(validLayouts, layout)
+	}
+	otel.HistogramBuckets = validLayouts
+}
+
+// validMetricPatterns removes malformed metric name glob patterns.
+func (v *validator) validMetricPatterns(stage int, patterns []string) []string {
+	valid := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			v.fix("invalid open_telemetry pipeline metric pattern, removing it",
+				"stage", stage,
+				"pattern", pattern)
+			continue
+		}
+
+		valid = append(valid, pattern)
+	}
+
+	return valid
+}
+
+// validateOperationClassificationConfig validates operation type classification rules.
+func (v *validator) validateOperationClassificationConfig(cfg *config) {
+	oc := &cfg.Collectors.OperationClassification
+
+	if !isClassifiableType(oc.Default) {
+		v.fix("operation_classification default has invalid value, using default",
+			"provided", oc.Default,
+			"allowed_values", domain.ClassifiableOperationTypes,
+			"default", domain.OperationTypeDocument)
+		oc.Default = string(domain.OperationTypeDocument)
+	}
+
+	validRules := make([]classificationRuleConfig, 0, len(oc.Rules))
+	for i, rule := range oc.Rules {
+		if !isClassifiableType(rule.Type) {
+			v.fix("operation_classification rule has invalid type, removing it",
+				"rule", i,
+				"provided", rule.Type,
+				"allowed_values", domain.ClassifiableOperationTypes)
+			continue
+		}
+
+		validFields := slices.IndexFunc(rule.HasFields, func(f string) bool {
+			return !fieldNameRegex.MatchString(f)
+		}) == -1
+		if !validFields {
+			v.fix("operation_classification rule has invalid field names, removing it",
+				"rule", i,
+				"has_fields", rule.HasFields,
+				"expected_format", fieldNameRegex.String())
+			continue
+		}
+
+		validRules = append(validRules, rule)
+	}
+	oc.Rules = validRules
+
+	validOverrides := make([]classificationOverrideConfig, 0, len(oc.Overrides))
+	for _, override := range oc.Overrides {
+		if !tableFilterPatternRegex.MatchString(override.Table) || !isClassifiableType(override.Classification) {
+			v.fix("invalid operation_classification override, removing it",
+				"table", override.Table,
+				"classification", override.Classification,
+				"expected_table_format", "namespace:database:table (wildcards allowed: *)",
+				"allowed_classifications", domain.ClassifiableOperationTypes)
+			continue
+		}
+
+		validOverrides = append(validOverrides, override)
+	}
+	oc.Overrides = validOverrides
+}
+
+// validateLoggingConfig validates log output settings.
+func (v *validator) validateLoggingConfig(cfg *config) {
+	l := &cfg.Logging
+
+	if !slices.Contains(AllowedLogOutputs, l.Output) {
+		v.fix("logging output has invalid value, using default",
+			"provided", l.Output,
+			"allowed_values", AllowedLogOutputs,
+			"default", DefaultLogOutput)
+		l.Output = DefaultLogOutput
+	}
+
+	if l.Output == logOutputFile && strings.TrimSpace(l.File.Path) == "" {
+		v.fix("logging output is file but file.path is empty, using default",
+			"default", DefaultLogOutput)
+		l.Output = DefaultLogOutput
+	}
+
+	if l.File.MaxSizeMB < 0 || l.File.MaxAge < 0 || l.File.MaxBackups < 0 {
+		v.fix("logging file rotation limits cannot be negative, disabling them",
+			"max_size_mb", l.File.MaxSizeMB,
+			"max_age", l.File.MaxAge,
+			"max_backups", l.File.MaxBackups)
+		l.File.MaxSizeMB = max(l.File.MaxSizeMB, 0)
+		l.File.MaxAge = max(l.File.MaxAge, 0)
+		l.File.MaxBackups = max(l.File.MaxBackups, 0)
+	}
+
+	if l.SlowQuery < 0 {
+		v.fix("logging slow_query_threshold cannot be negative, disabling slow query warnings",
+			"provided", l.SlowQuery)
+		l.SlowQuery = 0
+	}
+}
+
+// validBoundaries reports whether boundaries is a non-empty, strictly ascending list of
+// integers or of strings.
+func validBoundaries(boundaries []any) bool {
+	if len(boundaries) == 0 {
+		return false
+	}
+
+	for i, boundary := range boundaries {
+		switch value := boundary.(type) {
+		case int:
+			if i > 0 {
+				previous, ok := boundaries[i-1].(int)
+				if !ok || previous >= value {
+					return false
+				}
+			}
+		case string:
+			if i > 0 {
+				previous, ok := boundaries[i-1].(string)
+				if !ok || previous >= value {
+					return false
+				}
+			}
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isClassifiableType reports whether value names an operation type a record can be classified as.
+func isClassifiableType(value string) bool {
+	return slices.Contains(domain.ClassifiableOperationTypes, domain.OperationType(value))
 }
 
 func defaultConfig() *config {
@@ -328,6 +1857,43 @@ func defaultConfig() *config {
 		Exporter: exporterConfig{
 			Port:        DefaultPort,
 			MetricsPath: DefaultMetricsPath,
+			Compression: true,
+
+			TelemetryConstantLabels: true,
+			OverlappingScrapes:      domain.OverlappingScrapesAllow,
+
+			Push: pushConfig{
+				Enabled:  false,
+				Job:      DefaultPushJob,
+				Interval: DefaultPushInterval,
+				Grouping: map[string]string{},
+			},
+			JSONAPI: jsonAPIConfig{
+				Enabled: true,
+			},
+			StatusAPI: statusAPIConfig{
+				Enabled: true,
+			},
+			Admin: adminConfig{
+				Enabled: false,
+			},
+			Debug: debugConfig{
+				Pprof: false,
+				Port:  DefaultDebugPort,
+			},
+			LabelSanitization: labelSanitizationConfig{
+				Enabled:    false,
+				Labels:     slices.Clone(defaultSanitizedLabels),
+				MaxLength:  DefaultLabelMaxLength,
+				HashSuffix: true,
+			},
+			Tenants: tenantsConfig{
+				AllowAnonymous: true,
+			},
+			NamespaceEndpoints: namespaceEndpointsConfig{
+				Enabled:    false,
+				Namespaces: []string{"*"},
+			},
 		},
 		SurrealDB: surrealDBConfig{
 			Scheme:         "ws",
@@ -335,32 +1901,63 @@ func defaultConfig() *config {
 			Port:           "8000",
 			Username:       "root",
 			Password:       "root",
+			Level:          domain.AuthLevelRoot,
 			Timeout:        10 * time.Second,
 			ClusterName:    DefaultClusterName,
 			StorageEngine:  DefaultStorageEngine,
 			DeploymentMode: DefaultDeploymentMode,
+			AutoDetect:     false,
+			Connection: connectionConfig{
+				WriteTimeout:  DefaultConnectionWriteTimeout,
+				AutoReconnect: true,
+				IdleTimeout:   DefaultConnectionIdleTimeout,
+			},
+			RateLimit: rateLimitConfig{
+				QueriesPerSecond: 0,
+				Burst:            DefaultRateLimitBurst,
+			},
 		},
 		Collectors: collectorsConfig{
+			Info: infoConfig{
+				Cache: infoCacheConfig{
+					TableList:    domain.TableListShared,
+					TableListTTL: DefaultTableListTTL,
+				},
+				TimeoutBudget: infoBudgetConfig{
+					Root:      DefaultInfoRootBudget,
+					Namespace: DefaultInfoNamespaceBudget,
+					Table:     DefaultInfoTableBudget,
+				},
+				LoadAverage:            domain.LoadAveragePeriods,
+				LegacyMemoryUsageRatio: true,
+			},
 			LiveQuery: liveQueryConfig{
 				Enabled:              false,
 				ReconnectDelay:       5 * time.Second,
 				MaxReconnectAttempts: 10,
+				DetectOperationType:  true,
+				MaxTables:            DefaultLiveQueryMaxTables,
 				Tables: tableConfig{
 					Include: []string{},
 					Exclude: []string{},
 				},
 			},
 			RecordCount: recordCountConfig{
-				Enabled: true,
+				Enabled:           true,
+				Mode:              DefaultRecordCountMode,
+				ReconcileInterval: DefaultReconcileInterval,
+				TopNInterval:      DefaultTopNInterval,
 				Tables: tableConfig{
 					Include: []string{},
 					Exclude: []string{},
 				},
+				Overrides: []recordCountOverrideConfig{},
 			},
 			StatsTable: statsTableConfig{
 				Enabled:             false,
 				RemoveOrphanTables:  false,
 				SideTableNamePrefix: "_stats_",
+				Strategy:            DefaultStatsTableStrategy,
 				Tables: tableConfig{
 					Include: []string{},
 					Exclude: []string{},
@@ -371,14 +1968,146 @@ func defaultConfig() *config {
 				GRPCEndpoint:        ":4317",
 				MaxRecvSize:         4,
 				TranslationStrategy: "UnderscoreEscapingWithSuffixes",
-				EnableBatching:      true,
-				BatchSize:           100,
-				BatchTimeoutMs:      1000,
+				MetricPrefix:        DefaultOTLPMetricPrefix,
+				ResourceLabels: map[string][]string{
+					domain.ResourceLabelCluster:        {"surrealdb.cluster", "service.namespace"},
+					domain.ResourceLabelStorageEngine:  {"surrealdb.storage_engine"},
+					domain.ResourceLabelDeploymentMode: {"surrealdb.deployment_mode"},
+					domain.ResourceLabelNode:           {"surrealdb.node", "service.instance.id"},
+				},
+				EnableBatching: true,
+				BatchSize:      100,
+				BatchTimeoutMs: 1000,
+				GRPC: grpcConfig{
+					Compression: []string{"gzip"},
+				},
+				Logs: otlpLogsConfig{
+					SlowQueryPattern:   DefaultSlowQueryLogPattern,
+					AuthFailurePattern: DefaultAuthFailureLogPattern,
+				},
+				Traces: otlpTracesConfig{
+					MethodAttributes: DefaultTraceMethodAttributes,
+					Buckets:          DefaultTraceDurationBuckets,
+				},
+				Queue: queueConfig{
+					MaxMetrics: 100000,
+					Overflow:   domain.QueueOverflowReject,
+				},
 			},
 			Go:      collectorConfig{Enabled: false},
 			Process: collectorConfig{Enabled: false},
+			Derived: derivedConfig{
+				LargeTableRecords:       DefaultDerivedLargeTableRecords,
+				IndexBuildingStuckAfter: DefaultDerivedIndexBuildingStuckAfter,
+			},
+			Proxy: proxyConfig{
+				Prefix:  DefaultProxyPrefix,
+				Timeout: DefaultProxyTimeout,
+			},
+			OperationClassification: operationClassificationConfig{
+				Default: string(domain.OperationTypeDocument),
+				Rules: []classificationRuleConfig{
+					{Type: string(domain.OperationTypeGraph), HasFields: []string{"in", "out"}},
+					{Type: string(domain.OperationTypeKeyValue), MinFields: intPtr(1), MaxFields: intPtr(2)},
+					{Type: string(domain.OperationTypeRelational), MinScalarFields: intPtr(3), MaxComplexFields: intPtr(1)},
+				},
+				Overrides: []classificationOverrideConfig{},
+			},
 		},
+		Logging: loggingConfig{
+			Output:    DefaultLogOutput,
+			SlowQuery: DefaultSlowQueryThreshold,
+			File: loggingFileConfig{
+				MaxSizeMB:  100,
+				MaxAge:     7 * 24 * time.Hour,
+				MaxBackups: 5,
+			},
+		},
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// redactedValue replaces secrets in dumped configuration.
+const redactedValue = "<redacted>"
+
+// Dump writes the effective configuration, after environment overrides and defaulting,
+// as YAML with secrets redacted.
+func (c *config) Dump(w io.Writer) error {
+	redacted := redactSecrets(reflect.ValueOf(*c)).Interface().(config)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&redacted); err != nil {
+		return fmt.Errorf("failed to encode Config: %w", err)
 	}
+
+	return enc.Close()
+}
+
+// redactSecrets returns a copy of v with every string field tagged secret:"true"
+// redacted, at any depth. Slices, maps and pointers are copied, so v is left unchanged.
+func redactSecrets(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := range v.NumField() {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			if field.Tag.Get("secret") == "true" && field.Type.Kind() == reflect.String {
+				out.Field(i).SetString(redact(v.Field(i).String()))
+				continue
+			}
+
+			out.Field(i).Set(redactSecrets(v.Field(i)))
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(redactSecrets(v.Index(i)))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			out.SetMapIndex(iter.Key(), redactSecrets(iter.Value()))
+		}
+		return out
+
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(redactSecrets(v.Elem()))
+		return out
+	}
+
+	return v
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+
+	return redactedValue
 }
 
 func applyEnvironmentOverrides(cfg *config) {
@@ -390,20 +2119,158 @@ func applyEnvironmentOverrides(cfg *config) {
 			cfg.SurrealDB.Port = parsed.Port()
 		}
 	}
-	if username := os.Getenv("SURREALDB_USERNAME"); username != "" {
-		cfg.SurrealDB.Username = username
+	if username := os.Getenv("SURREALDB_USERNAME"); username != "" {
+		cfg.SurrealDB.Username = username
+	}
+	if password := os.Getenv("SURREALDB_PASSWORD"); password != "" {
+		cfg.SurrealDB.Password = password
+	}
+	if token := os.Getenv(AdminTokenEnv); token != "" {
+		cfg.Exporter.Admin.Token = token
+	}
+}
+
+func (c *config) Port() int {
+	return c.Exporter.Port
+}
+
+func (c *config) MetricsPath() string {
+	return c.Exporter.MetricsPath
+}
+
+func (c *config) ScrapeBudget() time.Duration {
+	return c.Exporter.ScrapeBudget
+}
+
+// MetricRenames returns the names metrics and labels are exported as.
+func (c *config) MetricRenames() domain.MetricRenames {
+	r := c.Exporter.Renames
+	return domain.MetricRenames{
+		Metrics:      maps.Clone(r.Metrics),
+		Labels:       maps.Clone(r.Labels),
+		KeepOriginal: r.KeepOriginal,
+	}
+}
+
+func (c *config) LabelSanitization() domain.LabelSanitization {
+	l := c.Exporter.LabelSanitization
+	return domain.LabelSanitization{
+		Enabled:    l.Enabled,
+		Labels:     slices.Clone(l.Labels),
+		MaxLength:  l.MaxLength,
+		HashSuffix: l.HashSuffix,
 	}
-	if password := os.Getenv("SURREALDB_PASSWORD"); password != "" {
-		cfg.SurrealDB.Password = password
+}
+
+// Tenants returns the tenants whose tokens scope metrics requests to their namespaces.
+func (c *config) Tenants() []domain.Tenant {
+	tenants := make([]domain.Tenant, 0, len(c.Exporter.Tenants.Tokens))
+	for _, tenant := range c.Exporter.Tenants.Tokens {
+		tenants = append(tenants, domain.Tenant{
+			Name:       tenant.Name,
+			Token:      tenant.Token,
+			Namespaces: slices.Clone(tenant.Namespaces),
+		})
 	}
+
+	return tenants
 }
 
-func (c *config) Port() int {
-	return c.Exporter.Port
+// TenantsAllowAnonymous reports whether metrics requests without a token get all series.
+func (c *config) TenantsAllowAnonymous() bool {
+	return c.Exporter.Tenants.AllowAnonymous
 }
 
-func (c *config) MetricsPath() string {
-	return c.Exporter.MetricsPath
+// NamespaceEndpointsEnabled reports whether each namespace gets its own metrics endpoint.
+func (c *config) NamespaceEndpointsEnabled() bool {
+	return c.Exporter.NamespaceEndpoints.Enabled
+}
+
+// NamespaceEndpointPatterns returns the patterns of the namespaces with an endpoint.
+func (c *config) NamespaceEndpointPatterns() []string {
+	return slices.Clone(c.Exporter.NamespaceEndpoints.Namespaces)
+}
+
+// OverlappingScrapes returns how metrics requests overlapping a running collection are
+// handled.
+func (c *config) OverlappingScrapes() string {
+	return c.Exporter.OverlappingScrapes
+}
+
+func (c *config) CacheTTL() time.Duration {
+	return c.Exporter.CacheTTL
+}
+
+func (c *config) Compression() bool {
+	return c.Exporter.Compression
+}
+
+func (c *config) TelemetryPort() int {
+	return c.Exporter.TelemetryPort
+}
+
+func (c *config) TelemetryConstantLabels() bool {
+	return c.Exporter.TelemetryConstantLabels
+}
+
+func (c *config) DebugPprofEnabled() bool {
+	return c.Exporter.Debug.Pprof
+}
+
+func (c *config) DebugPort() int {
+	return c.Exporter.Debug.Port
+}
+
+func (c *config) JSONAPIEnabled() bool {
+	return c.Exporter.JSONAPI.Enabled
+}
+
+func (c *config) StatusAPIEnabled() bool {
+	return c.Exporter.StatusAPI.Enabled
+}
+
+func (c *config) AdminEnabled() bool {
+	return c.Exporter.Admin.Enabled
+}
+
+func (c *config) AdminToken() string {
+	return c.Exporter.Admin.Token
+}
+
+func (c *config) PushEnabled() bool {
+	return c.Exporter.Push.Enabled
+}
+
+func (c *config) PushOnly() bool {
+	return c.Exporter.Push.PushOnly
+}
+
+func (c *config) PushURL() string {
+	return c.Exporter.Push.URL
+}
+
+func (c *config) PushJob() string {
+	return c.Exporter.Push.Job
+}
+
+func (c *config) PushInterval() time.Duration {
+	return c.Exporter.Push.Interval
+}
+
+func (c *config) PushGrouping() map[string]string {
+	return c.Exporter.Push.Grouping
+}
+
+func (c *config) PushUsername() string {
+	return c.Exporter.Push.Username
+}
+
+func (c *config) PushPassword() string {
+	return c.Exporter.Push.Password
+}
+
+func (c *config) PushDeleteOnShutdown() bool {
+	return c.Exporter.Push.DeleteOnShutdown
 }
 
 func (c *config) SurrealURL() string {
@@ -424,6 +2291,60 @@ func (c *config) SurrealPassword() string {
 	return c.SurrealDB.Password
 }
 
+// SurrealUserLevel returns the level of the configured SurrealDB user.
+func (c *config) SurrealUserLevel() string {
+	return c.SurrealDB.Level
+}
+
+// SurrealScope returns the namespaces and databases monitored instead of the whole
+// server, or nil when the exporter monitors the whole server.
+func (c *config) SurrealScope() []domain.ScopeEntry {
+	if len(c.SurrealDB.Scope) == 0 {
+		return nil
+	}
+
+	scope := make([]domain.ScopeEntry, 0, len(c.SurrealDB.Scope))
+	for _, entry := range c.SurrealDB.Scope {
+		namespace, database, _ := strings.Cut(entry, ":")
+		scope = append(scope, domain.ScopeEntry{Namespace: namespace, Database: database})
+	}
+
+	return scope
+}
+
+func (c *config) SurrealCredentialOverrides() []domain.CredentialOverride {
+	overrides := make([]domain.CredentialOverride, 0, len(c.SurrealDB.Credentials))
+	for _, credential := range c.SurrealDB.Credentials {
+		overrides = append(overrides, domain.CredentialOverride{
+			Pattern:  credential.Pattern,
+			Username: credential.Username,
+			Password: credential.Password,
+			Level:    credential.Level,
+		})
+	}
+
+	return overrides
+}
+
+// SurrealConnection returns the keep-alive settings of the WebSocket connections.
+func (c *config) SurrealConnection() domain.ConnectionSettings {
+	return domain.ConnectionSettings{
+		PingInterval:  c.SurrealDB.Connection.PingInterval,
+		ReadTimeout:   c.SurrealDB.Connection.ReadTimeout,
+		WriteTimeout:  c.SurrealDB.Connection.WriteTimeout,
+		AutoReconnect: c.SurrealDB.Connection.AutoReconnect,
+		Prewarm:       c.SurrealDB.Connection.Prewarm,
+		IdleTimeout:   c.SurrealDB.Connection.IdleTimeout,
+	}
+}
+
+func (c *config) SurrealQueryRateLimit() domain.QueryRateLimit {
+	return domain.QueryRateLimit{
+		QueriesPerSecond: c.SurrealDB.RateLimit.QueriesPerSecond,
+		Burst:            c.SurrealDB.RateLimit.Burst,
+	}
+}
+
 func (c *config) SurrealTimeout() time.Duration {
 	return c.SurrealDB.Timeout
 }
@@ -432,6 +2353,39 @@ func (c *config) ClusterName() string {
 	return c.SurrealDB.ClusterName
 }
 
+// ApplyDetectedTopology warns when the configured storage_engine or deployment_mode
+// disagrees with the detected topology and, with auto_detect enabled, uses the detected
+// values for the settings the configuration leaves unset. Explicitly configured values
+// are never replaced. The collectors the detected deployment_mode does not support are
+// disabled, and the corrections are returned.
+func (c *config) ApplyDetectedTopology(topology domain.Topology) []error {
+	apply := func(setting string, value *string, explicit bool, detected string) {
+		if detected == "" || detected == *value {
+			return
+		}
+
+		if c.SurrealDB.AutoDetect && !explicit {
+			slog.Info("using the detected SurrealDB topology", "setting", setting, "detected", detected)
+			*value = detected
+			return
+		}
+
+		slog.Warn("configured value disagrees with the detected SurrealDB topology",
+			"setting", setting,
+			"configured", *value,
+			"detected", detected,
+			"auto_detect", c.SurrealDB.AutoDetect)
+	}
+
+	apply("storage_engine", &c.SurrealDB.StorageEngine, c.configured.storageEngine, topology.StorageEngine)
+	apply("deployment_mode", &c.SurrealDB.DeploymentMode, c.configured.deploymentMode, topology.DeploymentMode)
+
+	v := &validator{}
+	v.validateTopologyCollectors(c)
+
+	return v.fixes
+}
+
 func (c *config) StorageEngine() string {
 	return c.SurrealDB.StorageEngine
 }
@@ -440,10 +2394,221 @@ func (c *config) DeploymentMode() string {
 	return c.SurrealDB.DeploymentMode
 }
 
+func (c *config) InfoNamespaceCacheTTL() time.Duration {
+	return c.Collectors.Info.Cache.NamespaceTTL
+}
+
+func (c *config) InfoDatabaseCacheTTL() time.Duration {
+	return c.Collectors.Info.Cache.DatabaseTTL
+}
+
+func (c *config) InfoTableCacheTTL() time.Duration {
+	return c.Collectors.Info.Cache.TableTTL
+}
+
+func (c *config) InfoIncrementalTableTTL() time.Duration {
+	return c.Collectors.Info.Cache.IncrementalTableTTL
+}
+
+func (c *config) InfoIndexCacheTTL() time.Duration {
+	return c.Collectors.Info.Cache.IndexTTL
+}
+
+// InfoTimeouts returns the timeouts of INFO fetches and their levels, derived from
+// surrealdb.timeout and the timeout budget shares.
+func (c *config) InfoTimeouts() domain.InfoTimeouts {
+	total := c.SurrealDB.Timeout
+	budget := c.Collectors.Info.TimeoutBudget
+
+	return domain.InfoTimeouts{
+		Total:     total,
+		Root:      time.Duration(budget.Root * float64(total)),
+		Namespace: time.Duration(budget.Namespace * float64(total)),
+		Table:     time.Duration(budget.Table * float64(total)),
+	}
+}
+
+func (c *config) InfoTableList() string {
+	return c.Collectors.Info.Cache.TableList
+}
+
+func (c *config) InfoTableListTTL() time.Duration {
+	return c.Collectors.Info.Cache.TableListTTL
+}
+
+func (c *config) InfoLoadAverage() string {
+	return c.Collectors.Info.LoadAverage
+}
+
+func (c *config) InfoLegacyMemoryUsageRatio() bool {
+	return c.Collectors.Info.LegacyMemoryUsageRatio
+}
+
+func (c *config) InfoDetailedSchema() bool {
+	return c.Collectors.Info.DetailedSchema
+}
+
+// CollectorEnabled reports whether the collector registered under name is enabled by
+// collectors.<name>.enabled.
+func (c *config) CollectorEnabled(name string) bool {
+	enabled, ok := c.collectorEnabledField(name)
+
+	return ok && enabled.Bool()
+}
+
+// setCollectorEnabled enables or disables the collector registered under name. It fails
+// for names without a collectors.<name>.enabled setting.
+func (c *config) setCollectorEnabled(name string, enabled bool) error {
+	field, ok := c.collectorEnabledField(name)
+	if !ok {
+		return fmt.Errorf("unknown collector %q", name)
+	}
+
+	field.SetBool(enabled)
+
+	return nil
+}
+
+// collectorEnabledField returns the enabled setting of the collectors.<name> section.
+func (c *config) collectorEnabledField(name string) (reflect.Value, bool) {
+	collectors := reflect.ValueOf(&c.Collectors).Elem()
+	for i := range collectors.NumField() {
+		if tag, _, _ := strings.Cut(collectors.Type().Field(i).Tag.Get("yaml"), ","); tag != name {
+			continue
+		}
+
+		section := collectors.Field(i)
+		if section.Kind() != reflect.Struct {
+			return reflect.Value{}, false
+		}
+
+		for j := range section.NumField() {
+			tag, _, _ := strings.Cut(section.Type().Field(j).Tag.Get("yaml"), ",")
+			if tag == "enabled" && section.Field(j).Kind() == reflect.Bool {
+				return section.Field(j), true
+			}
+		}
+
+		return reflect.Value{}, false
+	}
+
+	return reflect.Value{}, false
+}
+
+// TextfileDirectory returns the directory of the textfile collector, or an empty string
+// when it is disabled.
+func (c *config) TextfileDirectory() string {
+	if !c.Collectors.Textfile.Enabled {
+		return ""
+	}
+
+	return c.Collectors.Textfile.Directory
+}
+
+// IndexSampleQueries returns the sample queries of the index usage collector.
+func (c *config) IndexSampleQueries() []domain.IndexSampleQuery {
+	queries := make([]domain.IndexSampleQuery, 0, len(c.Collectors.IndexUsage.Queries))
+	for _, query := range c.Collectors.IndexUsage.Queries {
+		namespace, database, _ := strings.Cut(query.Database, ":")
+		queries = append(queries, domain.IndexSampleQuery{
+			Name:      query.Name,
+			Namespace: namespace,
+			Database:  database,
+			Query:     query.Query,
+		})
+	}
+
+	return queries
+}
+
+func (c *config) DerivedCollectorEnabled() bool {
+	return c.Collectors.Derived.Enabled
+}
+
+// DerivedMetrics returns the thresholds of the derived alert metrics.
+func (c *config) DerivedMetrics() domain.DerivedMetrics {
+	return domain.DerivedMetrics{
+		LargeTableRecords:       c.Collectors.Derived.LargeTableRecords,
+		IndexBuildingStuckAfter: c.Collectors.Derived.IndexBuildingStuckAfter,
+	}
+}
+
+// MetricsProxy returns the SurrealDB metrics endpoint merged into the metrics, with an
+// empty URL when the proxy is disabled.
+func (c *config) MetricsProxy() domain.MetricsProxy {
+	if !c.Collectors.Proxy.Enabled {
+		return domain.MetricsProxy{}
+	}
+
+	return domain.MetricsProxy{
+		URL:     c.Collectors.Proxy.URL,
+		Prefix:  c.Collectors.Proxy.Prefix,
+		Timeout: c.Collectors.Proxy.Timeout,
+	}
+}
+
+func (c *config) ExternalCollectors() []domain.ExternalCollector {
+	collectors := make([]domain.ExternalCollector, 0, len(c.Collectors.External))
+	for _, external := range c.Collectors.External {
+		collectors = append(collectors, domain.ExternalCollector{
+			Name:    external.Name,
+			Command: external.Command,
+			Timeout: external.Timeout,
+		})
+	}
+
+	return collectors
+}
+
 func (c *config) RecordCountCollectorEnabled() bool {
 	return c.Collectors.RecordCount.Enabled
 }
 
+func (c *config) RecordCountIncremental() bool {
+	return c.Collectors.RecordCount.Mode == RecordCountModeIncremental
+}
+
+func (c *config) RecordCountInterval() time.Duration {
+	return c.Collectors.RecordCount.Interval
+}
+
+func (c *config) RecordCountReconcileInterval() time.Duration {
+	return c.Collectors.RecordCount.ReconcileInterval
+}
+
+func (c *config) RecordCountIntervalOverrides() []domain.RecordCountIntervalOverride {
+	overrides := make([]domain.RecordCountIntervalOverride, 0, len(c.Collectors.RecordCount.Overrides))
+	for _, o := range c.Collectors.RecordCount.Overrides {
+		overrides = append(overrides, domain.RecordCountIntervalOverride{
+			Pattern:  o.Table,
+			Interval: o.Interval,
+		})
+	}
+
+	return overrides
+}
+
+func (c *config) RecordCountPartitions() []domain.RecordCountPartitioning {
+	partitions := make([]domain.RecordCountPartitioning, 0, len(c.Collectors.RecordCount.Partitions))
+	for _, p := range c.Collectors.RecordCount.Partitions {
+		partitions = append(partitions, domain.RecordCountPartitioning{
+			Pattern:     p.Table,
+			Boundaries:  slices.Clone(p.Boundaries),
+			Concurrency: p.Concurrency,
+		})
+	}
+
+	return partitions
+}
+
+func (c *config) RecordCountTopN() int {
+	return c.Collectors.RecordCount.TopN
+}
+
+func (c *config) RecordCountTopNInterval() time.Duration {
+	return c.Collectors.RecordCount.TopNInterval
+}
+
 func (c *config) RecordCountIncludePatterns() []string {
 	return c.Collectors.RecordCount.Tables.Include
 }
@@ -472,6 +2637,38 @@ func (c *config) CustomAttributes() map[string]any {
 	return c.Logging.CustomAttributes
 }
 
+func (c *config) Output() string {
+	return c.Logging.Output
+}
+
+func (c *config) FilePath() string {
+	return c.Logging.File.Path
+}
+
+func (c *config) FileMaxSizeMB() int {
+	return c.Logging.File.MaxSizeMB
+}
+
+func (c *config) FileMaxAge() time.Duration {
+	return c.Logging.File.MaxAge
+}
+
+func (c *config) FileMaxBackups() int {
+	return c.Logging.File.MaxBackups
+}
+
+func (c *config) TracingEnabled() bool {
+	return c.Tracing.Enabled
+}
+
+func (c *config) TraceQueries() bool {
+	return c.Logging.TraceQueries
+}
+
+func (c *config) SlowQueryThreshold() time.Duration {
+	return c.Logging.SlowQuery
+}
+
 func (c *config) LiveQueryEnabled() bool {
 	return c.Collectors.LiveQuery.Enabled
 }
@@ -492,6 +2689,34 @@ func (c *config) LiveQueryMaxReconnectAttempts() int {
 	return c.Collectors.LiveQuery.MaxReconnectAttempts
 }
 
+func (c *config) LiveQueryDetectOperationType() bool {
+	return c.Collectors.LiveQuery.DetectOperationType
+}
+
+func (c *config) LiveQuerySchemaPollInterval() time.Duration {
+	return c.Collectors.LiveQuery.SchemaPollInterval
+}
+
+func (c *config) LiveQueryDedicatedConnections() bool {
+	return c.Collectors.LiveQuery.DedicatedConnections
+}
+
+func (c *config) LiveQueryMaxTables() int {
+	return c.Collectors.LiveQuery.MaxTables
+}
+
+func (c *config) LiveQuerySampling() []domain.LiveQuerySampling {
+	sampling := make([]domain.LiveQuerySampling, 0, len(c.Collectors.LiveQuery.Sampling))
+	for _, s := range c.Collectors.LiveQuery.Sampling {
+		sampling = append(sampling, domain.LiveQuerySampling{
+			Pattern: s.Table,
+			Rate:    s.SampleRate,
+		})
+	}
+
+	return sampling
+}
+
 func (c *config) StatsTableEnabled() bool {
 	return c.Collectors.StatsTable.Enabled
 }
@@ -512,6 +2737,14 @@ func (c *config) StatsTableNamePrefix() string {
 	return c.Collectors.StatsTable.SideTableNamePrefix
 }
 
+func (c *config) StatsTableDryRun() bool {
+	return c.Collectors.StatsTable.DryRun
+}
+
+func (c *config) StatsTableDeltaRecords() bool {
+	return c.Collectors.StatsTable.Strategy == StatsTableStrategyDelta
+}
+
 func (c *config) OTLPReceiverEnabled() bool {
 	return c.Collectors.OpenTelemetry.Enabled
 }
@@ -528,6 +2761,14 @@ func (c *config) OTLPTranslationStrategy() string {
 	return c.Collectors.OpenTelemetry.TranslationStrategy
 }
 
+func (c *config) OTLPMetricPrefix() string {
+	return c.Collectors.OpenTelemetry.MetricPrefix
+}
+
+func (c *config) OTLPResourceLabels() map[string][]string {
+	return c.Collectors.OpenTelemetry.ResourceLabels
+}
+
 func (c *config) OTLPBatchingEnabled() bool {
 	return c.Collectors.OpenTelemetry.EnableBatching
 }
@@ -539,3 +2780,156 @@ func (c *config) OTLPBatchSize() int {
 func (c *config) OTLPBatchTimeoutMs() int {
 	return c.Collectors.OpenTelemetry.BatchTimeoutMs
 }
+
+func (c *config) OTLPQueueLimits() domain.QueueLimits {
+	queue := c.Collectors.OpenTelemetry.Queue
+
+	return domain.QueueLimits{
+		MaxMetrics: queue.MaxMetrics,
+		MaxBytes:   queue.MaxBytes,
+		Overflow:   queue.Overflow,
+	}
+}
+
+func (c *config) OTLPNativeHistograms() bool {
+	return c.Collectors.OpenTelemetry.NativeHistograms
+}
+
+func (c *config) OTLPHistogramBuckets() []domain.HistogramBucketLayout {
+	layouts := make([]domain.HistogramBucketLayout, 0, len(c.Collectors.OpenTelemetry.HistogramBuckets))
+	for _, layout := range c.Collectors.OpenTelemetry.HistogramBuckets {
+		layouts = append(layouts, domain.HistogramBucketLayout{
+			Pattern: layout.Pattern,
+			Buckets: layout.Buckets,
+		})
+	}
+
+	return layouts
+}
+
+func (c *config) OTLPGRPCMaxConcurrentStreams() uint32 {
+	return c.Collectors.OpenTelemetry.GRPC.MaxConcurrentStreams
+}
+
+func (c *config) OTLPGRPCCompression() []string {
+	return c.Collectors.OpenTelemetry.GRPC.Compression
+}
+
+func (c *config) OTLPGRPCReflection() bool {
+	return c.Collectors.OpenTelemetry.GRPC.Reflection
+}
+
+func (c *config) OTLPGRPCKeepalive() domain.GRPCKeepalive {
+	ka := c.Collectors.OpenTelemetry.GRPC.Keepalive
+
+	return domain.GRPCKeepalive{
+		Time:                  ka.Time,
+		Timeout:               ka.Timeout,
+		MaxConnectionIdle:     ka.MaxConnectionIdle,
+		MaxConnectionAge:      ka.MaxConnectionAge,
+		MaxConnectionAgeGrace: ka.MaxConnectionAgeGrace,
+		MinTime:               ka.MinTime,
+		PermitWithoutStream:   ka.PermitWithoutStream,
+	}
+}
+
+func (c *config) OTLPLogsEnabled() bool {
+	return c.Collectors.OpenTelemetry.Enabled && c.Collectors.OpenTelemetry.Logs.Enabled
+}
+
+func (c *config) OTLPSlowQueryLogPattern() *regexp.Regexp {
+	return logPattern(c.Collectors.OpenTelemetry.Logs.SlowQueryPattern, defaultSlowQueryLogRegex)
+}
+
+func (c *config) OTLPAuthFailureLogPattern() *regexp.Regexp {
+	return logPattern(c.Collectors.OpenTelemetry.Logs.AuthFailurePattern, defaultAuthFailureLogRegex)
+}
+
+// logPattern returns the compiled default when pattern is unchanged, and compiles
+// pattern, already validated, otherwise.
+func logPattern(pattern string, defaultRegex *regexp.Regexp) *regexp.Regexp {
+	if pattern == defaultRegex.String() {
+		return defaultRegex
+	}
+
+	return regexp.MustCompile(pattern)
+}
+
+func (c *config) OTLPTracesEnabled() bool {
+	return c.Collectors.OpenTelemetry.Enabled && c.Collectors.OpenTelemetry.Traces.Enabled
+}
+
+func (c *config) OTLPTraceMethodAttributes() []string {
+	return slices.Clone(c.Collectors.OpenTelemetry.Traces.MethodAttributes)
+}
+
+func (c *config) OTLPTraceDurationBuckets() []float64 {
+	return slices.Clone(c.Collectors.OpenTelemetry.Traces.Buckets)
+}
+
+func (c *config) OTLPPipeline() []domain.PipelineStage {
+	otel := c.Collectors.OpenTelemetry
+	if len(otel.Pipeline) == 0 {
+		if otel.EnableBatching {
+			return []domain.PipelineStage{{Type: domain.PipelineStageBatch}}
+		}
+		return nil
+	}
+
+	stages := make([]domain.PipelineStage, 0, len(otel.Pipeline))
+	for _, s := range otel.Pipeline {
+		rules := make([]domain.RelabelRule, 0, len(s.Rules))
+		for _, r := range s.Rules {
+			rules = append(rules, domain.RelabelRule{
+				Action:      r.Action,
+				SourceLabel: r.SourceLabel,
+				TargetLabel: r.TargetLabel,
+				Regex:       regexp.MustCompile(r.Regex),
+				Replacement: r.Replacement,
+			})
+		}
+
+		stages = append(stages, domain.PipelineStage{
+			Type:             s.Type,
+			Include:          s.Include,
+			Exclude:          s.Exclude,
+			RelabelRules:     rules,
+			MetricsPerSecond: s.MetricsPerSecond,
+			Burst:            s.Burst,
+		})
+	}
+
+	return stages
+}
+
+func (c *config) OperationClassificationDefault() domain.OperationType {
+	return domain.OperationType(c.Collectors.OperationClassification.Default)
+}
+
+func (c *config) OperationClassificationRules() []domain.ClassificationRule {
+	rules := make([]domain.ClassificationRule, 0, len(c.Collectors.OperationClassification.Rules))
+	for _, r := range c.Collectors.OperationClassification.Rules {
+		rules = append(rules, domain.ClassificationRule{
+			Type:             domain.OperationType(r.Type),
+			HasFields:        r.HasFields,
+			MinFields:        r.MinFields,
+			MaxFields:        r.MaxFields,
+			MinScalarFields:  r.MinScalarFields,
+			MaxComplexFields: r.MaxComplexFields,
+		})
+	}
+
+	return rules
+}
+
+func (c *config) OperationClassificationOverrides() []domain.ClassificationOverride {
+	overrides := make([]domain.ClassificationOverride, 0, len(c.Collectors.OperationClassification.Overrides))
+	for _, o := range c.Collectors.OperationClassification.Overrides {
+		overrides = append(overrides, domain.ClassificationOverride{
+			Pattern: o.Table,
+			Type:    domain.OperationType(o.Classification),
+		})
+	}
+
+	return overrides
+}
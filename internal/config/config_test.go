@@ -0,0 +1,85 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+func TestApplyDetectedTopologyDisablesLiveQuery(t *testing.T) {
+	tests := []struct {
+		name           string
+		inline         string
+		detected       string
+		wantMode       string
+		wantLiveQuery  bool
+		wantCorrection bool
+	}{
+		{
+			name:           "auto_detect distributed",
+			inline:         "surrealdb: {auto_detect: true}",
+			detected:       "distributed",
+			wantMode:       "distributed",
+			wantLiveQuery:  false,
+			wantCorrection: true,
+		},
+		{
+			name:           "auto_detect cloud",
+			inline:         "surrealdb: {auto_detect: true}",
+			detected:       "cloud",
+			wantMode:       "cloud",
+			wantLiveQuery:  false,
+			wantCorrection: true,
+		},
+		{
+			name:           "auto_detect single",
+			inline:         "surrealdb: {auto_detect: true}",
+			detected:       "single",
+			wantMode:       "single",
+			wantLiveQuery:  true,
+			wantCorrection: false,
+		},
+		{
+			name:           "configured deployment_mode",
+			inline:         "surrealdb: {auto_detect: true, deployment_mode: single}",
+			detected:       "distributed",
+			wantMode:       "single",
+			wantLiveQuery:  true,
+			wantCorrection: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overrides := Overrides{Collectors: map[string]bool{
+				"live_query":        true,
+				"stats_table":       true,
+				"consistency_audit": true,
+			}}
+
+			cfg, err := Load("", tt.inline, false, overrides)
+			if err != nil {
+				t.Fatalf("failed to load config: %v", err)
+			}
+
+			if !cfg.LiveQueryEnabled() || !cfg.CollectorEnabled("consistency_audit") {
+				t.Fatal("live_query and consistency_audit should be enabled before detection")
+			}
+
+			fixes := cfg.ApplyDetectedTopology(domain.Topology{DeploymentMode: tt.detected})
+
+			if got := cfg.DeploymentMode(); got != tt.wantMode {
+				t.Errorf("deployment_mode = %q, want %q", got, tt.wantMode)
+			}
+			if got := cfg.LiveQueryEnabled(); got != tt.wantLiveQuery {
+				t.Errorf("live_query enabled = %t, want %t", got, tt.wantLiveQuery)
+			}
+			if got := cfg.CollectorEnabled("consistency_audit"); got != tt.wantLiveQuery {
+				t.Errorf("consistency_audit enabled = %t, want %t", got, tt.wantLiveQuery)
+			}
+			if got := len(fixes) > 0; got != tt.wantCorrection {
+				t.Errorf("corrections = %v, want any: %t", fixes, tt.wantCorrection)
+			}
+		})
+	}
+}
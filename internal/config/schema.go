@@ -0,0 +1,217 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"gopkg.in/yaml.v3"
+)
+
+const schemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// durationPattern matches the durations accepted by time.ParseDuration.
+const durationPattern = `^(0|-?([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h)(([0-9]+(\.[0-9]*)?|\.[0-9]+)(ns|us|µs|ms|s|m|h))*)$`
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// schemaEnums holds the allowed values of settings by their dotted path; list items
+// share the path of their list.
+var schemaEnums = map[string][]string{
+	"validation":                                                   AllowedValidationModes,
+	"exporter.overlapping_scrapes":                                 AllowedOverlappingScrapes,
+	"surrealdb.scheme":                                             AllowedSchemes,
+	"surrealdb.storage_engine":                                     AllowedStorageEngines,
+	"surrealdb.deployment_mode":                                    AllowedDeploymentModes,
+	"surrealdb.level":                                              AllowedUserLevels,
+	"surrealdb.credentials.level":                                  AllowedAuthLevels,
+	"collectors.info.load_average":                                 AllowedLoadAverages,
+	"collectors.record_count.mode":                                 AllowedRecordCountModes,
+	"collectors.stats_table.strategy":                              AllowedStatsStrategies,
+	"collectors.operation_classification.default":                  classifiableTypes(),
+	"collectors.operation_classification.rules.type":               classifiableTypes(),
+	"collectors.operation_classification.overrides.classification": classifiableTypes(),
+	"collectors.open_telemetry.translation_strategy":               AllowedTranslationStrategies,
+	"collectors.open_telemetry.grpc.compression":                   AllowedGRPCCompressions,
+	"collectors.open_telemetry.pipeline.type":                      AllowedPipelineStages,
+	"collectors.open_telemetry.pipeline.rules.action":              AllowedRelabelActions,
+	"collectors.open_telemetry.queue.overflow":                     AllowedQueueOverflows,
+	"logging.output":                                               AllowedLogOutputs,
+}
+
+// schemaTypes overrides the JSON types of string settings that are also written as
+// YAML numbers.
+var schemaTypes = map[string]any{
+	"surrealdb.port": []string{"string", "integer"},
+}
+
+// jsonSchema is the subset of JSON Schema describing the configuration.
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 any                    `json:"type,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	AdditionalProperties any                    `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Minimum              *int                   `json:"minimum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+}
+
+// WriteSchema writes a JSON Schema of the YAML configuration, generated from the config
+// structs with their defaults, for IDE and Helm values validation.
+func WriteSchema(w io.Writer) error {
+	root := schemaFor(reflect.ValueOf(*defaultConfig()), reflect.TypeFor[config](), "")
+	root.Schema = schemaDraft
+	root.Title = "SurrealDB Prometheus exporter configuration"
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(root); err != nil {
+		return fmt.Errorf("failed to encode config schema: %w", err)
+	}
+
+	return nil
+}
+
+// schemaFor returns the schema of a setting of type t at path. defaults holds the default
+// value of the setting and is invalid for list items.
+func schemaFor(defaults reflect.Value, t reflect.Type, path string) *jsonSchema {
+	schema := &jsonSchema{Enum: schemaEnums[path]}
+
+	switch {
+	case t == durationType:
+		// Durations are strings like 1m30s, YAML integers are nanoseconds.
+		schema.Type = []string{"string", "integer"}
+		schema.Pattern = durationPattern
+	case t.Kind() == reflect.Struct:
+		schema.Type = "object"
+		schema.Properties = make(map[string]*jsonSchema)
+		schema.AdditionalProperties = false
+
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+
+			var fieldDefaults reflect.Value
+			if defaults.IsValid() {
+				fieldDefaults = defaults.Field(i)
+			}
+
+			property := schemaFor(fieldDefaults, field.Type, joinPath(path, name))
+			property.Description = field.Tag.Get("description")
+			if field.Tag.Get("secret") == "true" {
+				property.WriteOnly = true
+				property.Default = nil
+			}
+
+			schema.Properties[name] = property
+		}
+
+		return schema
+	case t.Kind() == reflect.Slice:
+		schema.Type = "array"
+		schema.Items = schemaFor(reflect.Value{}, t.Elem(), path)
+		schema.Items.Enum = schema.Enum
+		schema.Enum = nil
+	case t.Kind() == reflect.Map:
+		schema.Type = "object"
+		schema.AdditionalProperties = schemaFor(reflect.Value{}, t.Elem(), path)
+	case t.Kind() == reflect.Pointer:
+		// Optional settings, unset is null.
+		element := reflect.Value{}
+		if defaults.IsValid() && !defaults.IsNil() {
+			element = defaults.Elem()
+		}
+		schema = schemaFor(element, t.Elem(), path)
+		schema.Type = []any{schema.Type, "null"}
+		return schema
+	case t.Kind() == reflect.Bool:
+		schema.Type = "boolean"
+	case t.Kind() == reflect.String:
+		schema.Type = "string"
+	case t.Kind() == reflect.Int:
+		schema.Type = "integer"
+	case t.Kind() == reflect.Uint32:
+		schema.Type = "integer"
+		schema.Minimum = new(int)
+	case t.Kind() == reflect.Float64:
+		schema.Type = "number"
+	case t.Kind() == reflect.Interface:
+		// Any value, e.g. integer or string record IDs.
+	}
+
+	if override, ok := schemaTypes[path]; ok {
+		schema.Type = override
+	}
+
+	if hasDefault(defaults) {
+		schema.Default = schemaDefault(defaults)
+	}
+
+	return schema
+}
+
+// hasDefault reports whether a setting has a default worth documenting: false and
+// zero numbers are, empty strings, lists and maps are not.
+func hasDefault(defaults reflect.Value) bool {
+	if !defaults.IsValid() {
+		return false
+	}
+
+	switch defaults.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int64, reflect.Uint32, reflect.Float64:
+		return true
+	case reflect.Slice, reflect.Map, reflect.String:
+		return defaults.Len() > 0
+	default:
+		return false
+	}
+}
+
+// schemaDefault returns defaults as it is written in YAML, e.g. durations as strings and
+// structs with their YAML keys.
+func schemaDefault(defaults reflect.Value) any {
+	data, err := yaml.Marshal(defaults.Interface())
+	if err != nil {
+		return nil
+	}
+
+	var value any
+	if err := yaml.Unmarshal(data, &value); err != nil {
+		return nil
+	}
+
+	return value
+}
+
+// joinPath appends name to the dotted path of a setting.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// classifiableTypes returns the operation types settings can classify tables as.
+func classifiableTypes() []string {
+	types := make([]string, 0, len(domain.ClassifiableOperationTypes))
+	for _, opType := range domain.ClassifiableOperationTypes {
+		types = append(types, string(opType))
+	}
+
+	return types
+}
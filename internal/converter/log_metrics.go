@@ -0,0 +1,142 @@
+package converter
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxAuthFailureSeries caps the namespace and access pairs authentication failures are
+// counted by. Failed sign-ins carry names chosen by the client, so further pairs are
+// counted under authFailureOther instead of growing the series without bound.
+const maxAuthFailureSeries = 100
+
+// authFailureOther labels the authentication failures beyond maxAuthFailureSeries.
+const authFailureOther = "other"
+
+// LogMetrics derives metrics from SurrealDB log records received via OTLP.
+type LogMetrics struct {
+	slowQueryPattern   *regexp.Regexp
+	authFailurePattern *regexp.Regexp
+
+	records      *prometheus.CounterVec
+	slowQueries  prometheus.Counter
+	authFailures *prometheus.CounterVec
+
+	mu               sync.Mutex
+	authFailurePairs map[[2]string]struct{}
+}
+
+// NewLogMetrics creates log metrics and registers them with registry.
+// Records whose body matches slowQueryPattern are also counted as slow queries, and
+// records whose body matches authFailurePattern as authentication failures.
+func NewLogMetrics(
+	cfg Config,
+	registry prometheus.Registerer,
+	slowQueryPattern *regexp.Regexp,
+	authFailurePattern *regexp.Regexp,
+) *LogMetrics {
+	constLabels := prometheus.Labels{
+		"cluster":         cfg.ClusterName(),
+		"storage_engine":  cfg.StorageEngine(),
+		"deployment_mode": cfg.DeploymentMode(),
+	}
+
+	m := &LogMetrics{
+		slowQueryPattern:   slowQueryPattern,
+		authFailurePattern: authFailurePattern,
+		authFailurePairs:   make(map[[2]string]struct{}),
+		records: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   domain.Namespace,
+				Subsystem:   "log",
+				Name:        "records_total",
+				Help:        "Total number of SurrealDB log records received via OTLP by level and target",
+				ConstLabels: constLabels,
+			},
+			[]string{"level", "target"},
+		),
+		slowQueries: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Namespace:   domain.Namespace,
+				Subsystem:   "log",
+				Name:        "slow_queries_total",
+				Help:        "Total number of SurrealDB slow query log records received via OTLP",
+				ConstLabels: constLabels,
+			},
+		),
+		authFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   domain.Namespace,
+				Subsystem:   "auth",
+				Name:        "failures_total",
+				Help:        "Total number of SurrealDB authentication failure log records received via OTLP by namespace and access method",
+				ConstLabels: constLabels,
+			},
+			[]string{"namespace", "access"},
+		),
+	}
+
+	registry.MustRegister(m.records, m.slowQueries, m.authFailures)
+
+	return m
+}
+
+// Record counts the given log records.
+func (m *LogMetrics) Record(records []domain.LogRecord) {
+	for _, record := range records {
+		m.records.WithLabelValues(record.Level, record.Target).Inc()
+
+		if m.slowQueryPattern.MatchString(record.Body) {
+			m.slowQueries.Inc()
+		}
+
+		if match := m.authFailurePattern.FindStringSubmatch(record.Body); match != nil {
+			m.recordAuthFailure(record, match)
+		}
+	}
+}
+
+// recordAuthFailure counts an authentication failure. The namespace and access method
+// come from the named groups of the pattern, or else from the ns and ac attributes of
+// the record, and are empty when neither has them.
+func (m *LogMetrics) recordAuthFailure(record domain.LogRecord, match []string) {
+	namespace := firstNonEmpty(m.group(match, "namespace"), record.Attributes["ns"], record.Attributes["namespace"])
+	access := firstNonEmpty(m.group(match, "access"), record.Attributes["ac"], record.Attributes["access"])
+
+	m.mu.Lock()
+	pair := [2]string{namespace, access}
+	if _, ok := m.authFailurePairs[pair]; !ok {
+		if len(m.authFailurePairs) < maxAuthFailureSeries {
+			m.authFailurePairs[pair] = struct{}{}
+		} else {
+			namespace, access = authFailureOther, authFailureOther
+		}
+	}
+	m.mu.Unlock()
+
+	m.authFailures.WithLabelValues(namespace, access).Inc()
+}
+
+// group returns the submatch of the named group of the auth failure pattern, or an empty
+// string when the pattern has no such group.
+func (m *LogMetrics) group(match []string, name string) string {
+	if i := m.authFailurePattern.SubexpIndex(name); i > 0 {
+		return match[i]
+	}
+
+	return ""
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
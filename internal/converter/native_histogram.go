@@ -0,0 +1,118 @@
+package converter
+
+import (
+	"math"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+const (
+	// nativeSchemaMin and nativeSchemaMax bound the exponential schemas of Prometheus native histograms.
+	nativeSchemaMin = -4
+	nativeSchemaMax = 8
+
+	// explicitBucketSchema is the schema explicit bucket histograms are mapped to,
+	// giving buckets about 9% wide.
+	explicitBucketSchema = 3
+)
+
+// nativeHistogram holds the sparse buckets of a Prometheus native histogram.
+// Bucket index i of schema s covers (2^((i-1)*2^-s), 2^(i*2^-s)].
+type nativeHistogram struct {
+	schema        int32
+	zeroThreshold float64
+	zeroCount     uint64
+	positive      map[int]int64
+	negative      map[int]int64
+}
+
+// toNativeHistogram converts histogram data to native histogram buckets. Exponential
+// histograms map exactly, downscaled when finer than the native maximum schema. Explicit
+// buckets are approximated by counting each bucket at the native bucket of its upper
+// bound. It returns false when the data cannot be represented.
+func toNativeHistogram(data *domain.HistogramData) (*nativeHistogram, bool) {
+	if data.Exponential != nil {
+		return fromExponential(data.Exponential)
+	}
+
+	return fromExplicitBuckets(data.Buckets), true
+}
+
+// fromExponential converts OTLP exponential buckets. OTLP index i covers
+// (base^i, base^(i+1)], which is native index i+1.
+func fromExponential(exp *domain.ExponentialHistogramData) (*nativeHistogram, bool) {
+	if exp.Scale < nativeSchemaMin {
+		return nil, false
+	}
+
+	shift := max(exp.Scale-nativeSchemaMax, 0)
+
+	h := &nativeHistogram{
+		schema:        exp.Scale - shift,
+		zeroThreshold: exp.ZeroThreshold,
+		zeroCount:     exp.ZeroCount,
+		positive:      exponentialBuckets(exp.PositiveOffset, exp.PositiveCounts, shift),
+		negative:      exponentialBuckets(exp.NegativeOffset, exp.NegativeCounts, shift),
+	}
+
+	return h, true
+}
+
+// exponentialBuckets maps OTLP bucket counts to native bucket indexes, merging buckets
+// when downscaling by shift.
+func exponentialBuckets(offset int32, counts []uint64, shift int32) map[int]int64 {
+	buckets := make(map[int]int64, len(counts))
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+
+		index := (int(offset) + i) >> shift
+		buckets[index+1] += int64(count)
+	}
+
+	return buckets
+}
+
+// fromExplicitBuckets converts cumulative explicit buckets. Observations above the last
+// finite bound are counted in the native bucket following it.
+func fromExplicitBuckets(buckets []domain.HistogramBucket) *nativeHistogram {
+	h := &nativeHistogram{
+		schema:   explicitBucketSchema,
+		positive: make(map[int]int64),
+		negative: make(map[int]int64),
+	}
+
+	var previous uint64
+	lastIndex := 0
+	for _, bucket := range buckets {
+		count := int64(bucket.Count - previous)
+		previous = bucket.Count
+
+		switch {
+		case math.IsInf(bucket.UpperBound, 1):
+			if count > 0 {
+				h.positive[lastIndex+1] += count
+			}
+			continue
+		case bucket.UpperBound > 0:
+			lastIndex = nativeBucketIndex(bucket.UpperBound, explicitBucketSchema)
+			if count > 0 {
+				h.positive[lastIndex] += count
+			}
+		case bucket.UpperBound < 0:
+			if count > 0 {
+				h.negative[nativeBucketIndex(-bucket.UpperBound, explicitBucketSchema)] += count
+			}
+		default:
+			h.zeroCount += uint64(count)
+		}
+	}
+
+	return h
+}
+
+// nativeBucketIndex returns the index of the native bucket of schema containing v > 0.
+func nativeBucketIndex(v float64, schema int32) int {
+	return int(math.Ceil(math.Log2(v) * math.Exp2(float64(schema))))
+}
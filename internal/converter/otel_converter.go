@@ -8,6 +8,7 @@ import (
 	"math"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 	"github.com/prometheus/client_golang/prometheus"
@@ -16,16 +17,27 @@ import (
 // Config holds converter configuration.
 type Config interface {
 	OTLPTranslationStrategy() string
+	OTLPNativeHistograms() bool
+	OTLPMetricPrefix() string
+	OTLPResourceLabels() map[string][]string
+	OTLPHistogramBuckets() []domain.HistogramBucketLayout
 	ClusterName() string
 	StorageEngine() string
 	DeploymentMode() string
 }
 
+// ReservedNames reports metric names owned by the exporter's own collectors.
+type ReservedNames interface {
+	Contains(name string) bool
+}
+
 // Converter handles conversion of domain metrics to Prometheus format.
 type Converter struct {
 	config      Config
 	registry    *prometheus.Registry
 	constLabels map[string]string
+	reserved    ReservedNames
+	collisions  map[string]struct{}
 
 	gauges     map[string]*prometheus.GaugeVec
 	counters   map[string]*prometheus.CounterVec
@@ -33,25 +45,33 @@ type Converter struct {
 
 	metricLabelNames map[string][]string
 
+	logger *slog.Logger
+
 	mu sync.RWMutex
 }
 
-// NewConverter creates a new converter instance.
-func NewConverter(cfg Config, registry *prometheus.Registry) *Converter {
+// NewConverter creates a new converter instance. Converted metrics whose names are
+// reserved are skipped. The cluster, storage_engine and deployment_mode labels default
+// to the configured values and node to empty, unless set from resource attributes.
+func NewConverter(cfg Config, registry *prometheus.Registry, reserved ReservedNames, logger *slog.Logger) *Converter {
 	constLabels := map[string]string{
 		"cluster":         cfg.ClusterName(),
 		"storage_engine":  cfg.StorageEngine(),
 		"deployment_mode": cfg.DeploymentMode(),
+		"node":            "",
 	}
 
 	return &Converter{
 		config:           cfg,
 		registry:         registry,
 		constLabels:      constLabels,
+		reserved:         reserved,
+		collisions:       make(map[string]struct{}),
 		gauges:           make(map[string]*prometheus.GaugeVec),
 		counters:         make(map[string]*prometheus.CounterVec),
 		histograms:       make(map[string]*HistogramCollector),
 		metricLabelNames: make(map[string][]string),
+		logger:           logger,
 	}
 }
 
@@ -59,7 +79,7 @@ func NewConverter(cfg Config, registry *prometheus.Registry) *Converter {
 func (c *Converter) Convert(batch domain.MetricBatch) error {
 	for _, metric := range batch.Metrics {
 		if err := c.convertMetric(metric); err != nil {
-			slog.Warn("failed to convert metric",
+			c.logger.Warn("failed to convert metric",
 				"metric", metric.Name,
 				"type", metric.Type.String(),
 				"error", err)
@@ -78,9 +98,13 @@ func (c *Converter) convertMetric(metric domain.Metric) error {
 	promName := domain.SanitizeMetricName(metric.Name, c.config.OTLPTranslationStrategy())
 	promName = domain.AddSuffixByTypeForMetric(promName, originalName, metric.Type, metric.Unit)
 
-	promName = domain.Namespace + "_" + promName
+	promName = c.config.OTLPMetricPrefix() + promName
+
+	if c.collides(promName, originalName) {
+		return nil
+	}
 
-	promLabels, labelNames := c.prepareLabels(promName, metric.Labels)
+	promLabels, labelNames := c.prepareLabels(promName, metric.Labels, c.resourceLabels(metric.Resource))
 
 	switch metric.Type {
 	case domain.MetricTypeGauge:
@@ -94,8 +118,48 @@ func (c *Converter) convertMetric(metric domain.Metric) error {
 	}
 }
 
-// prepareLabels sanitizes labels and adds constant labels.
-func (c *Converter) prepareLabels(metricName string, labels map[string]string) (map[string]string, []string) {
+// collides reports whether name is reserved by the exporter, warning once per name.
+func (c *Converter) collides(name, originalName string) bool {
+	if c.reserved == nil || !c.reserved.Contains(name) {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, warned := c.collisions[name]; !warned {
+		c.collisions[name] = struct{}{}
+		c.logger.Warn("skipping OTLP metric whose name collides with an exporter metric, consider changing metric_prefix",
+			"metric", originalName,
+			"name", name)
+	}
+
+	return true
+}
+
+// resourceLabels returns the constant labels of a metric, taking each from the first
+// configured resource attribute present and falling back to the configured value.
+func (c *Converter) resourceLabels(resource map[string]string) map[string]string {
+	labels := maps.Clone(c.constLabels)
+
+	for label, attributes := range c.config.OTLPResourceLabels() {
+		for _, attribute := range attributes {
+			if value := resource[attribute]; value != "" {
+				labels[label] = value
+				break
+			}
+		}
+	}
+
+	return labels
+}
+
+// prepareLabels sanitizes labels and adds the constant labels.
+func (c *Converter) prepareLabels(
+	metricName string,
+	labels map[string]string,
+	constLabels map[string]string,
+) (map[string]string, []string) {
 	if existingLabelNames, exists := c.metricLabelNames[metricName]; exists {
 		promLabels := make(map[string]string)
 		for _, labelName := range existingLabelNames {
@@ -106,13 +170,13 @@ func (c *Converter) prepareLabels(metricName string, labels map[string]string) (
 			}
 		}
 
-		maps.Copy(promLabels, c.constLabels)
+		maps.Copy(promLabels, constLabels)
 
 		return promLabels, existingLabelNames
 	}
 
 	promLabels := make(map[string]string)
-	labelNames := make([]string, 0, len(labels)+len(c.constLabels))
+	labelNames := make([]string, 0, len(labels)+len(constLabels))
 
 	for k, v := range labels {
 		sanitizedKey := domain.SanitizeLabelName(k)
@@ -120,7 +184,7 @@ func (c *Converter) prepareLabels(metricName string, labels map[string]string) (
 		labelNames = append(labelNames, sanitizedKey)
 	}
 
-	for k, v := range c.constLabels {
+	for k, v := range constLabels {
 		promLabels[k] = v
 		labelNames = append(labelNames, k)
 	}
@@ -218,7 +282,7 @@ func (c *Converter) convertHistogram(
 
 	histCollector, exists := c.histograms[name]
 	if !exists {
-		histCollector = NewHistogramCollector(name, metric.Description, labelNames)
+		histCollector = NewHistogramCollector(name, metric.Description, labelNames, c.config.OTLPNativeHistograms())
 
 		if err := c.registry.Register(histCollector); err != nil {
 			var are prometheus.AlreadyRegisteredError
@@ -235,13 +299,18 @@ func (c *Converter) convertHistogram(
 		convertedMetric = convertHistogramUnitsForMetric(metric, originalName)
 	}
 
+	if bounds := c.bucketLayout(originalName); bounds != nil {
+		convertedMetric.HistogramData = rebucket(convertedMetric.HistogramData, bounds)
+	}
+
 	histCollector.Update(convertedMetric, labels)
 
 	return nil
 }
 
 // convertHistogramUnitsForMetric applies unit conversion to histogram bucket bounds and sum,
-// using metric-aware correction for known OTEL metrics.
+// using metric-aware correction for known OTEL metrics. Exponential buckets cannot be
+// rescaled, so converted histograms keep only their classic buckets.
 func convertHistogramUnitsForMetric(metric domain.Metric, originalName string) domain.Metric {
 	conv := domain.GetUnitConversionForMetric(originalName, metric.Unit)
 	if conv == nil || conv.Multiplier == 1 {
@@ -271,22 +340,28 @@ func convertHistogramUnitsForMetric(metric domain.Metric, originalName string) d
 		Description:   metric.Description,
 		Unit:          metric.Unit,
 		HistogramData: convertedData,
+		Resource:      metric.Resource,
 	}
 }
 
 // histogramData stores the data needed to create a histogram metric.
+// native is set when the histogram is exported as a native histogram.
 type histogramData struct {
 	count   uint64
 	sum     float64
+	created time.Time
 	buckets map[float64]uint64
+	native  *nativeHistogram
 }
 
 // HistogramCollector is a custom Prometheus collector for histograms.
-// It uses ConstHistogram to allow setting bucket values directly.
+// It uses ConstHistogram to allow setting bucket values directly, or
+// ConstNativeHistogram when native histograms are enabled.
 type HistogramCollector struct {
 	name        string
 	description string
 	labelNames  []string
+	native      bool
 
 	mu        sync.RWMutex
 	metrics   map[string]*histogramData
@@ -294,11 +369,14 @@ type HistogramCollector struct {
 }
 
 // NewHistogramCollector creates a new histogram collector.
-func NewHistogramCollector(name, description string, labelNames []string) *HistogramCollector {
+// When native is true, histograms are exported as Prometheus native histograms,
+// falling back to classic buckets for data that cannot be represented natively.
+func NewHistogramCollector(name, description string, labelNames []string, native bool) *HistogramCollector {
 	return &HistogramCollector{
 		name:        name,
 		description: description,
 		labelNames:  labelNames,
+		native:      native,
 		metrics:     make(map[string]*histogramData),
 		labelSets:   make(map[string]prometheus.Labels),
 	}
@@ -343,12 +421,21 @@ func (h *HistogramCollector) Update(metric domain.Metric, labels map[string]stri
 		buckets[bucket.UpperBound] = bucket.Count
 	}
 
-	h.metrics[key] = &histogramData{
+	data := &histogramData{
 		count:   metric.HistogramData.Count,
 		sum:     metric.HistogramData.Sum,
+		created: metric.HistogramData.CreatedTime,
 		buckets: buckets,
 	}
 
+	if h.native {
+		if native, ok := toNativeHistogram(metric.HistogramData); ok {
+			data.native = native
+		}
+	}
+
+	h.metrics[key] = data
+
 	h.labelSets[key] = promLabels
 
 	if len(h.metrics) > 10000 {
@@ -385,12 +472,28 @@ func (h *HistogramCollector) Collect(ch chan<- prometheus.Metric) {
 			promLabels,
 		)
 
-		histMetric, err := prometheus.NewConstHistogram(
-			desc,
-			data.count,
-			data.sum,
-			data.buckets,
-		)
+		var histMetric prometheus.Metric
+		var err error
+		if data.native != nil {
+			histMetric, err = prometheus.NewConstNativeHistogram(
+				desc,
+				data.count,
+				data.sum,
+				data.native.positive,
+				data.native.negative,
+				data.native.zeroCount,
+				data.native.schema,
+				data.native.zeroThreshold,
+				data.created,
+			)
+		} else {
+			histMetric, err = prometheus.NewConstHistogram(
+				desc,
+				data.count,
+				data.sum,
+				data.buckets,
+			)
+		}
 
 		if err != nil {
 			slog.Error("failed to create const histogram",
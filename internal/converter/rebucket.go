@@ -0,0 +1,80 @@
+package converter
+
+import (
+	"math"
+	"path"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// bucketLayout returns the configured bucket bounds for the OTLP metric name, or nil
+// when its histograms keep their incoming buckets. The first matching layout wins.
+func (c *Converter) bucketLayout(originalName string) []float64 {
+	for _, layout := range c.config.OTLPHistogramBuckets() {
+		if matched, _ := path.Match(layout.Pattern, originalName); matched {
+			return layout.Buckets
+		}
+	}
+
+	return nil
+}
+
+// rebucket re-aggregates cumulative histogram buckets onto bounds. Counts at bounds
+// between incoming bucket bounds are linearly interpolated, like histogram_quantile does,
+// and bounds above the highest finite incoming bound get its count. Re-aggregated
+// histograms have no exponential buckets, so they are always exported as classic
+// histograms.
+func rebucket(data *domain.HistogramData, bounds []float64) *domain.HistogramData {
+	incoming := make([]domain.HistogramBucket, 0, len(data.Buckets))
+	for _, bucket := range data.Buckets {
+		if !math.IsInf(bucket.UpperBound, 1) {
+			incoming = append(incoming, bucket)
+		}
+	}
+
+	rebucketed := &domain.HistogramData{
+		Count:       data.Count,
+		Sum:         data.Sum,
+		CreatedTime: data.CreatedTime,
+		Buckets:     make([]domain.HistogramBucket, 0, len(bounds)+1),
+	}
+
+	for _, bound := range bounds {
+		rebucketed.Buckets = append(rebucketed.Buckets, domain.HistogramBucket{
+			UpperBound: bound,
+			Count:      cumulativeCountAt(incoming, bound),
+		})
+	}
+
+	rebucketed.Buckets = append(rebucketed.Buckets, domain.HistogramBucket{
+		UpperBound: math.Inf(1),
+		Count:      data.Count,
+	})
+
+	return rebucketed
+}
+
+// cumulativeCountAt estimates the number of observations less than or equal to bound
+// from ascending cumulative buckets. The lowest bucket is assumed to start at zero.
+func cumulativeCountAt(buckets []domain.HistogramBucket, bound float64) uint64 {
+	lowerBound, lowerCount := 0.0, uint64(0)
+
+	for _, bucket := range buckets {
+		if bound == bucket.UpperBound {
+			return bucket.Count
+		}
+
+		if bound < bucket.UpperBound {
+			if bound <= lowerBound {
+				return lowerCount
+			}
+
+			fraction := (bound - lowerBound) / (bucket.UpperBound - lowerBound)
+			return lowerCount + uint64(math.Round(fraction*float64(bucket.Count-lowerCount)))
+		}
+
+		lowerBound, lowerCount = bucket.UpperBound, bucket.Count
+	}
+
+	return lowerCount
+}
@@ -0,0 +1,133 @@
+package converter
+
+import (
+	"sync"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxTraceMethodSeries caps the method and kind pairs spans are counted by. Spans of
+// client applications may carry arbitrary methods, so further pairs are counted under
+// traceMethodOther.
+const maxTraceMethodSeries = 100
+
+// traceMethodOther labels the spans beyond maxTraceMethodSeries.
+const traceMethodOther = "other"
+
+// TraceMetrics derives request rate, error and duration (RED) metrics per RPC method from
+// spans received via OTLP, for deployments that export traces but no metrics.
+type TraceMetrics struct {
+	methodAttributes []string
+
+	requests *prometheus.CounterVec
+	errors   *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+
+	mu    sync.Mutex
+	pairs map[[2]string]struct{}
+}
+
+// NewTraceMetrics creates trace metrics and registers them with registry. The method of
+// a span is read from the first of methodAttributes it has; spans with none are ignored.
+func NewTraceMetrics(
+	cfg Config,
+	registry prometheus.Registerer,
+	methodAttributes []string,
+	buckets []float64,
+) *TraceMetrics {
+	constLabels := prometheus.Labels{
+		"cluster":         cfg.ClusterName(),
+		"storage_engine":  cfg.StorageEngine(),
+		"deployment_mode": cfg.DeploymentMode(),
+	}
+
+	m := &TraceMetrics{
+		methodAttributes: methodAttributes,
+		pairs:            make(map[[2]string]struct{}),
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   domain.Namespace,
+				Subsystem:   "rpc",
+				Name:        "requests_total",
+				Help:        "Total number of SurrealDB RPC spans received via OTLP by method and span kind",
+				ConstLabels: constLabels,
+			},
+			[]string{"method", "kind"},
+		),
+		errors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   domain.Namespace,
+				Subsystem:   "rpc",
+				Name:        "errors_total",
+				Help:        "Total number of SurrealDB RPC spans with an error status received via OTLP by method and span kind",
+				ConstLabels: constLabels,
+			},
+			[]string{"method", "kind"},
+		),
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   domain.Namespace,
+				Subsystem:   "rpc",
+				Name:        "duration_seconds",
+				Help:        "Duration of SurrealDB RPC spans received via OTLP by method and span kind",
+				Buckets:     buckets,
+				ConstLabels: constLabels,
+			},
+			[]string{"method", "kind"},
+		),
+	}
+
+	registry.MustRegister(m.requests, m.errors, m.duration)
+
+	return m
+}
+
+// Record counts the given spans.
+func (m *TraceMetrics) Record(spans []domain.SpanRecord) {
+	for _, span := range spans {
+		method, ok := m.method(span)
+		if !ok {
+			continue
+		}
+
+		method, kind := m.labels(method, span.Kind)
+
+		m.requests.WithLabelValues(method, kind).Inc()
+		if span.Error {
+			m.errors.WithLabelValues(method, kind).Inc()
+		}
+		m.duration.WithLabelValues(method, kind).Observe(span.Duration.Seconds())
+	}
+}
+
+// method returns the RPC method of span from the first method attribute it has.
+func (m *TraceMetrics) method(span domain.SpanRecord) (string, bool) {
+	for _, attribute := range m.methodAttributes {
+		if method := span.Attributes[attribute]; method != "" {
+			return method, true
+		}
+	}
+
+	return "", false
+}
+
+// labels returns the label values of a span, or traceMethodOther once
+// maxTraceMethodSeries pairs are counted.
+func (m *TraceMetrics) labels(method, kind string) (string, string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pair := [2]string{method, kind}
+	if _, ok := m.pairs[pair]; ok {
+		return method, kind
+	}
+
+	if len(m.pairs) >= maxTraceMethodSeries {
+		return traceMethodOther, traceMethodOther
+	}
+
+	m.pairs[pair] = struct{}{}
+
+	return method, kind
+}
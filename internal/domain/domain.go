@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -13,89 +14,466 @@ const Namespace = "surrealdb"
 
 // SurrealDBInfo represents the complete hierarchical information about a SurrealDB instance.
 type SurrealDBInfo struct {
-	System         SystemMetrics
-	Namespaces     map[string]*NamespaceInfo
-	RootUsers      int
-	RootAccesses   int
-	Nodes          int
-	ScrapeDuration time.Duration
+	System         SystemMetrics             `json:"system"`
+	Namespaces     map[string]*NamespaceInfo `json:"namespaces"`
+	RootUsers      int                       `json:"root_users"`
+	RootAccesses   int                       `json:"root_accesses"`
+	Nodes          int                       `json:"nodes"`
+	NodeIDs        []string                  `json:"node_ids"`
+	Features       map[string]bool           `json:"features"`
+	Errors         []InfoError               `json:"errors,omitempty"`
+	ScrapeDuration time.Duration             `json:"-"`
+
+	// Scoped is set when the information covers the configured scope instead of the
+	// whole server; the root level fields and system metrics are then empty.
+	Scoped bool `json:"scoped,omitempty"`
+
+	// SchemaSkipped is set when the table and index levels were skipped because the
+	// schema deadline passed; the tables then only hold their name and definition.
+	SchemaSkipped bool `json:"schema_skipped,omitempty"`
+}
+
+// schemaDeadlineKey is the context key of the schema deadline.
+type schemaDeadlineKey struct{}
+
+// WithSchemaDeadline returns a context telling INFO fetches to skip the table and index
+// levels, the deepest and most expensive INFO queries, of the databases reached after
+// deadline.
+func WithSchemaDeadline(ctx context.Context, deadline time.Time) context.Context {
+	return context.WithValue(ctx, schemaDeadlineKey{}, deadline)
+}
+
+// SchemaDeadline returns the schema deadline of ctx, if any.
+func SchemaDeadline(ctx context.Context) (time.Time, bool) {
+	deadline, ok := ctx.Value(schemaDeadlineKey{}).(time.Time)
+	return deadline, ok
+}
+
+// Hierarchy levels at which an INFO query can fail.
+const (
+	InfoLevelRoot      = "root"
+	InfoLevelNamespace = "namespace"
+	InfoLevelDatabase  = "database"
+	InfoLevelTable     = "table"
+	InfoLevelIndex     = "index"
+)
+
+// InfoTimeouts bounds an INFO fetch: Total the whole fetch, Root the INFO FOR ROOT query,
+// Namespace each namespace with its databases and tables, and Table the tables and
+// indexes of each database.
+type InfoTimeouts struct {
+	Total     time.Duration
+	Root      time.Duration
+	Namespace time.Duration
+	Table     time.Duration
+}
+
+// InfoError records an INFO query that failed while the rest of the hierarchy was
+// still fetched. Namespace and Database are empty above their level.
+type InfoError struct {
+	Level     string `json:"level"`
+	Namespace string `json:"namespace"`
+	Database  string `json:"database"`
+	Err       error  `json:"-"`
+}
+
+// Classes of SurrealDB failures. The readers wrap their errors so that errors.Is matches
+// the class, while the message stays that of the failure.
+var (
+	ErrConnection         = errors.New("unable to connect to SurrealDB")
+	ErrAuth               = errors.New("authentication with SurrealDB failed")
+	ErrTimeout            = errors.New("SurrealDB query timed out")
+	ErrQueryStatus        = errors.New("SurrealDB statement returned an error status")
+	ErrUnsupportedVersion = errors.New("SurrealDB version is not supported")
+)
+
+// Values of the reason label of failure metrics.
+const (
+	ErrorReasonConnection         = "connection"
+	ErrorReasonAuth               = "auth"
+	ErrorReasonTimeout            = "timeout"
+	ErrorReasonQueryStatus        = "query_status"
+	ErrorReasonUnsupportedVersion = "unsupported_version"
+	ErrorReasonOther              = "other"
+)
+
+// ErrorReason classifies err into one of the ErrorReason values. Errors of several classes,
+// like joined errors of a batch, are classified by the first matching class in the order
+// of the constants.
+func ErrorReason(err error) string {
+	switch {
+	case errors.Is(err, ErrConnection):
+		return ErrorReasonConnection
+	case errors.Is(err, ErrAuth):
+		return ErrorReasonAuth
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return ErrorReasonTimeout
+	case errors.Is(err, ErrQueryStatus):
+		return ErrorReasonQueryStatus
+	case errors.Is(err, ErrUnsupportedVersion):
+		return ErrorReasonUnsupportedVersion
+	default:
+		return ErrorReasonOther
+	}
+}
+
+// Retryable reports whether retrying the operation that failed with err may succeed.
+// Rejected credentials and unsupported servers fail the same way until the configuration
+// or the server changes.
+func Retryable(err error) bool {
+	return !errors.Is(err, ErrAuth) && !errors.Is(err, ErrUnsupportedVersion)
+}
+
+// Topology is the detected deployment of a SurrealDB server. Empty fields are unknown.
+type Topology struct {
+	StorageEngine  string
+	DeploymentMode string
+}
+
+// SurrealDB features whose availability depends on the server version.
+const (
+	FeatureSystemInfo      = "system_info"
+	FeatureAPIs            = "apis"
+	FeatureIndexBuilding   = "index_building"
+	FeatureDefineOverwrite = "define_overwrite"
+)
+
+// Supports reports whether the server supports feature. Features are assumed supported
+// when unknown.
+func (i *SurrealDBInfo) Supports(feature string) bool {
+	supported, known := i.Features[feature]
+	return supported || !known
 }
 
 // SystemMetrics contains system-level performance metrics.
 type SystemMetrics struct {
-	AvailableParallelism int
-	CpuUsage             float64
-	LoadAverage          []float64
-	MemoryAllocated      int64
-	MemoryUsage          int64
-	PhysicalCores        int
-	Threads              int
+	AvailableParallelism int       `json:"available_parallelism"`
+	CpuUsage             float64   `json:"cpu_usage"`
+	LoadAverage          []float64 `json:"load_average"`
+	MemoryAllocated      int64     `json:"memory_allocated"`
+	MemoryUsage          int64     `json:"memory_usage"`
+	PhysicalCores        int       `json:"physical_cores"`
+	Threads              int       `json:"threads"`
 }
 
 // NamespaceInfo contains information about a single namespace.
 type NamespaceInfo struct {
-	Name      string
-	Databases map[string]*DatabaseInfo
-	Users     int
-	Accesses  int
+	Name      string                   `json:"name"`
+	Databases map[string]*DatabaseInfo `json:"databases"`
+	Users     int                      `json:"users"`
+	Accesses  int                      `json:"accesses"`
 }
 
 // DatabaseInfo contains information about a single database.
 type DatabaseInfo struct {
-	Name      string
-	Namespace string
-	Tables    map[string]*TableInfo
-	Users     int
-	Accesses  int
-	Analyzers int
-	Apis      int
-	Configs   int
-	Functions int
-	Models    int
-	Params    int
+	Name      string                `json:"name"`
+	Namespace string                `json:"namespace"`
+	Tables    map[string]*TableInfo `json:"tables"`
+	Users     int                   `json:"users"`
+	Accesses  int                   `json:"accesses"`
+	Analyzers int                   `json:"analyzers"`
+	Apis      int                   `json:"apis"`
+	Configs   int                   `json:"configs"`
+	Functions int                   `json:"functions"`
+	Models    int                   `json:"models"`
+	Params    int                   `json:"params"`
+
+	// Names of the defined functions, params and analyzers, only fetched with
+	// detailed_schema enabled.
+	FunctionNames []string `json:"function_names,omitempty"`
+	ParamNames    []string `json:"param_names,omitempty"`
+	AnalyzerNames []string `json:"analyzer_names,omitempty"`
+
+	// APIDefinitions lists the DEFINE API endpoints, only fetched with detailed_schema
+	// enabled on servers supporting them.
+	APIDefinitions []APIInfo `json:"api_definitions,omitempty"`
+}
+
+// APIInfo describes an HTTP API defined in a database.
+type APIInfo struct {
+	Path    string   `json:"path"`
+	Methods []string `json:"methods"`
 }
 
 // TableInfo contains information about a single table.
 type TableInfo struct {
-	Name      string
-	Database  string
-	Namespace string
-	Indexes   map[string]*IndexInfo
-	Events    int
-	Fields    int
-	Lives     int
-	Tables    int
+	Name      string                `json:"name"`
+	Database  string                `json:"database"`
+	Namespace string                `json:"namespace"`
+	Indexes   map[string]*IndexInfo `json:"indexes"`
+	Events    int                   `json:"events"`
+	Fields    int                   `json:"fields"`
+	Lives     int                   `json:"lives"`
+	Tables    int                   `json:"tables"`
+
+	Definition TableDefinition `json:"definition"`
+}
+
+// Table types of a DEFINE TABLE statement.
+const (
+	TableTypeAny      = "any"
+	TableTypeNormal   = "normal"
+	TableTypeRelation = "relation"
+)
+
+// TableDefinition holds the settings of the DEFINE TABLE statement of a table.
+type TableDefinition struct {
+	// Type is one of the TableType constants.
+	Type       string `json:"type"`
+	Schemafull bool   `json:"schemafull"`
+	Drop       bool   `json:"drop"`
+	Changefeed bool   `json:"changefeed"`
+	// ChangefeedRetention is how long changes are kept, 0 when unknown.
+	ChangefeedRetention time.Duration `json:"changefeed_retention,omitempty"`
+	// ChangefeedOriginal is set when changes include the original record.
+	ChangefeedOriginal bool `json:"changefeed_original,omitempty"`
 }
 
 // IndexInfo contains information about a single index.
 type IndexInfo struct {
-	Name      string
-	Table     string
-	Database  string
-	Namespace string
-	Building  IndexBuildingMetrics
+	Name      string               `json:"name"`
+	Table     string               `json:"table"`
+	Database  string               `json:"database"`
+	Namespace string               `json:"namespace"`
+	Building  IndexBuildingMetrics `json:"building"`
+
+	// Vector holds the settings of HNSW and MTREE vector indexes, nil for other indexes.
+	Vector *VectorIndexDefinition `json:"vector,omitempty"`
+}
+
+// Vector index types of a DEFINE INDEX statement.
+const (
+	VectorIndexHNSW  = "hnsw"
+	VectorIndexMTree = "mtree"
+)
+
+// VectorIndexDefinition holds the settings of the DEFINE INDEX statement of a vector
+// index. Settings the statement does not give, or the index type does not have, are zero.
+type VectorIndexDefinition struct {
+	// Type is one of the VectorIndex constants.
+	Type       string `json:"type"`
+	Dimension  int    `json:"dimension"`
+	Distance   string `json:"distance"`
+	VectorType string `json:"vector_type"`
+	// Capacity is the node capacity of an MTREE index.
+	Capacity int `json:"capacity,omitempty"`
+	// EFConstruction is the candidate list size while building an HNSW index, M and M0
+	// the maximum connections of its nodes above and on the bottom layer.
+	EFConstruction int `json:"ef_construction,omitempty"`
+	M              int `json:"m,omitempty"`
+	M0             int `json:"m0,omitempty"`
 }
 
 // IndexBuildingMetrics contains index building status metrics.
 type IndexBuildingMetrics struct {
-	Initial int
-	Pending int
-	Status  string
-	Updated int
+	Initial int    `json:"initial"`
+	Pending int    `json:"pending"`
+	Status  string `json:"status"`
+	Updated int    `json:"updated"`
 }
 
 // TableRecordCount contains table record count metric.
+// Incremental counts are adjusted by observed creates and deletes since CountedAt,
+// the time of the last full count.
 type TableRecordCount struct {
-	Name        string
-	Database    string
-	Namespace   string
-	RecordCount int
+	Name        string    `json:"name"`
+	Database    string    `json:"database"`
+	Namespace   string    `json:"namespace"`
+	RecordCount int       `json:"record_count"`
+	CountedAt   time.Time `json:"counted_at"`
+	Incremental bool      `json:"incremental,omitempty"`
+
+	// Partitions holds the counts of the record ID ranges of a partitioned count. When
+	// PartitionsFailed is not zero, RecordCount only sums the partitions that succeeded.
+	Partitions       []PartitionRecordCount `json:"partitions,omitempty"`
+	PartitionsFailed int                    `json:"partitions_failed,omitempty"`
+}
+
+// RelationEdgeCount is the number of edges in a relation table between records of two
+// tables.
+type RelationEdgeCount struct {
+	Namespace string `json:"namespace"`
+	Database  string `json:"database"`
+	Relation  string `json:"relation"`
+	FromTable string `json:"from_table"`
+	ToTable   string `json:"to_table"`
+	Count     int    `json:"count"`
+}
+
+// IndexSampleQuery is a representative SELECT statement run in Namespace/Database whose
+// EXPLAIN plan shows the indexes the workload uses. Name identifies it in the metrics.
+type IndexSampleQuery struct {
+	Name      string
+	Namespace string
+	Database  string
+	Query     string
+}
+
+// IndexSamplePlan is the EXPLAIN plan of a sample query: the indexes it iterates and the
+// tables it scans without an index. Err is set when the query could not be explained.
+type IndexSamplePlan struct {
+	Query         IndexSampleQuery
+	Indexes       []TableIndex
+	ScannedTables []string
+	Err           error
+}
+
+// TableIndex names an index of a table.
+type TableIndex struct {
+	Table string
+	Index string
+}
+
+// PartitionRecordCount is the number of records in one record ID range of a table.
+type PartitionRecordCount struct {
+	Range       string `json:"range"`
+	RecordCount int    `json:"record_count"`
+}
+
+// RecordCountPartitioning splits the record count of tables matching Pattern
+// (namespace:database:table, wildcards allowed) into range queries on the record ID.
+// Boundaries are ascending int64 or string record ID values; N boundaries give N+1
+// ranges, counted by at most Concurrency queries at a time.
+type RecordCountPartitioning struct {
+	Pattern     string
+	Boundaries  []any
+	Concurrency int
+}
+
+// Levels of SurrealDB system users.
+const (
+	AuthLevelRoot      = "root"
+	AuthLevelNamespace = "namespace"
+	AuthLevelDatabase  = "database"
+)
+
+// CredentialOverride replaces the root credentials for connections to databases matching
+// Pattern (namespace:database, wildcards allowed). Level tells whether the user is
+// defined on the namespace or on the database.
+type CredentialOverride struct {
+	Pattern  string
+	Username string
+	Password string
+	Level    string
+}
+
+// ScopeEntry is a namespace, or a single database of it when Database is set, monitored
+// by an exporter without root access instead of the whole server.
+type ScopeEntry struct {
+	Namespace string
+	Database  string
+}
+
+// ConnectionSettings holds the keep-alive settings of WebSocket connections to SurrealDB.
+// Zero PingInterval and ReadTimeout disable pings and the pong timeout. Prewarm opens the
+// connections of all databases at startup, IdleTimeout closes those unused for that long.
+type ConnectionSettings struct {
+	PingInterval  time.Duration
+	ReadTimeout   time.Duration
+	WriteTimeout  time.Duration
+	AutoReconnect bool
+	Prewarm       bool
+	IdleTimeout   time.Duration
+}
+
+// QueryRateLimit caps the queries sent to SurrealDB. A QueriesPerSecond of 0 disables the
+// limit.
+type QueryRateLimit struct {
+	QueriesPerSecond float64
+	Burst            int
+}
+
+// QueryThrottleStats counts the queries delayed by the query rate limit and the time they
+// waited since startup.
+type QueryThrottleStats struct {
+	Throttled uint64
+	Wait      time.Duration
+}
+
+// ConnectionStats counts the SurrealDB connections of a connection manager. Open is the
+// number of connections currently established; the others are totals since startup.
+type ConnectionStats struct {
+	Open         int
+	Opened       uint64
+	ClosedIdle   uint64
+	ClosedBroken uint64
+}
+
+// ConnectionStatus describes a cached SurrealDB connection of a connection manager. The
+// root connection has an empty namespace and database.
+type ConnectionStatus struct {
+	Namespace   string  `json:"namespace,omitempty"`
+	Database    string  `json:"database,omitempty"`
+	Generation  uint64  `json:"generation"`
+	Closed      bool    `json:"closed"`
+	IdleSeconds float64 `json:"idle_seconds"`
+}
+
+// LiveQueryStatus describes the live query of a table. Reconnects counts how often the
+// live query was recreated since startup, after errors or re-established connections.
+type LiveQueryStatus struct {
+	Namespace  string `json:"namespace"`
+	Database   string `json:"database"`
+	Table      string `json:"table"`
+	LiveID     string `json:"live_id,omitempty"`
+	Reconnects int    `json:"reconnects"`
+	Backlog    int    `json:"backlog"`
+}
+
+// ManagedStatsTable is a stats table maintained for a monitored table.
+type ManagedStatsTable struct {
+	Namespace  string `json:"namespace"`
+	Database   string `json:"database"`
+	Table      string `json:"table"`
+	StatsTable string `json:"stats_table"`
+}
+
+// ScrapeStatus describes a gather of the exported metrics.
+type ScrapeStatus struct {
+	StartedAt       time.Time `json:"started_at"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Series          int       `json:"series"`
+	SizeBytes       int       `json:"size_bytes"`
+	Error           string    `json:"error,omitempty"`
+}
+
+// ExternalCollector is a command run on every scrape whose standard output, in the
+// Prometheus text exposition format, is merged into the exported metrics.
+type ExternalCollector struct {
+	Name    string
+	Command []string
+	Timeout time.Duration
+}
+
+// DerivedMetrics holds the thresholds of the derived alert metrics.
+type DerivedMetrics struct {
+	// LargeTableRecords is the record count above which a table without indexes is
+	// reported.
+	LargeTableRecords int
+	// IndexBuildingStuckAfter is how long an index may be seen building before it is
+	// reported as stuck.
+	IndexBuildingStuckAfter time.Duration
+}
+
+// MetricsProxy is a Prometheus endpoint of SurrealDB itself scraped on every scrape, its
+// metric names prefixed with Prefix, and merged into the exported metrics.
+type MetricsProxy struct {
+	URL     string
+	Prefix  string
+	Timeout time.Duration
+}
+
+// RecordCountIntervalOverride sets the background count refresh interval for tables
+// matching Pattern (namespace:database:table, wildcards allowed).
+type RecordCountIntervalOverride struct {
+	Pattern  string
+	Interval time.Duration
 }
 
 // RecordCountMetrics contains the complete set of record count metrics.
 type RecordCountMetrics struct {
-	Tables         []*TableRecordCount
-	ScrapeDuration time.Duration
+	Tables         []*TableRecordCount `json:"tables"`
+	ScrapeDuration time.Duration       `json:"-"`
 }
 
 // TotalNamespaces returns the total number of namespaces.
@@ -371,12 +749,25 @@ func (db *DatabaseInfo) FullPath() string {
 	return fmt.Sprintf("%s.%s", db.Namespace, db.Name)
 }
 
-// MemoryUsagePercent returns memory usage as a percentage of allocated memory.
-func (m *SystemMetrics) MemoryUsagePercent() float64 { // TODO check this
+// MemoryUsageRatioOfAllocated returns memory usage as a ratio of the memory reserved by the
+// allocator, which overstates the pressure on the host when the allocator over-reserves.
+// SurrealDB does not report the host memory, so there is no ratio of host memory.
+func (m *SystemMetrics) MemoryUsageRatioOfAllocated() float64 {
 	if m.MemoryAllocated == 0 {
 		return 0
 	}
-	return (float64(m.MemoryUsage) / float64(m.MemoryAllocated)) * 100
+	return float64(m.MemoryUsage) / float64(m.MemoryAllocated)
+}
+
+// LoadAverageSupported reports whether the server platform provides load averages. Windows
+// and some container runtimes report none, or all of them as zero.
+func (m *SystemMetrics) LoadAverageSupported() bool {
+	for _, load := range m.LoadAverage {
+		if load != 0 {
+			return true
+		}
+	}
+	return false
 }
 
 // Namespace retrieves a specific namespace by name.
@@ -441,6 +832,32 @@ const (
 	OperationTypeUnknown    OperationType = "unknown"
 )
 
+// ClassifiableOperationTypes lists the operation types a record can be classified as.
+var ClassifiableOperationTypes = []OperationType{
+	OperationTypeGraph,
+	OperationTypeRelational,
+	OperationTypeKeyValue,
+	OperationTypeDocument,
+}
+
+// ClassificationRule classifies a record as Type when all of its set conditions hold.
+// Field counts exclude the record id. A rule without conditions always matches.
+type ClassificationRule struct {
+	Type             OperationType
+	HasFields        []string
+	MinFields        *int
+	MaxFields        *int
+	MinScalarFields  *int
+	MaxComplexFields *int
+}
+
+// ClassificationOverride forces the operation type for tables matching Pattern
+// (namespace:database:table, wildcards allowed).
+type ClassificationOverride struct {
+	Pattern string
+	Type    OperationType
+}
+
 // OperationAction represents the type of database operation.
 type OperationAction string
 
@@ -453,13 +870,70 @@ const (
 
 // TableOperationMetrics contains operation counts for a specific table and type.
 type TableOperationMetrics struct {
-	Namespace     string
-	Database      string
-	Table         string
-	OperationType OperationType
-	Creates       int64
-	Updates       int64
-	Deletes       int64
+	Namespace     string        `json:"namespace"`
+	Database      string        `json:"database"`
+	Table         string        `json:"table"`
+	OperationType OperationType `json:"operation_type"`
+	Creates       int64         `json:"creates"`
+	Updates       int64         `json:"updates"`
+	Deletes       int64         `json:"deletes"`
+}
+
+// LiveNotificationResult describes how a live query notification was handled.
+type LiveNotificationResult string
+
+const (
+	NotificationProcessed     LiveNotificationResult = "processed"
+	NotificationUnknownAction LiveNotificationResult = "unknown_action"
+	NotificationNilResult     LiveNotificationResult = "nil_result"
+	NotificationDecodeError   LiveNotificationResult = "decode_error"
+	NotificationSampledOut    LiveNotificationResult = "sampled_out"
+)
+
+// LiveQuerySampling limits the live notifications of tables matching Pattern
+// (namespace:database:table, wildcards allowed) that are decoded and classified to the
+// fraction Rate. Every operation is still counted; notifications that are not decoded
+// count under the operation type last classified for their action.
+type LiveQuerySampling struct {
+	Pattern string
+	Rate    float64
+}
+
+// TableNotificationCount contains the number of live notifications of a table handled with Result.
+type TableNotificationCount struct {
+	Namespace string                 `json:"namespace"`
+	Database  string                 `json:"database"`
+	Table     string                 `json:"table"`
+	Result    LiveNotificationResult `json:"result"`
+	Count     int64                  `json:"count"`
+}
+
+// TableNotificationBacklog holds the live notifications of a table received but not
+// handled yet.
+type TableNotificationBacklog struct {
+	Namespace string `json:"namespace"`
+	Database  string `json:"database"`
+	Table     string `json:"table"`
+	Backlog   int    `json:"backlog"`
+}
+
+// Actions of a stats table reconciliation.
+const (
+	StatsTableActionCreate  = "create"
+	StatsTableActionMigrate = "migrate"
+	StatsTableActionRemove  = "remove"
+)
+
+// StatsTableAction is a stats table change planned by a dry run reconciliation with the
+// SurrealQL statements that would execute it.
+type StatsTableAction struct {
+	Action     string    `json:"action"`
+	Namespace  string    `json:"namespace"`
+	Database   string    `json:"database"`
+	Table      string    `json:"table"`
+	StatsTable string    `json:"stats_table"`
+	Statements []string  `json:"statements"`
+	PlannedAt  time.Time `json:"planned_at"`
 }
 
 // LiveQueryMetrics contains all accumulated metrics.
@@ -475,22 +949,23 @@ func (t *TableOperationMetrics) Key() string {
 
 // StatsTableData contains operation counts from a side stats table for a specific table.
 type StatsTableData struct {
-	Namespace        string
-	Database         string
-	Table            string
-	CreateRelational int64
-	CreateKV         int64
-	CreateGraph      int64
-	CreateDocument   int64
-	UpdateRelational int64
-	UpdateKV         int64
-	UpdateGraph      int64
-	UpdateDocument   int64
-	DeleteRelational int64
-	DeleteKV         int64
-	DeleteGraph      int64
-	DeleteDocument   int64
-	LastUpdate       time.Time
+	Namespace        string    `json:"namespace"`
+	Database         string    `json:"database"`
+	Table            string    `json:"table"`
+	CreateRelational int64     `json:"create_relational"`
+	CreateKV         int64     `json:"create_kv"`
+	CreateGraph      int64     `json:"create_graph"`
+	CreateDocument   int64     `json:"create_document"`
+	UpdateRelational int64     `json:"update_relational"`
+	UpdateKV         int64     `json:"update_kv"`
+	UpdateGraph      int64     `json:"update_graph"`
+	UpdateDocument   int64     `json:"update_document"`
+	DeleteRelational int64     `json:"delete_relational"`
+	DeleteKV         int64     `json:"delete_kv"`
+	DeleteGraph      int64     `json:"delete_graph"`
+	DeleteDocument   int64     `json:"delete_document"`
+	LastUpdate       time.Time `json:"last_update"`
+	CreatedAt        time.Time `json:"created_at"`
 }
 
 // OTel related structures
@@ -532,14 +1007,32 @@ type Metric struct {
 	Description   string
 	Unit          string
 	HistogramData *HistogramData
+	// Resource holds the attributes of the OTLP resource the metric was received from.
+	Resource map[string]string
 }
 
 // HistogramData contains histogram-specific data with cumulative bucket counts.
+// Exponential is set for histograms received as OTLP exponential histograms; Buckets
+// then holds the equivalent classic buckets.
 type HistogramData struct {
 	Count       uint64
 	Sum         float64
 	Buckets     []HistogramBucket
 	CreatedTime time.Time
+	Exponential *ExponentialHistogramData
+}
+
+// ExponentialHistogramData contains the buckets of an OTLP exponential histogram.
+// Bucket index i of scale s covers (2^(i*2^-s), 2^((i+1)*2^-s)]; the counts of the
+// positive and negative ranges start at their offsets.
+type ExponentialHistogramData struct {
+	Scale          int32
+	ZeroCount      uint64
+	ZeroThreshold  float64
+	PositiveOffset int32
+	PositiveCounts []uint64
+	NegativeOffset int32
+	NegativeCounts []uint64
 }
 
 // HistogramBucket represents a single histogram bucket with cumulative count.
@@ -548,6 +1041,154 @@ type HistogramBucket struct {
 	Count      uint64
 }
 
+// Labels derived from OTLP resource attributes.
+const (
+	ResourceLabelCluster        = "cluster"
+	ResourceLabelStorageEngine  = "storage_engine"
+	ResourceLabelDeploymentMode = "deployment_mode"
+	ResourceLabelNode           = "node"
+)
+
+// HistogramBucketLayout re-aggregates OTLP histograms whose name matches Pattern onto
+// Buckets, given in exported units and sorted ascending.
+type HistogramBucketLayout struct {
+	Pattern string
+	Buckets []float64
+}
+
+// Pipeline stage types of the OTLP processing pipeline.
+const (
+	PipelineStageFilter    = "filter"
+	PipelineStageRelabel   = "relabel"
+	PipelineStageRateLimit = "rate_limit"
+	PipelineStageBatch     = "batch"
+)
+
+// Ways of exporting the 1m, 5m and 15m system load averages: one series per period, or a
+// single series holding their maximum or mean.
+const (
+	LoadAveragePeriods = "periods"
+	LoadAverageMax     = "max"
+	LoadAverageAvg     = "avg"
+)
+
+// Ways of sharing the tables found by the info collector with the table collectors: kept
+// until the next info scrape replaces them, forgotten after a TTL, or read with INFO
+// whenever a table collector needs them.
+const (
+	TableListShared   = "shared"
+	TableListTTL      = "ttl"
+	TableListUncached = "uncached"
+)
+
+// Handling of metrics requests arriving while a previous collection is still running.
+const (
+	OverlappingScrapesAllow     = "allow"
+	OverlappingScrapesReject    = "reject"
+	OverlappingScrapesServeLast = "serve_last"
+)
+
+// Overflow behaviors of the OTLP batch queue.
+const (
+	QueueOverflowReject     = "reject"
+	QueueOverflowDropOldest = "drop_oldest"
+)
+
+// LabelSanitization configures how label values such as namespace and table names are
+// made safe for Prometheus. Values longer than MaxLength are truncated, with a hash of
+// the original appended when HashSuffix is set.
+type LabelSanitization struct {
+	Enabled    bool
+	Labels     []string
+	MaxLength  int
+	HashSuffix bool
+}
+
+// MetricRenames maps the names of exported metrics and labels to the names dashboards of
+// earlier exporter versions expect. With KeepOriginal, the renamed metrics are exported
+// in addition to the original ones instead of replacing them.
+type MetricRenames struct {
+	Metrics      map[string]string
+	Labels       map[string]string
+	KeepOriginal bool
+}
+
+// Tenant is allowed to scrape the series whose namespace label matches one of
+// Namespaces (path.Match patterns) by presenting Token as a bearer token.
+type Tenant struct {
+	Name       string
+	Token      string
+	Namespaces []string
+}
+
+// QueueLimits bounds the metrics buffered by the OTLP batch processor. Zero limits are
+// unlimited.
+type QueueLimits struct {
+	MaxMetrics int
+	MaxBytes   int
+	Overflow   string
+}
+
+// PipelineStage configures one stage of the OTLP processing pipeline. Only the fields of
+// its Type are used.
+type PipelineStage struct {
+	Type             string
+	Include          []string
+	Exclude          []string
+	RelabelRules     []RelabelRule
+	MetricsPerSecond float64
+	Burst            int
+}
+
+// Relabel rule actions.
+const (
+	RelabelActionSet     = "set"
+	RelabelActionDrop    = "drop"
+	RelabelActionRename  = "rename"
+	RelabelActionReplace = "replace"
+)
+
+// RelabelRule rewrites the labels of OTLP metrics. set writes Replacement to TargetLabel,
+// drop removes SourceLabel, rename moves SourceLabel to TargetLabel and replace writes
+// Replacement, expanded with the groups of Regex, to TargetLabel when SourceLabel matches.
+type RelabelRule struct {
+	Action      string
+	SourceLabel string
+	TargetLabel string
+	Regex       *regexp.Regexp
+	Replacement string
+}
+
+// LogRecord is a log record received via OTLP, reduced to the parts metrics are derived from.
+type LogRecord struct {
+	Level      string
+	Target     string
+	Body       string
+	Attributes map[string]string
+}
+
+// SpanRecord is a span received via OTLP. Kind is the lowercase span kind, e.g. server
+// or client, and Error is set when the span status is an error.
+type SpanRecord struct {
+	Name       string
+	Kind       string
+	Error      bool
+	Duration   time.Duration
+	Attributes map[string]string
+}
+
+// GRPCKeepalive holds gRPC server keepalive parameters and the enforcement policy for
+// client pings. Zero durations keep the gRPC defaults.
+type GRPCKeepalive struct {
+	Time                  time.Duration
+	Timeout               time.Duration
+	MaxConnectionIdle     time.Duration
+	MaxConnectionAge      time.Duration
+	MaxConnectionAgeGrace time.Duration
+	MinTime               time.Duration
+	PermitWithoutStream   bool
+}
+
 // MetricBatch represents a collection of metrics received together.
 type MetricBatch struct {
 	Metrics       []Metric
@@ -0,0 +1,161 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// operationClassifier determines the data model type of a record from a shared rule set.
+// The same rules are evaluated in Go for live query notifications and rendered as
+// SurrealQL for stats table events, so both collectors classify records identically.
+type operationClassifier struct {
+	rules       []domain.ClassificationRule
+	overrides   []domain.ClassificationOverride
+	defaultType domain.OperationType
+}
+
+// NewOperationClassifier creates a new operation classifier.
+// Rules are evaluated in order; the first matching rule wins.
+func NewOperationClassifier(
+	rules []domain.ClassificationRule,
+	overrides []domain.ClassificationOverride,
+	defaultType domain.OperationType,
+) *operationClassifier {
+	return &operationClassifier{
+		rules:       rules,
+		overrides:   overrides,
+		defaultType: defaultType,
+	}
+}
+
+// Classify returns the operation type for a record of the given table.
+func (c *operationClassifier) Classify(tableID domain.TableIdentifier, record map[string]any) domain.OperationType {
+	if opType, ok := c.override(tableID); ok {
+		return opType
+	}
+
+	if record == nil {
+		return domain.OperationTypeUnknown
+	}
+
+	for _, rule := range c.rules {
+		if ruleMatches(rule, record) {
+			return rule.Type
+		}
+	}
+
+	return c.defaultType
+}
+
+// SurrealQL renders the rule set as a SurrealQL expression evaluating to the
+// operation type of the record held in recordVar (e.g. "$after").
+func (c *operationClassifier) SurrealQL(tableID domain.TableIdentifier, recordVar string) string {
+	if opType, ok := c.override(tableID); ok {
+		return fmt.Sprintf("%q", string(opType))
+	}
+
+	var sb strings.Builder
+	for i, rule := range c.rules {
+		if i > 0 {
+			sb.WriteString(" ELSE ")
+		}
+		fmt.Fprintf(&sb, "IF %s THEN %q", ruleSurrealQL(rule, recordVar), string(rule.Type))
+	}
+
+	if len(c.rules) == 0 {
+		return fmt.Sprintf("%q", string(c.defaultType))
+	}
+
+	fmt.Fprintf(&sb, " ELSE %q END", string(c.defaultType))
+
+	return sb.String()
+}
+
+// override returns the forced operation type for a table, if any.
+func (c *operationClassifier) override(tableID domain.TableIdentifier) (domain.OperationType, bool) {
+	identifier := tableID.String()
+	for _, o := range c.overrides {
+		if matchesPattern(identifier, o.Pattern) {
+			return o.Type, true
+		}
+	}
+
+	return "", false
+}
+
+// ruleMatches evaluates a rule against a record.
+func ruleMatches(rule domain.ClassificationRule, record map[string]any) bool {
+	for _, field := range rule.HasFields {
+		if value, ok := record[field]; !ok || value == nil {
+			return false
+		}
+	}
+
+	fields, complexFields := 0, 0
+	for key, value := range record {
+		if key == "id" {
+			continue
+		}
+
+		fields++
+
+		switch value.(type) {
+		case map[string]any, []any:
+			complexFields++
+		}
+	}
+
+	scalarFields := fields - complexFields
+
+	return atLeast(fields, rule.MinFields) &&
+		atMost(fields, rule.MaxFields) &&
+		atLeast(scalarFields, rule.MinScalarFields) &&
+		atMost(complexFields, rule.MaxComplexFields)
+}
+
+// ruleSurrealQL renders a rule condition as a SurrealQL boolean expression.
+func ruleSurrealQL(rule domain.ClassificationRule, recordVar string) string {
+	fieldCount := fmt.Sprintf("(array::len(object::keys(%s)) - 1)", recordVar)
+	complexCount := fmt.Sprintf(
+		"array::len(array::filter(object::values(%s), |$v| type::is::object($v) OR type::is::array($v)))",
+		recordVar,
+	)
+	scalarCount := fmt.Sprintf("(%s - %s)", fieldCount, complexCount)
+
+	var conditions []string
+	for _, field := range rule.HasFields {
+		conditions = append(conditions, fmt.Sprintf("%s.%s != NONE", recordVar, field))
+	}
+
+	if rule.MinFields != nil {
+		conditions = append(conditions, fmt.Sprintf("%s >= %d", fieldCount, *rule.MinFields))
+	}
+
+	if rule.MaxFields != nil {
+		conditions = append(conditions, fmt.Sprintf("%s <= %d", fieldCount, *rule.MaxFields))
+	}
+
+	if rule.MinScalarFields != nil {
+		conditions = append(conditions, fmt.Sprintf("%s >= %d", scalarCount, *rule.MinScalarFields))
+	}
+
+	if rule.MaxComplexFields != nil {
+		conditions = append(conditions, fmt.Sprintf("%s <= %d", complexCount, *rule.MaxComplexFields))
+	}
+
+	if len(conditions) == 0 {
+		return "true"
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+func atLeast(value int, bound *int) bool {
+	return bound == nil || value >= *bound
+}
+
+func atMost(value int, bound *int) bool {
+	return bound == nil || value <= *bound
+}
@@ -0,0 +1,277 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+type RecordCountReader interface {
+	RecordCount(ctx context.Context, tables []*domain.TableInfo) (*domain.RecordCountMetrics, error)
+}
+
+// OperationTotalsProvider provides create/update/delete counts observed since startup.
+type OperationTotalsProvider interface {
+	LiveQueryTotals() []*domain.TableOperationMetrics
+}
+
+// operationBaseline holds the observed creates and deletes of a table at its last full count.
+type operationBaseline struct {
+	creates int64
+	deletes int64
+}
+
+// RecordCountRefresher counts records on a background schedule instead of on every scrape.
+// RecordCount returns cached counts, so scrapes never wait for count queries except for
+// tables seen for the first time. Tables are grouped by refresh interval and each group
+// is refreshed by its own loop, so slow counts of large tables don't delay small ones.
+// With an operations provider, counts are kept approximately fresh between full counts
+// by applying observed creates and deletes.
+type RecordCountRefresher struct {
+	reader     RecordCountReader
+	operations OperationTotalsProvider
+	interval   time.Duration
+	overrides  []domain.RecordCountIntervalOverride
+	timeout    time.Duration
+	paused     func() bool
+	logger     *slog.Logger
+
+	mu           sync.Mutex
+	tables       map[string]*domain.TableInfo
+	counts       map[string]*domain.TableRecordCount
+	baselines    map[string]operationBaseline
+	lastDuration time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewRecordCountRefresher creates a refresher counting records every interval, or at the
+// interval of the first override whose pattern matches the table. Each refresh is bounded
+// by timeout. A non-nil operations provider makes counts incremental between refreshes.
+// Refreshes are skipped while a non-nil paused returns true.
+func NewRecordCountRefresher(
+	reader RecordCountReader,
+	operations OperationTotalsProvider,
+	interval time.Duration,
+	overrides []domain.RecordCountIntervalOverride,
+	timeout time.Duration,
+	paused func() bool,
+	logger *slog.Logger,
+) *RecordCountRefresher {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &RecordCountRefresher{
+		reader:     reader,
+		operations: operations,
+		baselines:  make(map[string]operationBaseline),
+		interval:   interval,
+		overrides:  overrides,
+		timeout:    timeout,
+		paused:     paused,
+		logger:     logger,
+		tables:     make(map[string]*domain.TableInfo),
+		counts:     make(map[string]*domain.TableRecordCount),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start launches one background refresh loop per distinct refresh interval.
+func (r *RecordCountRefresher) Start() {
+	intervals := map[time.Duration]struct{}{r.interval: {}}
+	for _, override := range r.overrides {
+		intervals[override.Interval] = struct{}{}
+	}
+
+	for interval := range intervals {
+		r.wg.Add(1)
+
+		go func() {
+			defer r.wg.Done()
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-r.ctx.Done():
+					return
+				case <-ticker.C:
+					if r.paused != nil && r.paused() {
+						continue
+					}
+					r.refresh(r.tracked(interval))
+				}
+			}
+		}()
+	}
+}
+
+// Stop stops the background refresh loops.
+func (r *RecordCountRefresher) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// RecordCount registers tables for background refresh and returns their cached counts.
+// Tables without a cached count are counted synchronously.
+func (r *RecordCountRefresher) RecordCount(
+	_ context.Context,
+	tables []*domain.TableInfo,
+) (*domain.RecordCountMetrics, error) {
+	var missing []*domain.TableInfo
+
+	r.mu.Lock()
+	r.tables = make(map[string]*domain.TableInfo, len(tables))
+	for _, table := range tables {
+		key := tableKey(table)
+		r.tables[key] = table
+
+		if _, ok := r.counts[key]; !ok {
+			missing = append(missing, table)
+		}
+	}
+	r.mu.Unlock()
+
+	if len(missing) > 0 {
+		r.refresh(missing)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := &domain.RecordCountMetrics{
+		Tables:         make([]*domain.TableRecordCount, 0, len(tables)),
+		ScrapeDuration: r.lastDuration,
+	}
+
+	observed := r.observedOperations()
+
+	for _, table := range tables {
+		key := tableKey(table)
+
+		count, ok := r.counts[key]
+		if !ok {
+			continue
+		}
+
+		if observed != nil {
+			count = r.incremental(count, observed[key], r.baselines[key])
+		}
+
+		result.Tables = append(result.Tables, count)
+	}
+
+	return result, nil
+}
+
+// observedOperations returns creates and deletes observed since startup by table, or nil
+// when counting is not incremental.
+func (r *RecordCountRefresher) observedOperations() map[string]operationBaseline {
+	if r.operations == nil {
+		return nil
+	}
+
+	observed := make(map[string]operationBaseline)
+	for _, m := range r.operations.LiveQueryTotals() {
+		key := domain.TableIdentifier{Namespace: m.Namespace, Database: m.Database, Table: m.Table}.String()
+
+		ops := observed[key]
+		ops.creates += m.Creates
+		ops.deletes += m.Deletes
+		observed[key] = ops
+	}
+
+	return observed
+}
+
+// incremental returns count adjusted by the creates and deletes observed since its baseline.
+func (r *RecordCountRefresher) incremental(
+	count *domain.TableRecordCount,
+	observed, baseline operationBaseline,
+) *domain.TableRecordCount {
+	delta := (observed.creates - baseline.creates) - (observed.deletes - baseline.deletes)
+
+	adjusted := *count
+	adjusted.RecordCount = max(count.RecordCount+int(delta), 0)
+	adjusted.Incremental = true
+
+	return &adjusted
+}
+
+// tracked returns the tables requested by the latest collection that refresh at interval.
+func (r *RecordCountRefresher) tracked(interval time.Duration) []*domain.TableInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var tables []*domain.TableInfo
+	for key, table := range r.tables {
+		if r.intervalFor(key) == interval {
+			tables = append(tables, table)
+		}
+	}
+
+	return tables
+}
+
+// intervalFor returns the refresh interval of the table identified by key.
+func (r *RecordCountRefresher) intervalFor(key string) time.Duration {
+	for _, override := range r.overrides {
+		if matchesPattern(key, override.Pattern) {
+			return override.Interval
+		}
+	}
+
+	return r.interval
+}
+
+// refresh counts records of the given tables and updates the cache. Counts of tables
+// that fail keep their previous value; tables no longer tracked are dropped. Operations
+// observed when the count starts become the baseline of incremental counts.
+func (r *RecordCountRefresher) refresh(tables []*domain.TableInfo) {
+	if len(tables) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.timeout)
+	defer cancel()
+
+	observed := r.observedOperations()
+
+	metrics, err := r.reader.RecordCount(ctx, tables)
+	if err != nil {
+		r.logger.Warn("Failed to refresh some record counts", "error", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if metrics != nil {
+		for _, count := range metrics.Tables {
+			key := tableCountKey(count)
+			r.counts[key] = count
+			r.baselines[key] = observed[key]
+		}
+		r.lastDuration = metrics.ScrapeDuration
+	}
+
+	for key := range r.counts {
+		if _, ok := r.tables[key]; !ok {
+			delete(r.counts, key)
+			delete(r.baselines, key)
+		}
+	}
+}
+
+func tableKey(table *domain.TableInfo) string {
+	return domain.TableIdentifier{Namespace: table.Namespace, Database: table.Database, Table: table.Name}.String()
+}
+
+func tableCountKey(count *domain.TableRecordCount) string {
+	return domain.TableIdentifier{Namespace: count.Namespace, Database: count.Database, Table: count.Name}.String()
+}
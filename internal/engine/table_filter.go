@@ -2,6 +2,7 @@ package engine
 
 import (
 	"path/filepath"
+	"strings"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 )
@@ -67,6 +68,42 @@ func (f *tableFilter) FilterTables(tables []*domain.TableInfo) []domain.TableIde
 	return filtered
 }
 
+// Priority returns the index of the first include pattern matching tableID, so tables
+// matching earlier patterns can be preferred. Without include patterns it is 0.
+func (f *tableFilter) Priority(tableID domain.TableIdentifier) int {
+	identifier := tableID.String()
+
+	for i, pattern := range f.includePatterns {
+		if matchesPattern(identifier, pattern) {
+			return i
+		}
+	}
+
+	return len(f.includePatterns)
+}
+
+// MayMonitorDatabase reports whether tables of namespace:database may be monitored, i.e.
+// some include pattern matches the database. Without include patterns all may be.
+func (f *tableFilter) MayMonitorDatabase(namespace, database string) bool {
+	if !f.hasIncludes {
+		return true
+	}
+
+	identifier := namespace + ":" + database
+	for _, pattern := range f.includePatterns {
+		databasePattern := pattern
+		if i := strings.LastIndex(pattern, ":"); i >= 0 && strings.Count(pattern, ":") == 2 {
+			databasePattern = pattern[:i]
+		}
+
+		if matchesPattern(identifier, databasePattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // matchesPattern checks if identifier matches glob pattern.
 func matchesPattern(identifier, pattern string) bool {
 	matched, err := filepath.Match(pattern, identifier)
@@ -0,0 +1,415 @@
+// Package exporter builds the SurrealDB connections, readers, background refreshers and
+// collector registries of an exporter, shared by the exporter binary and the embeddable
+// pkg/surrealexporter, so both run the same collectors the same way.
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/api"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/engine"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/logger"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/registry"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealdb"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Config holds the settings the collectors and their readers are built from.
+type Config interface {
+	surrealdb.Config
+	registry.Config
+	surrealcollectors.FactoryConfig
+
+	ApplyDetectedTopology(topology domain.Topology) []error
+	SurrealQueryRateLimit() domain.QueryRateLimit
+	AdminEnabled() bool
+	InfoTableList() string
+	InfoTableListTTL() time.Duration
+
+	OperationClassificationRules() []domain.ClassificationRule
+	OperationClassificationOverrides() []domain.ClassificationOverride
+	OperationClassificationDefault() domain.OperationType
+
+	LiveQueryEnabled() bool
+	LiveQueryDedicatedConnections() bool
+	LiveQueryIncludePatterns() []string
+	LiveQueryExcludePatterns() []string
+	LiveQueryReconnectDelay() time.Duration
+	LiveQueryMaxReconnectAttempts() int
+	LiveQuerySchemaPollInterval() time.Duration
+	LiveQuerySampling() []domain.LiveQuerySampling
+	LiveQueryMaxTables() int
+
+	RecordCountCollectorEnabled() bool
+	RecordCountPartitions() []domain.RecordCountPartitioning
+	RecordCountInterval() time.Duration
+	RecordCountIncremental() bool
+	RecordCountReconcileInterval() time.Duration
+	RecordCountIntervalOverrides() []domain.RecordCountIntervalOverride
+	RecordCountIncludePatterns() []string
+	RecordCountExcludePatterns() []string
+
+	StatsTableEnabled() bool
+	StatsTableIncludePatterns() []string
+	StatsTableExcludePatterns() []string
+	StatsTableRemoveOrphanTables() bool
+	StatsTableDeltaRecords() bool
+	StatsTableDryRun() bool
+}
+
+// connectionManager is a SurrealDB connection manager whose idle connections can be
+// reaped and which closes its connections on shutdown.
+type connectionManager interface {
+	surrealdb.ConnectionManager
+	surrealdb.ConnectionStatsProvider
+	surrealdb.ConnectionStatusProvider
+	StartReaper(ctx context.Context, idleTimeout time.Duration)
+	Close()
+}
+
+// infoReader reads the INFO hierarchy of the server.
+type infoReader interface {
+	surrealcollectors.InfoMetricsReader
+	NamespaceExists(ctx context.Context, namespace string) (bool, error)
+}
+
+// Exporter holds the collectors of an exporter and the subsystems they read from.
+type Exporter struct {
+	// Metrics gathers the SurrealDB collectors, Telemetry the collectors describing the
+	// exporter itself.
+	Metrics   prometheus.Gatherer
+	Telemetry prometheus.Gatherer
+	// MetricNames are the names of the metrics the collectors export.
+	MetricNames registry.MetricNames
+
+	// Pauses lets collectors be paused at runtime, nil without the admin endpoints.
+	Pauses     *surrealcollectors.CollectorPauses
+	LiveQuery  *surrealdb.LiveQueryManager
+	StatsTable *surrealdb.StatsTableManager
+
+	cfg  Config
+	deps surrealcollectors.Dependencies
+
+	connections          connectionManager
+	liveQueryConnections connectionManager
+	infoReader           infoReader
+	forNamespace         func(namespace string) surrealcollectors.InfoMetricsReader
+
+	recordCountRefresher *engine.RecordCountRefresher
+	reaperCancel         context.CancelFunc
+}
+
+// New connects to SurrealDB, detects its topology, starts the background refreshes and
+// live queries of the enabled collectors and builds the collector registries. Close must
+// be called to stop the background work.
+func New(cfg Config) (*Exporter, error) {
+	e := &Exporter{cfg: cfg, reaperCancel: func() {}}
+
+	queryLimiter := surrealdb.NewQueryLimiter(cfg.SurrealQueryRateLimit())
+
+	connections := surrealdb.NewMultiConnectionManager(cfg, queryLimiter)
+	e.connections = connections
+	e.liveQueryConnections = connections
+
+	detectCtx, detectCancel := context.WithTimeout(context.Background(), cfg.SurrealTimeout())
+	topology, err := surrealdb.DetectTopology(detectCtx, connections, cfg)
+	detectCancel()
+	if err != nil {
+		slog.Warn("Failed to detect SurrealDB topology", "error", err)
+	} else if fixes := cfg.ApplyDetectedTopology(topology); len(fixes) > 0 {
+		slog.Info("Configuration corrected for the detected SurrealDB topology", "corrections", len(fixes))
+	}
+
+	versionReader, err := surrealdb.NewVersionReader(connections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize version reader: %w", err)
+	}
+
+	infoReader, err := surrealdb.NewInfoReader(cfg, connections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create surrealdb metrics reader: %w", err)
+	}
+	e.infoReader = infoReader
+	e.forNamespace = func(namespace string) surrealcollectors.InfoMetricsReader {
+		return infoReader.ForNamespace(namespace)
+	}
+
+	var recordCountReader surrealcollectors.RecordCountReader
+	recordCountReader, err = surrealdb.NewRecordCountReader(connections, cfg.RecordCountPartitions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create surrealdb record count reader: %w", err)
+	}
+
+	relationEdgeReader, err := surrealdb.NewRelationEdgeReader(connections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create surrealdb relation edge reader: %w", err)
+	}
+
+	indexUsageReader, err := surrealdb.NewIndexUsageReader(connections)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create surrealdb index usage reader: %w", err)
+	}
+
+	operationClassifier := engine.NewOperationClassifier(
+		cfg.OperationClassificationRules(),
+		cfg.OperationClassificationOverrides(),
+		cfg.OperationClassificationDefault(),
+	)
+
+	if cfg.LiveQueryDedicatedConnections() {
+		e.liveQueryConnections = surrealdb.NewMultiConnectionManager(cfg, queryLimiter)
+	}
+
+	liveQueryFilter := engine.NewTableFilter(cfg.LiveQueryIncludePatterns(), cfg.LiveQueryExcludePatterns())
+	e.LiveQuery = surrealdb.NewLiveQueryManager(
+		e.liveQueryConnections,
+		operationClassifier,
+		liveQueryFilter,
+		infoReader,
+		cfg.LiveQueryDetectOperationType(),
+		cfg.LiveQueryReconnectDelay(),
+		cfg.LiveQueryMaxReconnectAttempts(),
+		cfg.LiveQuerySchemaPollInterval(),
+		cfg.LiveQuerySampling(),
+		cfg.LiveQueryMaxTables(),
+		logger.Component("live_query"),
+	)
+	if cfg.LiveQueryEnabled() {
+		e.LiveQuery.Start()
+	}
+
+	if idleTimeout := cfg.SurrealConnection().IdleTimeout; idleTimeout > 0 {
+		// Live queries do not use their connections between schema polls, so connections
+		// they share with the collectors must not be reaped.
+		if cfg.LiveQueryEnabled() && !cfg.LiveQueryDedicatedConnections() {
+			slog.Info("Idle connection reaping disabled, live queries share the connections")
+		} else {
+			var reaperCtx context.Context
+			reaperCtx, e.reaperCancel = context.WithCancel(context.Background())
+			connections.StartReaper(reaperCtx, idleTimeout)
+		}
+	}
+
+	if cfg.AdminEnabled() {
+		e.Pauses = surrealcollectors.NewCollectorPauses()
+	}
+
+	if cfg.RecordCountCollectorEnabled() && (cfg.RecordCountInterval() > 0 || cfg.RecordCountIncremental()) {
+		// Incremental counts are kept fresh by live query operations, so tables are only
+		// fully counted again on the reconcile interval.
+		var operationTotals engine.OperationTotalsProvider
+		interval, overrides := cfg.RecordCountInterval(), cfg.RecordCountIntervalOverrides()
+		if cfg.RecordCountIncremental() {
+			operationTotals = e.LiveQuery
+			interval, overrides = cfg.RecordCountReconcileInterval(), nil
+		}
+
+		pauses := e.Pauses
+		e.recordCountRefresher = engine.NewRecordCountRefresher(
+			recordCountReader,
+			operationTotals,
+			interval,
+			overrides,
+			cfg.SurrealTimeout(),
+			func() bool { return pauses != nil && pauses.Paused(surrealcollectors.CollectorRecordCount) },
+			logger.Component("record_count"),
+		)
+		e.recordCountRefresher.Start()
+		recordCountReader = e.recordCountRefresher
+	}
+
+	statsTableFilter := engine.NewTableFilter(cfg.StatsTableIncludePatterns(), cfg.StatsTableExcludePatterns())
+	e.StatsTable = surrealdb.NewStatsTableManager(
+		connections,
+		operationClassifier,
+		cfg.StatsTableRemoveOrphanTables(),
+		cfg.SurrealScope(),
+		cfg.StatsTableNamePrefix(),
+		cfg.StatsTableDeltaRecords(),
+		cfg.StatsTableDryRun(),
+		logger.Component("stats_table"),
+	)
+	if cfg.StatsTableEnabled() {
+		e.StatsTable.Start()
+	}
+
+	tableCache := e.newTableCache(infoReader)
+	e.prewarm(tableCache)
+
+	e.deps = surrealcollectors.Dependencies{
+		Config:             cfg,
+		VersionReader:      versionReader,
+		InfoMetricsReader:  infoReader,
+		RecordCountReader:  recordCountReader,
+		RelationEdgeReader: relationEdgeReader,
+		IndexUsageReader:   indexUsageReader,
+		LiveQueryProvider:  e.LiveQuery,
+		StatsTableProvider: e.StatsTable,
+		LiveQueryFilter:    liveQueryFilter,
+		StatsTableFilter:   statsTableFilter,
+		RecordCountFilter:  engine.NewTableFilter(cfg.RecordCountIncludePatterns(), cfg.RecordCountExcludePatterns()),
+		TableCache:         tableCache,
+		RecordCounts:       surrealcollectors.NewRecordCountCache(),
+		Pauses:             e.Pauses,
+
+		ConnectionStatsProvider: connections,
+	}
+	if queryLimiter != nil {
+		e.deps.QueryThrottleProvider = queryLimiter
+	}
+
+	e.Metrics, e.Telemetry, e.MetricNames, err = registry.New(cfg, e.deps)
+	if err != nil {
+		e.Close()
+		return nil, fmt.Errorf("failed to initialize registry: %w", err)
+	}
+
+	return e, nil
+}
+
+// newTableCache creates the cache sharing the tables reader finds with the table
+// collectors, as configured by info.cache.table_list.
+func (e *Exporter) newTableCache(reader surrealcollectors.InfoMetricsReader) surrealcollectors.TableCache {
+	switch e.cfg.InfoTableList() {
+	case domain.TableListTTL:
+		return surrealcollectors.NewTTLTableCache(e.cfg.InfoTableListTTL())
+	case domain.TableListUncached:
+		return surrealcollectors.NewUncachedTableCache(reader, e.cfg.SurrealTimeout())
+	default:
+		return surrealcollectors.NewTableCache()
+	}
+}
+
+// prewarm fills tableCache, and opens the database connections when configured, before
+// the first scrape.
+func (e *Exporter) prewarm(tableCache surrealcollectors.TableCache) {
+	cfg := e.cfg
+
+	prewarmConnections := cfg.SurrealConnection().Prewarm
+	if !prewarmConnections && !cfg.StatsTableEnabled() && !cfg.LiveQueryEnabled() && !cfg.RecordCountCollectorEnabled() &&
+		!cfg.CollectorEnabled(surrealcollectors.CollectorRelationEdges) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.SurrealTimeout())
+	info, err := e.infoReader.Info(ctx)
+	cancel()
+	if err != nil {
+		slog.Warn("Failed to pre-warm table cache", "error", err)
+		return
+	}
+
+	tableCache.SetTables(info.AllTables())
+	slog.Info("Table cache pre-warmed", "table_count", len(info.AllTables()))
+
+	if prewarmConnections {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.SurrealTimeout())
+		surrealdb.PrewarmConnections(ctx, e.connections, info.AllDatabases())
+		cancel()
+	}
+}
+
+// NamespaceExists reports whether the server has namespace.
+func (e *Exporter) NamespaceExists(ctx context.Context, namespace string) (bool, error) {
+	return e.infoReader.NamespaceExists(ctx, namespace)
+}
+
+// NamespaceGatherer builds the collectors of the metrics endpoint of namespace, which
+// only query that namespace.
+func (e *Exporter) NamespaceGatherer(namespace string) (prometheus.Gatherer, error) {
+	deps := e.deps
+	deps.InfoMetricsReader = e.forNamespace(namespace)
+	deps.TableCache = e.newTableCache(deps.InfoMetricsReader)
+	deps.RecordCounts = surrealcollectors.NewRecordCountCache()
+	deps.ConnectionStatsProvider = nil
+	deps.QueryThrottleProvider = nil
+	deps.Namespace = namespace
+
+	ctx, cancel := context.WithTimeout(context.Background(), e.cfg.SurrealTimeout())
+	if info, err := deps.InfoMetricsReader.Info(ctx); err == nil {
+		deps.TableCache.SetTables(info.AllTables())
+	}
+	cancel()
+
+	namespaceRegistry, err := registry.NewNamespace(e.cfg, deps)
+	if err != nil {
+		return nil, err
+	}
+
+	return registry.WithRenames(e.cfg, registry.WithLabelSanitization(e.cfg, namespaceRegistry)), nil
+}
+
+// JSONSources returns the readers of the JSON metrics endpoint, leaving those of disabled
+// collectors nil.
+func (e *Exporter) JSONSources() api.JSONSources {
+	sources := api.JSONSources{Info: e.infoReader}
+	if e.cfg.RecordCountCollectorEnabled() {
+		sources.RecordCount = e.deps.RecordCountReader
+		sources.RecordCountFilter = e.deps.RecordCountFilter
+	}
+	if e.cfg.LiveQueryEnabled() {
+		sources.LiveQuery = e.LiveQuery
+	}
+	if e.cfg.StatsTableEnabled() {
+		sources.StatsTable = e.StatsTable
+		sources.StatsTableFilter = e.deps.StatsTableFilter
+	}
+
+	return sources
+}
+
+// StatusSources returns the subsystems described by the status endpoint. The scrapes and
+// the OTLP receiver are left to the caller, which runs them.
+func (e *Exporter) StatusSources() api.StatusSources {
+	sources := api.StatusSources{
+		URL:         e.cfg.SurrealURL(),
+		Version:     e.deps.VersionReader,
+		Connections: e.connections,
+	}
+	if age, ok := e.deps.TableCache.(api.TableCacheAge); ok {
+		sources.TableCache = age
+	}
+	if e.cfg.LiveQueryEnabled() {
+		sources.LiveQuery = e.LiveQuery
+		if e.cfg.LiveQueryDedicatedConnections() {
+			sources.LiveQueryConnections = e.liveQueryConnections
+		}
+	}
+	if e.cfg.StatsTableEnabled() {
+		sources.StatsTable = e.StatsTable
+	}
+	if e.Pauses != nil {
+		sources.Pauses = e.Pauses
+	}
+
+	return sources
+}
+
+// Close stops the record count refreshes, the live queries, the stats table maintenance
+// and the connection reaping, then closes the connections. The HTTP server serving the
+// collectors must be shut down first, so scrapes in flight finish on open connections.
+func (e *Exporter) Close() {
+	if e.recordCountRefresher != nil {
+		e.recordCountRefresher.Stop()
+	}
+
+	if e.cfg.LiveQueryEnabled() {
+		e.LiveQuery.Stop()
+	}
+
+	if e.cfg.StatsTableEnabled() {
+		e.StatsTable.Stop()
+	}
+
+	e.reaperCancel()
+
+	e.connections.Close()
+	if e.liveQueryConnections != e.connections {
+		e.liveQueryConnections.Close()
+	}
+}
@@ -0,0 +1,162 @@
+package generator
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+type ruleFile struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// WriteAlerts writes Prometheus alerting rules for the currently enabled collectors.
+// All expressions are scoped to the configured cluster label.
+func WriteAlerts(w io.Writer, cfg Config) error {
+	rules := ruleFile{Groups: []ruleGroup{{
+		Name:  "surrealdb-" + cfg.ClusterName(),
+		Rules: alertRules(cfg),
+	}}}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(rules); err != nil {
+		return fmt.Errorf("failed to encode alert rules: %w", err)
+	}
+
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("failed to encode alert rules: %w", err)
+	}
+
+	return nil
+}
+
+// alertRules returns the alerting rules for all enabled collectors.
+func alertRules(cfg Config) []rule {
+	cluster := fmt.Sprintf(`cluster=%q`, cfg.ClusterName())
+	s := func(metric string, matchers ...string) string {
+		return metric + "{" + strings.Join(append([]string{cluster}, matchers...), ",") + "}"
+	}
+	opsRate := func(operation string) string {
+		return "sum by (namespace, database, table) (rate(" +
+			s("surrealdb_live_query_operations_total", fmt.Sprintf("operation=%q", operation)) + "[5m]))"
+	}
+
+	rules := []rule{
+		newRule("SurrealDBExporterAbsent", "absent("+s("surrealdb_build_info")+")", "5m", "critical",
+			"SurrealDB metrics are missing",
+			"No SurrealDB build info has been scraped for cluster {{ $labels.cluster }} for 5 minutes."),
+		newRule("SurrealDBHighCPUUsage", s("surrealdb_system_cpu_usage")+" > 0.9", "15m", "warning",
+			"SurrealDB CPU usage is high",
+			"CPU usage is {{ $value | humanizePercentage }} on cluster {{ $labels.cluster }}."),
+		newRule("SurrealDBHighMemoryUsage", s("surrealdb_system_memory_usage_ratio_of_allocated")+" > 0.9", "15m", "warning",
+			"SurrealDB memory usage is high",
+			"Memory usage is {{ $value | humanizePercentage }} of allocated memory on cluster {{ $labels.cluster }}."),
+		newRule("SurrealDBRestarted", "increase("+s("surrealdb_restarts_detected_total")+"[15m]) > 0", "", "warning",
+			"SurrealDB server restarted",
+			"The SurrealDB server of cluster {{ $labels.cluster }} restarted within the last 15 minutes."),
+		newRule("SurrealDBSlowInfoScrape", s("surrealdb_info_scrape_duration_seconds")+" > 5", "10m", "warning",
+			"SurrealDB INFO scrape is slow",
+			"Collecting INFO statements takes {{ $value | humanizeDuration }} on cluster {{ $labels.cluster }}."),
+		newRule("SurrealDBInfoQueriesFailing", s("surrealdb_info_errors")+" > 0", "10m", "warning",
+			"SurrealDB INFO queries are failing",
+			"INFO queries at {{ $labels.level }} level fail for {{ $labels.namespace }}/{{ $labels.database }} on cluster {{ $labels.cluster }}."),
+		newRule("SurrealDBSchemaChanged", "increase("+s("surrealdb_schema_changes_total")+"[15m]) > 0", "", "info",
+			"SurrealDB schema changed",
+			"The namespaces, databases, tables or indexes of cluster {{ $labels.cluster }} changed within the last 15 minutes."),
+		newRule("SurrealDBIndexBuildingStuck", s("surrealdb_index_building")+" == 1", "1h", "warning",
+			"SurrealDB index has been building for over an hour",
+			"Index {{ $labels.index }} on {{ $labels.namespace }}/{{ $labels.database }}/{{ $labels.table }} "+
+				"is still building."),
+	}
+
+	if cfg.RecordCountCollectorEnabled() {
+		rules = append(rules,
+			newRule("SurrealDBSlowRecordCountScrape",
+				s("surrealdb_record_count_scrape_duration_seconds")+" > 10", "10m", "warning",
+				"SurrealDB record count scrape is slow",
+				"Counting records takes {{ $value | humanizeDuration }} on cluster {{ $labels.cluster }}."),
+		)
+	}
+
+	if cfg.LiveQueryEnabled() {
+		rules = append(rules,
+			newRule("SurrealDBTableDeleteSpike",
+				opsRate("delete")+" > 10 * "+opsRate("create"),
+				"10m", "info",
+				"SurrealDB table is deleting much faster than creating",
+				"Deletes outpace creates tenfold on {{ $labels.namespace }}/{{ $labels.database }}/{{ $labels.table }}."),
+			newRule("SurrealDBLiveQueryTableLimit",
+				s("surrealdb_live_query_tables_skipped")+" > 0", "15m", "warning",
+				"SurrealDB live query table limit reached",
+				"{{ $value }} matching tables have no live query on cluster {{ $labels.cluster }}, raise live_query.max_tables or narrow the include patterns."),
+		)
+	}
+
+	if cfg.DerivedCollectorEnabled() {
+		rules = append(rules,
+			newRule("SurrealDBLargeUnindexedTable",
+				s("surrealdb_derived_table_unindexed_large")+" == 1", "1h", "info",
+				"SurrealDB table is large but has no indexes",
+				"Table {{ $labels.namespace }}/{{ $labels.database }}/{{ $labels.table }} has no indexes."),
+		)
+	}
+
+	if cfg.OTLPLogsEnabled() {
+		rules = append(rules,
+			newRule("SurrealDBErrorLogs",
+				"sum by (cluster, target) (rate("+s("surrealdb_log_records_total", `level=~"error|fatal"`)+"[5m])) > 0",
+				"10m", "warning",
+				"SurrealDB is logging errors",
+				"Target {{ $labels.target }} logs {{ $value }} errors per second on cluster {{ $labels.cluster }}."),
+			newRule("SurrealDBSlowQueries",
+				"rate("+s("surrealdb_log_slow_queries_total")+"[5m]) > 0.1", "15m", "info",
+				"SurrealDB is logging slow queries",
+				"Slow queries are logged at {{ $value }} per second on cluster {{ $labels.cluster }}."),
+			newRule("SurrealDBAuthFailures",
+				"sum by (cluster, namespace, access) (rate("+s("surrealdb_auth_failures_total")+"[5m])) > 1", "5m", "warning",
+				"SurrealDB authentication failures are frequent",
+				"Authentication fails {{ $value }} times per second for namespace {{ $labels.namespace }} "+
+					"and access {{ $labels.access }} on cluster {{ $labels.cluster }}, which may be a brute-force attempt."),
+		)
+	}
+
+	if cfg.ProcessCollectorEnabled() {
+		rules = append(rules,
+			newRule("SurrealDBExporterRestarted", "changes("+s("process_start_time_seconds")+"[15m]) > 2", "", "warning",
+				"SurrealDB exporter is restarting",
+				"The exporter for cluster {{ $labels.cluster }} restarted {{ $value }} times in 15 minutes."),
+		)
+	}
+
+	return rules
+}
+
+func newRule(name, expr, forDuration, severity, summary, description string) rule {
+	return rule{
+		Alert:  name,
+		Expr:   expr,
+		For:    forDuration,
+		Labels: map[string]string{"severity": severity},
+		Annotations: map[string]string{
+			"summary":     summary,
+			"description": description,
+		},
+	}
+}
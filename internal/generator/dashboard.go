@@ -0,0 +1,303 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+type Config interface {
+	ClusterName() string
+	RecordCountCollectorEnabled() bool
+	LiveQueryEnabled() bool
+	LiveQueryDetectOperationType() bool
+	StatsTableEnabled() bool
+	GoCollectorEnabled() bool
+	ProcessCollectorEnabled() bool
+	OTLPLogsEnabled() bool
+	DerivedCollectorEnabled() bool
+}
+
+const (
+	dashboardUID      = "surrealdb-exporter"
+	dashboardTitle    = "SurrealDB"
+	panelWidth        = 12
+	panelHeight       = 8
+	gridColumns       = 24
+	clusterSelector   = `cluster="$cluster"`
+	datasourceVarName = "datasource"
+)
+
+type dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Tags          []string   `json:"tags"`
+	Timezone      string     `json:"timezone"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Refresh       string     `json:"refresh"`
+	Time          timeRange  `json:"time"`
+	Templating    templating `json:"templating"`
+	Panels        []panel    `json:"panels"`
+}
+
+type timeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type templating struct {
+	List []templateVar `json:"list"`
+}
+
+type templateVar struct {
+	Name       string         `json:"name"`
+	Label      string         `json:"label"`
+	Type       string         `json:"type"`
+	Query      any            `json:"query"`
+	Datasource *datasourceRef `json:"datasource,omitempty"`
+	Current    *currentValue  `json:"current,omitempty"`
+	Refresh    int            `json:"refresh,omitempty"`
+}
+
+type currentValue struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+type datasourceRef struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type panel struct {
+	ID          int            `json:"id"`
+	Type        string         `json:"type"`
+	Title       string         `json:"title"`
+	Collapsed   bool           `json:"collapsed,omitempty"`
+	Datasource  *datasourceRef `json:"datasource,omitempty"`
+	GridPos     gridPos        `json:"gridPos"`
+	Targets     []target       `json:"targets,omitempty"`
+	FieldConfig *fieldConfig   `json:"fieldConfig,omitempty"`
+}
+
+type gridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+type fieldConfig struct {
+	Defaults fieldDefaults `json:"defaults"`
+}
+
+type fieldDefaults struct {
+	Unit string `json:"unit"`
+}
+
+// panelSpec describes a single time series panel of a dashboard row.
+type panelSpec struct {
+	title  string
+	unit   string
+	expr   string
+	legend string
+}
+
+// rowSpec describes a dashboard row containing panels of one collector.
+type rowSpec struct {
+	title  string
+	panels []panelSpec
+}
+
+// WriteDashboard writes a Grafana dashboard JSON model with rows for the currently enabled collectors.
+func WriteDashboard(w io.Writer, cfg Config) error {
+	d := buildDashboard(cfg)
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(d); err != nil {
+		return fmt.Errorf("failed to encode dashboard: %w", err)
+	}
+
+	return nil
+}
+
+// buildDashboard lays out the rows of all enabled collectors on the Grafana grid.
+func buildDashboard(cfg Config) *dashboard {
+	ds := &datasourceRef{Type: "prometheus", UID: "${" + datasourceVarName + "}"}
+
+	d := &dashboard{
+		UID:           dashboardUID,
+		Title:         dashboardTitle,
+		Tags:          []string{"surrealdb", "prometheus"},
+		Timezone:      "browser",
+		SchemaVersion: 39,
+		Refresh:       "30s",
+		Time:          timeRange{From: "now-6h", To: "now"},
+		Templating: templating{List: []templateVar{
+			{
+				Name:  datasourceVarName,
+				Label: "Data source",
+				Type:  "datasource",
+				Query: "prometheus",
+			},
+			{
+				Name:       "cluster",
+				Label:      "Cluster",
+				Type:       "query",
+				Query:      "label_values(surrealdb_build_info, cluster)",
+				Datasource: ds,
+				Current:    &currentValue{Text: cfg.ClusterName(), Value: cfg.ClusterName()},
+				Refresh:    2,
+			},
+		}},
+	}
+
+	id := 1
+	y := 0
+
+	for _, row := range dashboardRows(cfg) {
+		d.Panels = append(d.Panels, panel{
+			ID:      id,
+			Type:    "row",
+			Title:   row.title,
+			GridPos: gridPos{H: 1, W: gridColumns, X: 0, Y: y},
+		})
+		id++
+		y++
+
+		for i, spec := range row.panels {
+			x := (i % 2) * panelWidth
+			if i > 0 && i%2 == 0 {
+				y += panelHeight
+			}
+
+			d.Panels = append(d.Panels, panel{
+				ID:          id,
+				Type:        "timeseries",
+				Title:       spec.title,
+				Datasource:  ds,
+				GridPos:     gridPos{H: panelHeight, W: panelWidth, X: x, Y: y},
+				Targets:     []target{{RefID: "A", Expr: spec.expr, LegendFormat: spec.legend}},
+				FieldConfig: &fieldConfig{Defaults: fieldDefaults{Unit: spec.unit}},
+			})
+			id++
+		}
+
+		y += panelHeight
+	}
+
+	return d
+}
+
+// dashboardRows returns the rows for all enabled collectors.
+func dashboardRows(cfg Config) []rowSpec {
+	rows := []rowSpec{
+		{
+			title: "System",
+			panels: []panelSpec{
+				{"CPU usage", "percentunit", sel("surrealdb_system_cpu_usage"), "{{cluster}}"},
+				{"Memory usage", "bytes", sel("surrealdb_system_memory_usage_bytes"), "{{cluster}}"},
+				{"Load average", "short", sel("surrealdb_system_load_average"), "{{period}}"},
+				{"Threads", "short", sel("surrealdb_system_threads"), "{{cluster}}"},
+			},
+		},
+		{
+			title: "Schema",
+			panels: []panelSpec{
+				{"Databases per namespace", "short", sel("surrealdb_namespace_databases"), "{{namespace}}"},
+				{"Tables per database", "short", sel("surrealdb_database_tables"), "{{namespace}}/{{database}}"},
+				{"Indexes building", "short", sel("surrealdb_index_building") + " == 1", "{{table}}.{{index}}"},
+				{"Info scrape duration", "s", sel("surrealdb_info_scrape_duration_seconds"), "{{cluster}}"},
+			},
+		},
+	}
+
+	if cfg.RecordCountCollectorEnabled() {
+		rows = append(rows, rowSpec{
+			title: "Record counts",
+			panels: []panelSpec{
+				{"Records per table", "short", sel("surrealdb_table_record_count"), "{{namespace}}/{{database}}/{{table}}"},
+				{"Records per database", "short", sel("surrealdb_database_record_count"), "{{namespace}}/{{database}}"},
+				{"Record count scrape duration", "s", sel("surrealdb_record_count_scrape_duration_seconds"), "{{cluster}}"},
+			},
+		})
+	}
+
+	if cfg.LiveQueryEnabled() {
+		by := "namespace, database, table, operation"
+		legend := "{{table}} {{operation}}"
+		if cfg.LiveQueryDetectOperationType() {
+			by += ", operation_type"
+			legend += " ({{operation_type}})"
+		}
+
+		rows = append(rows, rowSpec{
+			title: "Live query operations",
+			panels: []panelSpec{
+				{"Operations rate", "ops", rateBy("surrealdb_live_query_operations_total", by), legend},
+			},
+		})
+	}
+
+	if cfg.StatsTableEnabled() {
+		rows = append(rows, rowSpec{
+			title: "Stats table operations",
+			panels: []panelSpec{
+				{
+					"Operations rate", "ops",
+					rateBy("surrealdb_stats_table_operations_total", "namespace, database, table, operation"),
+					"{{table}} {{operation}}",
+				},
+				{
+					"Time since last write", "s",
+					"time() - " + sel("surrealdb_stats_table_last_update_timestamp_seconds"),
+					"{{namespace}}/{{database}}/{{table}}",
+				},
+			},
+		})
+	}
+
+	if cfg.GoCollectorEnabled() || cfg.ProcessCollectorEnabled() {
+		rows = append(rows, exporterRow(cfg))
+	}
+
+	return rows
+}
+
+// exporterRow returns the row with the exporter's own runtime metrics.
+func exporterRow(cfg Config) rowSpec {
+	row := rowSpec{title: "Exporter"}
+
+	if cfg.ProcessCollectorEnabled() {
+		row.panels = append(row.panels,
+			panelSpec{"Exporter CPU", "percentunit", "rate(" + sel("process_cpu_seconds_total") + "[5m])", "cpu"},
+			panelSpec{"Exporter resident memory", "bytes", sel("process_resident_memory_bytes"), "rss"},
+		)
+	}
+
+	if cfg.GoCollectorEnabled() {
+		row.panels = append(row.panels,
+			panelSpec{"Exporter goroutines", "short", sel("go_goroutines"), "goroutines"},
+		)
+	}
+
+	return row
+}
+
+// sel returns the metric name with the cluster selector.
+func sel(metric string) string {
+	return metric + "{" + clusterSelector + "}"
+}
+
+// rateBy returns the per-second rate of a counter summed by the given labels.
+func rateBy(metric, by string) string {
+	return fmt.Sprintf("sum by (%s) (rate(%s[5m]))", by, sel(metric))
+}
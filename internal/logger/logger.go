@@ -1,16 +1,30 @@
 package logger
 
 import (
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"time"
 )
 
 type Config interface {
 	Format() string
 	Level() string
 	CustomAttributes() map[string]any
+	Output() string
+	FilePath() string
+	FileMaxSizeMB() int
+	FileMaxAge() time.Duration
+	FileMaxBackups() int
 }
 
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
 var logLevelMap = map[string]slog.Level{
 	"debug":   slog.LevelDebug,
 	"info":    slog.LevelInfo,
@@ -19,31 +33,71 @@ var logLevelMap = map[string]slog.Level{
 	"error":   slog.LevelError,
 }
 
-func Configure(cfg Config) {
+// Configure sets up the default logger. The returned closer releases the log file
+// when output is file and must be closed on shutdown.
+func Configure(cfg Config) (io.Closer, error) {
 	var handler slog.Handler
 
+	out, closer, err := openOutput(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	level := logLevelMap[cfg.Level()]
 
 	handlerOptions := &slog.HandlerOptions{AddSource: level == slog.LevelDebug, Level: level}
 
 	switch cfg.Format() {
 	case "json":
-		handler = slog.NewJSONHandler(os.Stdout, handlerOptions)
+		handler = slog.NewJSONHandler(out, handlerOptions)
 	case "text":
-		handler = slog.NewTextHandler(os.Stdout, handlerOptions)
+		handler = slog.NewTextHandler(out, handlerOptions)
 	default:
-		handler = slog.NewJSONHandler(os.Stdout, handlerOptions)
+		handler = slog.NewJSONHandler(out, handlerOptions)
 	}
 
 	logger := slog.New(handler)
 
-	// attrs := make([]slog.Attr, 0)
-	//
-	// for key, value := range cfg.CustomAttributes() {
-	//	attrs = append(attrs, slog.Any(key, value))
-	//}
-	//
-	// logger = logger.With(attrs) // TODO update because of !BADKEY
+	attrs := make([]any, 0, len(cfg.CustomAttributes()))
+	for key, value := range cfg.CustomAttributes() {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+
+	if len(attrs) > 0 {
+		logger = logger.With(attrs...)
+	}
 
 	slog.SetDefault(logger)
+
+	return closer, nil
+}
+
+// openOutput returns the writer for the configured log output.
+func openOutput(cfg Config) (io.Writer, io.Closer, error) {
+	switch cfg.Output() {
+	case OutputStderr:
+		return os.Stderr, nopCloser{}, nil
+	case OutputFile:
+		maxSize := int64(cfg.FileMaxSizeMB()) * 1024 * 1024
+
+		f, err := newRotatingFile(cfg.FilePath(), maxSize, cfg.FileMaxAge(), cfg.FileMaxBackups())
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log output: %w", err)
+		}
+
+		return f, f, nil
+	default:
+		return os.Stdout, nopCloser{}, nil
+	}
 }
+
+// Component returns a logger scoped to an exporter subsystem, so its log lines
+// can be filtered by the component attribute. Call it after Configure.
+func Component(name string) *slog.Logger {
+	return slog.Default().With("component", name)
+}
+
+// nopCloser is returned for outputs the logger does not own.
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
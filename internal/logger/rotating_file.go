@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const backupTimeFormat = "20060102T150405.000"
+
+// rotateRetryDelay is how long writes continue to the current file after a failed
+// rotation before rotating is tried again.
+const rotateRetryDelay = time.Minute
+
+// rotatingFile is an io.WriteCloser appending to a log file that is rotated when it
+// exceeds maxSize bytes or becomes older than maxAge. Rotated files are renamed with
+// a timestamp suffix and only the newest maxBackups are kept. Zero values disable
+// the corresponding limit.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	mu            sync.Mutex
+	file          *os.File
+	size          int64
+	openedAt      time.Time
+	rotateRetryAt time.Time
+}
+
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Write implements io.Writer.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	if f.shouldRotate(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			if f.file == nil {
+				return 0, err
+			}
+
+			// The log cannot log its own failure; writes continue to the current file.
+			fmt.Fprintf(os.Stderr, "log rotation failed, retrying in %s: %v\n", rotateRetryDelay, err)
+			f.rotateRetryAt = time.Now().Add(rotateRetryDelay)
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+// Close implements io.Closer.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	return f.file.Close()
+}
+
+func (f *rotatingFile) shouldRotate(writeSize int64) bool {
+	if f.size == 0 || time.Now().Before(f.rotateRetryAt) {
+		return false
+	}
+
+	if f.maxSize > 0 && f.size+writeSize > f.maxSize {
+		return true
+	}
+
+	return f.maxAge > 0 && time.Since(f.openedAt) > f.maxAge
+}
+
+// open opens or creates the log file, continuing an existing file. The age of the file
+// counts from now, as the exporter cannot know when an existing file was created.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat log file: %w", err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+
+	return nil
+}
+
+// rotate renames the current file to a timestamped backup, opens a new file and
+// removes backups beyond maxBackups. When the file cannot be renamed, it is reopened, so
+// writes continue to it; file is nil only when no file could be opened.
+func (f *rotatingFile) rotate() error {
+	var rotateErr error
+	if err := f.file.Close(); err != nil {
+		rotateErr = fmt.Errorf("failed to close log file: %w", err)
+	} else if err := os.Rename(f.path, f.path+"."+time.Now().Format(backupTimeFormat)); err != nil {
+		rotateErr = fmt.Errorf("failed to rotate log file: %w", err)
+	}
+
+	if err := f.open(); err != nil {
+		f.file = nil
+		return errors.Join(rotateErr, err)
+	}
+
+	if rotateErr != nil {
+		return rotateErr
+	}
+
+	f.removeOldBackups()
+
+	return nil
+}
+
+func (f *rotatingFile) removeOldBackups() {
+	if f.maxBackups <= 0 {
+		return
+	}
+
+	backups, err := filepath.Glob(f.path + ".*")
+	if err != nil {
+		return
+	}
+
+	backups = slices.DeleteFunc(backups, func(name string) bool {
+		_, err := time.Parse(backupTimeFormat, strings.TrimPrefix(name, f.path+"."))
+		return err != nil
+	})
+
+	// timestamp suffixes sort chronologically
+	slices.Sort(backups)
+
+	for len(backups) > f.maxBackups {
+		_ = os.Remove(backups[0])
+		backups = backups[1:]
+	}
+}
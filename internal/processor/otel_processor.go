@@ -2,6 +2,7 @@ package processor
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"maps"
 	"sync"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/converter"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // Processor defines the interface for metric processing.
@@ -16,66 +18,218 @@ type Processor interface {
 	Process(ctx context.Context, batch domain.MetricBatch) error
 }
 
-// Chain chains multiple processors together.
+// Stage transforms a batch on its way through a Chain.
+type Stage interface {
+	// Name returns the pipeline stage type, see domain.PipelineStageFilter.
+	Name() string
+	Apply(batch domain.MetricBatch) domain.MetricBatch
+}
+
+// Chain passes batches through ordered stages and hands the result to a final processor.
 type Chain struct {
-	processors []Processor
+	stages  []Stage
+	final   Processor
+	dropped *prometheus.CounterVec
 }
 
-// NewChain creates a new processor chain.
-func NewChain(processors ...Processor) *Chain {
-	return &Chain{
-		processors: processors,
+// NewChain creates a new processor chain ending in final and registers the count of
+// batches its stages drop, with constLabels, with registry.
+func NewChain(
+	final Processor,
+	registry prometheus.Registerer,
+	constLabels prometheus.Labels,
+	stages ...Stage,
+) *Chain {
+	c := &Chain{
+		stages: stages,
+		final:  final,
+		dropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace:   domain.Namespace,
+			Subsystem:   "otlp_pipeline",
+			Name:        "dropped_batches_total",
+			Help:        "Total number of OTLP batches dropped because a pipeline stage removed all of their metrics",
+			ConstLabels: constLabels,
+		}, []string{"stage"}),
 	}
+
+	for _, stage := range stages {
+		c.dropped.WithLabelValues(stage.Name())
+	}
+
+	registry.MustRegister(c.dropped)
+
+	return c
 }
 
-// Process processes a batch through all processors in the chain.
+// Process processes a batch through all stages of the chain. Batches emptied by a
+// stage are counted and not passed on.
 func (c *Chain) Process(ctx context.Context, batch domain.MetricBatch) error {
-	for _, processor := range c.processors {
-		if err := processor.Process(ctx, batch); err != nil {
-			return err
+	for _, stage := range c.stages {
+		if batch.Count() == 0 {
+			return nil
+		}
+
+		batch = stage.Apply(batch)
+		if batch.Count() == 0 {
+			c.dropped.WithLabelValues(stage.Name()).Inc()
+			return nil
 		}
 	}
 
+	return c.final.Process(ctx, batch)
+}
+
+// Flush flushes the final processor when it buffers metrics.
+func (c *Chain) Flush() error {
+	if flusher, ok := c.final.(interface{ Flush() error }); ok {
+		return flusher.Flush()
+	}
+
 	return nil
 }
 
-// BatchProcessor accumulates metrics and processes them in batches.
+// Stop stops the final processor when it runs in the background, flushing its
+// remaining metrics.
+func (c *Chain) Stop() error {
+	if stopper, ok := c.final.(interface{ Stop() error }); ok {
+		return stopper.Stop()
+	}
+
+	return nil
+}
+
+// ErrQueueFull is returned when a batch does not fit into the BatchProcessor queue and
+// the overflow behavior is to reject it. Senders should retry later.
+var ErrQueueFull = errors.New("metric queue is full")
+
+// BatchProcessor accumulates metrics and processes them in batches. Conversion runs in
+// the background, so queued metrics are bounded by the configured queue limits.
 type BatchProcessor struct {
 	converter    *converter.Converter
 	batchSize    int
 	batchTimeout time.Duration
+	limits       domain.QueueLimits
 	currentBatch domain.MetricBatch
+	pendingBytes int
+	flushing     int
+	flushBytes   int
 	mu           sync.Mutex
-	flushTimer   *time.Timer
 	stopChan     chan struct{}
+	doneChan     chan struct{}
 	flushChan    chan struct{}
+	logger       *slog.Logger
+
+	queueMetrics prometheus.Gauge
+	queueBytes   prometheus.Gauge
+	dropped      prometheus.Counter
+	rejected     prometheus.Counter
+	truncated    prometheus.Counter
 }
 
-// NewBatchProcessor creates a new batch processor.
-func NewBatchProcessor(conv *converter.Converter, batchSize int, batchTimeout time.Duration) *BatchProcessor {
+// NewBatchProcessor creates a new batch processor and registers its queue metrics, with
+// constLabels, with registry.
+func NewBatchProcessor(
+	conv *converter.Converter,
+	batchSize int,
+	batchTimeout time.Duration,
+	limits domain.QueueLimits,
+	registry prometheus.Registerer,
+	constLabels prometheus.Labels,
+	logger *slog.Logger,
+) *BatchProcessor {
 	bp := &BatchProcessor{
 		converter:    conv,
 		batchSize:    batchSize,
 		batchTimeout: batchTimeout,
+		limits:       limits,
 		currentBatch: domain.MetricBatch{
 			Metrics:       make([]domain.Metric, 0, batchSize),
 			ResourceAttrs: make(map[string]string),
 		},
 		stopChan:  make(chan struct{}),
+		doneChan:  make(chan struct{}),
 		flushChan: make(chan struct{}, 1),
+		logger:    logger,
+		queueMetrics: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   domain.Namespace,
+			Subsystem:   "otlp_queue",
+			Name:        "metrics",
+			Help:        "Number of OTLP metrics queued or being converted",
+			ConstLabels: constLabels,
+		}),
+		queueBytes: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace:   domain.Namespace,
+			Subsystem:   "otlp_queue",
+			Name:        "bytes",
+			Help:        "Estimated size in bytes of the OTLP metrics queued or being converted",
+			ConstLabels: constLabels,
+		}),
+		dropped: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   domain.Namespace,
+			Subsystem:   "otlp_queue",
+			Name:        "dropped_metrics_total",
+			Help:        "Total number of queued OTLP metrics dropped to make room for newer ones",
+			ConstLabels: constLabels,
+		}),
+		rejected: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   domain.Namespace,
+			Subsystem:   "otlp_queue",
+			Name:        "rejected_metrics_total",
+			Help:        "Total number of OTLP metrics rejected because the queue was full",
+			ConstLabels: constLabels,
+		}),
+		truncated: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace:   domain.Namespace,
+			Subsystem:   "otlp_queue",
+			Name:        "truncated_metrics_total",
+			Help:        "Total number of OTLP metrics dropped from batches exceeding the queue limits on their own",
+			ConstLabels: constLabels,
+		}),
 	}
 
+	registry.MustRegister(bp.queueMetrics, bp.queueBytes, bp.dropped, bp.rejected, bp.truncated)
+
 	go bp.backgroundFlusher()
 
 	return bp
 }
 
-// Process adds metrics to the batch and flushes if necessary.
+// Process adds metrics to the batch and schedules a flush if necessary. Returns
+// ErrQueueFull if the metrics do not fit and the overflow behavior is reject. Metrics of
+// a batch exceeding the limits on its own are dropped beyond them, as the whole batch
+// would be rejected on every retry.
 func (p *BatchProcessor) Process(ctx context.Context, batch domain.MetricBatch) error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	size := 0
+	for _, metric := range batch.Metrics {
+		size += metricSize(metric)
+	}
+
+	if p.limits.Overflow != domain.QueueOverflowDropOldest {
+		if fitting, fittingSize := p.withinLimits(batch.Metrics); len(fitting) < len(batch.Metrics) {
+			truncated := len(batch.Metrics) - len(fitting)
+			p.truncated.Add(float64(truncated))
+			p.logger.Warn("OTLP metric batch exceeds the queue limits, dropped the metrics beyond them",
+				"dropped", truncated,
+				"kept", len(fitting))
+
+			batch.Metrics, size = fitting, fittingSize
+		}
+
+		if p.exceedsLimitsLocked(len(batch.Metrics), size) {
+			p.rejected.Add(float64(len(batch.Metrics)))
+			return ErrQueueFull
+		}
+	}
+
 	p.currentBatch.Metrics = append(p.currentBatch.Metrics, batch.Metrics...)
+	p.pendingBytes += size
+
+	if p.limits.Overflow == domain.QueueOverflowDropOldest {
+		p.dropOldestLocked()
+	}
 
 	maps.Copy(p.currentBatch.ResourceAttrs, batch.ResourceAttrs)
 
@@ -83,71 +237,146 @@ func (p *BatchProcessor) Process(ctx context.Context, batch domain.MetricBatch)
 		p.currentBatch.ReceivedAt = batch.ReceivedAt
 	}
 
-	if p.flushTimer != nil {
-		p.flushTimer.Stop()
-	}
+	p.updateQueueMetricsLocked()
 
-	p.flushTimer = time.AfterFunc(p.batchTimeout, func() {
+	if len(p.currentBatch.Metrics) >= p.batchSize {
 		select {
 		case p.flushChan <- struct{}{}:
 		default:
 		}
-	})
-
-	if len(p.currentBatch.Metrics) >= p.batchSize {
-		return p.flushLocked()
 	}
 
 	return nil
 }
 
-// flushLocked flushes the current batch (caller must hold lock).
-func (p *BatchProcessor) flushLocked() error {
-	if len(p.currentBatch.Metrics) == 0 {
-		return nil
+// exceedsLimitsLocked reports whether adding count metrics of size bytes would exceed the
+// queue limits (caller must hold lock).
+func (p *BatchProcessor) exceedsLimitsLocked(count, size int) bool {
+	queued := len(p.currentBatch.Metrics) + p.flushing
+	queuedBytes := p.pendingBytes + p.flushBytes
+
+	return (p.limits.MaxMetrics > 0 && queued+count > p.limits.MaxMetrics) ||
+		(p.limits.MaxBytes > 0 && queuedBytes+size > p.limits.MaxBytes)
+}
+
+// withinLimits returns the longest prefix of metrics within the queue limits on its own,
+// and its size.
+func (p *BatchProcessor) withinLimits(metrics []domain.Metric) ([]domain.Metric, int) {
+	size := 0
+	for i, metric := range metrics {
+		metricBytes := metricSize(metric)
+		if (p.limits.MaxMetrics > 0 && i >= p.limits.MaxMetrics) ||
+			(p.limits.MaxBytes > 0 && size+metricBytes > p.limits.MaxBytes) {
+			return metrics[:i], size
+		}
+		size += metricBytes
 	}
 
-	slog.Debug("flushing metric batch",
-		"count", len(p.currentBatch.Metrics))
+	return metrics, size
+}
 
-	if err := p.converter.Convert(p.currentBatch); err != nil {
-		slog.Error("failed to convert batch", "error", err)
-		// Don't return error - just log it and continue
+// dropOldestLocked drops the oldest pending metrics until the queue is within its
+// limits (caller must hold lock). Metrics already being converted are not dropped.
+func (p *BatchProcessor) dropOldestLocked() {
+	dropped := 0
+	for len(p.currentBatch.Metrics) > 0 && p.exceedsLimitsLocked(0, 0) {
+		p.pendingBytes -= metricSize(p.currentBatch.Metrics[0])
+		p.currentBatch.Metrics = p.currentBatch.Metrics[1:]
+		dropped++
+	}
+
+	if dropped > 0 {
+		p.dropped.Add(float64(dropped))
+		p.logger.Warn("OTLP metric queue is full, dropped oldest metrics", "dropped", dropped)
+	}
+}
+
+// updateQueueMetricsLocked updates the queue depth gauges (caller must hold lock).
+func (p *BatchProcessor) updateQueueMetricsLocked() {
+	p.queueMetrics.Set(float64(len(p.currentBatch.Metrics) + p.flushing))
+	p.queueBytes.Set(float64(p.pendingBytes + p.flushBytes))
+}
+
+// Flush converts the current batch.
+func (p *BatchProcessor) Flush() error {
+	p.mu.Lock()
+	batch, size := p.currentBatch, p.pendingBytes
+	if len(batch.Metrics) == 0 {
+		p.mu.Unlock()
+		return nil
 	}
 
 	p.currentBatch = domain.MetricBatch{
 		Metrics:       make([]domain.Metric, 0, p.batchSize),
 		ResourceAttrs: make(map[string]string),
 	}
+	p.pendingBytes = 0
+	p.flushing += len(batch.Metrics)
+	p.flushBytes += size
+	p.mu.Unlock()
 
-	return nil
-}
+	p.logger.Debug("flushing metric batch",
+		"count", len(batch.Metrics))
+
+	if err := p.converter.Convert(batch); err != nil {
+		p.logger.Error("failed to convert batch", "error", err)
+		// Don't return error - just log it and continue
+	}
 
-// Flush flushes the current batch.
-func (p *BatchProcessor) Flush() error {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-	return p.flushLocked()
+	p.flushing -= len(batch.Metrics)
+	p.flushBytes -= size
+	p.updateQueueMetricsLocked()
+	p.mu.Unlock()
+
+	return nil
 }
 
-// backgroundFlusher periodically flushes batches.
+// backgroundFlusher flushes the current batch every batch timeout and whenever it
+// reaches the batch size.
 func (p *BatchProcessor) backgroundFlusher() {
+	defer close(p.doneChan)
+
+	ticker := time.NewTicker(p.batchTimeout)
+	defer ticker.Stop()
+
 	for {
 		select {
+		case <-ticker.C:
+			p.Flush()
 		case <-p.flushChan:
-			p.mu.Lock()
-			p.flushLocked()
-			p.mu.Unlock()
+			p.Flush()
+			ticker.Reset(p.batchTimeout)
 		case <-p.stopChan:
 			return
 		}
 	}
 }
 
-// Stop stops the batch processor.
-func (p *BatchProcessor) Stop() {
+// Stop stops the background flusher and flushes the remaining metrics. Stop must be
+// called after the senders have stopped calling Process.
+func (p *BatchProcessor) Stop() error {
 	close(p.stopChan)
-	p.Flush()
+	<-p.doneChan
+
+	return p.Flush()
+}
+
+// metricSize estimates the in-memory size of a metric in bytes.
+func metricSize(metric domain.Metric) int {
+	size := 128 + len(metric.Name) + len(metric.Description) + len(metric.Unit)
+	for k, v := range metric.Labels {
+		size += len(k) + len(v) + 32
+	}
+
+	if metric.HistogramData != nil {
+		size += 16 * len(metric.HistogramData.Buckets)
+		if exp := metric.HistogramData.Exponential; exp != nil {
+			size += 8 * (len(exp.PositiveCounts) + len(exp.NegativeCounts))
+		}
+	}
+
+	return size
 }
 
 // DirectProcessor processes metrics immediately without batching.
@@ -0,0 +1,214 @@
+package processor
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/converter"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// NewPipeline builds the processing chain for the configured stages. A trailing batch
+// stage batches converted metrics with batchSize and batchTimeout, queueing at most
+// limits, and registers its queue metrics with constLabels; without one, metrics are
+// converted directly.
+func NewPipeline(
+	conv *converter.Converter,
+	stages []domain.PipelineStage,
+	batchSize int,
+	batchTimeout time.Duration,
+	limits domain.QueueLimits,
+	registry prometheus.Registerer,
+	constLabels prometheus.Labels,
+	logger *slog.Logger,
+) (*Chain, error) {
+	var final Processor = NewDirectProcessor(conv)
+	chainStages := make([]Stage, 0, len(stages))
+
+	for i, stage := range stages {
+		switch stage.Type {
+		case domain.PipelineStageFilter:
+			chainStages = append(chainStages, NewFilterStage(stage.Include, stage.Exclude))
+		case domain.PipelineStageRelabel:
+			chainStages = append(chainStages, NewRelabelStage(stage.RelabelRules))
+		case domain.PipelineStageRateLimit:
+			chainStages = append(chainStages, NewRateLimitStage(stage.MetricsPerSecond, stage.Burst, logger))
+		case domain.PipelineStageBatch:
+			if i != len(stages)-1 {
+				return nil, fmt.Errorf("batch stage must be the last stage, found at position %d", i)
+			}
+			final = NewBatchProcessor(conv, batchSize, batchTimeout, limits, registry, constLabels, logger)
+		default:
+			return nil, fmt.Errorf("unknown pipeline stage type %q", stage.Type)
+		}
+	}
+
+	return NewChain(final, registry, constLabels, chainStages...), nil
+}
+
+// FilterStage keeps metrics whose OTLP name matches an include glob, if any are set,
+// and no exclude glob.
+type FilterStage struct {
+	include []string
+	exclude []string
+}
+
+// NewFilterStage creates a new filter stage.
+func NewFilterStage(include, exclude []string) *FilterStage {
+	return &FilterStage{
+		include: include,
+		exclude: exclude,
+	}
+}
+
+// Name implements Stage.
+func (s *FilterStage) Name() string {
+	return domain.PipelineStageFilter
+}
+
+// Apply implements Stage.
+func (s *FilterStage) Apply(batch domain.MetricBatch) domain.MetricBatch {
+	kept := make([]domain.Metric, 0, len(batch.Metrics))
+	for _, metric := range batch.Metrics {
+		if s.keep(metric.Name) {
+			kept = append(kept, metric)
+		}
+	}
+
+	batch.Metrics = kept
+
+	return batch
+}
+
+// keep reports whether a metric with the given name passes the filter.
+func (s *FilterStage) keep(name string) bool {
+	for _, pattern := range s.exclude {
+		if matched, _ := path.Match(pattern, name); matched {
+			return false
+		}
+	}
+
+	if len(s.include) == 0 {
+		return true
+	}
+
+	for _, pattern := range s.include {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RelabelStage rewrites metric labels with ordered relabel rules.
+type RelabelStage struct {
+	rules []domain.RelabelRule
+}
+
+// NewRelabelStage creates a new relabel stage.
+func NewRelabelStage(rules []domain.RelabelRule) *RelabelStage {
+	return &RelabelStage{rules: rules}
+}
+
+// Name implements Stage.
+func (s *RelabelStage) Name() string {
+	return domain.PipelineStageRelabel
+}
+
+// Apply implements Stage.
+func (s *RelabelStage) Apply(batch domain.MetricBatch) domain.MetricBatch {
+	metrics := make([]domain.Metric, len(batch.Metrics))
+	for i, metric := range batch.Metrics {
+		metric.Labels = s.relabel(maps.Clone(metric.Labels))
+		metrics[i] = metric
+	}
+
+	batch.Metrics = metrics
+
+	return batch
+}
+
+// relabel applies all rules to labels in place.
+func (s *RelabelStage) relabel(labels map[string]string) map[string]string {
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+
+	for _, rule := range s.rules {
+		switch rule.Action {
+		case domain.RelabelActionSet:
+			labels[rule.TargetLabel] = rule.Replacement
+		case domain.RelabelActionDrop:
+			delete(labels, rule.SourceLabel)
+		case domain.RelabelActionRename:
+			if value, ok := labels[rule.SourceLabel]; ok {
+				delete(labels, rule.SourceLabel)
+				labels[rule.TargetLabel] = value
+			}
+		case domain.RelabelActionReplace:
+			value := labels[rule.SourceLabel]
+			if match := rule.Regex.FindStringSubmatchIndex(value); match != nil {
+				labels[rule.TargetLabel] = string(rule.Regex.ExpandString(nil, rule.Replacement, value, match))
+			}
+		}
+	}
+
+	return labels
+}
+
+// RateLimitStage drops metrics beyond a sustained rate using a token bucket.
+type RateLimitStage struct {
+	rate   float64
+	burst  float64
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimitStage creates a stage passing at most metricsPerSecond metrics on average
+// and up to burst metrics at once.
+func NewRateLimitStage(metricsPerSecond float64, burst int, logger *slog.Logger) *RateLimitStage {
+	return &RateLimitStage{
+		rate:    metricsPerSecond,
+		burst:   float64(burst),
+		logger:  logger,
+		tokens:  float64(burst),
+		updated: time.Now(),
+	}
+}
+
+// Name implements Stage.
+func (s *RateLimitStage) Name() string {
+	return domain.PipelineStageRateLimit
+}
+
+// Apply implements Stage.
+func (s *RateLimitStage) Apply(batch domain.MetricBatch) domain.MetricBatch {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.tokens = min(s.tokens+now.Sub(s.updated).Seconds()*s.rate, s.burst)
+	s.updated = now
+
+	allowed := min(len(batch.Metrics), int(s.tokens))
+	s.tokens -= float64(allowed)
+
+	if dropped := len(batch.Metrics) - allowed; dropped > 0 {
+		s.logger.Warn("OTLP metric rate limit exceeded, dropping metrics",
+			"dropped", dropped,
+			"metrics_per_second", s.rate)
+	}
+
+	batch.Metrics = batch.Metrics[:allowed]
+
+	return batch
+}
@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// budgetGatherer gathers high-priority collectors first and skips low-priority collectors
+// when that already took longer than the scrape budget, sparing a struggling SurrealDB
+// the most expensive queries. It is the surrealcollectors.ScrapeBudget of the collectors
+// reading INFO, which skip the table and index levels once the budget is used up.
+type budgetGatherer struct {
+	highPriority prometheus.Gatherer
+	lowPriority  map[string]*prometheus.Registry
+	budget       time.Duration
+
+	// start is the start of the running scrape in unix nanoseconds.
+	start atomic.Int64
+
+	skipped  *prometheus.CounterVec
+	internal *prometheus.Registry
+	logger   *slog.Logger
+}
+
+func newBudgetGatherer(budget time.Duration, constantLabels prometheus.Labels, logger *slog.Logger) *budgetGatherer {
+	skipped := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        "surrealdb_exporter_collector_skipped_total",
+			Help:        "Total number of scrapes in which a low-priority collector, or the table and index levels of the info collector, were skipped because the scrape budget was exceeded",
+			ConstLabels: constantLabels,
+		},
+		[]string{"collector"},
+	)
+
+	internal := prometheus.NewRegistry()
+	internal.MustRegister(skipped)
+
+	return &budgetGatherer{
+		budget:   budget,
+		skipped:  skipped,
+		internal: internal,
+		logger:   logger,
+	}
+}
+
+// setCollectors sets the collectors gathered, once they are registered.
+func (g *budgetGatherer) setCollectors(highPriority prometheus.Gatherer, lowPriority map[string]*prometheus.Registry) {
+	g.highPriority = highPriority
+	g.lowPriority = lowPriority
+
+	g.skipped.WithLabelValues(surrealcollectors.CollectorInfo)
+	for collector := range lowPriority {
+		g.skipped.WithLabelValues(collector)
+	}
+}
+
+// Deadline implements surrealcollectors.ScrapeBudget.
+func (g *budgetGatherer) Deadline() time.Time {
+	return time.Unix(0, g.start.Load()).Add(g.budget)
+}
+
+// Skipped implements surrealcollectors.ScrapeBudget.
+func (g *budgetGatherer) Skipped(collector string) {
+	g.logger.Warn("Scrape budget exceeded, skipping the table and index levels", "collector", collector, "budget", g.budget)
+	g.skipped.WithLabelValues(collector).Inc()
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *budgetGatherer) Gather() ([]*dto.MetricFamily, error) {
+	start := time.Now()
+	g.start.Store(start.UnixNano())
+
+	families, err := g.highPriority.Gather()
+	elapsed := time.Since(start)
+
+	gatherers := prometheus.Gatherers{
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return families, err }),
+	}
+
+	for collector, gatherer := range g.lowPriority {
+		if elapsed > g.budget {
+			g.logger.Warn("Scrape budget exceeded, skipping low-priority collector",
+				"collector", collector,
+				"elapsed", elapsed,
+				"budget", g.budget)
+			g.skipped.WithLabelValues(collector).Inc()
+			continue
+		}
+
+		gatherers = append(gatherers, gatherer)
+	}
+
+	return append(gatherers, g.internal).Gather()
+}
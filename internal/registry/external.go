@@ -0,0 +1,147 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// externalGatherer runs external collector commands concurrently on every gather and
+// merges the metrics they print, adding the constant labels they do not set themselves.
+// A failing command only drops its own metrics.
+type externalGatherer struct {
+	collectors     []domain.ExternalCollector
+	constantLabels prometheus.Labels
+
+	success  *prometheus.GaugeVec
+	duration *prometheus.GaugeVec
+	internal *prometheus.Registry
+}
+
+func newExternalGatherer(collectors []domain.ExternalCollector, constantLabels prometheus.Labels) *externalGatherer {
+	success := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        "surrealdb_exporter_external_collector_success",
+			Help:        "Whether the last run of an external collector succeeded",
+			ConstLabels: constantLabels,
+		},
+		[]string{"collector"},
+	)
+	duration := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        "surrealdb_exporter_external_collector_duration_seconds",
+			Help:        "Duration of the last run of an external collector in seconds",
+			ConstLabels: constantLabels,
+		},
+		[]string{"collector"},
+	)
+
+	internal := prometheus.NewRegistry()
+	internal.MustRegister(success, duration)
+
+	return &externalGatherer{
+		collectors:     collectors,
+		constantLabels: constantLabels,
+		success:        success,
+		duration:       duration,
+		internal:       internal,
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *externalGatherer) Gather() ([]*dto.MetricFamily, error) {
+	results := make([][]*dto.MetricFamily, len(g.collectors))
+
+	var wg sync.WaitGroup
+	for i, collector := range g.collectors {
+		wg.Go(func() {
+			start := time.Now()
+			families, err := g.run(collector)
+			g.duration.WithLabelValues(collector.Name).Set(time.Since(start).Seconds())
+
+			if err != nil {
+				slog.Warn("external collector failed", "collector", collector.Name, "error", err)
+				g.success.WithLabelValues(collector.Name).Set(0)
+				return
+			}
+
+			g.success.WithLabelValues(collector.Name).Set(1)
+			results[i] = families
+		})
+	}
+	wg.Wait()
+
+	gatherers := prometheus.Gatherers{g.internal}
+	for _, families := range results {
+		gatherers = append(gatherers, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return families, nil
+		}))
+	}
+
+	return gatherers.Gather()
+}
+
+// run executes an external collector and parses its output.
+func (g *externalGatherer) run(collector domain.ExternalCollector) ([]*dto.MetricFamily, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), collector.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, collector.Command[0], collector.Command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("command failed: %w: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	parsed, err := parser.TextToMetricFamilies(&stdout)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+
+	return withConstantLabels(parsed, g.constantLabels), nil
+}
+
+// withConstantLabels returns the parsed families with the constant labels added to
+// metrics that do not already have them.
+func withConstantLabels(parsed map[string]*dto.MetricFamily, constantLabels prometheus.Labels) []*dto.MetricFamily {
+	families := make([]*dto.MetricFamily, 0, len(parsed))
+	for _, family := range parsed {
+		for _, metric := range family.Metric {
+			addConstantLabels(metric, constantLabels)
+		}
+		families = append(families, family)
+	}
+
+	return families
+}
+
+// addConstantLabels adds the constant labels a metric does not already have.
+func addConstantLabels(metric *dto.Metric, constantLabels prometheus.Labels) {
+	present := make(map[string]struct{}, len(metric.Label))
+	for _, label := range metric.Label {
+		present[label.GetName()] = struct{}{}
+	}
+
+	for name, value := range constantLabels {
+		if _, ok := present[name]; !ok {
+			metric.Label = append(metric.Label, &dto.LabelPair{
+				Name:  proto.String(name),
+				Value: proto.String(value),
+			})
+		}
+	}
+}
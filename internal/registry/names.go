@@ -0,0 +1,35 @@
+package registry
+
+import (
+	"regexp"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// descNameRegex extracts the fully-qualified name from a descriptor's string form, as
+// prometheus.Desc does not expose it otherwise.
+var descNameRegex = regexp.MustCompile(`fqName: "([^"]*)"`)
+
+// MetricNames is the set of metric names described by the exporter's own collectors.
+type MetricNames map[string]struct{}
+
+// Contains reports whether name is described by an exporter collector.
+func (n MetricNames) Contains(name string) bool {
+	_, ok := n[name]
+	return ok
+}
+
+// add records the names of all descriptors of collector.
+func (n MetricNames) add(collector prometheus.Collector) {
+	ch := make(chan *prometheus.Desc)
+	go func() {
+		collector.Describe(ch)
+		close(ch)
+	}()
+
+	for desc := range ch {
+		if match := descNameRegex.FindStringSubmatch(desc.String()); match != nil {
+			n[match[1]] = struct{}{}
+		}
+	}
+}
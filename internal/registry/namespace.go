@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"fmt"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// NewNamespace builds the enabled namespace-scoped collectors for the metrics endpoint of
+// deps.Namespace, with the constant cluster labels. deps should carry an info reader and
+// a table cache covering only that namespace, so a slow or broken schema elsewhere does
+// not affect the endpoint. Series of other namespaces, e.g. live query notifications, are
+// dropped.
+func NewNamespace(cfg Config, deps surrealcollectors.Dependencies) (prometheus.Gatherer, error) {
+	registry := prometheus.NewRegistry()
+	constantLabels := constantLabelsFor(cfg)
+
+	for _, registration := range surrealcollectors.Registrations() {
+		if !registration.NamespaceScoped {
+			continue
+		}
+
+		if !registration.AlwaysEnabled && !cfg.CollectorEnabled(registration.Name) {
+			continue
+		}
+
+		collector := prometheus.WrapCollectorWith(constantLabels, pausable(deps, registration))
+		if err := registry.Register(collector); err != nil {
+			return nil, fmt.Errorf("failed to register %s collector for namespace %s: %w",
+				registration.Name, deps.Namespace, err)
+		}
+	}
+
+	return &namespaceGatherer{gatherer: registry, namespace: deps.Namespace}, nil
+}
+
+// namespaceGatherer drops the series whose namespace label names another namespace.
+type namespaceGatherer struct {
+	gatherer  prometheus.Gatherer
+	namespace string
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *namespaceGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		metrics := family.Metric[:0]
+		for _, metric := range family.Metric {
+			if g.inNamespace(metric) {
+				metrics = append(metrics, metric)
+			}
+		}
+
+		if len(metrics) == 0 {
+			continue
+		}
+
+		family.Metric = metrics
+		result = append(result, family)
+	}
+
+	return result, err
+}
+
+// inNamespace reports whether metric has no namespace label or one naming the namespace.
+func (g *namespaceGatherer) inNamespace(metric *dto.Metric) bool {
+	for _, pair := range metric.GetLabel() {
+		if pair.GetName() == "namespace" {
+			return pair.GetValue() == g.namespace
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+	"google.golang.org/protobuf/proto"
+)
+
+// proxyMaxResponseSize bounds the body read from the SurrealDB metrics endpoint.
+const proxyMaxResponseSize = 32 << 20
+
+// proxyGatherer scrapes the metrics endpoint of SurrealDB on every gather and merges its
+// metrics, prefixed and with the constant labels added, so Prometheus only needs to scrape
+// the exporter. A failed scrape only drops the proxied metrics.
+type proxyGatherer struct {
+	proxy          domain.MetricsProxy
+	constantLabels prometheus.Labels
+	client         *http.Client
+
+	success  prometheus.Gauge
+	duration prometheus.Gauge
+	internal *prometheus.Registry
+}
+
+func newProxyGatherer(proxy domain.MetricsProxy, constantLabels prometheus.Labels) *proxyGatherer {
+	success := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "surrealdb_exporter_proxy_success",
+			Help:        "Whether the last scrape of the SurrealDB metrics endpoint succeeded",
+			ConstLabels: constantLabels,
+		},
+	)
+	duration := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "surrealdb_exporter_proxy_duration_seconds",
+			Help:        "Duration of the last scrape of the SurrealDB metrics endpoint in seconds",
+			ConstLabels: constantLabels,
+		},
+	)
+
+	internal := prometheus.NewRegistry()
+	internal.MustRegister(success, duration)
+
+	return &proxyGatherer{
+		proxy:          proxy,
+		constantLabels: constantLabels,
+		client:         &http.Client{},
+		success:        success,
+		duration:       duration,
+		internal:       internal,
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *proxyGatherer) Gather() ([]*dto.MetricFamily, error) {
+	start := time.Now()
+	families, err := g.scrape()
+	g.duration.Set(time.Since(start).Seconds())
+
+	if err != nil {
+		slog.Warn("failed to scrape SurrealDB metrics", "url", g.proxy.URL, "error", err)
+		g.success.Set(0)
+		return g.internal.Gather()
+	}
+
+	g.success.Set(1)
+
+	return prometheus.Gatherers{
+		g.internal,
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return families, nil
+		}),
+	}.Gather()
+}
+
+// scrape fetches and parses the metrics endpoint.
+func (g *proxyGatherer) scrape() ([]*dto.MetricFamily, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), g.proxy.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.proxy.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.NewFormat(expfmt.TypeTextPlain)))
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	parsed, err := parser.TextToMetricFamilies(io.LimitReader(resp.Body, proxyMaxResponseSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	renamed := make(map[string]*dto.MetricFamily, len(parsed))
+	for _, family := range parsed {
+		name := g.metricName(family.GetName())
+		if existing, ok := renamed[name]; ok && existing.GetType() == family.GetType() {
+			// Names differing only in characters Prometheus does not allow end up the same.
+			existing.Metric = append(existing.Metric, family.Metric...)
+			continue
+		}
+
+		family.Name = proto.String(name)
+		renamed[name] = family
+	}
+
+	return withConstantLabels(renamed, g.constantLabels), nil
+}
+
+// metricName returns name with the characters Prometheus does not allow in metric names
+// replaced by underscores, prefixed with the configured prefix unless it already is.
+func (g *proxyGatherer) metricName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r == '_' || r == ':' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+
+	if !strings.HasPrefix(name, g.proxy.Prefix) {
+		name = g.proxy.Prefix + name
+	}
+
+	return name
+}
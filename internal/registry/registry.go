@@ -1,100 +1,123 @@
 package registry
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/logger"
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors"
 	"github.com/prometheus/client_golang/prometheus"
-	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 type Config interface {
-	RecordCountCollectorEnabled() bool
-	LiveQueryEnabled() bool
-	StatsTableEnabled() bool
-	StatsTableNamePrefix() string
-	GoCollectorEnabled() bool
-	ProcessCollectorEnabled() bool
+	CollectorEnabled(name string) bool
 	ClusterName() string
 	StorageEngine() string
 	DeploymentMode() string
+	ScrapeBudget() time.Duration
+	ExternalCollectors() []domain.ExternalCollector
+	TextfileDirectory() string
+	MetricsProxy() domain.MetricsProxy
+	LabelSanitization() domain.LabelSanitization
+	MetricRenames() domain.MetricRenames
+	TelemetryConstantLabels() bool
 }
 
+// New builds every enabled registered collector from deps and registers it with the
+// constant cluster labels. Low-priority collectors, and the table and index levels of the
+// info collector, are skipped once a configured scrape budget is used up, and the output
+// of external collectors, textfiles and the proxied SurrealDB metrics endpoint is merged
+// in. Telemetry collectors, which describe the
+// exporter itself, are returned in a separate gatherer, without the constant labels unless
+// configured otherwise.
 func New(
 	cfg Config,
-	versionReader surrealcollectors.VersionReader,
-	infoMetricsReader surrealcollectors.InfoMetricsReader,
-	recordCountReader surrealcollectors.RecordCountReader,
-	liveQueryProvider surrealcollectors.LiveQueryInfoProvider,
-	statsTableProvider surrealcollectors.StatsTableInfoProvider,
-	liveQueryFilter surrealcollectors.TableFilter,
-	statsTableFilter surrealcollectors.TableFilter,
-	recordCountFilter surrealcollectors.TableFilter,
-) (prometheus.Gatherer, error) {
+	deps surrealcollectors.Dependencies,
+) (metrics, telemetry prometheus.Gatherer, names MetricNames, err error) {
 	registry := prometheus.NewRegistry()
+	telemetryRegistry := prometheus.NewRegistry()
+	names = make(MetricNames)
 
-	constantLabels := prometheus.Labels{
-		"cluster":         cfg.ClusterName(),
-		"storage_engine":  cfg.StorageEngine(),
-		"deployment_mode": cfg.DeploymentMode(),
+	constantLabels := constantLabelsFor(cfg)
+
+	lowPriority := make(map[string]*prometheus.Registry)
+
+	var budget *budgetGatherer
+	if cfg.ScrapeBudget() > 0 {
+		budget = newBudgetGatherer(cfg.ScrapeBudget(), constantLabels, logger.Component("scrape_budget"))
+		deps.ScrapeBudget = budget
+	}
+
+	for _, registration := range surrealcollectors.Registrations() {
+		if !registration.AlwaysEnabled && !cfg.CollectorEnabled(registration.Name) {
+			continue
+		}
+
+		reg := registry
+		labels := constantLabels
+		switch {
+		case registration.Telemetry:
+			reg = telemetryRegistry
+			if !cfg.TelemetryConstantLabels() {
+				labels = nil
+			}
+		case registration.LowPriority && budget != nil:
+			reg = prometheus.NewRegistry()
+			lowPriority[registration.Name] = reg
+		}
+
+		collector := prometheus.WrapCollectorWith(labels, pausable(deps, registration))
+		names.add(collector)
+
+		if err := reg.Register(collector); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to register %s collector: %w", registration.Name, err)
+		}
 	}
 
-	prometheus.WrapCollectorWith(constantLabels, registry)
-
-	registry.MustRegister(
-		prometheus.WrapCollectorWith(
-			constantLabels,
-			surrealcollectors.NewInfoCollector(versionReader, infoMetricsReader),
-		),
-	)
-
-	if cfg.RecordCountCollectorEnabled() {
-		registry.MustRegister(
-			prometheus.WrapCollectorWith(
-				constantLabels,
-				surrealcollectors.NewRecordCountCollector(recordCountReader, recordCountFilter),
-			),
-		)
+	if deps.Pauses != nil {
+		if err := registry.Register(prometheus.WrapCollectorWith(constantLabels, deps.Pauses)); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to register collector pauses: %w", err)
+		}
 	}
 
-	if cfg.LiveQueryEnabled() {
-		registry.MustRegister(
-			prometheus.WrapCollectorWith(
-				constantLabels,
-				surrealcollectors.NewLiveQueryCollector(liveQueryProvider, liveQueryFilter),
-			),
-		)
+	var gatherer prometheus.Gatherer = registry
+	if budget != nil {
+		budget.setCollectors(registry, lowPriority)
+		gatherer = budget
 	}
 
-	if cfg.StatsTableEnabled() {
-		registry.MustRegister(
-			prometheus.WrapCollectorWith(
-				constantLabels,
-				surrealcollectors.NewStatsTableCollector(
-					statsTableProvider,
-					statsTableFilter,
-					cfg.StatsTableNamePrefix(),
-				),
-			),
-		)
+	if external := cfg.ExternalCollectors(); len(external) > 0 {
+		gatherer = prometheus.Gatherers{gatherer, newExternalGatherer(external, constantLabels)}
 	}
 
-	if cfg.GoCollectorEnabled() {
-		registry.MustRegister(prometheus.WrapCollectorWith(constantLabels, collectors.NewBuildInfoCollector()))
-		registry.MustRegister(
-			prometheus.WrapCollectorWith(
-				constantLabels,
-				collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll)),
-			),
-		)
+	if directory := cfg.TextfileDirectory(); directory != "" {
+		gatherer = prometheus.Gatherers{gatherer, newTextfileGatherer(directory, constantLabels)}
 	}
 
-	if cfg.ProcessCollectorEnabled() {
-		registry.MustRegister(
-			prometheus.WrapCollectorWith(
-				constantLabels,
-				collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
-			),
-		)
+	if proxy := cfg.MetricsProxy(); proxy.URL != "" {
+		gatherer = prometheus.Gatherers{gatherer, newProxyGatherer(proxy, constantLabels)}
 	}
 
-	return registry, nil
+	return gatherer, telemetryRegistry, names, nil
+}
+
+// pausable builds the collector of registration, pausable at runtime through deps.Pauses
+// unless it is always enabled or a telemetry collector.
+func pausable(deps surrealcollectors.Dependencies, registration surrealcollectors.Registration) prometheus.Collector {
+	collector := registration.Factory(deps)
+	if deps.Pauses == nil || registration.AlwaysEnabled || registration.Telemetry {
+		return collector
+	}
+
+	return deps.Pauses.Wrap(registration.Name, collector)
+}
+
+// constantLabelsFor returns the labels added to every exporter metric.
+func constantLabelsFor(cfg Config) prometheus.Labels {
+	return prometheus.Labels{
+		"cluster":         cfg.ClusterName(),
+		"storage_engine":  cfg.StorageEngine(),
+		"deployment_mode": cfg.DeploymentMode(),
+	}
 }
@@ -0,0 +1,99 @@
+package registry
+
+import (
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// renamer exports gathered metrics and labels under the configured names, so dashboards
+// built for an earlier exporter keep working while they are migrated.
+type renamer struct {
+	gatherer prometheus.Gatherer
+	renames  domain.MetricRenames
+}
+
+// WithRenames wraps gatherer to rename metrics and labels as configured. gatherer is
+// returned unchanged when nothing is renamed.
+func WithRenames(cfg Config, gatherer prometheus.Gatherer) prometheus.Gatherer {
+	renames := cfg.MetricRenames()
+	if len(renames.Metrics) == 0 && len(renames.Labels) == 0 {
+		return gatherer
+	}
+
+	return &renamer{gatherer: gatherer, renames: renames}
+}
+
+// Gather implements prometheus.Gatherer. Families renamed to the same name are merged.
+func (r *renamer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := r.gatherer.Gather()
+
+	result := make([]*dto.MetricFamily, 0, len(families))
+	for _, family := range families {
+		name, renamed := r.renames.Metrics[family.GetName()]
+		if !renamed {
+			name = family.GetName()
+		}
+
+		if r.renames.KeepOriginal {
+			result = append(result, family)
+			if renamed {
+				result = append(result, r.rename(family, name))
+			}
+			continue
+		}
+
+		result = append(result, r.rename(family, name))
+	}
+
+	return prometheus.Gatherers{
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return result, err }),
+	}.Gather()
+}
+
+// rename returns a copy of family named name with the labels of its metrics renamed.
+// Collectors share metrics between gathers, so they are copied instead of modified.
+func (r *renamer) rename(family *dto.MetricFamily, name string) *dto.MetricFamily {
+	metrics := make([]*dto.Metric, 0, len(family.Metric))
+	for _, metric := range family.Metric {
+		metrics = append(metrics, &dto.Metric{
+			Label:       r.renameLabels(metric.Label),
+			Gauge:       metric.Gauge,
+			Counter:     metric.Counter,
+			Summary:     metric.Summary,
+			Untyped:     metric.Untyped,
+			Histogram:   metric.Histogram,
+			TimestampMs: metric.TimestampMs,
+		})
+	}
+
+	return &dto.MetricFamily{
+		Name:   &name,
+		Help:   family.Help,
+		Type:   family.Type,
+		Unit:   family.Unit,
+		Metric: metrics,
+	}
+}
+
+// renameLabels returns labels with the configured names. A label is kept under its own
+// name when the metric already has a label of the new name.
+func (r *renamer) renameLabels(labels []*dto.LabelPair) []*dto.LabelPair {
+	present := make(map[string]struct{}, len(labels))
+	for _, pair := range labels {
+		present[pair.GetName()] = struct{}{}
+	}
+
+	result := make([]*dto.LabelPair, 0, len(labels))
+	for _, pair := range labels {
+		name, ok := r.renames.Labels[pair.GetName()]
+		if _, taken := present[name]; !ok || taken {
+			result = append(result, pair)
+			continue
+		}
+
+		result = append(result, &dto.LabelPair{Name: &name, Value: pair.Value})
+	}
+
+	return result
+}
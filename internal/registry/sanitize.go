@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// hashSuffixLength is the length of the "_" separated hash appended to shortened or
+// colliding label values.
+const hashSuffixLength = 9
+
+// labelSanitizer rewrites the values of the configured labels of every gathered metric
+// and exposes surrealdb_exporter_label_mapping for each rewritten value. Rewritten values
+// that collide with another value of the same label in a gather get a hash of their
+// original appended, so distinct identifiers never merge into one series and the result
+// does not depend on the order values are seen in.
+type labelSanitizer struct {
+	gatherer    prometheus.Gatherer
+	settings    domain.LabelSanitization
+	constLabels prometheus.Labels
+}
+
+// WithLabelSanitization wraps gatherer to sanitize label values as configured. gatherer
+// is returned unchanged when sanitization is disabled.
+func WithLabelSanitization(cfg Config, gatherer prometheus.Gatherer) prometheus.Gatherer {
+	settings := cfg.LabelSanitization()
+	if !settings.Enabled {
+		return gatherer
+	}
+
+	return &labelSanitizer{
+		gatherer:    gatherer,
+		settings:    settings,
+		constLabels: constantLabelsFor(cfg),
+	}
+}
+
+// Gather implements prometheus.Gatherer. The sanitized values and the mapping are built
+// from the values of this gather only, so they are bounded by the series exported.
+func (s *labelSanitizer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := s.gatherer.Gather()
+
+	values := s.sanitizedValues(families)
+
+	mapping := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "surrealdb_exporter_label_mapping",
+		Help:        "Maps a sanitized label value to the original SurrealDB identifier",
+		ConstLabels: s.constLabels,
+	}, []string{"label", "sanitized", "original"})
+
+	for label, originals := range values {
+		for original, value := range originals {
+			if value != original {
+				mapping.WithLabelValues(label, value, original).Set(1)
+			}
+		}
+	}
+
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			metric.Label = sanitizeLabels(metric.Label, values)
+		}
+	}
+
+	internal := prometheus.NewRegistry()
+	internal.MustRegister(mapping)
+
+	gatherers := prometheus.Gatherers{
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return families, err }),
+		internal,
+	}
+
+	return gatherers.Gather()
+}
+
+// sanitizedValues returns the sanitized value of every value of the configured labels in
+// families, keyed by label and original value. A rewritten value colliding with another
+// value of its label gets a hash of its original appended; values left unchanged keep
+// their value, as at most one original can equal a sanitized value.
+func (s *labelSanitizer) sanitizedValues(families []*dto.MetricFamily) map[string]map[string]string {
+	values := make(map[string]map[string]string)
+
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			for _, pair := range metric.Label {
+				label := pair.GetName()
+				if !slices.Contains(s.settings.Labels, label) {
+					continue
+				}
+
+				if values[label] == nil {
+					values[label] = make(map[string]string)
+				}
+
+				original := pair.GetValue()
+				if _, ok := values[label][original]; !ok {
+					values[label][original] = sanitizeLabelValue(original, s.settings.MaxLength, s.settings.HashSuffix)
+				}
+			}
+		}
+	}
+
+	for _, originals := range values {
+		count := make(map[string]int, len(originals))
+		for _, value := range originals {
+			count[value]++
+		}
+
+		for original, value := range originals {
+			if value != original && count[value] > 1 {
+				originals[original] = withHashSuffix(value, original, s.settings.MaxLength)
+			}
+		}
+	}
+
+	return values
+}
+
+// sanitizeLabels returns labels with the values of the configured labels replaced by
+// their sanitized values. Collectors share label pairs between gathers, so they are
+// copied instead of modified.
+func sanitizeLabels(labels []*dto.LabelPair, values map[string]map[string]string) []*dto.LabelPair {
+	var result []*dto.LabelPair
+
+	for i, pair := range labels {
+		original := pair.GetValue()
+		value, ok := values[pair.GetName()][original]
+		if !ok || value == original {
+			continue
+		}
+
+		if result == nil {
+			result = slices.Clone(labels)
+		}
+		result[i] = &dto.LabelPair{Name: pair.Name, Value: &value}
+	}
+
+	if result == nil {
+		return labels
+	}
+
+	return result
+}
+
+// sanitizeLabelValue replaces characters outside [a-zA-Z0-9_.:-] with underscores and
+// truncates the result to maxLength bytes, replacing its end with a hash of value when
+// hashSuffix is set.
+func sanitizeLabelValue(value string, maxLength int, hashSuffix bool) string {
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		case r == '_', r == '.', r == ':', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, value)
+
+	if len(sanitized) <= maxLength {
+		return sanitized
+	}
+
+	if hashSuffix {
+		return withHashSuffix(sanitized, value, maxLength)
+	}
+
+	return sanitized[:maxLength]
+}
+
+// withHashSuffix appends a hash of original to value, shortening value so the result
+// fits into maxLength bytes.
+func withHashSuffix(value, original string, maxLength int) string {
+	h := fnv.New32a()
+	h.Write([]byte(original))
+
+	return value[:min(len(value), maxLength-hashSuffixLength)] + fmt.Sprintf("_%08x", h.Sum32())
+}
@@ -0,0 +1,167 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// scrapeStatsGatherer adds the number of series and the size of the text exposition of
+// each gather, so exposition growth and cardinality explosions show up in the metrics
+// themselves. The statistics do not count their own series. HTTP handlers report the
+// size of the responses they actually sent through ObserveResponse.
+type scrapeStatsGatherer struct {
+	gatherer prometheus.Gatherer
+
+	series       prometheus.Gauge
+	size         prometheus.Gauge
+	responseSize *prometheus.GaugeVec
+	internal     *prometheus.Registry
+
+	mu   sync.Mutex
+	last *domain.ScrapeStatus
+}
+
+// WithScrapeStats wraps gatherer to also expose surrealdb_exporter_scrape_series,
+// surrealdb_exporter_scrape_size_bytes and surrealdb_exporter_scrape_response_size_bytes.
+func WithScrapeStats(cfg Config, gatherer prometheus.Gatherer) prometheus.Gatherer {
+	constantLabels := constantLabelsFor(cfg)
+
+	series := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "surrealdb_exporter_scrape_series",
+		Help:        "Number of series exposed by the last scrape",
+		ConstLabels: constantLabels,
+	})
+	size := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name:        "surrealdb_exporter_scrape_size_bytes",
+		Help:        "Size in bytes of the text exposition of the last scrape",
+		ConstLabels: constantLabels,
+	})
+
+	responseSize := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:        "surrealdb_exporter_scrape_response_size_bytes",
+		Help:        "Size in bytes of the last metrics response sent over HTTP by content encoding",
+		ConstLabels: constantLabels,
+	}, []string{"encoding"})
+
+	internal := prometheus.NewRegistry()
+	internal.MustRegister(series, size, responseSize)
+
+	return &scrapeStatsGatherer{
+		gatherer:     gatherer,
+		series:       series,
+		size:         size,
+		responseSize: responseSize,
+		internal:     internal,
+	}
+}
+
+// ObserveResponse records the size of a metrics response sent with encoding.
+func (g *scrapeStatsGatherer) ObserveResponse(encoding string, size int) {
+	g.responseSize.WithLabelValues(encoding).Set(float64(size))
+}
+
+// LastScrape returns the last gather, false before the first.
+func (g *scrapeStatsGatherer) LastScrape() (domain.ScrapeStatus, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.last == nil {
+		return domain.ScrapeStatus{}, false
+	}
+
+	return *g.last, true
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *scrapeStatsGatherer) Gather() ([]*dto.MetricFamily, error) {
+	start := time.Now()
+	families, err := g.gatherer.Gather()
+	duration := time.Since(start)
+
+	var counter countingWriter
+	encoder := expfmt.NewEncoder(&counter, expfmt.NewFormat(expfmt.TypeTextPlain))
+
+	series := 0
+	for _, family := range families {
+		series += familySeries(family)
+		if encodeErr := encoder.Encode(family); encodeErr != nil {
+			// The gather error, if any, is kept alongside the encode error.
+			err = errors.Join(err, fmt.Errorf("failed to encode metric family %s: %w", family.GetName(), encodeErr))
+			g.record(domain.ScrapeStatus{StartedAt: start, DurationSeconds: duration.Seconds()}, err)
+			return families, err
+		}
+	}
+
+	g.series.Set(float64(series))
+	g.size.Set(float64(counter))
+	g.record(domain.ScrapeStatus{
+		StartedAt:       start,
+		DurationSeconds: duration.Seconds(),
+		Series:          series,
+		SizeBytes:       int(counter),
+	}, err)
+
+	gatherers := prometheus.Gatherers{
+		prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) { return families, err }),
+		g.internal,
+	}
+
+	return gatherers.Gather()
+}
+
+// record stores the last gather.
+func (g *scrapeStatsGatherer) record(status domain.ScrapeStatus, err error) {
+	if err != nil {
+		status.Error = err.Error()
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.last = &status
+}
+
+// familySeries returns the number of series of a metric family. Histograms and
+// summaries have a series per bucket or quantile besides their count and sum.
+func familySeries(family *dto.MetricFamily) int {
+	series := 0
+	for _, metric := range family.Metric {
+		switch {
+		case metric.Histogram != nil:
+			series += len(metric.Histogram.Bucket) + 2
+			if !hasInfBucket(metric.Histogram) {
+				series++
+			}
+		case metric.Summary != nil:
+			series += len(metric.Summary.Quantile) + 2
+		default:
+			series++
+		}
+	}
+
+	return series
+}
+
+// hasInfBucket reports whether a histogram lists its +Inf bucket explicitly.
+func hasInfBucket(histogram *dto.Histogram) bool {
+	buckets := histogram.Bucket
+	return len(buckets) > 0 && math.IsInf(buckets[len(buckets)-1].GetUpperBound(), 1)
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter int
+
+// Write implements io.Writer.
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
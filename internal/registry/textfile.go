@@ -0,0 +1,96 @@
+package registry
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// textfileGatherer exposes the metrics of *.prom files in a directory, written by
+// sidecars such as backup or migration jobs, with the constant labels added. Files are
+// read on every gather; an unreadable file only drops its own metrics. The textfile
+// metrics are built anew on every gather, so concurrent gathers do not share them.
+type textfileGatherer struct {
+	directory      string
+	constantLabels prometheus.Labels
+}
+
+func newTextfileGatherer(directory string, constantLabels prometheus.Labels) *textfileGatherer {
+	return &textfileGatherer{
+		directory:      directory,
+		constantLabels: constantLabels,
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (g *textfileGatherer) Gather() ([]*dto.MetricFamily, error) {
+	paths, err := filepath.Glob(filepath.Join(g.directory, "*.prom"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list textfile directory: %w", err)
+	}
+
+	mtime := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        "surrealdb_exporter_textfile_mtime_seconds",
+			Help:        "Unix time of the last modification of a textfile collector file",
+			ConstLabels: g.constantLabels,
+		},
+		[]string{"file"},
+	)
+	scrapeError := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name:        "surrealdb_exporter_textfile_scrape_error",
+			Help:        "1 if reading a textfile collector file failed in the last scrape, 0 otherwise",
+			ConstLabels: g.constantLabels,
+		},
+	)
+
+	internal := prometheus.NewRegistry()
+	internal.MustRegister(mtime, scrapeError)
+
+	gatherers := prometheus.Gatherers{internal}
+	for _, path := range paths {
+		families, modified, err := g.read(path)
+		if err != nil {
+			slog.Warn("failed to read textfile", "file", path, "error", err)
+			scrapeError.Set(1)
+			continue
+		}
+
+		mtime.WithLabelValues(filepath.Base(path)).Set(float64(modified.UnixNano()) / 1e9)
+		gatherers = append(gatherers, prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+			return families, nil
+		}))
+	}
+
+	return gatherers.Gather()
+}
+
+// read parses a textfile and returns its metrics and modification time.
+func (g *textfileGatherer) read(path string) ([]*dto.MetricFamily, time.Time, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	parsed, err := parser.TextToMetricFamilies(file)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("invalid content: %w", err)
+	}
+
+	return withConstantLabels(parsed, g.constantLabels), info.ModTime(), nil
+}
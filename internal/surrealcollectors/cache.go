@@ -1,62 +1,142 @@
 package surrealcollectors
 
 import (
+	"context"
+	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 )
 
-type tableInfoCache struct {
-	mu     sync.RWMutex
-	tables []*domain.TableInfo
+// TableCache holds the tables discovered by the info collector for the collectors that
+// monitor individual tables (live_query, stats_table, record_count). Each registry gets
+// its own cache through Dependencies.
+type TableCache interface {
+	// Tables returns the cached tables. The returned slice may be modified by the caller.
+	Tables() []*domain.TableInfo
+	// SetTables replaces the cached tables.
+	SetTables(tables []*domain.TableInfo)
 }
 
-var (
-	instance *tableInfoCache
-	once     sync.Once
-)
+type tableInfoCache struct {
+	mu       sync.RWMutex
+	tables   []*domain.TableInfo
+	ttl      time.Duration
+	storedAt time.Time
+}
 
-// getTableInfoCache returns the singleton instance of tableInfoCache.
-func getTableInfoCache() *tableInfoCache {
-	once.Do(func() {
-		instance = &tableInfoCache{
-			tables: make([]*domain.TableInfo, 0),
-		}
-	})
+// NewTableCache creates a table cache that keeps the tables until they are replaced.
+func NewTableCache() TableCache {
+	return &tableInfoCache{
+		tables: make([]*domain.TableInfo, 0),
+	}
+}
 
-	return instance
+// NewTTLTableCache creates a table cache that forgets the tables ttl after they were set,
+// so table collectors stop exporting tables that were not seen recently, e.g. while info
+// scrapes fail.
+func NewTTLTableCache(ttl time.Duration) TableCache {
+	return &tableInfoCache{
+		tables: make([]*domain.TableInfo, 0),
+		ttl:    ttl,
+	}
 }
 
-// set stores the table information in the cache.
-func (c *tableInfoCache) set(tables []*domain.TableInfo) {
+// SetTables implements TableCache.
+func (c *tableInfoCache) SetTables(tables []*domain.TableInfo) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.tables = tables
+	c.storedAt = time.Now()
+}
+
+// UpdatedAt returns when the tables were last set, the zero time when they never were.
+func (c *tableInfoCache) UpdatedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.storedAt
 }
 
-// get retrieves the table information from the cache.
-func (c *tableInfoCache) get() []*domain.TableInfo {
+// Tables implements TableCache.
+func (c *tableInfoCache) Tables() []*domain.TableInfo {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
+	if c.ttl > 0 && time.Since(c.storedAt) > c.ttl {
+		return []*domain.TableInfo{}
+	}
+
 	result := make([]*domain.TableInfo, len(c.tables))
 	copy(result, c.tables)
 	return result
 }
 
-// clear removes all table information from the cache.
-func (c *tableInfoCache) clear() {
+// uncachedTables reads the tables from the info reader on every call instead of caching them.
+type uncachedTables struct {
+	reader  InfoMetricsReader
+	timeout time.Duration
+}
+
+// NewUncachedTableCache creates a TableCache that ignores SetTables and fetches the tables
+// with reader whenever they are requested, trading extra INFO queries for tables that are
+// never stale.
+func NewUncachedTableCache(reader InfoMetricsReader, timeout time.Duration) TableCache {
+	return &uncachedTables{reader: reader, timeout: timeout}
+}
+
+// SetTables implements TableCache.
+func (u *uncachedTables) SetTables([]*domain.TableInfo) {}
+
+// Tables implements TableCache.
+func (u *uncachedTables) Tables() []*domain.TableInfo {
+	ctx, cancel := context.WithTimeout(context.Background(), u.timeout)
+	defer cancel()
+
+	info, err := u.reader.Info(ctx)
+	if err != nil {
+		slog.Error("Failed to fetch tables", "error", err)
+		return []*domain.TableInfo{}
+	}
+
+	return info.AllTables()
+}
+
+// RecordCountCache holds the record counts last exported by the record_count collector,
+// so other collectors can use them without counting again. Each registry gets its own
+// cache through Dependencies.
+type RecordCountCache interface {
+	// RecordCounts returns the cached counts.
+	RecordCounts() []*domain.TableRecordCount
+	// SetRecordCounts replaces the cached counts.
+	SetRecordCounts(counts []*domain.TableRecordCount)
+}
+
+type recordCountCache struct {
+	mu     sync.RWMutex
+	counts []*domain.TableRecordCount
+}
+
+// NewRecordCountCache creates a record count cache that keeps the counts until they are
+// replaced.
+func NewRecordCountCache() RecordCountCache {
+	return &recordCountCache{}
+}
+
+// SetRecordCounts implements RecordCountCache.
+func (c *recordCountCache) SetRecordCounts(counts []*domain.TableRecordCount) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.tables = make([]*domain.TableInfo, 0)
+	c.counts = counts
 }
 
-// PrewarmTableCache pre-populates the table cache with table information.
-// This should be called at startup before the HTTP server starts to avoid
-// race conditions between collectors that depend on the cache.
-func PrewarmTableCache(tables []*domain.TableInfo) {
-	cache := getTableInfoCache()
-	cache.set(tables)
+// RecordCounts implements RecordCountCache.
+func (c *recordCountCache) RecordCounts() []*domain.TableRecordCount {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.counts
 }
@@ -0,0 +1,81 @@
+package surrealcollectors
+
+import (
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	Register(Registration{
+		Name:          CollectorConnections,
+		AlwaysEnabled: true,
+		Telemetry:     true,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			if deps.ConnectionStatsProvider == nil {
+				return collectorGroup{}
+			}
+
+			return NewConnectionsCollector(deps.ConnectionStatsProvider)
+		},
+	})
+}
+
+// ConnectionStatsProvider counts the SurrealDB connections of the exporter.
+type ConnectionStatsProvider interface {
+	ConnectionStats() domain.ConnectionStats
+}
+
+// ConnectionsCollector exports the number of open SurrealDB connections and how many were
+// opened and closed.
+type ConnectionsCollector struct {
+	provider ConnectionStatsProvider
+
+	openDesc   *prometheus.Desc
+	openedDesc *prometheus.Desc
+	closedDesc *prometheus.Desc
+}
+
+// NewConnectionsCollector creates the connections collector.
+func NewConnectionsCollector(provider ConnectionStatsProvider) *ConnectionsCollector {
+	return &ConnectionsCollector{
+		provider: provider,
+
+		openDesc: prometheus.NewDesc(
+			"surrealdb_exporter_connections_open",
+			"SurrealDB connections currently open",
+			nil,
+			nil,
+		),
+
+		openedDesc: prometheus.NewDesc(
+			"surrealdb_exporter_connections_opened_total",
+			"SurrealDB connections opened since startup",
+			nil,
+			nil,
+		),
+
+		closedDesc: prometheus.NewDesc(
+			"surrealdb_exporter_connections_closed_total",
+			"SurrealDB connections closed since startup, by reason: idle (reaped) or broken (replaced after the socket closed)",
+			[]string{"reason"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *ConnectionsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.openDesc
+	ch <- c.openedDesc
+	ch <- c.closedDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *ConnectionsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.provider.ConnectionStats()
+
+	ch <- prometheus.MustNewConstMetric(c.openDesc, prometheus.GaugeValue, float64(stats.Open))
+	ch <- prometheus.MustNewConstMetric(c.openedDesc, prometheus.CounterValue, float64(stats.Opened))
+	ch <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue, float64(stats.ClosedIdle), "idle")
+	ch <- prometheus.MustNewConstMetric(c.closedDesc, prometheus.CounterValue, float64(stats.ClosedBroken), "broken")
+}
@@ -0,0 +1,212 @@
+package surrealcollectors
+
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const SubsystemConsistency = "consistency"
+
+func init() {
+	Register(Registration{
+		Name: CollectorConsistencyAudit,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			return NewConsistencyAuditCollector(
+				deps.LiveQueryProvider,
+				deps.StatsTableProvider,
+				deps.LiveQueryFilter,
+				deps.StatsTableFilter,
+				deps.TableCache,
+				deps.Config.StatsTableNamePrefix(),
+			)
+		},
+		NamespaceScoped: true,
+	})
+}
+
+// operationCounts holds the creates, updates and deletes of a table over all operation
+// types.
+type operationCounts struct {
+	creates, updates, deletes int64
+}
+
+// auditBaseline holds the counts of both sources when a table was first audited. The
+// sources count from different starts, the live queries since the exporter started and
+// the stats table since it was created, so only the operations after the baseline are
+// compared.
+type auditBaseline struct {
+	live           operationCounts
+	stats          operationCounts
+	statsCreatedAt time.Time
+	since          time.Time
+}
+
+// ConsistencyAuditCollector compares the operations counted by live queries with the
+// operations counted by stats tables for the tables both track. A divergence that keeps
+// growing points at dropped live notifications or a stats event that stopped firing.
+type ConsistencyAuditCollector struct {
+	liveQueryProvider  LiveQueryInfoProvider
+	statsTableProvider StatsTableInfoProvider
+	liveQueryFilter    TableFilter
+	statsTableFilter   TableFilter
+	tableCache         TableCache
+	statsTablePrefix   string
+
+	mu        sync.Mutex
+	baselines map[string]auditBaseline
+
+	divergenceDesc *prometheus.Desc
+	sinceDesc      *prometheus.Desc
+}
+
+// NewConsistencyAuditCollector creates a collector auditing the live_query collector
+// against the stats_table collector.
+func NewConsistencyAuditCollector(
+	liveQueryProvider LiveQueryInfoProvider,
+	statsTableProvider StatsTableInfoProvider,
+	liveQueryFilter TableFilter,
+	statsTableFilter TableFilter,
+	tableCache TableCache,
+	statsTablePrefix string,
+) *ConsistencyAuditCollector {
+	return &ConsistencyAuditCollector{
+		liveQueryProvider:  liveQueryProvider,
+		statsTableProvider: statsTableProvider,
+		liveQueryFilter:    liveQueryFilter,
+		statsTableFilter:   statsTableFilter,
+		tableCache:         tableCache,
+		statsTablePrefix:   statsTablePrefix,
+		baselines:          make(map[string]auditBaseline),
+
+		divergenceDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemConsistency, "divergence"),
+			"Operations counted by the stats table minus operations counted by live queries since the audit baseline",
+			[]string{"namespace", "database", "table", "operation"},
+			nil,
+		),
+		sinceDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemConsistency, "baseline_timestamp_seconds"),
+			"Unix timestamp of the audit baseline, reset when the stats table is recreated",
+			[]string{"namespace", "database", "table"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *ConsistencyAuditCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.divergenceDesc
+	ch <- c.sinceDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *ConsistencyAuditCollector) Collect(ch chan<- prometheus.Metric) {
+	_, span := startCollectSpan("consistency_audit")
+	defer span.End()
+
+	tableIDs := c.auditedTables()
+	if len(tableIDs) == 0 {
+		return
+	}
+
+	statsData, err := c.statsTableProvider.StatsTableInfo(tableIDs)
+	if err != nil {
+		slog.Error("Failed to get stats table counts for the consistency audit", "error", err)
+		return
+	}
+
+	live := make(map[string]operationCounts)
+	for _, m := range c.liveQueryProvider.LiveQueryTotals() {
+		key := domain.TableIdentifier{Namespace: m.Namespace, Database: m.Database, Table: m.Table}.String()
+		counts := live[key]
+		counts.creates += m.Creates
+		counts.updates += m.Updates
+		counts.deletes += m.Deletes
+		live[key] = counts
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	audited := make(map[string]struct{}, len(statsData))
+	now := time.Now()
+	for _, data := range statsData {
+		key := domain.TableIdentifier{Namespace: data.Namespace, Database: data.Database, Table: data.Table}.String()
+		audited[key] = struct{}{}
+
+		stats := statsTableCounts(data)
+		baseline, ok := c.baselines[key]
+		if !ok || baseline.reset(live[key], stats, data.CreatedAt) {
+			baseline = auditBaseline{live: live[key], stats: stats, statsCreatedAt: data.CreatedAt, since: now}
+			c.baselines[key] = baseline
+		}
+
+		divergences := []struct {
+			operation string
+			value     int64
+		}{
+			{"create", (stats.creates - baseline.stats.creates) - (live[key].creates - baseline.live.creates)},
+			{"update", (stats.updates - baseline.stats.updates) - (live[key].updates - baseline.live.updates)},
+			{"delete", (stats.deletes - baseline.stats.deletes) - (live[key].deletes - baseline.live.deletes)},
+		}
+		for _, d := range divergences {
+			ch <- prometheus.MustNewConstMetric(c.divergenceDesc, prometheus.GaugeValue, float64(d.value),
+				data.Namespace, data.Database, data.Table, d.operation)
+		}
+
+		ch <- prometheus.MustNewConstMetric(c.sinceDesc, prometheus.GaugeValue, float64(baseline.since.Unix()),
+			data.Namespace, data.Database, data.Table)
+	}
+
+	for key := range c.baselines {
+		if _, ok := audited[key]; !ok {
+			delete(c.baselines, key)
+		}
+	}
+}
+
+// auditedTables returns the tables tracked by both the live queries and the stats tables.
+func (c *ConsistencyAuditCollector) auditedTables() []domain.TableIdentifier {
+	var tables []*domain.TableInfo
+	for _, table := range c.tableCache.Tables() {
+		if !strings.HasPrefix(table.Name, c.statsTablePrefix) {
+			tables = append(tables, table)
+		}
+	}
+
+	live := make(map[domain.TableIdentifier]struct{})
+	for _, id := range c.liveQueryFilter.FilterTables(tables) {
+		live[id] = struct{}{}
+	}
+
+	var both []domain.TableIdentifier
+	for _, id := range c.statsTableFilter.FilterTables(tables) {
+		if _, ok := live[id]; ok {
+			both = append(both, id)
+		}
+	}
+
+	return both
+}
+
+// reset reports whether the baseline no longer applies: the stats table was recreated,
+// or either count went down.
+func (b auditBaseline) reset(live, stats operationCounts, statsCreatedAt time.Time) bool {
+	return !statsCreatedAt.Equal(b.statsCreatedAt) ||
+		live.creates < b.live.creates || live.updates < b.live.updates || live.deletes < b.live.deletes ||
+		stats.creates < b.stats.creates || stats.updates < b.stats.updates || stats.deletes < b.stats.deletes
+}
+
+// statsTableCounts sums the operations of a stats table over all operation types.
+func statsTableCounts(data *domain.StatsTableData) operationCounts {
+	return operationCounts{
+		creates: data.CreateRelational + data.CreateKV + data.CreateGraph + data.CreateDocument,
+		updates: data.UpdateRelational + data.UpdateKV + data.UpdateGraph + data.UpdateDocument,
+		deletes: data.DeleteRelational + data.DeleteKV + data.DeleteGraph + data.DeleteDocument,
+	}
+}
@@ -0,0 +1,199 @@
+package surrealcollectors
+
+import (
+	"log/slog"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const SubsystemDerived = "derived"
+
+func init() {
+	Register(Registration{
+		Name: CollectorDerived,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			collector := NewDerivedCollector(deps.InfoMetricsReader, deps.RecordCounts, deps.Config.DerivedMetrics())
+			collector.budget = deps.ScrapeBudget
+			return collector
+		},
+		NamespaceScoped: true,
+	})
+}
+
+// DerivedCollector exports boolean gauges for schema conditions worth alerting on, so
+// alert rules do not need to combine several series: empty databases, large tables
+// without indexes, indexes building for too long and namespaces without users.
+type DerivedCollector struct {
+	infoMetricsReader InfoMetricsReader
+	recordCounts      RecordCountCache
+	settings          domain.DerivedMetrics
+
+	// budget shares the schema deadline of the info collector, so both share one INFO
+	// fetch, nil never skips the table and index levels.
+	budget ScrapeBudget
+
+	// buildingSince holds when each building index was first seen building.
+	mu            sync.Mutex
+	buildingSince map[string]time.Time
+
+	databaseEmptyDesc         *prometheus.Desc
+	tableUnindexedDesc        *prometheus.Desc
+	indexBuildingStuckDesc    *prometheus.Desc
+	namespaceWithoutUsersDesc *prometheus.Desc
+}
+
+// NewDerivedCollector creates a derived metrics collector. Large tables without indexes
+// are only reported with recordCounts, filled by the record_count collector.
+func NewDerivedCollector(
+	infoMetricsReader InfoMetricsReader,
+	recordCounts RecordCountCache,
+	settings domain.DerivedMetrics,
+) *DerivedCollector {
+	return &DerivedCollector{
+		infoMetricsReader: infoMetricsReader,
+		recordCounts:      recordCounts,
+		settings:          settings,
+		buildingSince:     make(map[string]time.Time),
+		databaseEmptyDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemDerived, "database_empty"),
+			"1 if the database defines no tables, 0 otherwise",
+			[]string{"namespace", "database"},
+			nil,
+		),
+		tableUnindexedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemDerived, "table_unindexed_large"),
+			"1 if the table defines no indexes and holds more records than the configured threshold, 0 otherwise",
+			[]string{"namespace", "database", "table"},
+			nil,
+		),
+		indexBuildingStuckDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemDerived, "index_building_stuck"),
+			"1 if the index has been seen building for longer than the configured duration, 0 otherwise",
+			[]string{"namespace", "database", "table", "index"},
+			nil,
+		),
+		namespaceWithoutUsersDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemDerived, "namespace_without_users"),
+			"1 if the namespace defines no users, 0 otherwise",
+			[]string{"namespace"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *DerivedCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.databaseEmptyDesc
+	ch <- c.tableUnindexedDesc
+	ch <- c.indexBuildingStuckDesc
+	ch <- c.namespaceWithoutUsersDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *DerivedCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, span := startCollectSpan("derived")
+	defer span.End()
+
+	info, err := c.infoMetricsReader.Info(withSchemaDeadline(ctx, c.budget))
+	if err != nil {
+		slog.Error("DerivedCollector: failed to fetch server info", "error", err)
+		return
+	}
+
+	for _, ns := range info.Namespaces {
+		ch <- prometheus.MustNewConstMetric(
+			c.namespaceWithoutUsersDesc,
+			prometheus.GaugeValue,
+			boolValue(ns.Users == 0),
+			ns.Name,
+		)
+	}
+
+	for _, db := range info.AllDatabases() {
+		ch <- prometheus.MustNewConstMetric(
+			c.databaseEmptyDesc,
+			prometheus.GaugeValue,
+			boolValue(db.TableCount() == 0),
+			db.Namespace, db.Name,
+		)
+	}
+
+	if !info.SchemaSkipped {
+		c.collectUnindexedTables(ch, info)
+		c.collectStuckIndexes(ch, info, time.Now())
+	}
+}
+
+// collectUnindexedTables emits the tables without indexes that have a record count.
+func (c *DerivedCollector) collectUnindexedTables(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	if c.recordCounts == nil {
+		return
+	}
+
+	for _, count := range c.recordCounts.RecordCounts() {
+		if count.PartitionsFailed > 0 {
+			continue
+		}
+
+		table, ok := info.Table(count.Namespace, count.Database, count.Name)
+		if !ok || table.IndexCount() > 0 {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.tableUnindexedDesc,
+			prometheus.GaugeValue,
+			boolValue(count.RecordCount > c.settings.LargeTableRecords),
+			count.Namespace, count.Database, count.Name,
+		)
+	}
+}
+
+// collectStuckIndexes emits every building index and tracks since when it has been seen
+// building. Indexes that finished or disappeared are forgotten, so a rebuild starts over;
+// while INFO queries fail, indexes missing from info are kept.
+func (c *DerivedCollector) collectStuckIndexes(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo, now time.Time) {
+	if !info.Supports(domain.FeatureIndexBuilding) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	building := make(map[string]time.Time)
+	if len(info.Errors) > 0 {
+		maps.Copy(building, c.buildingSince)
+	}
+
+	for _, idx := range info.BuildingIndexes() {
+		key := idx.FullPath()
+
+		since, ok := c.buildingSince[key]
+		if !ok {
+			since = now
+		}
+		building[key] = since
+
+		ch <- prometheus.MustNewConstMetric(
+			c.indexBuildingStuckDesc,
+			prometheus.GaugeValue,
+			boolValue(now.Sub(since) > c.settings.IndexBuildingStuckAfter),
+			idx.Namespace, idx.Database, idx.Table, idx.Name,
+		)
+	}
+
+	c.buildingSince = building
+}
+
+// boolValue returns 1 for true and 0 for false.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
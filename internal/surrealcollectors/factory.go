@@ -0,0 +1,167 @@
+package surrealcollectors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Names of the built-in collectors. A collector is enabled by collectors.<name>.enabled
+// in the configuration.
+const (
+	CollectorInfo          = "info"
+	CollectorRecordCount   = "record_count"
+	CollectorLiveQuery     = "live_query"
+	CollectorStatsTable    = "stats_table"
+	CollectorGo            = "go"
+	CollectorProcess       = "process"
+	CollectorConnections   = "connections"
+	CollectorQueryThrottle = "query_throttle"
+	CollectorDerived       = "derived"
+	CollectorRelationEdges = "relation_edges"
+	CollectorIndexUsage    = "index_usage"
+
+	CollectorConsistencyAudit = "consistency_audit"
+)
+
+// FactoryConfig holds the settings built-in collector factories need.
+type FactoryConfig interface {
+	LiveQueryDetectOperationType() bool
+	StatsTableNamePrefix() string
+	RecordCountTopN() int
+	RecordCountTopNInterval() time.Duration
+	DerivedMetrics() domain.DerivedMetrics
+	InfoLoadAverage() string
+	InfoLegacyMemoryUsageRatio() bool
+	IndexSampleQueries() []domain.IndexSampleQuery
+}
+
+// Dependencies holds the readers, providers and filters collector factories build
+// collectors from. Readers of disabled collectors may be nil.
+type Dependencies struct {
+	Config             FactoryConfig
+	VersionReader      VersionReader
+	InfoMetricsReader  InfoMetricsReader
+	RecordCountReader  RecordCountReader
+	RelationEdgeReader RelationEdgeReader
+	IndexUsageReader   IndexUsageReader
+	LiveQueryProvider  LiveQueryInfoProvider
+	StatsTableProvider StatsTableInfoProvider
+	LiveQueryFilter    TableFilter
+	StatsTableFilter   TableFilter
+	RecordCountFilter  TableFilter
+	// TableCache shares the tables found by the info collector with the table collectors.
+	TableCache TableCache
+	// RecordCounts shares the counts of the record_count collector with the derived
+	// collector, nil shares none.
+	RecordCounts RecordCountCache
+	// ConnectionStatsProvider counts the connections of the exporter, nil exports none.
+	ConnectionStatsProvider ConnectionStatsProvider
+	// QueryThrottleProvider counts the queries delayed by the query rate limit, nil
+	// exports none.
+	QueryThrottleProvider QueryThrottleProvider
+	// Pauses lets collectors be paused at runtime, nil makes none pausable.
+	Pauses *CollectorPauses
+	// ScrapeBudget tells the collectors reading INFO when to skip the table and index
+	// levels, nil never skips them.
+	ScrapeBudget ScrapeBudget
+	// Namespace is set when the collectors serve the metrics endpoint of one namespace.
+	// InfoMetricsReader then only covers that namespace.
+	Namespace string
+}
+
+// ScrapeBudget is the time budget of the running scrape.
+type ScrapeBudget interface {
+	// Deadline returns when the running scrape uses up its budget.
+	Deadline() time.Time
+	// Skipped records that collector skipped part of its work for the budget.
+	Skipped(collector string)
+}
+
+// Factory creates the collector of a registration.
+type Factory func(deps Dependencies) prometheus.Collector
+
+// Registration describes a collector the exporter can run.
+//
+// To add a collector, call Register from an init function of the file implementing it
+// and add a collectors.<name> section with an enabled setting to the configuration,
+// which strict validation then knows. The registry builds every enabled registration
+// with Factory and wraps it with the cluster, storage_engine and deployment_mode labels.
+type Registration struct {
+	// Name identifies the collector and its collectors.<name>.enabled setting. Collectors
+	// without that setting only run when AlwaysEnabled.
+	Name string
+	// Factory creates the collector.
+	Factory Factory
+	// AlwaysEnabled collectors run regardless of configuration.
+	AlwaysEnabled bool
+	// LowPriority collectors are skipped when the scrape budget is exceeded.
+	LowPriority bool
+	// Telemetry collectors describe the exporter itself and are served on the telemetry
+	// port when one is configured.
+	Telemetry bool
+	// NamespaceScoped collectors only export series of the tables InfoMetricsReader and
+	// TableCache cover, and are also built for the per-namespace metrics endpoints.
+	NamespaceScoped bool
+}
+
+var (
+	registrationsMu sync.RWMutex
+	registrations   = make(map[string]Registration)
+)
+
+// Register makes a collector available to the registry. It panics when the
+// registration has no name or factory, or its name is already registered.
+func Register(registration Registration) {
+	registrationsMu.Lock()
+	defer registrationsMu.Unlock()
+
+	if registration.Name == "" || registration.Factory == nil {
+		panic("surrealcollectors: collector registration needs a name and a factory")
+	}
+
+	if _, exists := registrations[registration.Name]; exists {
+		panic(fmt.Sprintf("surrealcollectors: collector %q registered twice", registration.Name))
+	}
+
+	registrations[registration.Name] = registration
+}
+
+// Registrations returns all registered collectors ordered by name.
+func Registrations() []Registration {
+	registrationsMu.RLock()
+	defer registrationsMu.RUnlock()
+
+	result := make([]Registration, 0, len(registrations))
+	for _, registration := range registrations {
+		result = append(result, registration)
+	}
+
+	slices.SortFunc(result, func(a, b Registration) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return result
+}
+
+// collectorGroup combines several collectors into one.
+type collectorGroup []prometheus.Collector
+
+// Describe implements prometheus.Collector.
+func (g collectorGroup) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range g {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (g collectorGroup) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range g {
+		c.Collect(ch)
+	}
+}
@@ -0,0 +1,90 @@
+package surrealcollectors
+
+import (
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// recordCountSnapshot is a record count of a table and when it was taken.
+type recordCountSnapshot struct {
+	count int
+	at    time.Time
+}
+
+// tableGrowth is the change between the last two record count snapshots of a table.
+type tableGrowth struct {
+	delta int
+	rate  float64
+}
+
+// growthTracker keeps the previous record count of every table, so growth can be
+// exported without range queries that depend on the scrape interval. Counts served
+// from a background refresh only advance the snapshot when they were counted again.
+type growthTracker struct {
+	mu        sync.Mutex
+	snapshots map[domain.TableIdentifier]recordCountSnapshot
+	growth    map[domain.TableIdentifier]tableGrowth
+}
+
+func newGrowthTracker() *growthTracker {
+	return &growthTracker{
+		snapshots: make(map[domain.TableIdentifier]recordCountSnapshot),
+		growth:    make(map[domain.TableIdentifier]tableGrowth),
+	}
+}
+
+// observe records the counts seen at now and returns the growth of every table with at
+// least two snapshots. Incomplete partitioned counts are skipped, tables missing from
+// counts are forgotten.
+func (t *growthTracker) observe(
+	counts []*domain.TableRecordCount,
+	now time.Time,
+) map[domain.TableIdentifier]tableGrowth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	seen := make(map[domain.TableIdentifier]struct{}, len(counts))
+	result := make(map[domain.TableIdentifier]tableGrowth, len(counts))
+
+	for _, count := range counts {
+		id := tableCountID(count)
+		seen[id] = struct{}{}
+
+		if count.PartitionsFailed > 0 {
+			continue
+		}
+
+		at := count.CountedAt
+		if count.Incremental || at.IsZero() {
+			at = now
+		}
+
+		previous, ok := t.snapshots[id]
+		if !ok || at.After(previous.at) {
+			t.snapshots[id] = recordCountSnapshot{count: count.RecordCount, at: at}
+
+			if ok {
+				delta := count.RecordCount - previous.count
+				t.growth[id] = tableGrowth{
+					delta: delta,
+					rate:  float64(delta) / at.Sub(previous.at).Seconds(),
+				}
+			}
+		}
+
+		if growth, ok := t.growth[id]; ok {
+			result[id] = growth
+		}
+	}
+
+	for id := range t.snapshots {
+		if _, ok := seen[id]; !ok {
+			delete(t.snapshots, id)
+			delete(t.growth, id)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,213 @@
+package surrealcollectors
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	Register(Registration{
+		Name:        CollectorIndexUsage,
+		LowPriority: true,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			queries := deps.Config.IndexSampleQueries()
+			if deps.Namespace != "" {
+				queries = slices.DeleteFunc(slices.Clone(queries), func(query domain.IndexSampleQuery) bool {
+					return query.Namespace != deps.Namespace
+				})
+			}
+
+			return NewIndexUsageCollector(deps.InfoMetricsReader, deps.IndexUsageReader, queries)
+		},
+		NamespaceScoped: true,
+	})
+}
+
+// IndexUsageReader defines the interface for explaining sample queries.
+type IndexUsageReader interface {
+	IndexUsage(ctx context.Context, queries []domain.IndexSampleQuery) []*domain.IndexSamplePlan
+}
+
+// IndexUsageCollector exports which indexes the EXPLAIN plans of representative sample
+// queries use, so indexes no query uses can be found and dropped, and when each index was
+// last seen to finish building.
+type IndexUsageCollector struct {
+	infoMetricsReader InfoMetricsReader
+	reader            IndexUsageReader
+	queries           []domain.IndexSampleQuery
+
+	// building and builtAt hold the indexes seen building on the last scrape and when
+	// indexes were last seen to finish building.
+	mu       sync.Mutex
+	building map[string]bool
+	builtAt  map[string]time.Time
+
+	indexUsesDesc    *prometheus.Desc
+	tableScansDesc   *prometheus.Desc
+	querySuccessDesc *prometheus.Desc
+	lastBuiltDesc    *prometheus.Desc
+}
+
+// NewIndexUsageCollector creates a collector explaining queries on every scrape.
+func NewIndexUsageCollector(
+	infoMetricsReader InfoMetricsReader,
+	reader IndexUsageReader,
+	queries []domain.IndexSampleQuery,
+) *IndexUsageCollector {
+	return &IndexUsageCollector{
+		infoMetricsReader: infoMetricsReader,
+		reader:            reader,
+		queries:           queries,
+		building:          make(map[string]bool),
+		builtAt:           make(map[string]time.Time),
+		indexUsesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "index", "sample_query_uses"),
+			"Number of sample queries whose EXPLAIN plan uses the index, for every index of the databases with sample queries; 0 marks indexes no sample query uses",
+			[]string{"namespace", "database", "table", "index"},
+			nil,
+		),
+		tableScansDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "index", "sample_query_table_scan"),
+			"1 for each table the EXPLAIN plan of a sample query iterates without an index",
+			[]string{"query", "namespace", "database", "table"},
+			nil,
+		),
+		querySuccessDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "index", "sample_query_success"),
+			"1 if the sample query could be explained, 0 otherwise",
+			[]string{"query"},
+			nil,
+		),
+		lastBuiltDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "index", "last_built_timestamp_seconds"),
+			"Unix time the index was last seen to finish building, known once a build was observed",
+			[]string{"namespace", "database", "table", "index"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *IndexUsageCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.indexUsesDesc
+	ch <- c.tableScansDesc
+	ch <- c.querySuccessDesc
+	ch <- c.lastBuiltDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *IndexUsageCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, span := startCollectSpan("index_usage")
+	defer span.End()
+
+	info, err := c.infoMetricsReader.Info(ctx)
+	if err != nil {
+		slog.Error("IndexUsageCollector: failed to fetch server info", "error", err)
+		return
+	}
+
+	c.collectLastBuilt(ch, info, time.Now())
+
+	if len(c.queries) == 0 {
+		return
+	}
+
+	uses := make(map[string]int)
+	sampled := make(map[[2]string]bool)
+	for _, plan := range c.reader.IndexUsage(ctx, c.queries) {
+		query := plan.Query
+		sampled[[2]string{query.Namespace, query.Database}] = true
+
+		if plan.Err != nil {
+			slog.Error("unable to explain index sample query", "query", query.Name, "error", plan.Err)
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.querySuccessDesc,
+			prometheus.GaugeValue,
+			boolValue(plan.Err == nil),
+			query.Name,
+		)
+
+		for _, use := range plan.Indexes {
+			index := domain.IndexInfo{Namespace: query.Namespace, Database: query.Database, Table: use.Table, Name: use.Index}
+			uses[index.FullPath()]++
+		}
+
+		for _, table := range plan.ScannedTables {
+			ch <- prometheus.MustNewConstMetric(
+				c.tableScansDesc,
+				prometheus.GaugeValue,
+				1,
+				query.Name, query.Namespace, query.Database, table,
+			)
+		}
+	}
+
+	for _, idx := range info.AllIndexes() {
+		if !sampled[[2]string{idx.Namespace, idx.Database}] {
+			continue
+		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.indexUsesDesc,
+			prometheus.GaugeValue,
+			float64(uses[idx.FullPath()]),
+			idx.Namespace, idx.Database, idx.Table, idx.Name,
+		)
+	}
+}
+
+// collectLastBuilt records when indexes seen building on an earlier scrape are no longer
+// building and emits the last build of every index. Indexes that disappeared are
+// forgotten; while INFO queries fail, indexes missing from info are kept.
+func (c *IndexUsageCollector) collectLastBuilt(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo, now time.Time) {
+	if !info.Supports(domain.FeatureIndexBuilding) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	building := make(map[string]bool)
+	builtAt := make(map[string]time.Time)
+	if len(info.Errors) > 0 {
+		maps.Copy(building, c.building)
+		maps.Copy(builtAt, c.builtAt)
+	}
+
+	for _, idx := range info.AllIndexes() {
+		key := idx.FullPath()
+
+		if at, ok := c.builtAt[key]; ok {
+			builtAt[key] = at
+		}
+
+		switch {
+		case idx.IsBuilding():
+			building[key] = true
+		case c.building[key]:
+			builtAt[key] = now
+			delete(building, key)
+		}
+
+		if at, ok := builtAt[key]; ok {
+			ch <- prometheus.MustNewConstMetric(
+				c.lastBuiltDesc,
+				prometheus.GaugeValue,
+				float64(at.UnixNano())/1e9,
+				idx.Namespace, idx.Database, idx.Table, idx.Name,
+			)
+		}
+	}
+
+	c.building = building
+	c.builtAt = builtAt
+}
@@ -2,7 +2,12 @@ package surrealcollectors
 
 import (
 	"context"
+	"errors"
 	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,6 +19,22 @@ const (
 	SubsystemSystem = "system"
 )
 
+func init() {
+	Register(Registration{
+		Name:          CollectorInfo,
+		AlwaysEnabled: true,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			collector := NewInfoCollector(deps.VersionReader, deps.InfoMetricsReader, deps.TableCache)
+			collector.namespace = deps.Namespace
+			collector.loadAverage = deps.Config.InfoLoadAverage()
+			collector.legacyMemoryUsageRatio = deps.Config.InfoLegacyMemoryUsageRatio()
+			collector.budget = deps.ScrapeBudget
+			return collector
+		},
+		NamespaceScoped: true,
+	})
+}
+
 type VersionReader interface {
 	Version(ctx context.Context) (string, error)
 }
@@ -27,9 +48,30 @@ type InfoCollector struct {
 	infoMetricsReader InfoMetricsReader
 	constantLabels    prometheus.Labels
 
-	tableInfoCache *tableInfoCache
+	// namespace is set when the collector serves a namespace endpoint; infoMetricsReader
+	// then only covers that namespace.
+	namespace string
+
+	// loadAverage is one of the domain.LoadAverage* modes.
+	loadAverage string
+
+	// legacyMemoryUsageRatio keeps exporting the deprecated memory_usage_ratio, which
+	// holds the ratio of allocated memory now also exported as
+	// memory_usage_ratio_of_allocated.
+	legacyMemoryUsageRatio bool
 
-	versionDesc *prometheus.Desc
+	// budget makes the table and index levels skippable when the scrape runs out of
+	// time, nil never skips them.
+	budget ScrapeBudget
+
+	tableCache TableCache
+	uptime     uptimeTracker
+	schema     schemaTracker
+
+	versionDesc   *prometheus.Desc
+	startTimeDesc *prometheus.Desc
+	uptimeDesc    *prometheus.Desc
+	restartsDesc  *prometheus.Desc
 
 	availableParallelismDesc *prometheus.Desc
 	cpuUsageDesc             *prometheus.Desc
@@ -37,10 +79,17 @@ type InfoCollector struct {
 	memoryAllocatedDesc      *prometheus.Desc
 	memoryUsageDesc          *prometheus.Desc
 	memoryUsageRatioDesc     *prometheus.Desc
+	memoryRatioAllocatedDesc *prometheus.Desc
 	physicalCoresDesc        *prometheus.Desc
 	threadsDesc              *prometheus.Desc
+	systemSupportedDesc      *prometheus.Desc
 
-	scrapeDurationDesc *prometheus.Desc
+	scrapeDurationDesc   *prometheus.Desc
+	infoErrorsDesc       *prometheus.Desc
+	infoDeadlinesDesc    *prometheus.Desc
+	featureSupportedDesc *prometheus.Desc
+	schemaHashDesc       *prometheus.Desc
+	schemaChangesDesc    *prometheus.Desc
 
 	rootAccessesDesc *prometheus.Desc
 	rootUsersDesc    *prometheus.Desc
@@ -60,24 +109,52 @@ type InfoCollector struct {
 	databaseTablesDesc    *prometheus.Desc
 	databaseUsersDesc     *prometheus.Desc
 
+	databaseFunctionInfoDesc *prometheus.Desc
+	databaseParamInfoDesc    *prometheus.Desc
+	databaseAnalyzerInfoDesc *prometheus.Desc
+	databaseAPIInfoDesc      *prometheus.Desc
+
 	tableEventsDesc  *prometheus.Desc
 	tableFieldsDesc  *prometheus.Desc
 	tableIndexesDesc *prometheus.Desc
 	tableLivesDesc   *prometheus.Desc
 	tableTablesDesc  *prometheus.Desc
 
+	tableDefinitionDesc          *prometheus.Desc
+	tableChangefeedRetentionDesc *prometheus.Desc
+
 	indexBuildingDesc        *prometheus.Desc
 	indexBuildingInitialDesc *prometheus.Desc
 	indexBuildingPendingDesc *prometheus.Desc
 	indexBuildingUpdatedDesc *prometheus.Desc
+
+	vectorIndexInfoDesc            *prometheus.Desc
+	vectorIndexDimensionDesc       *prometheus.Desc
+	vectorIndexCapacityDesc        *prometheus.Desc
+	vectorIndexEFConstructionDesc  *prometheus.Desc
+	vectorIndexMDesc               *prometheus.Desc
+	vectorIndexM0Desc              *prometheus.Desc
+	vectorIndexBuildingDesc        *prometheus.Desc
+	vectorIndexBuildingInitialDesc *prometheus.Desc
+	vectorIndexBuildingPendingDesc *prometheus.Desc
+	vectorIndexBuildingUpdatedDesc *prometheus.Desc
 }
 
-func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetricsReader) *InfoCollector {
+// NewInfoCollector creates the info collector. The tables of every successful info scrape
+// are stored in tableCache for the table collectors.
+func NewInfoCollector(
+	versionReader VersionReader,
+	infoMetricsReader InfoMetricsReader,
+	tableCache TableCache,
+) *InfoCollector {
 	return &InfoCollector{
 		versionReader:     versionReader,
 		infoMetricsReader: infoMetricsReader,
+		loadAverage:       domain.LoadAveragePeriods,
+
+		legacyMemoryUsageRatio: true,
 
-		tableInfoCache: getTableInfoCache(),
+		tableCache: tableCache,
 
 		versionDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(domain.Namespace, SubsystemBuild, "info"),
@@ -86,6 +163,41 @@ func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetrics
 			nil,
 		),
 
+		startTimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "", "start_time_seconds"),
+			"Estimated start time of the SurrealDB server since unix epoch in seconds, reset when its node IDs or version change",
+			nil,
+			nil,
+		),
+
+		uptimeDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "", "uptime_seconds"),
+			"Estimated uptime of the SurrealDB server in seconds, reset when its node IDs or version change",
+			nil,
+			nil,
+		),
+
+		restartsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "", "restarts_detected_total"),
+			"Number of SurrealDB server restarts detected from changed node IDs or version since the exporter started",
+			nil,
+			nil,
+		),
+
+		schemaHashDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "schema", "hash"),
+			"Hash of the namespaces, databases, tables and indexes seen by the last complete INFO scrape",
+			[]string{"hash"},
+			nil,
+		),
+
+		schemaChangesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "schema", "changes_total"),
+			"Number of times the schema hash changed since the exporter started",
+			nil,
+			nil,
+		),
+
 		availableParallelismDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(domain.Namespace, SubsystemSystem, "available_parallelism"),
 			"Available CPU parallelism for the SurrealDB instance",
@@ -122,7 +234,13 @@ func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetrics
 
 		memoryUsageRatioDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(domain.Namespace, SubsystemSystem, "memory_usage_ratio"),
-			"Memory usage as ratio of allocated memory (0.0 to 1.0)",
+			"Deprecated: use memory_usage_ratio_of_allocated. Memory usage as ratio of allocated memory (0.0 to 1.0)",
+			nil,
+			nil,
+		),
+		memoryRatioAllocatedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemSystem, "memory_usage_ratio_of_allocated"),
+			"Memory usage as ratio of the memory reserved by the allocator (0.0 to 1.0)",
 			nil,
 			nil,
 		),
@@ -140,6 +258,20 @@ func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetrics
 			nil,
 		),
 
+		systemSupportedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemSystem, "metrics_supported"),
+			"Whether the server platform provides a system metric; unsupported metrics are not exported",
+			[]string{"metric"},
+			nil,
+		),
+
+		featureSupportedDesc: prometheus.NewDesc(
+			"surrealdb_exporter_feature_supported",
+			"Whether the SurrealDB server version supports a version-dependent feature",
+			[]string{"feature"},
+			nil,
+		),
+
 		scrapeDurationDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(domain.Namespace, SubsystemInfo, "scrape_duration_seconds"),
 			"Duration of the INFO scrape in seconds",
@@ -147,6 +279,20 @@ func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetrics
 			nil,
 		),
 
+		infoErrorsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemInfo, "errors"),
+			"Number of INFO queries that failed during the last scrape by hierarchy level and reason",
+			[]string{"level", "namespace", "database", "reason"},
+			nil,
+		),
+
+		infoDeadlinesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemInfo, "deadline_exceeded"),
+			"Number of INFO queries that ran out of their timeout budget during the last scrape by hierarchy level",
+			[]string{"level"},
+			nil,
+		),
+
 		rootAccessesDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(domain.Namespace, "root", "accesses"),
 			"Number of accesses defined at root level",
@@ -251,6 +397,34 @@ func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetrics
 			nil,
 		),
 
+		databaseFunctionInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "database", "function_info"),
+			"Function defined in the database, exported with detailed_schema",
+			[]string{"namespace", "database", "function"},
+			nil,
+		),
+
+		databaseParamInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "database", "param_info"),
+			"Param defined in the database, exported with detailed_schema",
+			[]string{"namespace", "database", "param"},
+			nil,
+		),
+
+		databaseAnalyzerInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "database", "analyzer_info"),
+			"Analyzer defined in the database, exported with detailed_schema",
+			[]string{"namespace", "database", "analyzer"},
+			nil,
+		),
+
+		databaseAPIInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "database", "api_info"),
+			"HTTP API defined in the database with its comma separated methods, exported with detailed_schema",
+			[]string{"namespace", "database", "path", "methods"},
+			nil,
+		),
+
 		tableEventsDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(domain.Namespace, "table", "events"),
 			"Number of events defined in the table",
@@ -286,6 +460,20 @@ func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetrics
 			nil,
 		),
 
+		tableDefinitionDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "table", "definition_info"),
+			"Settings of the DEFINE TABLE statement of the table: type (any, normal, relation), schemafull, drop and changefeed",
+			[]string{"namespace", "database", "table", "type", "schemafull", "drop", "changefeed"},
+			nil,
+		),
+
+		tableChangefeedRetentionDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "table", "changefeed_retention_seconds"),
+			"Time the changefeed of the table keeps changes in seconds",
+			[]string{"namespace", "database", "table", "include_original"},
+			nil,
+		),
+
 		indexBuildingDesc: prometheus.NewDesc(
 			prometheus.BuildFQName(domain.Namespace, "index", "building"),
 			"Whether the index is currently building (1) or not (0)",
@@ -313,11 +501,84 @@ func NewInfoCollector(versionReader VersionReader, infoMetricsReader InfoMetrics
 			[]string{"namespace", "database", "table", "index", "status"},
 			nil,
 		),
+
+		vectorIndexInfoDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "info"),
+			"Vector index with its type (hnsw, mtree), distance function and vector element type, always 1",
+			[]string{"namespace", "database", "table", "index", "type", "distance", "vector_type"},
+			nil,
+		),
+
+		vectorIndexDimensionDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "dimension"),
+			"Dimension of the vectors of the vector index",
+			[]string{"namespace", "database", "table", "index", "type"},
+			nil,
+		),
+
+		vectorIndexCapacityDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "mtree_capacity"),
+			"Node capacity of the MTREE index",
+			[]string{"namespace", "database", "table", "index"},
+			nil,
+		),
+
+		vectorIndexEFConstructionDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "hnsw_ef_construction"),
+			"Size of the candidate list while building the HNSW index (EFC)",
+			[]string{"namespace", "database", "table", "index"},
+			nil,
+		),
+
+		vectorIndexMDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "hnsw_m"),
+			"Maximum connections of the nodes above the bottom layer of the HNSW index (M)",
+			[]string{"namespace", "database", "table", "index"},
+			nil,
+		),
+
+		vectorIndexM0Desc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "hnsw_m0"),
+			"Maximum connections of the nodes on the bottom layer of the HNSW index (M0)",
+			[]string{"namespace", "database", "table", "index"},
+			nil,
+		),
+
+		vectorIndexBuildingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "building"),
+			"Whether the vector index is currently building (1) or not (0)",
+			[]string{"namespace", "database", "table", "index", "type", "status"},
+			nil,
+		),
+
+		vectorIndexBuildingInitialDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "building_initial"),
+			"Initial count for vector index building process",
+			[]string{"namespace", "database", "table", "index", "type", "status"},
+			nil,
+		),
+
+		vectorIndexBuildingPendingDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "building_pending"),
+			"Pending count for vector index building process",
+			[]string{"namespace", "database", "table", "index", "type", "status"},
+			nil,
+		),
+
+		vectorIndexBuildingUpdatedDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "vector_index", "building_updated"),
+			"Updated count for vector index building process",
+			[]string{"namespace", "database", "table", "index", "type", "status"},
+			nil,
+		),
 	}
 }
 
 func (c *InfoCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.versionDesc
+	ch <- c.startTimeDesc
+	ch <- c.uptimeDesc
+	ch <- c.restartsDesc
 
 	ch <- c.availableParallelismDesc
 	ch <- c.cpuUsageDesc
@@ -325,10 +586,17 @@ func (c *InfoCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.memoryAllocatedDesc
 	ch <- c.memoryUsageDesc
 	ch <- c.memoryUsageRatioDesc
+	ch <- c.memoryRatioAllocatedDesc
 	ch <- c.physicalCoresDesc
 	ch <- c.threadsDesc
+	ch <- c.systemSupportedDesc
 
 	ch <- c.scrapeDurationDesc
+	ch <- c.infoErrorsDesc
+	ch <- c.infoDeadlinesDesc
+	ch <- c.featureSupportedDesc
+	ch <- c.schemaHashDesc
+	ch <- c.schemaChangesDesc
 
 	ch <- c.rootAccessesDesc
 	ch <- c.rootUsersDesc
@@ -348,45 +616,99 @@ func (c *InfoCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.databaseTablesDesc
 	ch <- c.databaseUsersDesc
 
+	ch <- c.databaseFunctionInfoDesc
+	ch <- c.databaseParamInfoDesc
+	ch <- c.databaseAnalyzerInfoDesc
+	ch <- c.databaseAPIInfoDesc
+
 	ch <- c.tableEventsDesc
 	ch <- c.tableFieldsDesc
 	ch <- c.tableIndexesDesc
 	ch <- c.tableLivesDesc
 	ch <- c.tableTablesDesc
 
+	ch <- c.tableDefinitionDesc
+	ch <- c.tableChangefeedRetentionDesc
+
 	ch <- c.indexBuildingDesc
 	ch <- c.indexBuildingInitialDesc
 	ch <- c.indexBuildingPendingDesc
 	ch <- c.indexBuildingUpdatedDesc
+
+	ch <- c.vectorIndexInfoDesc
+	ch <- c.vectorIndexDimensionDesc
+	ch <- c.vectorIndexCapacityDesc
+	ch <- c.vectorIndexEFConstructionDesc
+	ch <- c.vectorIndexMDesc
+	ch <- c.vectorIndexM0Desc
+	ch <- c.vectorIndexBuildingDesc
+	ch <- c.vectorIndexBuildingInitialDesc
+	ch <- c.vectorIndexBuildingPendingDesc
+	ch <- c.vectorIndexBuildingUpdatedDesc
 }
 
 func (c *InfoCollector) Collect(ch chan<- prometheus.Metric) {
-	ctx := context.Background()
+	ctx, span := startCollectSpan("info")
+	defer span.End()
 
-	c.collectVersion(ctx, ch)
+	// Namespace collectors leave the server-wide metrics to the full scrape.
+	var version string
+	if c.namespace == "" {
+		version = c.collectVersion(ctx, ch)
+	}
 
-	info, err := c.infoMetricsReader.Info(ctx)
+	info, err := c.infoMetricsReader.Info(withSchemaDeadline(ctx, c.budget))
+	c.collectDeadlines(ch, info, err)
 	if err != nil {
-		slog.Error("InfoCollector: failed to fetch server info", "error", err)
+		slog.Error("InfoCollector: failed to fetch server info", "error", err, "reason", domain.ErrorReason(err))
 		return
 	}
 
-	c.tableInfoCache.set(info.AllTables())
+	if info.SchemaSkipped {
+		c.budget.Skipped(CollectorInfo)
+	}
+
+	c.tableCache.SetTables(info.AllTables())
 
-	c.collectSystemMetrics(ch, info)
+	if c.namespace == "" {
+		c.collectUptime(ch, version, info)
+		c.collectFeatures(ch, info)
+		c.collectSystemMetrics(ch, info)
+	}
 	c.collectScrapeDuration(ch, info)
-	c.collectRootMetrics(ch, info)
+	c.collectInfoErrors(ch, info)
+	if c.namespace == "" && !info.Scoped {
+		c.collectRootMetrics(ch, info)
+	}
 	c.collectNamespaceMetrics(ch, info)
 	c.collectDatabaseMetrics(ch, info)
-	c.collectTableMetrics(ch, info)
-	c.collectIndexMetrics(ch, info)
+	c.collectSchemaInventory(ch, info)
+
+	// Without the table and index levels, their metrics and the schema hash are left out
+	// of this scrape.
+	if !info.SchemaSkipped {
+		c.collectSchemaChanges(ch, info)
+		c.collectTableMetrics(ch, info)
+		c.collectIndexMetrics(ch, info)
+		c.collectVectorIndexMetrics(ch, info)
+	}
+}
+
+// withSchemaDeadline returns ctx with the deadline of budget for the table and index
+// levels, or ctx itself without a budget.
+func withSchemaDeadline(ctx context.Context, budget ScrapeBudget) context.Context {
+	if budget == nil {
+		return ctx
+	}
+
+	return domain.WithSchemaDeadline(ctx, budget.Deadline())
 }
 
-func (c *InfoCollector) collectVersion(ctx context.Context, ch chan<- prometheus.Metric) {
+func (c *InfoCollector) collectVersion(ctx context.Context, ch chan<- prometheus.Metric) string {
 	version, err := c.versionReader.Version(ctx)
 	if err != nil {
 		slog.Error("InfoCollector: failed to fetch version info", "error", err)
-		return
+		return ""
 	}
 
 	ch <- prometheus.MustNewConstMetric(
@@ -395,63 +717,117 @@ func (c *InfoCollector) collectVersion(ctx context.Context, ch chan<- prometheus
 		1,
 		version,
 	)
+
+	return version
 }
 
-func (c *InfoCollector) collectSystemMetrics(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+func (c *InfoCollector) collectUptime(ch chan<- prometheus.Metric, version string, info *domain.SurrealDBInfo) {
+	now := time.Now()
+	startTime, restarts := c.uptime.observe(version, info.NodeIDs, now)
+
 	ch <- prometheus.MustNewConstMetric(
-		c.availableParallelismDesc,
+		c.startTimeDesc,
 		prometheus.GaugeValue,
-		float64(info.System.AvailableParallelism),
+		float64(startTime.UnixNano())/1e9,
 	)
 
 	ch <- prometheus.MustNewConstMetric(
-		c.cpuUsageDesc,
+		c.uptimeDesc,
 		prometheus.GaugeValue,
-		info.System.CpuUsage/100,
+		now.Sub(startTime).Seconds(),
 	)
 
-	periods := []string{"1m", "5m", "15m"}
-	for i, load := range info.System.LoadAverage {
-		if i < len(periods) {
-			ch <- prometheus.MustNewConstMetric(
-				c.loadAverageDesc,
-				prometheus.GaugeValue,
-				load,
-				periods[i],
-			)
+	ch <- prometheus.MustNewConstMetric(
+		c.restartsDesc,
+		prometheus.CounterValue,
+		float64(restarts),
+	)
+}
+
+func (c *InfoCollector) collectFeatures(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	for feature, supported := range info.Features {
+		value := float64(0)
+		if supported {
+			value = 1
 		}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.featureSupportedDesc,
+			prometheus.GaugeValue,
+			value,
+			feature,
+		)
 	}
+}
 
-	ch <- prometheus.MustNewConstMetric(
-		c.memoryAllocatedDesc,
-		prometheus.GaugeValue,
-		float64(info.System.MemoryAllocated),
-	)
+// systemMetric is a system metric value and whether the server platform provides it.
+type systemMetric struct {
+	name      string
+	desc      *prometheus.Desc
+	value     float64
+	supported bool
+}
 
-	ch <- prometheus.MustNewConstMetric(
-		c.memoryUsageDesc,
-		prometheus.GaugeValue,
-		float64(info.System.MemoryUsage),
-	)
+func (c *InfoCollector) collectSystemMetrics(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	if !info.Supports(domain.FeatureSystemInfo) || info.Scoped {
+		return
+	}
 
-	// TODO check this
-	ch <- prometheus.MustNewConstMetric(
-		c.memoryUsageRatioDesc,
-		prometheus.GaugeValue,
-		info.System.MemoryUsagePercent()/100.0,
-	)
+	system := &info.System
+
+	// Platforms that cannot measure a value report it as zero; such metrics are left out
+	// instead of exporting a misleading zero, and flagged in system_metrics_supported.
+	// A zero CPU usage is a valid reading, so it is always exported.
+	metrics := []systemMetric{
+		{"available_parallelism", c.availableParallelismDesc, float64(system.AvailableParallelism), system.AvailableParallelism > 0},
+		{"cpu_usage", c.cpuUsageDesc, system.CpuUsage / 100, true},
+		{"memory_allocated_bytes", c.memoryAllocatedDesc, float64(system.MemoryAllocated), system.MemoryAllocated > 0},
+		{"memory_usage_bytes", c.memoryUsageDesc, float64(system.MemoryUsage), system.MemoryUsage > 0},
+		{"memory_usage_ratio_of_allocated", c.memoryRatioAllocatedDesc, system.MemoryUsageRatioOfAllocated(), system.MemoryAllocated > 0},
+		{"physical_cores", c.physicalCoresDesc, float64(system.PhysicalCores), system.PhysicalCores > 0},
+		{"threads", c.threadsDesc, float64(system.Threads), system.Threads > 0},
+	}
 
-	ch <- prometheus.MustNewConstMetric(
-		c.physicalCoresDesc,
-		prometheus.GaugeValue,
-		float64(info.System.PhysicalCores),
-	)
+	if c.legacyMemoryUsageRatio {
+		metrics = append(metrics, systemMetric{"memory_usage_ratio", c.memoryUsageRatioDesc, system.MemoryUsageRatioOfAllocated(), system.MemoryAllocated > 0})
+	}
 
-	ch <- prometheus.MustNewConstMetric(
-		c.threadsDesc,
-		prometheus.GaugeValue,
-		float64(info.System.Threads),
-	)
+	for _, m := range metrics {
+		ch <- prometheus.MustNewConstMetric(c.systemSupportedDesc, prometheus.GaugeValue, boolValue(m.supported), m.name)
+		if m.supported {
+			ch <- prometheus.MustNewConstMetric(m.desc, prometheus.GaugeValue, m.value)
+		}
+	}
+
+	loadSupported := system.LoadAverageSupported()
+	ch <- prometheus.MustNewConstMetric(c.systemSupportedDesc, prometheus.GaugeValue, boolValue(loadSupported), "load_average")
+	if loadSupported {
+		c.collectLoadAverage(ch, system.LoadAverage)
+	}
+}
+
+// collectLoadAverage exports the load averages per period, or as a single series labelled
+// with the aggregation when per-period values are not wanted.
+func (c *InfoCollector) collectLoadAverage(ch chan<- prometheus.Metric, loads []float64) {
+	periods := []string{"1m", "5m", "15m"}
+	if len(loads) > len(periods) {
+		loads = loads[:len(periods)]
+	}
+
+	switch c.loadAverage {
+	case domain.LoadAverageMax:
+		ch <- prometheus.MustNewConstMetric(c.loadAverageDesc, prometheus.GaugeValue, slices.Max(loads), domain.LoadAverageMax)
+	case domain.LoadAverageAvg:
+		var sum float64
+		for _, load := range loads {
+			sum += load
+		}
+		ch <- prometheus.MustNewConstMetric(c.loadAverageDesc, prometheus.GaugeValue, sum/float64(len(loads)), domain.LoadAverageAvg)
+	default:
+		for i, load := range loads {
+			ch <- prometheus.MustNewConstMetric(c.loadAverageDesc, prometheus.GaugeValue, load, periods[i])
+		}
+	}
 }
 
 func (c *InfoCollector) collectScrapeDuration(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
@@ -462,6 +838,66 @@ func (c *InfoCollector) collectScrapeDuration(ch chan<- prometheus.Metric, info
 	)
 }
 
+func (c *InfoCollector) collectInfoErrors(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	type errorKey struct {
+		level, namespace, database, reason string
+	}
+
+	counts := make(map[errorKey]int)
+	for _, infoErr := range info.Errors {
+		counts[errorKey{infoErr.Level, infoErr.Namespace, infoErr.Database, domain.ErrorReason(infoErr.Err)}]++
+	}
+
+	for key, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.infoErrorsDesc,
+			prometheus.GaugeValue,
+			float64(count),
+			key.level, key.namespace, key.database, key.reason,
+		)
+	}
+}
+
+// collectSchemaChanges exports the schema hash and how often it changed, once a complete
+// schema has been seen.
+func (c *InfoCollector) collectSchemaChanges(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	hash, changes, ok := c.schema.observe(info)
+	if !ok {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.schemaHashDesc, prometheus.GaugeValue, 1, hash)
+	ch <- prometheus.MustNewConstMetric(c.schemaChangesDesc, prometheus.CounterValue, float64(changes))
+}
+
+// collectDeadlines exports the INFO queries per level that hit their deadline. A fetch
+// failing with err on its deadline failed at the root level.
+func (c *InfoCollector) collectDeadlines(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo, err error) {
+	counts := map[string]int{
+		domain.InfoLevelRoot:      0,
+		domain.InfoLevelNamespace: 0,
+		domain.InfoLevelDatabase:  0,
+		domain.InfoLevelTable:     0,
+		domain.InfoLevelIndex:     0,
+	}
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			counts[domain.InfoLevelRoot]++
+		}
+	} else {
+		for _, infoErr := range info.Errors {
+			if errors.Is(infoErr.Err, context.DeadlineExceeded) {
+				counts[infoErr.Level]++
+			}
+		}
+	}
+
+	for level, count := range counts {
+		ch <- prometheus.MustNewConstMetric(c.infoDeadlinesDesc, prometheus.GaugeValue, float64(count), level)
+	}
+}
+
 func (c *InfoCollector) collectRootMetrics(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
 	ch <- prometheus.MustNewConstMetric(
 		c.rootAccessesDesc,
@@ -523,12 +959,14 @@ func (c *InfoCollector) collectDatabaseMetrics(ch chan<- prometheus.Metric, info
 			db.Namespace, db.Name,
 		)
 
-		ch <- prometheus.MustNewConstMetric(
-			c.databaseApisDesc,
-			prometheus.GaugeValue,
-			float64(db.Apis),
-			db.Namespace, db.Name,
-		)
+		if info.Supports(domain.FeatureAPIs) {
+			ch <- prometheus.MustNewConstMetric(
+				c.databaseApisDesc,
+				prometheus.GaugeValue,
+				float64(db.Apis),
+				db.Namespace, db.Name,
+			)
+		}
 
 		ch <- prometheus.MustNewConstMetric(
 			c.databaseConfigsDesc,
@@ -574,6 +1012,33 @@ func (c *InfoCollector) collectDatabaseMetrics(ch chan<- prometheus.Metric, info
 	}
 }
 
+// collectSchemaInventory emits one info series per named function, param, analyzer and
+// API.
+// Names are only fetched with detailed_schema enabled.
+func (c *InfoCollector) collectSchemaInventory(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	for _, db := range info.AllDatabases() {
+		for _, name := range db.FunctionNames {
+			ch <- prometheus.MustNewConstMetric(c.databaseFunctionInfoDesc, prometheus.GaugeValue, 1,
+				db.Namespace, db.Name, name)
+		}
+
+		for _, name := range db.ParamNames {
+			ch <- prometheus.MustNewConstMetric(c.databaseParamInfoDesc, prometheus.GaugeValue, 1,
+				db.Namespace, db.Name, name)
+		}
+
+		for _, name := range db.AnalyzerNames {
+			ch <- prometheus.MustNewConstMetric(c.databaseAnalyzerInfoDesc, prometheus.GaugeValue, 1,
+				db.Namespace, db.Name, name)
+		}
+
+		for _, api := range db.APIDefinitions {
+			ch <- prometheus.MustNewConstMetric(c.databaseAPIInfoDesc, prometheus.GaugeValue, 1,
+				db.Namespace, db.Name, api.Path, strings.Join(api.Methods, ","))
+		}
+	}
+}
+
 func (c *InfoCollector) collectTableMetrics(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
 	for _, table := range info.AllTables() {
 		ch <- prometheus.MustNewConstMetric(
@@ -610,10 +1075,36 @@ func (c *InfoCollector) collectTableMetrics(ch chan<- prometheus.Metric, info *d
 			float64(table.Tables),
 			table.Namespace, table.Database, table.Name,
 		)
+
+		definition := table.Definition
+		ch <- prometheus.MustNewConstMetric(
+			c.tableDefinitionDesc,
+			prometheus.GaugeValue,
+			1,
+			table.Namespace, table.Database, table.Name,
+			definition.Type,
+			strconv.FormatBool(definition.Schemafull),
+			strconv.FormatBool(definition.Drop),
+			strconv.FormatBool(definition.Changefeed),
+		)
+
+		if definition.Changefeed {
+			ch <- prometheus.MustNewConstMetric(
+				c.tableChangefeedRetentionDesc,
+				prometheus.GaugeValue,
+				definition.ChangefeedRetention.Seconds(),
+				table.Namespace, table.Database, table.Name,
+				strconv.FormatBool(definition.ChangefeedOriginal),
+			)
+		}
 	}
 }
 
 func (c *InfoCollector) collectIndexMetrics(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	if !info.Supports(domain.FeatureIndexBuilding) {
+		return
+	}
+
 	for _, idx := range info.AllIndexes() {
 		buildingValue := float64(0)
 		if idx.IsBuilding() {
@@ -654,3 +1145,55 @@ func (c *InfoCollector) collectIndexMetrics(ch chan<- prometheus.Metric, info *d
 		)
 	}
 }
+
+// collectVectorIndexMetrics exports the settings of the HNSW and MTREE indexes and, apart
+// from the other indexes, their building progress labelled with their type.
+func (c *InfoCollector) collectVectorIndexMetrics(ch chan<- prometheus.Metric, info *domain.SurrealDBInfo) {
+	for _, idx := range info.AllIndexes() {
+		vector := idx.Vector
+		if vector == nil {
+			continue
+		}
+
+		labels := []string{idx.Namespace, idx.Database, idx.Table, idx.Name}
+
+		ch <- prometheus.MustNewConstMetric(
+			c.vectorIndexInfoDesc,
+			prometheus.GaugeValue,
+			1,
+			append(labels, vector.Type, vector.Distance, vector.VectorType)...,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.vectorIndexDimensionDesc,
+			prometheus.GaugeValue,
+			float64(vector.Dimension),
+			append(labels, vector.Type)...,
+		)
+
+		switch vector.Type {
+		case domain.VectorIndexMTree:
+			ch <- prometheus.MustNewConstMetric(c.vectorIndexCapacityDesc, prometheus.GaugeValue, float64(vector.Capacity), labels...)
+
+		case domain.VectorIndexHNSW:
+			ch <- prometheus.MustNewConstMetric(c.vectorIndexEFConstructionDesc, prometheus.GaugeValue, float64(vector.EFConstruction), labels...)
+			ch <- prometheus.MustNewConstMetric(c.vectorIndexMDesc, prometheus.GaugeValue, float64(vector.M), labels...)
+			ch <- prometheus.MustNewConstMetric(c.vectorIndexM0Desc, prometheus.GaugeValue, float64(vector.M0), labels...)
+		}
+
+		if !info.Supports(domain.FeatureIndexBuilding) {
+			continue
+		}
+
+		status := idx.Building.Status
+		if status == "" {
+			status = "none"
+		}
+		buildingLabels := append(labels, vector.Type, status)
+
+		ch <- prometheus.MustNewConstMetric(c.vectorIndexBuildingDesc, prometheus.GaugeValue, boolValue(idx.IsBuilding()), buildingLabels...)
+		ch <- prometheus.MustNewConstMetric(c.vectorIndexBuildingInitialDesc, prometheus.GaugeValue, float64(idx.Building.Initial), buildingLabels...)
+		ch <- prometheus.MustNewConstMetric(c.vectorIndexBuildingPendingDesc, prometheus.GaugeValue, float64(idx.Building.Pending), buildingLabels...)
+		ch <- prometheus.MustNewConstMetric(c.vectorIndexBuildingUpdatedDesc, prometheus.GaugeValue, float64(idx.Building.Updated), buildingLabels...)
+	}
+}
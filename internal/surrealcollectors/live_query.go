@@ -12,6 +12,14 @@ const SubsystemLiveQuery = "live_query"
 // LiveQueryInfoProvider provides live query metrics.
 type LiveQueryInfoProvider interface {
 	LiveQueryInfo(tableIDs []domain.TableIdentifier) ([]*domain.TableOperationMetrics, error)
+	LiveQueryNotifications() []*domain.TableNotificationCount
+	LiveQueryTables() (active, skipped int)
+	// LiveQueryProcessing returns the histogram of the notification processing latencies.
+	LiveQueryProcessing() prometheus.Collector
+	LiveQueryBacklog() []*domain.TableNotificationBacklog
+	// LiveQueryTotals returns the operation counts since startup without reconciling the
+	// live queries.
+	LiveQueryTotals() []*domain.TableOperationMetrics
 }
 
 type TableFilter interface {
@@ -21,44 +29,106 @@ type TableFilter interface {
 // LiveQueryCollector collects metrics from live queries.
 type LiveQueryCollector struct {
 	liveQueryProvider LiveQueryInfoProvider
-	tableCache        *tableInfoCache
+	tableCache        TableCache
 	filter            TableFilter
 
-	operations *prometheus.CounterVec
+	detectOperationType bool
+
+	operationsDesc    *prometheus.Desc
+	notificationsDesc *prometheus.Desc
+
+	activeTablesDesc  *prometheus.Desc
+	skippedTablesDesc *prometheus.Desc
+	backlogDesc       *prometheus.Desc
+}
+
+func init() {
+	Register(Registration{
+		Name: CollectorLiveQuery,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			return NewLiveQueryCollector(
+				deps.LiveQueryProvider,
+				deps.LiveQueryFilter,
+				deps.TableCache,
+				deps.Config.LiveQueryDetectOperationType(),
+			)
+		},
+		NamespaceScoped: true,
+	})
 }
 
 // NewLiveQueryCollector creates a new live query collector.
+// The operation_type label is only exported when detectOperationType is true.
 func NewLiveQueryCollector(
 	liveQueryProvider LiveQueryInfoProvider,
 	filter TableFilter,
+	tableCache TableCache,
+	detectOperationType bool,
 ) *LiveQueryCollector {
+	labelNames := []string{"namespace", "database", "table", "operation"}
+	if detectOperationType {
+		labelNames = append(labelNames, "operation_type")
+	}
+
 	return &LiveQueryCollector{
-		liveQueryProvider: liveQueryProvider,
-		tableCache:        getTableInfoCache(),
-		filter:            filter,
-
-		operations: prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Namespace: domain.Namespace,
-				Subsystem: SubsystemLiveQuery,
-				Name:      "operations_total",
-				Help:      "Total number of operations by type (create, update, delete)",
-			},
-			[]string{"namespace", "database", "table", "operation", "operation_type"},
+		liveQueryProvider:   liveQueryProvider,
+		tableCache:          tableCache,
+		filter:              filter,
+		detectOperationType: detectOperationType,
+
+		operationsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemLiveQuery, "operations_total"),
+			"Total number of operations by type (create, update, delete)",
+			labelNames,
+			nil,
+		),
+
+		notificationsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemLiveQuery, "notifications_total"),
+			"Total number of live query notifications by handling result (processed, unknown_action, nil_result, decode_error, sampled_out)",
+			[]string{"namespace", "database", "table", "result"},
+			nil,
+		),
+
+		activeTablesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemLiveQuery, "tables_active"),
+			"Number of tables with a live query",
+			nil,
+			nil,
+		),
+
+		skippedTablesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemLiveQuery, "tables_skipped"),
+			"Number of matching tables without a live query because max_tables was reached",
+			nil,
+			nil,
+		),
+
+		backlogDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemLiveQuery, "notification_backlog"),
+			"Number of received live notifications waiting to be handled",
+			[]string{"namespace", "database", "table"},
+			nil,
 		),
 	}
 }
 
 // Describe implements prometheus.Collector.
 func (c *LiveQueryCollector) Describe(ch chan<- *prometheus.Desc) {
-	c.operations.Describe(ch)
+	ch <- c.operationsDesc
+	ch <- c.notificationsDesc
+	ch <- c.activeTablesDesc
+	ch <- c.skippedTablesDesc
+	ch <- c.backlogDesc
+	c.liveQueryProvider.LiveQueryProcessing().Describe(ch)
 }
 
 // Collect implements prometheus.Collector.
 func (c *LiveQueryCollector) Collect(ch chan<- prometheus.Metric) {
-	// ctx := context.Background() // TODO implement using context
+	_, span := startCollectSpan("live_query")
+	defer span.End()
 
-	tables := c.tableCache.get()
+	tables := c.tableCache.Tables()
 	if len(tables) == 0 {
 		slog.Debug("No tables in cache for live query monitoring")
 		return
@@ -77,36 +147,44 @@ func (c *LiveQueryCollector) Collect(ch chan<- prometheus.Metric) {
 	}
 
 	for _, m := range metrics {
-		if m.Creates > 0 {
-			c.operations.With(prometheus.Labels{
-				"namespace":      m.Namespace,
-				"database":       m.Database,
-				"table":          m.Table,
-				"operation":      "create",
-				"operation_type": string(m.OperationType),
-			}).Add(float64(m.Creates))
-		}
-
-		if m.Updates > 0 {
-			c.operations.With(prometheus.Labels{
-				"namespace":      m.Namespace,
-				"database":       m.Database,
-				"table":          m.Table,
-				"operation":      "update",
-				"operation_type": string(m.OperationType),
-			}).Add(float64(m.Updates))
-		}
-
-		if m.Deletes > 0 {
-			c.operations.With(prometheus.Labels{
-				"namespace":      m.Namespace,
-				"database":       m.Database,
-				"table":          m.Table,
-				"operation":      "delete",
-				"operation_type": string(m.OperationType),
-			}).Add(float64(m.Deletes))
-		}
+		c.collectOperations(ch, m, "create", m.Creates)
+		c.collectOperations(ch, m, "update", m.Updates)
+		c.collectOperations(ch, m, "delete", m.Deletes)
+	}
+
+	for _, n := range c.liveQueryProvider.LiveQueryNotifications() {
+		ch <- prometheus.MustNewConstMetric(c.notificationsDesc, prometheus.CounterValue, float64(n.Count),
+			n.Namespace, n.Database, n.Table, string(n.Result))
+	}
+
+	active, skipped := c.liveQueryProvider.LiveQueryTables()
+	ch <- prometheus.MustNewConstMetric(c.activeTablesDesc, prometheus.GaugeValue, float64(active))
+	ch <- prometheus.MustNewConstMetric(c.skippedTablesDesc, prometheus.GaugeValue, float64(skipped))
+
+	c.liveQueryProvider.LiveQueryProcessing().Collect(ch)
+
+	for _, b := range c.liveQueryProvider.LiveQueryBacklog() {
+		ch <- prometheus.MustNewConstMetric(c.backlogDesc, prometheus.GaugeValue, float64(b.Backlog),
+			b.Namespace, b.Database, b.Table)
+	}
+}
+
+// collectOperations exports the operations counter of a table and operation from the
+// totals accumulated since startup.
+func (c *LiveQueryCollector) collectOperations(
+	ch chan<- prometheus.Metric,
+	m *domain.TableOperationMetrics,
+	operation string,
+	count int64,
+) {
+	if count <= 0 {
+		return
+	}
+
+	labelValues := []string{m.Namespace, m.Database, m.Table, operation}
+	if c.detectOperationType {
+		labelValues = append(labelValues, string(m.OperationType))
 	}
 
-	c.operations.Collect(ch)
+	ch <- prometheus.MustNewConstMetric(c.operationsDesc, prometheus.CounterValue, float64(count), labelValues...)
 }
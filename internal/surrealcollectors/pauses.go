@@ -0,0 +1,132 @@
+package surrealcollectors
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CollectorPauses holds the collectors paused at runtime, e.g. through the admin
+// endpoints during incident mitigation. Paused collectors export nothing and send no
+// queries on scrapes until they are resumed. It exports
+// surrealdb_exporter_collector_paused for every collector that can be paused.
+type CollectorPauses struct {
+	mu       sync.RWMutex
+	pausable map[string]bool
+	paused   map[string]bool
+
+	desc *prometheus.Desc
+}
+
+// NewCollectorPauses creates a pause set with no collector paused.
+func NewCollectorPauses() *CollectorPauses {
+	return &CollectorPauses{
+		pausable: make(map[string]bool),
+		paused:   make(map[string]bool),
+		desc: prometheus.NewDesc(
+			"surrealdb_exporter_collector_paused",
+			"Whether a collector is paused at runtime (1) or running (0)",
+			[]string{"collector"},
+			nil,
+		),
+	}
+}
+
+// Wrap makes collector pausable under name.
+func (p *CollectorPauses) Wrap(name string, collector prometheus.Collector) prometheus.Collector {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.pausable[name] = true
+
+	return &pausableCollector{name: name, collector: collector, pauses: p}
+}
+
+// Pause pauses the collector name. It returns false when no such collector can be paused.
+func (p *CollectorPauses) Pause(name string) bool {
+	return p.set(name, true)
+}
+
+// Resume resumes the collector name. It returns false when no such collector can be
+// paused.
+func (p *CollectorPauses) Resume(name string) bool {
+	return p.set(name, false)
+}
+
+func (p *CollectorPauses) set(name string, paused bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.pausable[name] {
+		return false
+	}
+
+	p.paused[name] = paused
+
+	return true
+}
+
+// Paused reports whether the collector name is paused.
+func (p *CollectorPauses) Paused(name string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.paused[name]
+}
+
+// PausedCollectors returns the names of the paused collectors in order.
+func (p *CollectorPauses) PausedCollectors() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	names := make([]string, 0, len(p.paused))
+	for name, paused := range p.paused {
+		if paused {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	return names
+}
+
+// Describe implements prometheus.Collector.
+func (p *CollectorPauses) Describe(ch chan<- *prometheus.Desc) {
+	ch <- p.desc
+}
+
+// Collect implements prometheus.Collector.
+func (p *CollectorPauses) Collect(ch chan<- prometheus.Metric) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for name := range p.pausable {
+		value := 0.0
+		if p.paused[name] {
+			value = 1
+		}
+		ch <- prometheus.MustNewConstMetric(p.desc, prometheus.GaugeValue, value, name)
+	}
+}
+
+// pausableCollector skips the collection of a collector while it is paused.
+type pausableCollector struct {
+	name      string
+	collector prometheus.Collector
+	pauses    *CollectorPauses
+}
+
+// Describe implements prometheus.Collector.
+func (c *pausableCollector) Describe(ch chan<- *prometheus.Desc) {
+	c.collector.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *pausableCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.pauses.Paused(c.name) {
+		return
+	}
+
+	c.collector.Collect(ch)
+}
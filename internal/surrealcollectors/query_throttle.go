@@ -0,0 +1,70 @@
+package surrealcollectors
+
+import (
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	Register(Registration{
+		Name:          CollectorQueryThrottle,
+		AlwaysEnabled: true,
+		Telemetry:     true,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			if deps.QueryThrottleProvider == nil {
+				return collectorGroup{}
+			}
+
+			return NewQueryThrottleCollector(deps.QueryThrottleProvider)
+		},
+	})
+}
+
+// QueryThrottleProvider counts the queries delayed by the query rate limit.
+type QueryThrottleProvider interface {
+	ThrottleStats() domain.QueryThrottleStats
+}
+
+// QueryThrottleCollector exports how many queries the query rate limit delayed and how
+// long they waited, so a too tight query budget shows up before scrapes time out.
+type QueryThrottleCollector struct {
+	provider QueryThrottleProvider
+
+	throttledDesc *prometheus.Desc
+	waitDesc      *prometheus.Desc
+}
+
+// NewQueryThrottleCollector creates the query throttle collector.
+func NewQueryThrottleCollector(provider QueryThrottleProvider) *QueryThrottleCollector {
+	return &QueryThrottleCollector{
+		provider: provider,
+
+		throttledDesc: prometheus.NewDesc(
+			"surrealdb_exporter_queries_throttled_total",
+			"SurrealDB queries delayed by the query rate limit since startup",
+			nil,
+			nil,
+		),
+
+		waitDesc: prometheus.NewDesc(
+			"surrealdb_exporter_query_throttle_wait_seconds_total",
+			"Time SurrealDB queries waited for the query rate limit since startup",
+			nil,
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *QueryThrottleCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.throttledDesc
+	ch <- c.waitDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *QueryThrottleCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.provider.ThrottleStats()
+
+	ch <- prometheus.MustNewConstMetric(c.throttledDesc, prometheus.CounterValue, float64(stats.Throttled))
+	ch <- prometheus.MustNewConstMetric(c.waitDesc, prometheus.CounterValue, stats.Wait.Seconds())
+}
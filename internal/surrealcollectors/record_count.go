@@ -3,11 +3,30 @@ package surrealcollectors
 import (
 	"context"
 	"log/slog"
+	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
+func init() {
+	Register(Registration{
+		Name:        CollectorRecordCount,
+		LowPriority: true,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			return NewRecordCountCollector(
+				deps.RecordCountReader,
+				deps.RecordCountFilter,
+				deps.TableCache,
+				deps.RecordCounts,
+				deps.Config.RecordCountTopN(),
+				deps.Config.RecordCountTopNInterval(),
+			)
+		},
+		NamespaceScoped: true,
+	})
+}
+
 // RecordCountReader defines the interface for reading table record counts.
 type RecordCountReader interface {
 	RecordCount(ctx context.Context, tables []*domain.TableInfo) (*domain.RecordCountMetrics, error)
@@ -18,24 +37,115 @@ type recordCountCollector struct {
 	reader RecordCountReader
 	filter TableFilter
 
-	tableInfoCache *tableInfoCache
+	tableCache TableCache
+	counts     RecordCountCache
+	growth     *growthTracker
+	topN       *topNSelector
 
-	tableRecordCount *prometheus.Desc
-	scrapeDuration   *prometheus.Desc
+	tableRecordCount          *prometheus.Desc
+	tableRecordCountTimestamp *prometheus.Desc
+	reconciliationAge         *prometheus.Desc
+	recordCountDelta          *prometheus.Desc
+	recordCountGrowthRate     *prometheus.Desc
+	partitionRecordCount      *prometheus.Desc
+	partitionsFailed          *prometheus.Desc
+	databaseRecordCount       *prometheus.Desc
+	namespaceRecordCount      *prometheus.Desc
+	otherTables               *prometheus.Desc
+	otherRecordCount          *prometheus.Desc
+	scrapeDuration            *prometheus.Desc
 }
 
-// NewRecordCountCollector creates a new record count collector.
-func NewRecordCountCollector(reader RecordCountReader, filter TableFilter) prometheus.Collector {
+// NewRecordCountCollector creates a new record count collector. With a positive topN,
+// only the topN largest tables, selected again every topNInterval, are exported per
+// table and all others are aggregated. The exported counts are stored in counts unless
+// it is nil.
+func NewRecordCountCollector(
+	reader RecordCountReader,
+	filter TableFilter,
+	tableCache TableCache,
+	counts RecordCountCache,
+	topN int,
+	topNInterval time.Duration,
+) prometheus.Collector {
+	var selector *topNSelector
+	if topN > 0 {
+		selector = newTopNSelector(topN, topNInterval)
+	}
+
 	return &recordCountCollector{
-		reader:         reader,
-		filter:         filter,
-		tableInfoCache: getTableInfoCache(),
+		topN:       selector,
+		reader:     reader,
+		filter:     filter,
+		tableCache: tableCache,
+		counts:     counts,
+		growth:     newGrowthTracker(),
 		tableRecordCount: prometheus.NewDesc(
 			"surrealdb_table_record_count",
 			"Number of records in a table",
 			[]string{"namespace", "database", "table"},
 			nil,
 		),
+		tableRecordCountTimestamp: prometheus.NewDesc(
+			"surrealdb_table_record_count_timestamp_seconds",
+			"Unix time when the exported record count of a table was last refreshed",
+			[]string{"namespace", "database", "table"},
+			nil,
+		),
+		reconciliationAge: prometheus.NewDesc(
+			"surrealdb_table_record_count_reconciliation_age_seconds",
+			"Seconds since the incrementally maintained record count of a table was last fully counted",
+			[]string{"namespace", "database", "table"},
+			nil,
+		),
+		recordCountDelta: prometheus.NewDesc(
+			"surrealdb_table_record_count_delta",
+			"Change of the record count of a table between its last two counts",
+			[]string{"namespace", "database", "table"},
+			nil,
+		),
+		recordCountGrowthRate: prometheus.NewDesc(
+			"surrealdb_table_record_count_growth_rate",
+			"Records per second a table grew by between its last two counts, negative when shrinking",
+			[]string{"namespace", "database", "table"},
+			nil,
+		),
+		partitionRecordCount: prometheus.NewDesc(
+			"surrealdb_table_record_count_partition",
+			"Number of records in a record ID range of a table counted in partitions",
+			[]string{"namespace", "database", "table", "range"},
+			nil,
+		),
+		partitionsFailed: prometheus.NewDesc(
+			"surrealdb_table_record_count_partitions_failed",
+			"Number of record ID ranges of a table whose last count failed",
+			[]string{"namespace", "database", "table"},
+			nil,
+		),
+		databaseRecordCount: prometheus.NewDesc(
+			"surrealdb_database_record_count",
+			"Number of records in the counted tables of a database",
+			[]string{"namespace", "database"},
+			nil,
+		),
+		namespaceRecordCount: prometheus.NewDesc(
+			"surrealdb_namespace_record_count",
+			"Number of records in the counted tables of a namespace",
+			[]string{"namespace"},
+			nil,
+		),
+		otherTables: prometheus.NewDesc(
+			"surrealdb_record_count_other_tables",
+			"Number of tables outside the top N largest whose record counts are aggregated",
+			nil,
+			nil,
+		),
+		otherRecordCount: prometheus.NewDesc(
+			"surrealdb_record_count_other_records",
+			"Total number of records in the tables outside the top N largest, without incomplete partitioned counts",
+			nil,
+			nil,
+		),
 		scrapeDuration: prometheus.NewDesc(
 			"surrealdb_record_count_scrape_duration_seconds",
 			"Duration of the record count scrape in seconds",
@@ -48,14 +158,25 @@ func NewRecordCountCollector(reader RecordCountReader, filter TableFilter) prome
 // Describe implements prometheus.Collector.
 func (c *recordCountCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.tableRecordCount
+	ch <- c.tableRecordCountTimestamp
+	ch <- c.reconciliationAge
+	ch <- c.recordCountDelta
+	ch <- c.recordCountGrowthRate
+	ch <- c.partitionRecordCount
+	ch <- c.partitionsFailed
+	ch <- c.databaseRecordCount
+	ch <- c.namespaceRecordCount
+	ch <- c.otherTables
+	ch <- c.otherRecordCount
 	ch <- c.scrapeDuration
 }
 
 // Collect implements prometheus.Collector.
 func (c *recordCountCollector) Collect(ch chan<- prometheus.Metric) {
-	ctx := context.Background()
+	ctx, span := startCollectSpan("record_count")
+	defer span.End()
 
-	tables := c.tableInfoCache.get()
+	tables := c.tableCache.Tables()
 
 	if len(tables) == 0 {
 		slog.Warn("no tables found to collect record counts")
@@ -97,10 +218,32 @@ func (c *recordCountCollector) Collect(ch chan<- prometheus.Metric) {
 	metrics, err := c.reader.RecordCount(ctx, filteredTables)
 	if err != nil {
 		slog.Error("unable to collect record counts", "error", err)
-		return
+		if metrics == nil {
+			return
+		}
+	}
+
+	if c.counts != nil {
+		c.counts.SetRecordCounts(metrics.Tables)
 	}
 
-	for _, tableCount := range metrics.Tables {
+	c.collectAggregates(ch, metrics.Tables)
+
+	tableCounts := metrics.Tables
+	if c.topN != nil {
+		var rest []*domain.TableRecordCount
+		tableCounts, rest = c.topN.split(metrics.Tables, time.Now())
+		c.collectOther(ch, rest)
+	}
+
+	for _, tableCount := range tableCounts {
+		c.collectPartitions(ch, tableCount)
+
+		// The total of a partially failed partitioned count is incomplete.
+		if tableCount.PartitionsFailed > 0 {
+			continue
+		}
+
 		ch <- prometheus.MustNewConstMetric(
 			c.tableRecordCount,
 			prometheus.GaugeValue,
@@ -109,11 +252,141 @@ func (c *recordCountCollector) Collect(ch chan<- prometheus.Metric) {
 			tableCount.Database,
 			tableCount.Name,
 		)
+
+		if !tableCount.CountedAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.tableRecordCountTimestamp,
+				prometheus.GaugeValue,
+				float64(tableCount.CountedAt.UnixNano())/1e9,
+				tableCount.Namespace,
+				tableCount.Database,
+				tableCount.Name,
+			)
+		}
+
+		if tableCount.Incremental && !tableCount.CountedAt.IsZero() {
+			ch <- prometheus.MustNewConstMetric(
+				c.reconciliationAge,
+				prometheus.GaugeValue,
+				time.Since(tableCount.CountedAt).Seconds(),
+				tableCount.Namespace,
+				tableCount.Database,
+				tableCount.Name,
+			)
+		}
 	}
 
+	c.collectGrowth(ch, tableCounts)
+
 	ch <- prometheus.MustNewConstMetric(
 		c.scrapeDuration,
 		prometheus.GaugeValue,
 		metrics.ScrapeDuration.Seconds(),
 	)
 }
+
+// collectPartitions emits the per-range counts of a partitioned record count.
+func (c *recordCountCollector) collectPartitions(ch chan<- prometheus.Metric, tableCount *domain.TableRecordCount) {
+	if len(tableCount.Partitions) == 0 && tableCount.PartitionsFailed == 0 {
+		return
+	}
+
+	for _, partition := range tableCount.Partitions {
+		ch <- prometheus.MustNewConstMetric(
+			c.partitionRecordCount,
+			prometheus.GaugeValue,
+			float64(partition.RecordCount),
+			tableCount.Namespace,
+			tableCount.Database,
+			tableCount.Name,
+			partition.Range,
+		)
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.partitionsFailed,
+		prometheus.GaugeValue,
+		float64(tableCount.PartitionsFailed),
+		tableCount.Namespace,
+		tableCount.Database,
+		tableCount.Name,
+	)
+}
+
+// collectGrowth emits the change of the record counts since their previous counts.
+func (c *recordCountCollector) collectGrowth(ch chan<- prometheus.Metric, counts []*domain.TableRecordCount) {
+	for id, growth := range c.growth.observe(counts, time.Now()) {
+		ch <- prometheus.MustNewConstMetric(
+			c.recordCountDelta,
+			prometheus.GaugeValue,
+			float64(growth.delta),
+			id.Namespace,
+			id.Database,
+			id.Table,
+		)
+
+		ch <- prometheus.MustNewConstMetric(
+			c.recordCountGrowthRate,
+			prometheus.GaugeValue,
+			growth.rate,
+			id.Namespace,
+			id.Database,
+			id.Table,
+		)
+	}
+}
+
+// collectOther emits the aggregate of the tables outside the top N. Incomplete partitioned
+// counts are left out.
+func (c *recordCountCollector) collectOther(ch chan<- prometheus.Metric, rest []*domain.TableRecordCount) {
+	tables, records := 0, 0
+	for _, tableCount := range rest {
+		if tableCount.PartitionsFailed > 0 {
+			continue
+		}
+
+		tables++
+		records += tableCount.RecordCount
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.otherTables, prometheus.GaugeValue, float64(tables))
+	ch <- prometheus.MustNewConstMetric(c.otherRecordCount, prometheus.GaugeValue, float64(records))
+}
+
+// collectAggregates emits the record counts summed per database and namespace over all
+// counted tables, including those outside the top N. Incomplete partitioned counts are
+// left out.
+func (c *recordCountCollector) collectAggregates(ch chan<- prometheus.Metric, counts []*domain.TableRecordCount) {
+	type databaseKey struct{ namespace, database string }
+
+	databases := make(map[databaseKey]int)
+	namespaces := make(map[string]int)
+
+	for _, tableCount := range counts {
+		if tableCount.PartitionsFailed > 0 {
+			continue
+		}
+
+		databases[databaseKey{tableCount.Namespace, tableCount.Database}] += tableCount.RecordCount
+		namespaces[tableCount.Namespace] += tableCount.RecordCount
+	}
+
+	for key, records := range databases {
+		ch <- prometheus.MustNewConstMetric(
+			c.databaseRecordCount,
+			prometheus.GaugeValue,
+			float64(records),
+			key.namespace,
+			key.database,
+		)
+	}
+
+	for namespace, records := range namespaces {
+		ch <- prometheus.MustNewConstMetric(
+			c.namespaceRecordCount,
+			prometheus.GaugeValue,
+			float64(records),
+			namespace,
+		)
+	}
+}
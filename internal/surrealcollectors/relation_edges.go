@@ -0,0 +1,74 @@
+package surrealcollectors
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func init() {
+	Register(Registration{
+		Name:        CollectorRelationEdges,
+		LowPriority: true,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			return NewRelationEdgesCollector(deps.RelationEdgeReader, deps.TableCache)
+		},
+		NamespaceScoped: true,
+	})
+}
+
+// RelationEdgeReader defines the interface for counting the edges of relation tables.
+type RelationEdgeReader interface {
+	RelationEdges(ctx context.Context, tables []*domain.TableInfo) ([]*domain.RelationEdgeCount, error)
+}
+
+// RelationEdgesCollector exports the edge counts of relation tables by the tables their
+// edges connect, so edges between unexpected tables stand out.
+type RelationEdgesCollector struct {
+	reader     RelationEdgeReader
+	tableCache TableCache
+
+	edgesDesc *prometheus.Desc
+}
+
+// NewRelationEdgesCollector creates a collector counting the edges of the relation tables
+// in tableCache.
+func NewRelationEdgesCollector(reader RelationEdgeReader, tableCache TableCache) *RelationEdgesCollector {
+	return &RelationEdgesCollector{
+		reader:     reader,
+		tableCache: tableCache,
+		edgesDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, "relation", "edges"),
+			"Number of edges in a relation table from records of from_table to records of to_table",
+			[]string{"namespace", "database", "relation", "from_table", "to_table"},
+			nil,
+		),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *RelationEdgesCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.edgesDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *RelationEdgesCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, span := startCollectSpan("relation_edges")
+	defer span.End()
+
+	counts, err := c.reader.RelationEdges(ctx, c.tableCache.Tables())
+	if err != nil {
+		slog.Error("unable to count relation edges", "error", err)
+	}
+
+	for _, count := range counts {
+		ch <- prometheus.MustNewConstMetric(
+			c.edgesDesc,
+			prometheus.GaugeValue,
+			float64(count.Count),
+			count.Namespace, count.Database, count.Relation, count.FromTable, count.ToTable,
+		)
+	}
+}
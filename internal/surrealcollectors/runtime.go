@@ -0,0 +1,27 @@
+package surrealcollectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+func init() {
+	Register(Registration{
+		Name:      CollectorGo,
+		Telemetry: true,
+		Factory: func(Dependencies) prometheus.Collector {
+			return collectorGroup{
+				collectors.NewBuildInfoCollector(),
+				collectors.NewGoCollector(collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll)),
+			}
+		},
+	})
+
+	Register(Registration{
+		Name:      CollectorProcess,
+		Telemetry: true,
+		Factory: func(Dependencies) prometheus.Collector {
+			return collectors.NewProcessCollector(collectors.ProcessCollectorOpts{})
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package surrealcollectors
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// schemaTracker hashes the schema seen by the info collector and counts how often the
+// hash changed since the exporter started.
+type schemaTracker struct {
+	mu      sync.Mutex
+	hash    string
+	changes uint64
+}
+
+// observe hashes the schema of info and returns the hash with the number of changes. Info
+// with errors is incomplete, so it keeps the previous hash instead of counting a change;
+// ok is false when no complete schema has been seen yet.
+func (t *schemaTracker) observe(info *domain.SurrealDBInfo) (hash string, changes uint64, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(info.Errors) == 0 {
+		current := schemaHash(info)
+		if t.hash != "" && current != t.hash {
+			t.changes++
+		}
+		t.hash = current
+	}
+
+	return t.hash, t.changes, t.hash != ""
+}
+
+// schemaHash returns a hash of the namespaces, databases with their definition counts,
+// tables with their field and event counts and DEFINE TABLE settings, and indexes of info, independent of the order
+// of its maps.
+func schemaHash(info *domain.SurrealDBInfo) string {
+	h := sha256.New()
+
+	for _, nsName := range slices.Sorted(maps.Keys(info.Namespaces)) {
+		ns := info.Namespaces[nsName]
+		writeSchemaLine(h, "namespace", nsName)
+
+		for _, dbName := range slices.Sorted(maps.Keys(ns.Databases)) {
+			db := ns.Databases[dbName]
+			writeSchemaLine(h, "database", dbName, db.Analyzers, db.Apis, db.Configs, db.Functions, db.Models, db.Params)
+
+			for _, tableName := range slices.Sorted(maps.Keys(db.Tables)) {
+				table := db.Tables[tableName]
+				writeSchemaLine(h, "table", tableName, table.Fields, table.Events, table.Tables, table.Definition)
+
+				for _, indexName := range slices.Sorted(maps.Keys(table.Indexes)) {
+					writeSchemaLine(h, "index", indexName)
+				}
+			}
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+}
+
+// writeSchemaLine writes a schema element of kind with its name and settings to h. Names
+// are quoted, so names containing separators cannot collide.
+func writeSchemaLine(h hash.Hash, kind, name string, settings ...any) {
+	_, _ = fmt.Fprintf(h, "%s %q %v\n", kind, name, settings)
+}
@@ -3,6 +3,7 @@ package surrealcollectors
 import (
 	"log/slog"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
@@ -19,34 +20,57 @@ type StatsTableInfoProvider interface {
 // StatsTableCollector collects metrics from side stats tables.
 type StatsTableCollector struct {
 	statsTableProvider StatsTableInfoProvider
-	tableCache         *tableInfoCache
+	tableCache         TableCache
 	filter             TableFilter
 	statsTablePrefix   string
 
-	operations     *prometheus.GaugeVec
+	operationsDesc *prometheus.Desc
+	lastUpdateDesc *prometheus.Desc
 	scrapeDuration *prometheus.Desc
+
+	mu         sync.Mutex
+	lastValues map[string]int64
+}
+
+func init() {
+	Register(Registration{
+		Name: CollectorStatsTable,
+		Factory: func(deps Dependencies) prometheus.Collector {
+			return NewStatsTableCollector(
+				deps.StatsTableProvider,
+				deps.StatsTableFilter,
+				deps.TableCache,
+				deps.Config.StatsTableNamePrefix(),
+			)
+		},
+		NamespaceScoped: true,
+	})
 }
 
 // NewStatsTableCollector creates a new stats table collector.
 func NewStatsTableCollector(
 	statsTableProvider StatsTableInfoProvider,
 	filter TableFilter,
+	tableCache TableCache,
 	statsTablePrefix string,
 ) *StatsTableCollector {
 	return &StatsTableCollector{
 		statsTableProvider: statsTableProvider,
-		tableCache:         getTableInfoCache(),
+		tableCache:         tableCache,
 		filter:             filter,
 		statsTablePrefix:   statsTablePrefix,
 
-		operations: prometheus.NewGaugeVec(
-			prometheus.GaugeOpts{
-				Namespace: domain.Namespace,
-				Subsystem: SubsystemStatsTable,
-				Name:      "operations",
-				Help:      "Total number of operations by type from side stats tables",
-			},
+		operationsDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemStatsTable, "operations_total"),
+			"Total number of operations by type from side stats tables",
 			[]string{"namespace", "database", "table", "operation", "operation_type"},
+			nil,
+		),
+		lastUpdateDesc: prometheus.NewDesc(
+			prometheus.BuildFQName(domain.Namespace, SubsystemStatsTable, "last_update_timestamp_seconds"),
+			"Unix timestamp of the last update written to the side stats table by its event",
+			[]string{"namespace", "database", "table"},
+			nil,
 		),
 		scrapeDuration: prometheus.NewDesc(
 			domain.Namespace+"_"+SubsystemStatsTable+"_scrape_duration_seconds",
@@ -54,20 +78,25 @@ func NewStatsTableCollector(
 			nil,
 			nil,
 		),
+		lastValues: make(map[string]int64),
 	}
 }
 
 // Describe implements prometheus.Collector.
 func (c *StatsTableCollector) Describe(ch chan<- *prometheus.Desc) {
-	c.operations.Describe(ch)
+	ch <- c.operationsDesc
+	ch <- c.lastUpdateDesc
 	ch <- c.scrapeDuration
 }
 
 // Collect implements prometheus.Collector.
 func (c *StatsTableCollector) Collect(ch chan<- prometheus.Metric) {
+	_, span := startCollectSpan("stats_table")
+	defer span.End()
+
 	startTime := time.Now()
 
-	tables := c.tableCache.get()
+	tables := c.tableCache.Tables()
 	if len(tables) == 0 {
 		slog.Debug("No tables in cache for stats table monitoring")
 
@@ -120,105 +149,17 @@ func (c *StatsTableCollector) Collect(ch chan<- prometheus.Metric) {
 		return
 	}
 
+	// Only the counters of the stats tables read now are kept, so removed tables are
+	// forgotten.
+	values := make(map[string]int64)
 	for _, data := range statsData {
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "create",
-			"operation_type": string(domain.OperationTypeRelational),
-		}).Set(float64(data.CreateRelational))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "create",
-			"operation_type": string(domain.OperationTypeKeyValue),
-		}).Set(float64(data.CreateKV))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "create",
-			"operation_type": string(domain.OperationTypeGraph),
-		}).Set(float64(data.CreateGraph))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "create",
-			"operation_type": string(domain.OperationTypeDocument),
-		}).Set(float64(data.CreateDocument))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "update",
-			"operation_type": string(domain.OperationTypeRelational),
-		}).Set(float64(data.UpdateRelational))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "update",
-			"operation_type": string(domain.OperationTypeKeyValue),
-		}).Set(float64(data.UpdateKV))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "update",
-			"operation_type": string(domain.OperationTypeGraph),
-		}).Set(float64(data.UpdateGraph))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "update",
-			"operation_type": string(domain.OperationTypeDocument),
-		}).Set(float64(data.UpdateDocument))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "delete",
-			"operation_type": string(domain.OperationTypeRelational),
-		}).Set(float64(data.DeleteRelational))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "delete",
-			"operation_type": string(domain.OperationTypeKeyValue),
-		}).Set(float64(data.DeleteKV))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "delete",
-			"operation_type": string(domain.OperationTypeGraph),
-		}).Set(float64(data.DeleteGraph))
-
-		c.operations.With(prometheus.Labels{
-			"namespace":      data.Namespace,
-			"database":       data.Database,
-			"table":          data.Table,
-			"operation":      "delete",
-			"operation_type": string(domain.OperationTypeDocument),
-		}).Set(float64(data.DeleteDocument))
+		c.collectOperations(ch, data, values)
+		c.collectLastUpdate(ch, data)
 	}
 
-	c.operations.Collect(ch)
+	c.mu.Lock()
+	c.lastValues = values
+	c.mu.Unlock()
 
 	ch <- prometheus.MustNewConstMetric(
 		c.scrapeDuration,
@@ -226,3 +167,87 @@ func (c *StatsTableCollector) Collect(ch chan<- prometheus.Metric) {
 		time.Since(startTime).Seconds(),
 	)
 }
+
+// collectOperations emits the operation counters of a single stats table and records
+// their values in values.
+// Counters carry the stats table creation time as created timestamp, so a recreated
+// stats table is recognised as a counter reset rather than a decrease.
+func (c *StatsTableCollector) collectOperations(
+	ch chan<- prometheus.Metric,
+	data *domain.StatsTableData,
+	values map[string]int64,
+) {
+	counters := []struct {
+		operation string
+		opType    domain.OperationType
+		value     int64
+	}{
+		{"create", domain.OperationTypeRelational, data.CreateRelational},
+		{"create", domain.OperationTypeKeyValue, data.CreateKV},
+		{"create", domain.OperationTypeGraph, data.CreateGraph},
+		{"create", domain.OperationTypeDocument, data.CreateDocument},
+		{"update", domain.OperationTypeRelational, data.UpdateRelational},
+		{"update", domain.OperationTypeKeyValue, data.UpdateKV},
+		{"update", domain.OperationTypeGraph, data.UpdateGraph},
+		{"update", domain.OperationTypeDocument, data.UpdateDocument},
+		{"delete", domain.OperationTypeRelational, data.DeleteRelational},
+		{"delete", domain.OperationTypeKeyValue, data.DeleteKV},
+		{"delete", domain.OperationTypeGraph, data.DeleteGraph},
+		{"delete", domain.OperationTypeDocument, data.DeleteDocument},
+	}
+
+	for _, counter := range counters {
+		labelValues := []string{
+			data.Namespace, data.Database, data.Table, counter.operation, string(counter.opType),
+		}
+
+		key := strings.Join(labelValues, ":")
+		c.detectReset(key, counter.value)
+		values[key] = counter.value
+
+		var metric prometheus.Metric
+		if data.CreatedAt.IsZero() {
+			metric = prometheus.MustNewConstMetric(
+				c.operationsDesc,
+				prometheus.CounterValue,
+				float64(counter.value),
+				labelValues...,
+			)
+		} else {
+			metric = prometheus.MustNewConstMetricWithCreatedTimestamp(
+				c.operationsDesc,
+				prometheus.CounterValue,
+				float64(counter.value),
+				data.CreatedAt,
+				labelValues...,
+			)
+		}
+
+		ch <- metric
+	}
+}
+
+// collectLastUpdate emits the last update time of a single stats table, allowing alerts
+// when the stats event stops firing.
+func (c *StatsTableCollector) collectLastUpdate(ch chan<- prometheus.Metric, data *domain.StatsTableData) {
+	if data.LastUpdate.IsZero() {
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(
+		c.lastUpdateDesc,
+		prometheus.GaugeValue,
+		float64(data.LastUpdate.UnixNano())/1e9,
+		data.Namespace, data.Database, data.Table,
+	)
+}
+
+// detectReset logs when a counter went backwards, which happens when a stats table is recreated.
+func (c *StatsTableCollector) detectReset(key string, value int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if last, ok := c.lastValues[key]; ok && value < last {
+		slog.Info("Stats table counter reset detected", "series", key, "previous", last, "current", value)
+	}
+}
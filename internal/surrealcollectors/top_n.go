@@ -0,0 +1,67 @@
+package surrealcollectors
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// topNSelector picks the n tables with the most records. The selection is kept for
+// interval, so tables do not flap in and out of the exported set on every scrape.
+type topNSelector struct {
+	n        int
+	interval time.Duration
+
+	mu         sync.Mutex
+	selected   map[domain.TableIdentifier]struct{}
+	selectedAt time.Time
+}
+
+func newTopNSelector(n int, interval time.Duration) *topNSelector {
+	return &topNSelector{n: n, interval: interval}
+}
+
+// split returns the counts of the selected tables and the counts of all others,
+// selecting again from counts when the selection is older than interval.
+func (s *topNSelector) split(
+	counts []*domain.TableRecordCount,
+	now time.Time,
+) (top, rest []*domain.TableRecordCount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.selected == nil || now.Sub(s.selectedAt) >= s.interval {
+		s.selectLocked(counts)
+		s.selectedAt = now
+	}
+
+	for _, count := range counts {
+		if _, ok := s.selected[tableCountID(count)]; ok {
+			top = append(top, count)
+		} else {
+			rest = append(rest, count)
+		}
+	}
+
+	return top, rest
+}
+
+// selectLocked selects the n largest tables of counts (caller must hold lock).
+func (s *topNSelector) selectLocked(counts []*domain.TableRecordCount) {
+	sorted := slices.SortedFunc(slices.Values(counts), func(a, b *domain.TableRecordCount) int {
+		return cmp.Compare(b.RecordCount, a.RecordCount)
+	})
+
+	s.selected = make(map[domain.TableIdentifier]struct{}, s.n)
+	for _, count := range sorted[:min(s.n, len(sorted))] {
+		s.selected[tableCountID(count)] = struct{}{}
+	}
+}
+
+// tableCountID returns the identifier of the table of count.
+func tableCountID(count *domain.TableRecordCount) domain.TableIdentifier {
+	return domain.TableIdentifier{Namespace: count.Namespace, Database: count.Database, Table: count.Name}
+}
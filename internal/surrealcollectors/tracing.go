@@ -0,0 +1,21 @@
+package surrealcollectors
+
+import (
+	"context"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer creates collection spans; SurrealDB query spans of the collection are nested in
+// them.
+var tracer = otel.Tracer("github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors")
+
+// startCollectSpan starts the span of a collection of collector name. prometheus.Collector
+// Collect carries no context, so the span is nested in the scrape in flight, see
+// tracing.CollectContext.
+func startCollectSpan(name string) (context.Context, trace.Span) {
+	ctx, opts := tracing.CollectContext()
+	return tracer.Start(ctx, "collect "+name, opts...)
+}
@@ -0,0 +1,45 @@
+package surrealcollectors
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// uptimeTracker estimates when the SurrealDB server started. SurrealDB does not report
+// its start time, so the server is assumed to have started when the exporter first saw
+// it, and again whenever its node IDs or version change, which happens on restart.
+// Only the changes are server-side restarts; the first sighting is a restart of the
+// exporter, so restarts counts the changes alone.
+type uptimeTracker struct {
+	mu        sync.Mutex
+	startTime time.Time
+	version   string
+	nodeIDs   []string
+	restarts  uint64
+}
+
+// observe records the version and node IDs seen at now and returns the estimated
+// start time and the number of server restarts detected. An empty version is treated
+// as unknown and never signals a restart.
+func (t *uptimeTracker) observe(version string, nodeIDs []string, now time.Time) (time.Time, uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch {
+	case t.startTime.IsZero():
+		t.startTime = now
+		t.nodeIDs = nodeIDs
+
+	case !slices.Equal(t.nodeIDs, nodeIDs) || (version != "" && t.version != "" && version != t.version):
+		t.startTime = now
+		t.nodeIDs = nodeIDs
+		t.restarts++
+	}
+
+	if version != "" {
+		t.version = version
+	}
+
+	return t.startTime, t.restarts
+}
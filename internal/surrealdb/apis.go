@@ -0,0 +1,41 @@
+package surrealdb
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// apiMethodsRegex matches the FOR clauses of a DEFINE API statement, such as
+// "FOR get, post".
+var apiMethodsRegex = regexp.MustCompile(
+	`(?i)\bFOR\s+((?:any|get|post|put|patch|delete|trace)(?:\s*,\s*(?:any|get|post|put|patch|delete|trace))*)\b`)
+
+// parseAPIDefinitions returns the APIs of INFO FOR DB, keyed by path with DEFINE API
+// statements as values, sorted by path. Methods are lower case, sorted and unique.
+func parseAPIDefinitions(apis map[string]any) []domain.APIInfo {
+	result := make([]domain.APIInfo, 0, len(apis))
+
+	for _, path := range slices.Sorted(maps.Keys(apis)) {
+		definition, _ := apis[path].(string)
+
+		var methods []string
+		for _, match := range apiMethodsRegex.FindAllStringSubmatch(definition, -1) {
+			for method := range strings.SplitSeq(match[1], ",") {
+				methods = append(methods, strings.ToLower(strings.TrimSpace(method)))
+			}
+		}
+
+		slices.Sort(methods)
+
+		result = append(result, domain.APIInfo{
+			Path:    path,
+			Methods: slices.Compact(methods),
+		})
+	}
+
+	return result
+}
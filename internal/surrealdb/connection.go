@@ -1,14 +1,25 @@
 package surrealdb
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/gorilla/websocket"
 	"github.com/surrealdb/surrealdb.go"
+	"github.com/surrealdb/surrealdb.go/pkg/connection"
+	"github.com/surrealdb/surrealdb.go/pkg/connection/gorillaws"
+	"github.com/surrealdb/surrealdb.go/pkg/connection/http"
 )
 
 const commonConnectionKey = "__common__"
@@ -17,43 +28,161 @@ type Config interface {
 	SurrealURL() string
 	SurrealUsername() string
 	SurrealPassword() string
+	SurrealUserLevel() string
+	SurrealScope() []domain.ScopeEntry
+	SurrealCredentialOverrides() []domain.CredentialOverride
 	SurrealTimeout() time.Duration // TODO figure out if required
+	SurrealConnection() domain.ConnectionSettings
 	StatsTableNamePrefix() string
+	InfoNamespaceCacheTTL() time.Duration
+	InfoDatabaseCacheTTL() time.Duration
+	InfoTableCacheTTL() time.Duration
+	InfoIncrementalTableTTL() time.Duration
+	InfoIndexCacheTTL() time.Duration
+	InfoDetailedSchema() bool
+	InfoTimeouts() domain.InfoTimeouts
 }
 
+// ConnectionManager provides connections to SurrealDB. Get returns the SDK connection,
+// which live queries need; everything else should query through a Querier.
 type ConnectionManager interface {
+	QuerierProvider
 	Get(ctx context.Context, ns, db string) (*surrealdb.DB, error)
 }
 
+// ConnectionStatsProvider is implemented by connection managers that count the
+// connections they open and close.
+type ConnectionStatsProvider interface {
+	ConnectionStats() domain.ConnectionStats
+}
+
+// ConnectionStatusProvider is implemented by connection managers that can list the
+// connections they hold.
+type ConnectionStatusProvider interface {
+	ConnectionStatus() []domain.ConnectionStatus
+}
+
+// ConnectionGenerations is implemented by connection managers that re-establish closed
+// connections. Generation returns how often the connection to ns/db was re-established,
+// so holders of connection bound state such as live query IDs can recreate it.
+type ConnectionGenerations interface {
+	Generation(ns, db string) uint64
+}
+
+// managedConnection is a cached connection with the generation it was established in.
+type managedConnection struct {
+	namespace  string
+	database   string
+	db         *surrealdb.DB
+	conn       connection.Connection
+	querier    *sdkQuerier
+	generation uint64
+	lastUsed   atomic.Int64
+}
+
+// closed reports whether the websocket of the connection was closed. HTTP connections
+// are never reported as closed.
+func (c *managedConnection) closed() bool {
+	closer, ok := c.conn.(interface{ IsClosed() bool })
+	return ok && closer.IsClosed()
+}
+
+// touch records that the connection was handed out now.
+func (c *managedConnection) touch() {
+	c.lastUsed.Store(time.Now().UnixNano())
+}
+
+// idleFor returns how long ago the connection was last handed out.
+func (c *managedConnection) idleFor(now time.Time) time.Duration {
+	return now.Sub(time.Unix(0, c.lastUsed.Load()))
+}
+
 type multiConnectionManager struct {
 	connections sync.Map
 	creating    sync.Map
 	cfg         Config
+	limiter     *QueryLimiter
+
+	// evicted holds the generation of connections closed by the reaper, so connections
+	// re-established later get a new generation.
+	evicted sync.Map
+
+	opened       atomic.Uint64
+	closedIdle   atomic.Uint64
+	closedBroken atomic.Uint64
 }
 
-func NewMultiConnectionManager(cfg Config) *multiConnectionManager {
+// NewMultiConnectionManager creates a connection manager whose queries are admitted by
+// limiter, nil admitting all.
+func NewMultiConnectionManager(cfg Config, limiter *QueryLimiter) *multiConnectionManager {
 	return &multiConnectionManager{
 		connections: sync.Map{},
 		creating:    sync.Map{},
 		cfg:         cfg,
+		limiter:     limiter,
 	}
 }
 
 func (m *multiConnectionManager) Get(ctx context.Context, ns, db string) (*surrealdb.DB, error) {
-	if (ns == "" && db != "") || (ns != "" && db == "") {
-		return nil, errors.New("namespace and database must both be provided or both be empty")
+	conn, err := m.managed(ctx, ns, db)
+	if err != nil {
+		return nil, err
 	}
 
+	return conn.db, nil
+}
+
+// Querier implements QuerierProvider.
+func (m *multiConnectionManager) Querier(ctx context.Context, ns, db string) (Querier, error) {
+	conn, err := m.managed(ctx, ns, db)
+	if err != nil {
+		return nil, err
+	}
+
+	return conn.querier, nil
+}
+
+// managed returns the connection to ns/db, or to the namespace ns when db is empty.
+// Without root access there is no root connection, so the connection to the first scope
+// entry serves the queries not bound to a database, such as the version.
+func (m *multiConnectionManager) managed(ctx context.Context, ns, db string) (*managedConnection, error) {
+	if ns == "" && db != "" {
+		return nil, errors.New("database cannot be provided without namespace")
+	}
+
+	if ns == "" {
+		if scope := m.cfg.SurrealScope(); len(scope) > 0 && m.cfg.SurrealUserLevel() != domain.AuthLevelRoot {
+			ns, db = scope[0].Namespace, scope[0].Database
+		}
+	}
+
+	return m.getOrCreate(ctx, connectionKey(ns, db), ns, db)
+}
+
+// Generation implements ConnectionGenerations.
+func (m *multiConnectionManager) Generation(ns, db string) uint64 {
+	if conn, ok := m.connections.Load(connectionKey(ns, db)); ok {
+		return conn.(*managedConnection).generation
+	}
+
+	return 0
+}
+
+// connectionKey returns the key of the connection to ns/db.
+func connectionKey(ns, db string) string {
 	if ns != "" { // it is not necessary to check db value after first condition
-		return m.getOrCreate(ctx, ns+":"+db, ns, db)
+		return ns + ":" + db
 	}
 
-	return m.getOrCreate(ctx, commonConnectionKey, "", "")
+	return commonConnectionKey
 }
 
-func (m *multiConnectionManager) getOrCreate(ctx context.Context, key, ns, db string) (*surrealdb.DB, error) {
-	if conn, ok := m.connections.Load(key); ok {
-		return conn.(*surrealdb.DB), nil
+// getOrCreate returns the cached connection for key, replacing it with a connection of
+// the next generation when its websocket was closed, e.g. after a SurrealDB failover.
+func (m *multiConnectionManager) getOrCreate(ctx context.Context, key, ns, db string) (*managedConnection, error) {
+	if conn, ok := m.connections.Load(key); ok && !conn.(*managedConnection).closed() {
+		conn.(*managedConnection).touch()
+		return conn.(*managedConnection), nil
 	}
 
 	mutexInterface, _ := m.creating.LoadOrStore(key, &sync.Mutex{})
@@ -62,40 +191,168 @@ func (m *multiConnectionManager) getOrCreate(ctx context.Context, key, ns, db st
 	mutex.Lock()
 	defer mutex.Unlock()
 
+	var generation uint64
 	if conn, ok := m.connections.Load(key); ok {
-		return conn.(*surrealdb.DB), nil
+		if !conn.(*managedConnection).closed() {
+			conn.(*managedConnection).touch()
+			return conn.(*managedConnection), nil
+		}
+
+		if !m.cfg.SurrealConnection().AutoReconnect {
+			return nil, classify(domain.ErrConnection,
+				fmt.Errorf("connection %s was closed and auto_reconnect is disabled", key))
+		}
+
+		generation = conn.(*managedConnection).generation + 1
+		slog.Warn("SurrealDB connection closed, reconnecting", "connection", key, "generation", generation)
+		m.closedBroken.Add(1)
+	} else if evicted, ok := m.evicted.Load(key); ok {
+		generation = evicted.(uint64) + 1
 	}
 
-	newConn, err := createConnection(ctx, m.cfg, ns, db)
+	newConn, err := createConnection(ctx, m.cfg, ns, db, m.limiter)
 	if err != nil {
 		return nil, err
 	}
 
+	newConn.namespace = ns
+	newConn.database = db
+	newConn.generation = generation
+	newConn.touch()
 	m.connections.Store(key, newConn)
+	m.evicted.Delete(key)
+	m.opened.Add(1)
 
 	return newConn, nil
 }
 
-func createConnection(ctx context.Context, cfg Config, ns, db string) (*surrealdb.DB, error) {
-	conn, err := surrealdb.FromEndpointURLString(ctx, cfg.SurrealURL())
+// StartReaper closes and evicts database connections not handed out for idleTimeout,
+// e.g. those of deleted databases, until ctx is done. The root connection is kept.
+func (m *multiConnectionManager) StartReaper(ctx context.Context, idleTimeout time.Duration) {
+	go func() {
+		ticker := time.NewTicker(max(idleTimeout/2, time.Second))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				m.reapIdle(now, idleTimeout)
+			}
+		}
+	}()
+}
+
+// reapIdle closes and evicts the database connections idle for longer than idleTimeout.
+func (m *multiConnectionManager) reapIdle(now time.Time, idleTimeout time.Duration) {
+	m.connections.Range(func(key, value any) bool {
+		conn := value.(*managedConnection)
+		if key == commonConnectionKey || conn.idleFor(now) <= idleTimeout {
+			return true
+		}
+
+		// Hold the creation lock, so the connection is not replaced meanwhile.
+		mutexInterface, _ := m.creating.LoadOrStore(key, &sync.Mutex{})
+		mutex := mutexInterface.(*sync.Mutex)
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if conn.idleFor(now) <= idleTimeout || !m.connections.CompareAndDelete(key, conn) {
+			return true
+		}
+
+		m.evicted.Store(key, conn.generation)
+		m.closedIdle.Add(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.cfg.SurrealTimeout())
+		closeConnectionWithWarning(ctx, conn.db)
+		cancel()
+
+		slog.Debug("Closed idle SurrealDB connection", "connection", key, "idle", conn.idleFor(now))
+
+		return true
+	})
+}
+
+// Close closes every connection. Holders of connections, such as live queries, must be
+// stopped first, or they re-establish the connections they lose.
+func (m *multiConnectionManager) Close() {
+	m.connections.Range(func(key, value any) bool {
+		m.connections.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.Background(), m.cfg.SurrealTimeout())
+		closeConnectionWithWarning(ctx, value.(*managedConnection).db)
+		cancel()
+
+		return true
+	})
+}
+
+// ConnectionStats implements ConnectionStatsProvider.
+func (m *multiConnectionManager) ConnectionStats() domain.ConnectionStats {
+	stats := domain.ConnectionStats{
+		Opened:       m.opened.Load(),
+		ClosedIdle:   m.closedIdle.Load(),
+		ClosedBroken: m.closedBroken.Load(),
+	}
+
+	m.connections.Range(func(_, value any) bool {
+		if !value.(*managedConnection).closed() {
+			stats.Open++
+		}
+		return true
+	})
+
+	return stats
+}
+
+// ConnectionStatus implements ConnectionStatusProvider.
+func (m *multiConnectionManager) ConnectionStatus() []domain.ConnectionStatus {
+	now := time.Now()
+
+	var status []domain.ConnectionStatus
+	m.connections.Range(func(_, value any) bool {
+		conn := value.(*managedConnection)
+		status = append(status, domain.ConnectionStatus{
+			Namespace:   conn.namespace,
+			Database:    conn.database,
+			Generation:  conn.generation,
+			Closed:      conn.closed(),
+			IdleSeconds: conn.idleFor(now).Seconds(),
+		})
+		return true
+	})
+
+	slices.SortFunc(status, func(a, b domain.ConnectionStatus) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Database, b.Database))
+	})
+
+	return status
+}
+
+func createConnection(ctx context.Context, cfg Config, ns, db string, limiter *QueryLimiter) (*managedConnection, error) {
+	sdkConn, err := newSDKConnection(cfg.SurrealURL(), cfg.SurrealConnection())
 	if err != nil {
-		return nil, fmt.Errorf("unable to connect to SurrealDB: %w", err)
+		return nil, classify(domain.ErrConnection, fmt.Errorf("unable to connect to SurrealDB: %w", err))
 	}
 
-	authData := &surrealdb.Auth{
-		Username: cfg.SurrealUsername(),
-		Password: cfg.SurrealPassword(),
+	conn, err := surrealdb.FromConnection(ctx, sdkConn)
+	if err != nil {
+		return nil, classify(domain.ErrConnection, fmt.Errorf("unable to connect to SurrealDB: %w", err))
 	}
 
+	authData := authFor(cfg, ns, db)
+
 	token, err := conn.SignIn(ctx, authData)
 	if err != nil {
 		closeConnectionWithWarning(ctx, conn)
-		return nil, fmt.Errorf("unable to sign in to SurrealDB: %w", err)
+		return nil, classifyAuthError(ctx, fmt.Errorf("unable to sign in to SurrealDB: %w", err))
 	}
 
 	if err = conn.Authenticate(ctx, token); err != nil {
 		closeConnectionWithWarning(ctx, conn)
-		return nil, fmt.Errorf("unable to authenticate: %w", err)
+		return nil, classifyAuthError(ctx, fmt.Errorf("unable to authenticate: %w", err))
 	}
 
 	if ns != "" && db != "" {
@@ -105,7 +362,123 @@ func createConnection(ctx context.Context, cfg Config, ns, db string) (*surreald
 		}
 	}
 
-	return conn, nil
+	return &managedConnection{db: conn, conn: sdkConn, querier: newSDKQuerier(conn, sdkConn, ns, db, limiter)}, nil
+}
+
+// newSDKConnection creates the SDK connection for endpoint like
+// surrealdb.FromEndpointURLString, keeping it accessible to detect closed websockets.
+// Websockets are kept alive as configured by settings.
+func newSDKConnection(endpoint string, settings domain.ConnectionSettings) (connection.Connection, error) {
+	u, err := url.ParseRequestURI(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	conf := connection.NewConfig(u)
+	if err = conf.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid connection config: %w", err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return http.New(conf), nil
+	case "ws", "wss":
+		ws := gorillaws.New(conf)
+		if settings.PingInterval > 0 {
+			ws.Option = append(ws.Option, keepAlive(settings))
+		}
+		return ws, nil
+	default:
+		return nil, fmt.Errorf("unsupported connection scheme %q", u.Scheme)
+	}
+}
+
+// keepAlive returns a gorillaws option pinging the server every PingInterval. When no pong
+// arrived within ReadTimeout, or a ping cannot be written within WriteTimeout, the socket
+// is closed so the connection reports itself closed and gets re-established, instead of
+// waiting forever on a connection a load balancer dropped silently.
+func keepAlive(settings domain.ConnectionSettings) gorillaws.Option {
+	return func(ws *gorillaws.Connection) error {
+		conn := ws.Conn
+
+		var lastPong atomic.Int64
+		lastPong.Store(time.Now().UnixNano())
+		conn.SetPongHandler(func(string) error {
+			lastPong.Store(time.Now().UnixNano())
+			return nil
+		})
+
+		go func() {
+			ticker := time.NewTicker(settings.PingInterval)
+			defer ticker.Stop()
+
+			for range ticker.C {
+				if ws.IsClosed() {
+					return
+				}
+
+				silence := time.Since(time.Unix(0, lastPong.Load()))
+				if settings.ReadTimeout > 0 && silence > settings.ReadTimeout {
+					slog.Warn("No pong from SurrealDB, closing the connection", "silence", silence)
+					_ = conn.Close()
+					return
+				}
+
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(settings.WriteTimeout)); err != nil {
+					slog.Warn("Failed to ping SurrealDB, closing the connection", "error", err)
+					_ = conn.Close()
+					return
+				}
+			}
+		}()
+
+		return nil
+	}
+}
+
+// authFor returns the credentials for a connection to ns/db: those of the first matching
+// credentials override, or the configured credentials, signing in to ns/db for users
+// without root access.
+func authFor(cfg Config, ns, db string) *surrealdb.Auth {
+	if ns != "" {
+		for _, override := range cfg.SurrealCredentialOverrides() {
+			// Namespace connections match on the namespace of the pattern only, and
+			// only users defined on the namespace can sign in to them.
+			if db == "" {
+				patternNamespace, _, _ := strings.Cut(override.Pattern, ":")
+				if matched, _ := path.Match(patternNamespace, ns); !matched || override.Level != domain.AuthLevelNamespace {
+					continue
+				}
+			} else if matched, _ := path.Match(override.Pattern, ns+":"+db); !matched {
+				continue
+			}
+
+			auth := &surrealdb.Auth{
+				Namespace: ns,
+				Username:  override.Username,
+				Password:  override.Password,
+			}
+			if override.Level == domain.AuthLevelDatabase {
+				auth.Database = db
+			}
+
+			return auth
+		}
+	}
+
+	auth := &surrealdb.Auth{
+		Username: cfg.SurrealUsername(),
+		Password: cfg.SurrealPassword(),
+	}
+	switch cfg.SurrealUserLevel() {
+	case domain.AuthLevelNamespace:
+		auth.Namespace = ns
+	case domain.AuthLevelDatabase:
+		auth.Namespace = ns
+		auth.Database = db
+	}
+
+	return auth
 }
 
 func closeConnectionWithWarning(ctx context.Context, conn *surrealdb.DB) {
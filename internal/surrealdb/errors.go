@@ -0,0 +1,77 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	sdk "github.com/surrealdb/surrealdb.go"
+)
+
+// classifiedError marks err as a failure of class, one of the domain.Err* classes,
+// without changing its message.
+type classifiedError struct {
+	class error
+	err   error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.class, e.err}
+}
+
+// classify marks err as a failure of class. Errors already of that class are returned
+// unchanged.
+func classify(class, err error) error {
+	if err == nil || errors.Is(err, class) {
+		return err
+	}
+
+	return &classifiedError{class: class, err: err}
+}
+
+// queryStatus returns the error of a statement that returned a non-OK status.
+func queryStatus(err *sdk.QueryError) error {
+	if err == nil {
+		return domain.ErrQueryStatus
+	}
+
+	return classify(domain.ErrQueryStatus, err)
+}
+
+// classifyQueryError classifies the error of a query run with ctx: a timeout when ctx
+// ran out of time, a query status error when statements failed.
+func classifyQueryError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return classify(domain.ErrTimeout, err)
+	}
+
+	var queryErr *sdk.QueryError
+	if errors.As(err, &queryErr) {
+		return classify(domain.ErrQueryStatus, err)
+	}
+
+	return err
+}
+
+// classifyAuthError classifies the error of a sign in or authentication run with ctx:
+// ErrAuth only when SurrealDB answered with an error, i.e. rejected the credentials, a
+// timeout when ctx ended and a connection failure otherwise.
+func classifyAuthError(ctx context.Context, err error) error {
+	var rpcErr *sdk.RPCError
+	switch {
+	case ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded):
+		return classify(domain.ErrTimeout, err)
+	case errors.As(err, &rpcErr):
+		return classify(domain.ErrAuth, err)
+	default:
+		return classify(domain.ErrConnection, err)
+	}
+}
@@ -0,0 +1,81 @@
+package surrealdb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// serverVersion is a parsed SurrealDB server version.
+type serverVersion struct {
+	major, minor, patch int
+}
+
+// atLeast reports whether v is the same as or newer than other.
+func (v serverVersion) atLeast(other serverVersion) bool {
+	if v.major != other.major {
+		return v.major > other.major
+	}
+	if v.minor != other.minor {
+		return v.minor > other.minor
+	}
+	return v.patch >= other.patch
+}
+
+// featureMinVersions holds the first SurrealDB version providing each feature.
+var featureMinVersions = map[string]serverVersion{
+	domain.FeatureSystemInfo:      {2, 2, 0},
+	domain.FeatureAPIs:            {2, 2, 0},
+	domain.FeatureIndexBuilding:   {2, 0, 0},
+	domain.FeatureDefineOverwrite: {2, 0, 0},
+}
+
+// minServerVersion is the oldest SurrealDB version whose INFO output the readers parse.
+var minServerVersion = serverVersion{1, 0, 0}
+
+// checkServerVersion returns an error matching domain.ErrUnsupportedVersion for servers
+// older than minServerVersion. Versions that cannot be parsed are assumed supported.
+func checkServerVersion(version string) error {
+	parsed, ok := parseServerVersion(version)
+	if ok && !parsed.atLeast(minServerVersion) {
+		return fmt.Errorf("%w: %s, the oldest supported version is %d.%d.%d", domain.ErrUnsupportedVersion,
+			version, minServerVersion.major, minServerVersion.minor, minServerVersion.patch)
+	}
+
+	return nil
+}
+
+// parseServerVersion parses versions like "surrealdb-2.1.0", "2.1.0" or "v2.1.0-beta.1".
+func parseServerVersion(version string) (serverVersion, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "surrealdb-")
+	version = strings.TrimPrefix(version, "v")
+	version, _, _ = strings.Cut(version, "-")
+	version, _, _ = strings.Cut(version, "+")
+
+	parts := strings.SplitN(version, ".", 3)
+	numbers := make([]int, 3)
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return serverVersion{}, false
+		}
+		numbers[i] = n
+	}
+
+	return serverVersion{numbers[0], numbers[1], numbers[2]}, true
+}
+
+// supportedFeatures returns which version-dependent features the server version
+// provides. All features are assumed supported when the version cannot be parsed.
+func supportedFeatures(version string) map[string]bool {
+	parsed, ok := parseServerVersion(version)
+
+	features := make(map[string]bool, len(featureMinVersions))
+	for feature, minVersion := range featureMinVersions {
+		features[feature] = !ok || parsed.atLeast(minVersion)
+	}
+
+	return features
+}
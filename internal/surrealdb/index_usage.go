@@ -0,0 +1,126 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// indexUsageParallelism bounds the sample queries explained at once.
+const indexUsageParallelism = 4
+
+// explainStep is one step of an EXPLAIN plan, e.g. the "Iterate Index" step with the
+// index in detail.plan.index and the table in detail.table.
+type explainStep struct {
+	Operation string         `json:"operation"`
+	Detail    map[string]any `json:"detail"`
+}
+
+type indexUsageReader struct {
+	conn QuerierProvider
+}
+
+// NewIndexUsageReader creates a reader explaining sample queries to find the indexes they
+// use.
+func NewIndexUsageReader(conn QuerierProvider) (*indexUsageReader, error) {
+	if conn == nil {
+		return nil, errors.New("conn argument cannot be nil")
+	}
+
+	return &indexUsageReader{conn: conn}, nil
+}
+
+// IndexUsage returns the EXPLAIN plan of every query, in order. Queries that cannot be
+// explained are returned with their error.
+func (r *indexUsageReader) IndexUsage(ctx context.Context, queries []domain.IndexSampleQuery) []*domain.IndexSamplePlan {
+	plans := make([]*domain.IndexSamplePlan, len(queries))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, indexUsageParallelism)
+	for i, query := range queries {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			plans[i] = r.explain(ctx, query)
+		})
+	}
+	wg.Wait()
+
+	return plans
+}
+
+// explain runs query with EXPLAIN, which returns the plan instead of the records.
+func (r *indexUsageReader) explain(ctx context.Context, query domain.IndexSampleQuery) *domain.IndexSamplePlan {
+	plan := &domain.IndexSamplePlan{Query: query}
+
+	db, err := r.conn.Querier(ctx, query.Namespace, query.Database)
+	if err != nil {
+		plan.Err = fmt.Errorf("could not get DB connection for %s.%s: %w", query.Namespace, query.Database, err)
+		return plan
+	}
+
+	statement := strings.TrimSuffix(strings.TrimSpace(query.Query), ";") + " EXPLAIN;"
+	results, err := tracedQuery[[]explainStep](ctx, db, statement, nil)
+	if err == nil && (results == nil || len(*results) == 0) {
+		err = errors.New("query returned no results")
+	}
+	if err == nil && (*results)[0].Status != "OK" {
+		err = fmt.Errorf("query returned %s status: %w", (*results)[0].Status, queryStatus((*results)[0].Error))
+	}
+	if err != nil {
+		plan.Err = fmt.Errorf("EXPLAIN failed for sample query %s: %w", query.Name, err)
+		return plan
+	}
+
+	for _, step := range (*results)[0].Result {
+		table, _ := step.Detail["table"].(string)
+
+		switch {
+		case strings.HasPrefix(step.Operation, "Iterate Index"):
+			for _, index := range planIndexes(step.Detail["plan"]) {
+				use := domain.TableIndex{Table: table, Index: index}
+				if !slices.Contains(plan.Indexes, use) {
+					plan.Indexes = append(plan.Indexes, use)
+				}
+			}
+
+		case step.Operation == "Iterate Table" && table != "":
+			if !slices.Contains(plan.ScannedTables, table) {
+				plan.ScannedTables = append(plan.ScannedTables, table)
+			}
+		}
+	}
+
+	return plan
+}
+
+// planIndexes returns the index names in the plan of an index iteration. Plans of
+// unions and joins nest the plans of the indexes they combine.
+func planIndexes(plan any) []string {
+	var indexes []string
+
+	switch plan := plan.(type) {
+	case map[string]any:
+		if index, ok := plan["index"].(string); ok {
+			indexes = append(indexes, index)
+		}
+		for key, value := range plan {
+			if key != "index" {
+				indexes = append(indexes, planIndexes(value)...)
+			}
+		}
+
+	case []any:
+		for _, value := range plan {
+			indexes = append(indexes, planIndexes(value)...)
+		}
+	}
+
+	return indexes
+}
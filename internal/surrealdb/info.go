@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"reflect"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
-	sdk "github.com/surrealdb/surrealdb.go"
 )
 
 type rootInfo struct {
@@ -56,6 +60,13 @@ type tableInfo struct {
 	Tables  map[string]any `json:"tables"`
 }
 
+// definedTable is the INFO FOR TABLE result of a table with the definition INFO FOR DB
+// listed for the table when it was fetched.
+type definedTable struct {
+	definition any
+	info       *tableInfo
+}
+
 type indexInfo struct {
 	Building indexBuildingInfo `json:"building"`
 }
@@ -68,29 +79,236 @@ type indexBuildingInfo struct {
 }
 
 type infoReader struct {
-	cfg  Config
-	conn ConnectionManager
+	cfg     Config
+	conn    QuerierProvider
+	version *versionReader
+
+	// scope limits the hierarchy to the configured namespaces and databases, read without
+	// INFO FOR ROOT; empty for the whole server.
+	scope []domain.ScopeEntry
+
+	// features of the server as of the running fetch; version-dependent queries are
+	// skipped when unsupported.
+	features atomic.Pointer[map[string]bool]
+
+	namespaceCache *ttlCache[*namespaceInfo]
+	databaseCache  *ttlCache[*databaseInfo]
+	tableCache     *ttlCache[*tableInfo]
+	indexCache     *ttlCache[*indexInfo]
+
+	// definedTables keeps table info for as long as the DEFINE TABLE statement it was
+	// fetched for is unchanged, bounded by the incremental table TTL, so stable schemas
+	// need no INFO FOR TABLE queries after table_ttl. INFO FOR DB lists no fields, events
+	// or indexes, so their changes are only seen once the TTL expires.
+	definedTables *ttlCache[definedTable]
+
+	flight flightGroup[*domain.SurrealDBInfo]
 }
 
-func NewInfoReader(cfg Config, conn ConnectionManager) (*infoReader, error) {
+func NewInfoReader(cfg Config, conn QuerierProvider) (*infoReader, error) {
 	if conn == nil {
 		return nil, errors.New("conn argument cannot be nil")
 	}
 
-	return &infoReader{cfg: cfg, conn: conn}, nil
+	version, err := NewVersionReader(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return &infoReader{
+		cfg:            cfg,
+		conn:           conn,
+		version:        version,
+		scope:          cfg.SurrealScope(),
+		namespaceCache: newTTLCache[*namespaceInfo](cfg.InfoNamespaceCacheTTL()),
+		databaseCache:  newTTLCache[*databaseInfo](cfg.InfoDatabaseCacheTTL()),
+		tableCache:     newTTLCache[*tableInfo](cfg.InfoTableCacheTTL()),
+		indexCache:     newTTLCache[*indexInfo](cfg.InfoIndexCacheTTL()),
+		definedTables:  newTTLCache[definedTable](cfg.InfoIncrementalTableTTL()),
+	}, nil
 }
 
 // Info retrieves complete hierarchical information about the SurrealDB instance.
+// Concurrent calls share a single backend fetch. With a schema deadline in ctx, the
+// tables of databases reached after it are not fetched.
 func (r *infoReader) Info(ctx context.Context) (*domain.SurrealDBInfo, error) {
+	// Fetches that may skip the schema are not shared with those that need all of it.
+	key := "info"
+	if _, ok := domain.SchemaDeadline(ctx); ok {
+		key = "info/schema_deadline"
+	}
+
+	return r.flight.do(key, func() (*domain.SurrealDBInfo, error) {
+		ctx, cancel := context.WithTimeout(ctx, r.cfg.InfoTimeouts().Total)
+		defer cancel()
+
+		return r.fetchInfo(ctx)
+	})
+}
+
+// ErrNamespaceNotFound is returned for namespaces the server does not have.
+var ErrNamespaceNotFound = errors.New("namespace not found")
+
+// NamespaceInfo retrieves the information of a single namespace and its databases. The
+// root level fields (System, root users and accesses, nodes) are left empty. Concurrent calls for the same namespace share a single
+// backend fetch.
+func (r *infoReader) NamespaceInfo(ctx context.Context, namespace string) (*domain.SurrealDBInfo, error) {
+	return r.flight.do("namespace/"+namespace, func() (*domain.SurrealDBInfo, error) {
+		ctx, cancel := context.WithTimeout(ctx, r.cfg.InfoTimeouts().Total)
+		defer cancel()
+
+		return r.fetchNamespaceInfo(ctx, namespace)
+	})
+}
+
+// NamespaceExists reports whether the server has namespace. Within a scope, only its
+// namespaces exist.
+func (r *infoReader) NamespaceExists(ctx context.Context, namespace string) (bool, error) {
+	if len(r.scope) > 0 {
+		return slices.Contains(scopeNamespaces(r.scope), namespace), nil
+	}
+
+	rootData, err := r.fetchRootInfo(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to fetch root info: %w", err)
+	}
+
+	_, ok := rootData.Namespaces[namespace]
+	return ok, nil
+}
+
+// Databases returns the databases of every namespace, within a scope only those of the
+// scope. INFO FOR NS results are served from cache when fresh.
+func (r *infoReader) Databases(ctx context.Context) (map[string][]string, error) {
+	var namespaces []string
+	if len(r.scope) > 0 {
+		namespaces = scopeNamespaces(r.scope)
+	} else {
+		rootData, err := r.fetchRootInfo(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch root info: %w", err)
+		}
+		namespaces = slices.Sorted(maps.Keys(rootData.Namespaces))
+	}
+
+	databases := make(map[string][]string, len(namespaces))
+	var errs []error
+	for _, namespace := range namespaces {
+		if scoped, ok := scopeDatabases(r.scope, namespace); ok {
+			databases[namespace] = scoped
+			continue
+		}
+
+		nsData, err := r.fetchNamespaceData(ctx, namespace)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to fetch namespace %s: %w", namespace, err))
+			continue
+		}
+		databases[namespace] = slices.Sorted(maps.Keys(nsData.Databases))
+	}
+
+	if len(databases) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return databases, nil
+}
+
+// ForNamespace returns a reader whose Info covers only namespace, for the collectors of
+// a namespace metrics endpoint.
+func (r *infoReader) ForNamespace(namespace string) *namespaceInfoReader {
+	return &namespaceInfoReader{reader: r, namespace: namespace}
+}
+
+// namespaceInfoReader reads the information of one namespace.
+type namespaceInfoReader struct {
+	reader    *infoReader
+	namespace string
+}
+
+// Info implements surrealcollectors.InfoMetricsReader.
+func (r *namespaceInfoReader) Info(ctx context.Context) (*domain.SurrealDBInfo, error) {
+	return r.reader.NamespaceInfo(ctx, r.namespace)
+}
+
+// fetchNamespaceInfo walks the hierarchy below namespace.
+func (r *infoReader) fetchNamespaceInfo(ctx context.Context, namespace string) (*domain.SurrealDBInfo, error) {
+	start := time.Now()
+
+	version, err := r.version.Version(ctx)
+	if err != nil {
+		slog.Debug("Unable to determine SurrealDB version, assuming all features are supported", "error", err)
+	} else if err := checkServerVersion(version); err != nil {
+		return nil, err
+	}
+	features := supportedFeatures(version)
+	r.features.Store(&features)
+
+	exists, err := r.NamespaceExists(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNamespaceNotFound, namespace)
+	}
+
+	var errs infoErrors
+
+	nsInfo, err := r.fetchNamespace(ctx, namespace, &errs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch namespace %s: %w", namespace, err)
+	}
+
+	result := &domain.SurrealDBInfo{
+		Namespaces:     map[string]*domain.NamespaceInfo{namespace: nsInfo},
+		Features:       features,
+		Errors:         errs.list,
+		SchemaSkipped:  errs.schemaSkipped,
+		ScrapeDuration: time.Since(start),
+	}
+
+	if len(result.Errors) > 0 {
+		slog.Warn("Some INFO queries failed, their part of the namespace is missing",
+			"namespace", namespace,
+			"errors", len(result.Errors),
+			"first_error", result.Errors[0].Err)
+	}
+
+	return result, nil
+}
+
+// fetchInfo walks the complete hierarchy starting from the root.
+func (r *infoReader) fetchInfo(ctx context.Context) (*domain.SurrealDBInfo, error) {
 	start := time.Now()
 
+	version, err := r.version.Version(ctx)
+	if err != nil {
+		slog.Debug("Unable to determine SurrealDB version, assuming all features are supported", "error", err)
+	} else if err := checkServerVersion(version); err != nil {
+		return nil, err
+	}
+	features := supportedFeatures(version)
+	r.features.Store(&features)
+
+	if len(r.scope) > 0 {
+		return r.fetchScopedInfo(ctx, start, features)
+	}
+
 	rootData, err := r.fetchRootInfo(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch root info: %w", err)
 	}
 
 	result := &domain.SurrealDBInfo{
-		System: domain.SystemMetrics{
+		Namespaces:   make(map[string]*domain.NamespaceInfo),
+		RootUsers:    len(rootData.Users),
+		RootAccesses: len(rootData.Accesses),
+		Nodes:        len(rootData.Nodes),
+		Features:     features,
+	}
+
+	if features[domain.FeatureSystemInfo] {
+		result.System = domain.SystemMetrics{
 			AvailableParallelism: rootData.System.AvailableParallelism,
 			CpuUsage:             rootData.System.CpuUsage,
 			LoadAverage:          rootData.System.LoadAverage,
@@ -98,39 +316,74 @@ func (r *infoReader) Info(ctx context.Context) (*domain.SurrealDBInfo, error) {
 			MemoryUsage:          rootData.System.MemoryUsage,
 			PhysicalCores:        rootData.System.PhysicalCores,
 			Threads:              rootData.System.Threads,
-		},
-		Namespaces:   make(map[string]*domain.NamespaceInfo),
-		RootUsers:    len(rootData.Users),
-		RootAccesses: len(rootData.Accesses),
-		Nodes:        len(rootData.Nodes),
+		}
 	}
 
+	result.NodeIDs = slices.Sorted(maps.Keys(rootData.Nodes))
+
 	namespaceNames := make([]string, 0, len(rootData.Namespaces))
 	for name := range rootData.Namespaces {
 		namespaceNames = append(namespaceNames, name)
 	}
 
+	var errs infoErrors
+
 	if len(namespaceNames) > 0 {
-		namespaces, err := r.fetchNamespacesParallel(ctx, namespaceNames)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch namespaces: %w", err)
-		}
-		result.Namespaces = namespaces
+		result.Namespaces = r.fetchNamespacesParallel(ctx, namespaceNames, &errs)
 	}
 
+	result.Errors = errs.list
+	result.SchemaSkipped = errs.schemaSkipped
 	result.ScrapeDuration = time.Since(start)
 
+	if len(result.Errors) > 0 {
+		slog.Warn("Some INFO queries failed, their part of the hierarchy is missing",
+			"errors", len(result.Errors),
+			"first_error", result.Errors[0].Err)
+	}
+
 	return result, nil
 }
 
-// fetchRootInfo retrieves root level information.
+// fetchScopedInfo walks the hierarchy below the namespaces and databases of the scope.
+// INFO FOR ROOT is skipped, so the root level fields and system metrics are left empty.
+func (r *infoReader) fetchScopedInfo(
+	ctx context.Context,
+	start time.Time,
+	features map[string]bool,
+) (*domain.SurrealDBInfo, error) {
+	var errs infoErrors
+
+	result := &domain.SurrealDBInfo{
+		Namespaces: r.fetchNamespacesParallel(ctx, scopeNamespaces(r.scope), &errs),
+		Features:   features,
+		Scoped:     true,
+	}
+
+	result.Errors = errs.list
+	result.SchemaSkipped = errs.schemaSkipped
+	result.ScrapeDuration = time.Since(start)
+
+	if len(result.Errors) > 0 {
+		slog.Warn("Some INFO queries failed, their part of the scope is missing",
+			"errors", len(result.Errors),
+			"first_error", result.Errors[0].Err)
+	}
+
+	return result, nil
+}
+
+// fetchRootInfo retrieves root level information within the root timeout budget.
 func (r *infoReader) fetchRootInfo(ctx context.Context) (*rootInfo, error) {
-	db, err := r.conn.Get(ctx, "", "")
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.InfoTimeouts().Root)
+	defer cancel()
+
+	db, err := r.conn.Querier(ctx, "", "")
 	if err != nil {
 		return nil, fmt.Errorf("could not get DB connection: %w", err)
 	}
 
-	results, err := sdk.Query[*rootInfo](ctx, db, "INFO FOR ROOT", nil)
+	results, err := tracedQuery[*rootInfo](ctx, db, "INFO FOR ROOT", nil)
 	if err != nil {
 		return nil, fmt.Errorf("INFO FOR ROOT query failed: %w", err)
 	}
@@ -141,17 +394,19 @@ func (r *infoReader) fetchRootInfo(ctx context.Context) (*rootInfo, error) {
 
 	rootResult := (*results)[0]
 	if rootResult.Status != "OK" {
-		return nil, fmt.Errorf("INFO FOR ROOT returned %s status: %w", rootResult.Status, rootResult.Error)
+		return nil, fmt.Errorf("INFO FOR ROOT returned %s status: %w", rootResult.Status, queryStatus(rootResult.Error))
 	}
 
 	return rootResult.Result, nil
 }
 
-// fetchNamespacesParallel retrieves multiple namespaces in parallel.
+// fetchNamespacesParallel retrieves multiple namespaces in parallel. Namespaces that
+// cannot be fetched are recorded in errs and left out.
 func (r *infoReader) fetchNamespacesParallel(
 	ctx context.Context,
 	namespaceNames []string,
-) (map[string]*domain.NamespaceInfo, error) {
+	errs *infoErrors,
+) map[string]*domain.NamespaceInfo {
 	type nsResult struct {
 		name string
 		info *domain.NamespaceInfo
@@ -165,7 +420,7 @@ func (r *infoReader) fetchNamespacesParallel(
 		wg.Add(1)
 		go func(name string) {
 			defer wg.Done()
-			nsInfo, err := r.fetchNamespace(ctx, name)
+			nsInfo, err := r.fetchNamespace(ctx, name, errs)
 			resultChan <- nsResult{name: name, info: nsInfo, err: err}
 		}(nsName)
 	}
@@ -176,46 +431,42 @@ func (r *infoReader) fetchNamespacesParallel(
 	}()
 
 	namespaces := make(map[string]*domain.NamespaceInfo)
-	var errs []error
 
 	for result := range resultChan {
 		if result.err != nil {
-			errs = append(errs, fmt.Errorf("namespace %s: %w", result.name, result.err))
+			errs.add(domain.InfoLevelNamespace, result.name, "", result.err)
 			continue
 		}
 		namespaces[result.name] = result.info
 	}
 
-	if len(errs) > 0 {
-		return namespaces, fmt.Errorf("errors fetching namespaces: %v", errs)
-	}
-
-	return namespaces, nil
+	return namespaces
 }
 
-// fetchNamespace retrieves information for a single namespace and its databases.
-func (r *infoReader) fetchNamespace(ctx context.Context, namespaceName string) (*domain.NamespaceInfo, error) {
-	db, err := r.conn.Get(ctx, "", "")
-	if err != nil {
-		return nil, fmt.Errorf("could not get DB connection: %w", err)
-	}
-
-	query := fmt.Sprintf("USE NS %s; INFO FOR NS;", namespaceName)
-	results, err := sdk.Query[*namespaceInfo](ctx, db, query, nil)
+// fetchNamespace retrieves information for a single namespace and its databases within
+// the namespace timeout budget.
+func (r *infoReader) fetchNamespace(
+	ctx context.Context,
+	namespaceName string,
+	errs *infoErrors,
+) (*domain.NamespaceInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.InfoTimeouts().Namespace)
+	defer cancel()
+
+	// Namespaces scoped to single databases are not listed with INFO FOR NS, which
+	// database users cannot run; their users and accesses are unknown.
+	if databases, ok := scopeDatabases(r.scope, namespaceName); ok {
+		return &domain.NamespaceInfo{
+			Name:      namespaceName,
+			Databases: r.fetchDatabasesParallel(ctx, namespaceName, databases, errs),
+		}, nil
+	}
+
+	nsData, err := r.fetchNamespaceData(ctx, namespaceName)
 	if err != nil {
-		return nil, fmt.Errorf("INFO FOR NAMESPACE query failed: %w", err)
+		return nil, err
 	}
 
-	if results == nil || len(*results) < 2 {
-		return nil, errors.New("INFO FOR NAMESPACE returned insufficient results")
-	}
-
-	nsResult := (*results)[1]
-	if nsResult.Status != "OK" {
-		return nil, fmt.Errorf("INFO FOR NAMESPACE returned %s status: %w", nsResult.Status, nsResult.Error)
-	}
-
-	nsData := nsResult.Result
 	nsInfo := &domain.NamespaceInfo{
 		Name:      namespaceName,
 		Databases: make(map[string]*domain.DatabaseInfo),
@@ -229,22 +480,51 @@ func (r *infoReader) fetchNamespace(ctx context.Context, namespaceName string) (
 	}
 
 	if len(databaseNames) > 0 {
-		databases, err := r.fetchDatabasesParallel(ctx, namespaceName, databaseNames)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch databases: %w", err)
-		}
-		nsInfo.Databases = databases
+		nsInfo.Databases = r.fetchDatabasesParallel(ctx, namespaceName, databaseNames, errs)
 	}
 
 	return nsInfo, nil
 }
 
-// fetchDatabasesParallel retrieves multiple databases in parallel.
+// fetchNamespaceData returns the raw INFO FOR NS result, served from cache when fresh.
+func (r *infoReader) fetchNamespaceData(ctx context.Context, namespaceName string) (*namespaceInfo, error) {
+	if cached, ok := r.namespaceCache.get(namespaceName); ok {
+		return cached, nil
+	}
+
+	db, err := r.conn.Querier(ctx, namespaceConnection(r.scope, namespaceName), "")
+	if err != nil {
+		return nil, fmt.Errorf("could not get DB connection: %w", err)
+	}
+
+	query := fmt.Sprintf("USE NS %s; INFO FOR NS;", quoteIdent(namespaceName))
+	results, err := tracedQuery[*namespaceInfo](ctx, db, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("INFO FOR NAMESPACE query failed: %w", err)
+	}
+
+	if results == nil || len(*results) < 2 {
+		return nil, errors.New("INFO FOR NAMESPACE returned insufficient results")
+	}
+
+	nsResult := (*results)[1]
+	if nsResult.Status != "OK" {
+		return nil, fmt.Errorf("INFO FOR NAMESPACE returned %s status: %w", nsResult.Status, queryStatus(nsResult.Error))
+	}
+
+	r.namespaceCache.set(namespaceName, nsResult.Result)
+
+	return nsResult.Result, nil
+}
+
+// fetchDatabasesParallel retrieves multiple databases in parallel. Databases that cannot
+// be fetched are recorded in errs and left out.
 func (r *infoReader) fetchDatabasesParallel(
 	ctx context.Context,
 	namespace string,
 	databaseNames []string,
-) (map[string]*domain.DatabaseInfo, error) {
+	errs *infoErrors,
+) map[string]*domain.DatabaseInfo {
 	type dbResult struct {
 		name string
 		info *domain.DatabaseInfo
@@ -258,7 +538,7 @@ func (r *infoReader) fetchDatabasesParallel(
 		wg.Add(1)
 		go func(name string) {
 			defer wg.Done()
-			dbInfo, err := r.fetchDatabase(ctx, namespace, name)
+			dbInfo, err := r.fetchDatabase(ctx, namespace, name, errs)
 			resultChan <- dbResult{name: name, info: dbInfo, err: err}
 		}(dbName)
 	}
@@ -269,46 +549,29 @@ func (r *infoReader) fetchDatabasesParallel(
 	}()
 
 	databases := make(map[string]*domain.DatabaseInfo)
-	var errs []error
 
 	for result := range resultChan {
 		if result.err != nil {
-			errs = append(errs, fmt.Errorf("database %s: %w", result.name, result.err))
+			errs.add(domain.InfoLevelDatabase, namespace, result.name, result.err)
 			continue
 		}
 		databases[result.name] = result.info
 	}
 
-	if len(errs) > 0 {
-		return databases, fmt.Errorf("errors fetching databases: %v", errs)
-	}
-
-	return databases, nil
+	return databases
 }
 
 // fetchDatabase retrieves information for a single database and its tables.
-func (r *infoReader) fetchDatabase(ctx context.Context, namespace, databaseName string) (*domain.DatabaseInfo, error) {
-	db, err := r.conn.Get(ctx, namespace, databaseName)
-	if err != nil {
-		return nil, fmt.Errorf("could not get DB connection: %w", err)
-	}
-
-	query := "INFO FOR DB"
-	results, err := sdk.Query[*databaseInfo](ctx, db, query, nil)
+func (r *infoReader) fetchDatabase(
+	ctx context.Context,
+	namespace, databaseName string,
+	errs *infoErrors,
+) (*domain.DatabaseInfo, error) {
+	dbData, err := r.fetchDatabaseData(ctx, namespace, databaseName)
 	if err != nil {
-		return nil, fmt.Errorf("INFO FOR DATABASE query failed: %w", err)
-	}
-
-	if results == nil || len(*results) == 0 {
-		return nil, errors.New("INFO FOR DATABASE returned no results")
+		return nil, err
 	}
 
-	dbResult := (*results)[0]
-	if dbResult.Status != "OK" {
-		return nil, fmt.Errorf("INFO FOR DATABASE returned %s status: %w", dbResult.Status, dbResult.Error)
-	}
-
-	dbData := dbResult.Result
 	dbInfo := &domain.DatabaseInfo{
 		Name:      databaseName,
 		Namespace: namespace,
@@ -323,6 +586,16 @@ func (r *infoReader) fetchDatabase(ctx context.Context, namespace, databaseName
 		Params:    len(dbData.Params),
 	}
 
+	if r.cfg.InfoDetailedSchema() {
+		dbInfo.FunctionNames = slices.Sorted(maps.Keys(dbData.Functions))
+		dbInfo.ParamNames = slices.Sorted(maps.Keys(dbData.Params))
+		dbInfo.AnalyzerNames = slices.Sorted(maps.Keys(dbData.Analyzers))
+
+		if r.supports(domain.FeatureAPIs) {
+			dbInfo.APIDefinitions = parseAPIDefinitions(dbData.Apis)
+		}
+	}
+
 	tableNames := make([]string, 0, len(dbData.Tables))
 	for name := range dbData.Tables {
 		if !strings.HasPrefix(name, r.cfg.StatsTableNamePrefix()) {
@@ -330,197 +603,279 @@ func (r *infoReader) fetchDatabase(ctx context.Context, namespace, databaseName
 		}
 	}
 
-	if len(tableNames) > 0 {
-		tables, err := r.fetchTablesParallel(ctx, namespace, databaseName, tableNames)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch tables: %w", err)
+	if deadline, ok := domain.SchemaDeadline(ctx); ok && time.Now().After(deadline) {
+		errs.skipSchema()
+		for _, name := range tableNames {
+			dbInfo.Tables[name] = &domain.TableInfo{Name: name, Database: databaseName, Namespace: namespace}
 		}
-		dbInfo.Tables = tables
+	} else if len(tableNames) > 0 {
+		dbInfo.Tables = r.fetchTablesBatch(ctx, namespace, databaseName, tableNames, dbData.Tables, errs)
+	}
+
+	for name, table := range dbInfo.Tables {
+		table.Definition = parseTableDefinition(dbData.Tables[name])
 	}
 
 	return dbInfo, nil
 }
 
-// fetchTablesParallel retrieves multiple tables in parallel.
-func (r *infoReader) fetchTablesParallel(
+// fetchDatabaseData returns the raw INFO FOR DB result, served from cache when fresh.
+func (r *infoReader) fetchDatabaseData(ctx context.Context, namespace, databaseName string) (*databaseInfo, error) {
+	cacheKey := namespace + ":" + databaseName
+	if cached, ok := r.databaseCache.get(cacheKey); ok {
+		return cached, nil
+	}
+
+	db, err := r.conn.Querier(ctx, namespace, databaseName)
+	if err != nil {
+		return nil, fmt.Errorf("could not get DB connection: %w", err)
+	}
+
+	query := "INFO FOR DB"
+	results, err := tracedQuery[*databaseInfo](ctx, db, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("INFO FOR DATABASE query failed: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return nil, errors.New("INFO FOR DATABASE returned no results")
+	}
+
+	dbResult := (*results)[0]
+	if dbResult.Status != "OK" {
+		return nil, fmt.Errorf("INFO FOR DATABASE returned %s status: %w", dbResult.Status, queryStatus(dbResult.Error))
+	}
+
+	r.databaseCache.set(cacheKey, dbResult.Result)
+
+	return dbResult.Result, nil
+}
+
+// fetchTablesBatch retrieves information for all given tables of one database and their
+// indexes. Entries missing from the cache, and not reusable because their definition is
+// unchanged, are fetched with one multi-statement query for tables and one for indexes.
+// Tables and indexes that cannot be fetched are recorded in errs and left out. Both
+// queries share the table timeout budget.
+func (r *infoReader) fetchTablesBatch(
 	ctx context.Context,
 	namespace, database string,
 	tableNames []string,
-) (map[string]*domain.TableInfo, error) {
-	type tblResult struct {
-		name string
-		info *domain.TableInfo
-		err  error
-	}
+	definitions map[string]any,
+	errs *infoErrors,
+) map[string]*domain.TableInfo {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.InfoTimeouts().Table)
+	defer cancel()
 
-	resultChan := make(chan tblResult, len(tableNames))
-	var wg sync.WaitGroup
+	tables := make(map[string]*domain.TableInfo, len(tableNames))
 
-	for _, tblName := range tableNames {
-		wg.Add(1)
-		go func(name string) {
-			defer wg.Done()
-			tblInfo, err := r.fetchTable(ctx, namespace, database, name)
-			resultChan <- tblResult{name: name, info: tblInfo, err: err}
-		}(tblName)
+	db, err := r.conn.Querier(ctx, namespace, database)
+	if err != nil {
+		errs.add(domain.InfoLevelTable, namespace, database, fmt.Errorf("could not get DB connection: %w", err))
+		return tables
 	}
 
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+	tableData := make(map[string]*tableInfo, len(tableNames))
+	var missing []string
+	var statements []string
 
-	tables := make(map[string]*domain.TableInfo)
-	var errs []error
+	for _, tableName := range tableNames {
+		key := tableCacheKey(namespace, database, tableName)
+		if cached, ok := r.tableCache.get(key); ok {
+			tableData[tableName] = cached
+			continue
+		}
 
-	for result := range resultChan {
-		if result.err != nil {
-			errs = append(errs, fmt.Errorf("table %s: %w", result.name, result.err))
+		if defined, ok := r.definedTables.get(key); ok && reflect.DeepEqual(defined.definition, definitions[tableName]) {
+			tableData[tableName] = defined.info
 			continue
 		}
-		tables[result.name] = result.info
-	}
 
-	if len(errs) > 0 {
-		return tables, fmt.Errorf("errors fetching tables: %v", errs)
+		missing = append(missing, tableName)
+		statements = append(statements, fmt.Sprintf("INFO FOR TABLE %s", quoteIdent(tableName)))
 	}
 
-	return tables, nil
-}
+	if len(statements) > 0 {
+		results, err := queryBatch[*tableInfo](ctx, db, statements)
+		if err != nil {
+			errs.add(domain.InfoLevelTable, namespace, database, fmt.Errorf("INFO FOR TABLE batch failed: %w", err))
+		}
 
-// fetchTable retrieves information for a single table and its indexes.
-func (r *infoReader) fetchTable(ctx context.Context, namespace, database, tableName string) (*domain.TableInfo, error) {
-	db, err := r.conn.Get(ctx, namespace, database)
-	if err != nil {
-		return nil, fmt.Errorf("could not get DB connection: %w", err)
-	}
+		for i, tblResult := range results {
+			tableName := missing[i]
 
-	query := fmt.Sprintf("INFO FOR TABLE %s", tableName)
-	results, err := sdk.Query[*tableInfo](ctx, db, query, nil)
-	if err != nil {
-		return nil, fmt.Errorf("INFO FOR TABLE query failed: %w", err)
-	}
+			if tblResult.Status != "OK" || tblResult.Result == nil {
+				errs.add(domain.InfoLevelTable, namespace, database,
+					fmt.Errorf("table %s: INFO FOR TABLE returned %s status: %w",
+						tableName, tblResult.Status, queryStatus(tblResult.Error)))
+				continue
+			}
 
-	if results == nil || len(*results) == 0 {
-		return nil, errors.New("INFO FOR TABLE returned no results")
+			tableData[tableName] = tblResult.Result
+			r.tableCache.set(tableCacheKey(namespace, database, tableName), tblResult.Result)
+			r.definedTables.set(tableCacheKey(namespace, database, tableName),
+				definedTable{definition: definitions[tableName], info: tblResult.Result})
+		}
 	}
 
-	tblResult := (*results)[0]
-	if tblResult.Status != "OK" {
-		return nil, fmt.Errorf("INFO FOR TABLE returned %s status: %w", tblResult.Status, tblResult.Error)
-	}
+	var indexRefs []domain.IndexInfo
 
-	tblData := tblResult.Result
-	tblInfo := &domain.TableInfo{
-		Name:      tableName,
-		Database:  database,
-		Namespace: namespace,
-		Indexes:   make(map[string]*domain.IndexInfo),
-		Events:    len(tblData.Events),
-		Fields:    len(tblData.Fields),
-		Lives:     len(tblData.Lives),
-		Tables:    len(tblData.Tables),
-	}
+	for tableName, tblData := range tableData {
+		tables[tableName] = &domain.TableInfo{
+			Name:      tableName,
+			Database:  database,
+			Namespace: namespace,
+			Indexes:   make(map[string]*domain.IndexInfo),
+			Events:    len(tblData.Events),
+			Fields:    len(tblData.Fields),
+			Lives:     len(tblData.Lives),
+			Tables:    len(tblData.Tables),
+		}
 
-	indexNames := make([]string, 0, len(tblData.Indexes))
-	for name := range tblData.Indexes {
-		indexNames = append(indexNames, name)
+		for indexName, indexDefinition := range tblData.Indexes {
+			indexRefs = append(indexRefs, domain.IndexInfo{
+				Name:   indexName,
+				Table:  tableName,
+				Vector: parseVectorIndexDefinition(indexDefinition),
+			})
+		}
 	}
 
-	if len(indexNames) > 0 {
-		indexes, err := r.fetchIndexesParallel(ctx, namespace, database, tableName, indexNames)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch indexes: %w", err)
+	if len(indexRefs) > 0 && r.supports(domain.FeatureIndexBuilding) {
+		indexes := r.fetchIndexesBatch(ctx, db, namespace, database, indexRefs, errs)
+
+		for _, idx := range indexes {
+			if tbl, ok := tables[idx.Table]; ok {
+				tbl.Indexes[idx.Name] = idx
+			}
+		}
+	} else {
+		// Without INFO FOR INDEX, indexes are known by name only.
+		for _, ref := range indexRefs {
+			tables[ref.Table].Indexes[ref.Name] = &domain.IndexInfo{
+				Name:      ref.Name,
+				Table:     ref.Table,
+				Database:  database,
+				Namespace: namespace,
+				Vector:    ref.Vector,
+			}
 		}
-		tblInfo.Indexes = indexes
 	}
 
-	return tblInfo, nil
+	return tables
 }
 
-// fetchIndexesParallel retrieves multiple indexes in parallel.
-func (r *infoReader) fetchIndexesParallel(
+// fetchIndexesBatch retrieves information for the referenced indexes of one database.
+// Entries missing from the cache are fetched using a single multi-statement query.
+// Indexes that cannot be fetched are recorded in errs and left out.
+func (r *infoReader) fetchIndexesBatch(
 	ctx context.Context,
-	namespace, database, table string,
-	indexNames []string,
-) (map[string]*domain.IndexInfo, error) {
-	type idxResult struct {
-		name string
-		info *domain.IndexInfo
-		err  error
-	}
-
-	resultChan := make(chan idxResult, len(indexNames))
-	var wg sync.WaitGroup
+	db Querier,
+	namespace, database string,
+	refs []domain.IndexInfo,
+	errs *infoErrors,
+) []*domain.IndexInfo {
+	indexData := make([]*indexInfo, len(refs))
+	var missing []int
+	var statements []string
+
+	for i, ref := range refs {
+		if cached, ok := r.indexCache.get(indexCacheKey(namespace, database, ref.Table, ref.Name)); ok {
+			indexData[i] = cached
+			continue
+		}
 
-	for _, idxName := range indexNames {
-		wg.Add(1)
-		go func(name string) {
-			defer wg.Done()
-			idxInfo, err := r.fetchIndex(ctx, namespace, database, table, name)
-			resultChan <- idxResult{name: name, info: idxInfo, err: err}
-		}(idxName)
+		missing = append(missing, i)
+		statements = append(statements,
+			fmt.Sprintf("INFO FOR INDEX %s ON %s", quoteIdent(ref.Name), quoteIdent(ref.Table)))
 	}
 
-	go func() {
-		wg.Wait()
-		close(resultChan)
-	}()
+	if len(statements) > 0 {
+		results, err := queryBatch[*indexInfo](ctx, db, statements)
+		if err != nil {
+			errs.add(domain.InfoLevelIndex, namespace, database, fmt.Errorf("INFO FOR INDEX batch failed: %w", err))
+		}
 
-	indexes := make(map[string]*domain.IndexInfo)
-	var errs []error
+		for i, idxResult := range results {
+			ref := refs[missing[i]]
 
-	for result := range resultChan {
-		if result.err != nil {
-			errs = append(errs, fmt.Errorf("index %s: %w", result.name, result.err))
-			continue
+			if idxResult.Status != "OK" || idxResult.Result == nil {
+				errs.add(domain.InfoLevelIndex, namespace, database,
+					fmt.Errorf("index %s on %s: INFO FOR INDEX returned %s status: %w",
+						ref.Name, ref.Table, idxResult.Status, queryStatus(idxResult.Error)))
+				continue
+			}
+
+			indexData[missing[i]] = idxResult.Result
+			r.indexCache.set(indexCacheKey(namespace, database, ref.Table, ref.Name), idxResult.Result)
 		}
-		indexes[result.name] = result.info
 	}
 
-	if len(errs) > 0 {
-		return indexes, fmt.Errorf("errors fetching indexes: %v", errs)
-	}
+	indexes := make([]*domain.IndexInfo, 0, len(refs))
+	for i, idxData := range indexData {
+		if idxData == nil {
+			continue
+		}
 
-	return indexes, nil
+		indexes = append(indexes, &domain.IndexInfo{
+			Name:      refs[i].Name,
+			Table:     refs[i].Table,
+			Database:  database,
+			Namespace: namespace,
+			Building: domain.IndexBuildingMetrics{
+				Initial: idxData.Building.Initial,
+				Pending: idxData.Building.Pending,
+				Status:  idxData.Building.Status,
+				Updated: idxData.Building.Updated,
+			},
+			Vector: refs[i].Vector,
+		})
+	}
+
+	return indexes
 }
 
-// fetchIndex retrieves information for a single index.
-func (r *infoReader) fetchIndex(
-	ctx context.Context,
-	namespace, database, table, indexName string,
-) (*domain.IndexInfo, error) {
-	db, err := r.conn.Get(ctx, namespace, database)
-	if err != nil {
-		return nil, fmt.Errorf("could not get DB connection: %w", err)
-	}
+// infoErrors collects the INFO queries that failed during one fetch. It is safe for
+// concurrent use.
+type infoErrors struct {
+	mu   sync.Mutex
+	list []domain.InfoError
 
-	query := fmt.Sprintf("INFO FOR INDEX %s ON %s", indexName, table)
-	results, err := sdk.Query[*indexInfo](ctx, db, query, nil)
-	if err != nil {
-		return nil, fmt.Errorf("INFO FOR INDEX query failed: %w", err)
-	}
+	// schemaSkipped is set when the tables of a database were skipped for the schema
+	// deadline.
+	schemaSkipped bool
+}
 
-	if results == nil || len(*results) == 0 {
-		return nil, errors.New("INFO FOR INDEX returned no results")
-	}
+// skipSchema records that the tables of a database were skipped for the schema deadline.
+func (e *infoErrors) skipSchema() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
 
-	idxResult := (*results)[0]
-	if idxResult.Status != "OK" {
-		return nil, fmt.Errorf("INFO FOR INDEX returned %s status: %w", idxResult.Status, idxResult.Error)
-	}
+	e.schemaSkipped = true
+}
 
-	idxData := idxResult.Result
-	return &domain.IndexInfo{
-		Name:      indexName,
-		Table:     table,
-		Database:  database,
+// add records a failed INFO query at level.
+func (e *infoErrors) add(level, namespace, database string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.list = append(e.list, domain.InfoError{
+		Level:     level,
 		Namespace: namespace,
-		Building: domain.IndexBuildingMetrics{
-			Initial: idxData.Building.Initial,
-			Pending: idxData.Building.Pending,
-			Status:  idxData.Building.Status,
-			Updated: idxData.Building.Updated,
-		},
-	}, nil
+		Database:  database,
+		Err:       err,
+	})
+}
+
+// supports reports whether the server supports feature as of the running fetch.
+func (r *infoReader) supports(feature string) bool {
+	features := r.features.Load()
+	return features == nil || (*features)[feature]
+}
+
+func tableCacheKey(namespace, database, table string) string {
+	return namespace + ":" + database + ":" + table
+}
+
+func indexCacheKey(namespace, database, table, index string) string {
+	return namespace + ":" + database + ":" + table + ":" + index
 }
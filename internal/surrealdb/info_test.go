@@ -0,0 +1,406 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/fxamacker/cbor/v2"
+	sdk "github.com/surrealdb/surrealdb.go"
+)
+
+// fakeServer answers statements with canned results keyed by the namespace and database
+// they run in, like "shop/app INFO FOR DB". DEFINE and REMOVE statements without a result
+// are answered by define, other statements without a result fail.
+type fakeServer struct {
+	version   string
+	responses map[string]any
+	define    func(statement string) error
+
+	mu         sync.Mutex
+	statements []string
+}
+
+// Querier implements QuerierProvider.
+func (s *fakeServer) Querier(_ context.Context, ns, db string) (Querier, error) {
+	return &fakeQuerier{server: s, ns: ns, db: db}, nil
+}
+
+// ran reports whether a statement starting with prefix was run.
+func (s *fakeServer) ran(prefix string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.ContainsFunc(s.statements, func(statement string) bool {
+		return strings.HasPrefix(statement, prefix)
+	})
+}
+
+// fakeQuerier is a connection to a fakeServer, encoding results with CBOR like the SDK.
+type fakeQuerier struct {
+	server *fakeServer
+	ns, db string
+}
+
+// Query implements Querier.
+func (q *fakeQuerier) Query(_ context.Context, sql string, _ map[string]any) ([]RawQueryResult, error) {
+	ns, db := q.ns, q.db
+
+	var results []RawQueryResult
+	var errs []error
+	for _, statement := range strings.Split(sql, ";") {
+		statement = strings.TrimSpace(statement)
+		if statement == "" {
+			continue
+		}
+
+		if namespace, ok := strings.CutPrefix(statement, "USE NS "); ok {
+			ns, db = strings.Trim(namespace, "⟨⟩"), ""
+			results = append(results, RawQueryResult{Status: "OK"})
+			continue
+		}
+
+		key := ns + "/" + db + " " + statement
+
+		q.server.mu.Lock()
+		q.server.statements = append(q.server.statements, key)
+		q.server.mu.Unlock()
+
+		response, ok := q.server.responses[key]
+		if !ok && q.server.define != nil && (strings.HasPrefix(statement, "DEFINE ") || strings.HasPrefix(statement, "REMOVE ")) {
+			if err := q.server.define(statement); err != nil {
+				queryErr := &sdk.QueryError{Message: err.Error()}
+				results = append(results, RawQueryResult{Status: "ERR", Error: queryErr})
+				errs = append(errs, queryErr)
+				continue
+			}
+
+			response, ok = nil, true
+		}
+		if !ok {
+			queryErr := &sdk.QueryError{Message: "no result for " + key}
+			results = append(results, RawQueryResult{Status: "ERR", Error: queryErr})
+			errs = append(errs, queryErr)
+			continue
+		}
+
+		data, err := cbor.Marshal(response)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, RawQueryResult{Status: "OK", Result: data})
+	}
+
+	return results, errors.Join(errs...)
+}
+
+// Unmarshal implements Querier.
+func (q *fakeQuerier) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
+
+// Version implements Querier.
+func (q *fakeQuerier) Version(context.Context) (string, error) {
+	return q.server.version, nil
+}
+
+// fakeConfig configures the info reader without caching.
+type fakeConfig struct{}
+
+func (fakeConfig) SurrealURL() string                                      { return "" }
+func (fakeConfig) SurrealUsername() string                                 { return "" }
+func (fakeConfig) SurrealPassword() string                                 { return "" }
+func (fakeConfig) SurrealUserLevel() string                                { return "" }
+func (fakeConfig) SurrealScope() []domain.ScopeEntry                       { return nil }
+func (fakeConfig) SurrealCredentialOverrides() []domain.CredentialOverride { return nil }
+func (fakeConfig) SurrealTimeout() time.Duration                           { return time.Second }
+func (fakeConfig) SurrealConnection() domain.ConnectionSettings            { return domain.ConnectionSettings{} }
+func (fakeConfig) StatsTableNamePrefix() string                            { return "_stats_" }
+func (fakeConfig) InfoNamespaceCacheTTL() time.Duration                    { return 0 }
+func (fakeConfig) InfoDatabaseCacheTTL() time.Duration                     { return 0 }
+func (fakeConfig) InfoTableCacheTTL() time.Duration                        { return 0 }
+func (fakeConfig) InfoIncrementalTableTTL() time.Duration                  { return 0 }
+func (fakeConfig) InfoIndexCacheTTL() time.Duration                        { return 0 }
+func (fakeConfig) InfoDetailedSchema() bool                                { return false }
+
+func (fakeConfig) InfoTimeouts() domain.InfoTimeouts {
+	return domain.InfoTimeouts{Total: time.Second, Root: time.Second, Namespace: time.Second, Table: time.Second}
+}
+
+// v1Responses are INFO results of a SurrealDB 1.x server, which reports no system
+// metrics, nodes or accesses and has no INFO FOR INDEX.
+var v1Responses = map[string]any{
+	"/ INFO FOR ROOT": map[string]any{
+		"namespaces": map[string]any{"shop": "DEFINE NAMESPACE shop"},
+		"users":      map[string]any{"root": "DEFINE USER root ON ROOT PASSHASH '...' ROLES OWNER"},
+	},
+	"shop/ INFO FOR NS": map[string]any{
+		"databases": map[string]any{"app": "DEFINE DATABASE app"},
+		"tokens":    map[string]any{},
+		"users":     map[string]any{},
+	},
+	"shop/app INFO FOR DB": map[string]any{
+		"analyzers": map[string]any{},
+		"functions": map[string]any{"fn::total": "DEFINE FUNCTION fn::total() { RETURN 1; }"},
+		"models":    map[string]any{},
+		"params":    map[string]any{},
+		"scopes":    map[string]any{"account": "DEFINE SCOPE account"},
+		"tables": map[string]any{
+			"user":         "DEFINE TABLE user TYPE NORMAL SCHEMAFULL PERMISSIONS NONE",
+			"_stats_user":  "DEFINE TABLE _stats_user TYPE NORMAL SCHEMALESS PERMISSIONS NONE",
+			"order":        "DEFINE TABLE order TYPE NORMAL SCHEMALESS CHANGEFEED 1d PERMISSIONS NONE",
+			"order_placed": "DEFINE TABLE order_placed TYPE RELATION SCHEMALESS PERMISSIONS NONE",
+		},
+		"tokens": map[string]any{},
+		"users":  map[string]any{},
+	},
+	"shop/app INFO FOR TABLE ⟨user⟩": map[string]any{
+		"events": map[string]any{},
+		"fields": map[string]any{
+			"name":  "DEFINE FIELD name ON user TYPE string PERMISSIONS FULL",
+			"email": "DEFINE FIELD email ON user TYPE string PERMISSIONS FULL",
+		},
+		"indexes": map[string]any{"email_idx": "DEFINE INDEX email_idx ON user FIELDS email UNIQUE"},
+		"lives":   map[string]any{},
+		"tables":  map[string]any{},
+	},
+	"shop/app INFO FOR TABLE ⟨order⟩": map[string]any{
+		"events":  map[string]any{"placed": "DEFINE EVENT placed ON order WHEN $event = 'CREATE' THEN {}"},
+		"fields":  map[string]any{},
+		"indexes": map[string]any{},
+		"lives":   map[string]any{},
+		"tables":  map[string]any{},
+	},
+	"shop/app INFO FOR TABLE ⟨order_placed⟩": map[string]any{
+		"events":  map[string]any{},
+		"fields":  map[string]any{},
+		"indexes": map[string]any{},
+		"lives":   map[string]any{},
+		"tables":  map[string]any{},
+	},
+}
+
+// v2Responses are INFO results of a SurrealDB 2.2 server, which adds system metrics,
+// nodes, accesses and the build status of indexes.
+var v2Responses = map[string]any{
+	"/ INFO FOR ROOT": map[string]any{
+		"accesses":   map[string]any{},
+		"namespaces": map[string]any{"shop": "DEFINE NAMESPACE shop"},
+		"nodes":      map[string]any{"0195a3f4-0000-7000-8000-000000000001": "NODE 0195a3f4 SEEN 1700000000 ACTIVE"},
+		"system": map[string]any{
+			"available_parallelism": 8,
+			"cpu_usage":             12.5,
+			"load_average":          []float64{0.5, 0.25, 0.125},
+			"memory_allocated":      1 << 30,
+			"memory_usage":          1 << 28,
+			"physical_cores":        4,
+			"threads":               32,
+		},
+		"users": map[string]any{"root": "DEFINE USER root ON ROOT PASSHASH '...' ROLES OWNER"},
+	},
+	"shop/ INFO FOR NS": map[string]any{
+		"accesses":  map[string]any{"api": "DEFINE ACCESS api ON NAMESPACE TYPE JWT"},
+		"databases": map[string]any{"app": "DEFINE DATABASE app"},
+		"users":     map[string]any{},
+	},
+	"shop/app INFO FOR DB": map[string]any{
+		"accesses":  map[string]any{"account": "DEFINE ACCESS account ON DATABASE TYPE RECORD"},
+		"analyzers": map[string]any{},
+		"apis":      map[string]any{},
+		"configs":   map[string]any{},
+		"functions": map[string]any{"fn::total": "DEFINE FUNCTION fn::total() { RETURN 1; }"},
+		"models":    map[string]any{},
+		"params":    map[string]any{},
+		"tables": map[string]any{
+			"user":        "DEFINE TABLE user TYPE NORMAL SCHEMAFULL PERMISSIONS NONE",
+			"_stats_user": "DEFINE TABLE _stats_user TYPE NORMAL SCHEMALESS PERMISSIONS NONE",
+			"order":       "DEFINE TABLE order TYPE NORMAL SCHEMALESS CHANGEFEED 1d PERMISSIONS NONE",
+		},
+		"users": map[string]any{},
+	},
+	"shop/app INFO FOR TABLE ⟨user⟩": map[string]any{
+		"events": map[string]any{},
+		"fields": map[string]any{
+			"name":  "DEFINE FIELD name ON user TYPE string PERMISSIONS FULL",
+			"email": "DEFINE FIELD email ON user TYPE string PERMISSIONS FULL",
+		},
+		"indexes": map[string]any{"email_idx": "DEFINE INDEX email_idx ON user FIELDS email UNIQUE"},
+		"lives":   map[string]any{},
+		"tables":  map[string]any{},
+	},
+	"shop/app INFO FOR TABLE ⟨order⟩": map[string]any{
+		"events":  map[string]any{"placed": "DEFINE EVENT placed ON order WHEN $event = 'CREATE' THEN {}"},
+		"fields":  map[string]any{},
+		"indexes": map[string]any{},
+		"lives":   map[string]any{},
+		"tables":  map[string]any{},
+	},
+	"shop/app INFO FOR INDEX ⟨email_idx⟩ ON ⟨user⟩": map[string]any{
+		"building": map[string]any{"status": "indexing", "initial": 100, "pending": 5, "updated": 2},
+	},
+}
+
+func TestInfoReaderInfo(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   string
+		responses map[string]any
+
+		wantTables      []string
+		wantNodes       int
+		wantAccesses    int
+		wantMemoryUsage int64
+		wantIndexStatus string
+		wantIndexQuery  bool
+	}{
+		{
+			name:            "1.x",
+			version:         "surrealdb-1.5.4",
+			responses:       v1Responses,
+			wantTables:      []string{"order", "order_placed", "user"},
+			wantNodes:       0,
+			wantAccesses:    0,
+			wantMemoryUsage: 0,
+			wantIndexStatus: "",
+			wantIndexQuery:  false,
+		},
+		{
+			name:            "2.x",
+			version:         "surrealdb-2.2.1",
+			responses:       v2Responses,
+			wantTables:      []string{"order", "user"},
+			wantNodes:       1,
+			wantAccesses:    1,
+			wantMemoryUsage: 1 << 28,
+			wantIndexStatus: "indexing",
+			wantIndexQuery:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeServer{version: tt.version, responses: tt.responses}
+
+			reader, err := NewInfoReader(fakeConfig{}, server)
+			if err != nil {
+				t.Fatalf("failed to create info reader: %v", err)
+			}
+
+			info, err := reader.Info(context.Background())
+			if err != nil {
+				t.Fatalf("Info failed: %v", err)
+			}
+
+			if len(info.Errors) > 0 {
+				t.Fatalf("unexpected INFO errors: %v", info.Errors)
+			}
+
+			if info.Nodes != tt.wantNodes {
+				t.Errorf("nodes = %d, want %d", info.Nodes, tt.wantNodes)
+			}
+
+			if info.System.MemoryUsage != tt.wantMemoryUsage {
+				t.Errorf("memory usage = %d, want %d", info.System.MemoryUsage, tt.wantMemoryUsage)
+			}
+
+			ns, ok := info.Namespaces["shop"]
+			if !ok {
+				t.Fatalf("namespace shop missing, got %v", info.Namespaces)
+			}
+
+			if ns.Accesses != tt.wantAccesses {
+				t.Errorf("namespace accesses = %d, want %d", ns.Accesses, tt.wantAccesses)
+			}
+
+			db, ok := ns.Databases["app"]
+			if !ok {
+				t.Fatalf("database app missing, got %v", ns.Databases)
+			}
+
+			if db.Functions != 1 {
+				t.Errorf("functions = %d, want 1", db.Functions)
+			}
+
+			var tables []string
+			for name := range db.Tables {
+				tables = append(tables, name)
+			}
+			slices.Sort(tables)
+			if !slices.Equal(tables, tt.wantTables) {
+				t.Errorf("tables = %v, want %v, stats tables must be left out", tables, tt.wantTables)
+			}
+
+			user := db.Tables["user"]
+			if user.Fields != 2 || user.Events != 0 {
+				t.Errorf("user fields = %d, events = %d, want 2 and 0", user.Fields, user.Events)
+			}
+			if !user.Definition.Schemafull {
+				t.Error("user table should be schemafull")
+			}
+
+			order := db.Tables["order"]
+			if order.Events != 1 || !order.Definition.Changefeed {
+				t.Errorf("order events = %d, changefeed = %t, want 1 and true", order.Events, order.Definition.Changefeed)
+			}
+
+			index, ok := user.Indexes["email_idx"]
+			if !ok {
+				t.Fatalf("index email_idx missing, got %v", user.Indexes)
+			}
+			if index.Building.Status != tt.wantIndexStatus {
+				t.Errorf("index status = %q, want %q", index.Building.Status, tt.wantIndexStatus)
+			}
+
+			if ran := server.ran("shop/app INFO FOR INDEX"); ran != tt.wantIndexQuery {
+				t.Errorf("INFO FOR INDEX run = %t, want %t", ran, tt.wantIndexQuery)
+			}
+		})
+	}
+}
+
+func TestInfoReaderInfoPartialFailure(t *testing.T) {
+	responses := make(map[string]any, len(v2Responses))
+	for key, response := range v2Responses {
+		if key != "shop/app INFO FOR TABLE ⟨order⟩" {
+			responses[key] = response
+		}
+	}
+
+	reader, err := NewInfoReader(fakeConfig{}, &fakeServer{version: "surrealdb-2.2.1", responses: responses})
+	if err != nil {
+		t.Fatalf("failed to create info reader: %v", err)
+	}
+
+	info, err := reader.Info(context.Background())
+	if err != nil {
+		t.Fatalf("Info failed: %v", err)
+	}
+
+	if len(info.Errors) != 1 || info.Errors[0].Level != domain.InfoLevelTable {
+		t.Fatalf("errors = %v, want one table level error", info.Errors)
+	}
+
+	tables := info.Namespaces["shop"].Databases["app"].Tables
+	if _, ok := tables["order"]; ok {
+		t.Error("table order should be left out when INFO FOR TABLE fails")
+	}
+	if _, ok := tables["user"]; !ok {
+		t.Error("table user should still be fetched")
+	}
+}
+
+func TestInfoReaderUnsupportedVersion(t *testing.T) {
+	reader, err := NewInfoReader(fakeConfig{}, &fakeServer{version: "surrealdb-0.3.0", responses: v1Responses})
+	if err != nil {
+		t.Fatalf("failed to create info reader: %v", err)
+	}
+
+	if _, err := reader.Info(context.Background()); !errors.Is(err, domain.ErrUnsupportedVersion) {
+		t.Fatalf("error = %v, want %v", err, domain.ErrUnsupportedVersion)
+	}
+}
@@ -1,94 +1,298 @@
 package surrealdb
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"path"
+	"reflect"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/prometheus/client_golang/prometheus"
 	sdk "github.com/surrealdb/surrealdb.go"
 	sconn "github.com/surrealdb/surrealdb.go/pkg/connection"
 	"github.com/surrealdb/surrealdb.go/pkg/models"
 )
 
+// OperationClassifier determines the data model type of changed records.
+type OperationClassifier interface {
+	Classify(tableID domain.TableIdentifier, record map[string]any) domain.OperationType
+	SurrealQL(tableID domain.TableIdentifier, recordVar string) string
+}
+
+// TableFilter selects the tables to run live queries on. Tables with a lower priority
+// are preferred when the number of live queries is capped.
+type TableFilter interface {
+	FilterTables(tables []*domain.TableInfo) []domain.TableIdentifier
+	Priority(tableID domain.TableIdentifier) int
+	MayMonitorDatabase(namespace, database string) bool
+}
+
+// DatabaseLister lists the databases of the server by namespace.
+type DatabaseLister interface {
+	Databases(ctx context.Context) (map[string][]string, error)
+}
+
 // LiveQueryManager manages live queries and accumulates metrics.
 type LiveQueryManager struct {
 	connManager          ConnectionManager
 	accumulator          *OperationAccumulator
-	detector             *OperationTypeDetector
+	classifier           OperationClassifier
+	filter               TableFilter
+	databases            DatabaseLister
+	detectOperationType  bool
 	reconnectDelay       time.Duration
 	maxReconnectAttempts int
-
-	activeQueries map[string]*liveQueryState
-	mu            sync.RWMutex
+	schemaPollInterval   time.Duration
+	sampling             []domain.LiveQuerySampling
+	maxTables            int
+	logger               *slog.Logger
+
+	activeQueries    map[string]*liveQueryState
+	desiredTables    []domain.TableIdentifier
+	discoveredTables map[string]domain.TableIdentifier
+	skippedTables    int
+	reconnects       map[string]int
+	mu               sync.RWMutex
 
 	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
+// liveNotificationQueueSize is the number of received notifications buffered per table
+// while earlier ones are handled.
+const liveNotificationQueueSize = 1024
+
+// liveQueryKillTimeout bounds the KILL of a stopped live query.
+const liveQueryKillTimeout = 5 * time.Second
+
+// notificationProcessingBuckets are the upper bounds in seconds of the notification
+// processing histogram.
+var notificationProcessingBuckets = []float64{
+	0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1,
+}
+
+// errLiveQueryRestart is returned by runLiveQuery when the live query was canceled to be
+// recreated on a re-established connection.
+var errLiveQueryRestart = errors.New("live query restart requested")
+
 // liveQueryState tracks state for a single live query.
 type liveQueryState struct {
-	tableID   domain.TableIdentifier
-	db        *sdk.DB
-	liveID    string
-	cancelCtx context.CancelFunc
+	tableID    domain.TableIdentifier
+	db         *sdk.DB
+	liveID     string
+	generation uint64
+	cancelCtx  context.CancelFunc
+	restart    atomic.Bool
+	queue      chan receivedNotification
+}
+
+// receivedNotification is a live notification with the time it was received.
+type receivedNotification struct {
+	notification sconn.Notification
+	received     time.Time
 }
 
 // NewLiveQueryManager creates a new live query manager.
+// When detectOperationType is false, live queries use DIFF notifications and
+// operations are only counted per table without classification.
+// A positive schemaPollInterval makes Start poll monitored databases for new tables
+// matching filter, so live queries start without waiting for the next info scrape.
+// sampling limits the notifications decoded and classified for matching tables.
+// A positive maxTables caps the number of tables with live queries.
 func NewLiveQueryManager(
 	connManager ConnectionManager,
+	classifier OperationClassifier,
+	filter TableFilter,
+	databases DatabaseLister,
+	detectOperationType bool,
 	reconnectDelay time.Duration,
 	maxReconnectAttempts int,
+	schemaPollInterval time.Duration,
+	sampling []domain.LiveQuerySampling,
+	maxTables int,
+	logger *slog.Logger,
 ) *LiveQueryManager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &LiveQueryManager{
 		connManager:          connManager,
 		accumulator:          NewOperationAccumulator(),
-		detector:             NewOperationTypeDetector(),
+		classifier:           classifier,
+		filter:               filter,
+		databases:            databases,
+		detectOperationType:  detectOperationType,
 		reconnectDelay:       reconnectDelay,
 		maxReconnectAttempts: maxReconnectAttempts,
+		schemaPollInterval:   schemaPollInterval,
+		sampling:             sampling,
+		maxTables:            maxTables,
+		logger:               logger,
 		activeQueries:        make(map[string]*liveQueryState),
+		discoveredTables:     make(map[string]domain.TableIdentifier),
+		reconnects:           make(map[string]int),
 		ctx:                  ctx,
 		cancel:               cancel,
 	}
 }
 
-// LiveQueryInfo returns accumulated metrics and reconciles live queries.
-// This is the main entry point called by the collector on each scrape.
+// LiveQueryInfo returns operation counts accumulated since startup and reconciles live
+// queries. This is the main entry point called by the collector on each scrape. Counts
+// are never cleared, so a failed scrape loses nothing.
 func (m *LiveQueryManager) LiveQueryInfo(tableIDs []domain.TableIdentifier) ([]*domain.TableOperationMetrics, error) {
-	metrics := m.accumulator.GetAndClear()
+	metrics := m.accumulator.Totals()
 
 	go m.reconcileQueries(tableIDs)
 
 	return metrics, nil
 }
 
-// Stop gracefully shuts down all live queries.
+// LiveQueryNotifications returns live notification counts by handling result accumulated
+// since startup.
+func (m *LiveQueryManager) LiveQueryNotifications() []*domain.TableNotificationCount {
+	return m.accumulator.NotificationTotals()
+}
+
+// LiveQueryTables returns the number of tables with live queries and of tables skipped
+// because of the max_tables cap.
+func (m *LiveQueryManager) LiveQueryTables() (active, skipped int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return len(m.activeQueries), m.skippedTables
+}
+
+// LiveQueryProcessing returns the histogram of the notification processing latencies of
+// the tables with a live query.
+func (m *LiveQueryManager) LiveQueryProcessing() prometheus.Collector {
+	return m.accumulator.processing
+}
+
+// LiveQueryBacklog returns the current backlog of every table with a live query.
+func (m *LiveQueryManager) LiveQueryBacklog() []*domain.TableNotificationBacklog {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	backlog := make([]*domain.TableNotificationBacklog, 0, len(m.activeQueries))
+	for _, state := range m.activeQueries {
+		if state.queue == nil {
+			continue
+		}
+
+		backlog = append(backlog, &domain.TableNotificationBacklog{
+			Namespace: state.tableID.Namespace,
+			Database:  state.tableID.Database,
+			Table:     state.tableID.Table,
+			Backlog:   len(state.queue),
+		})
+	}
+
+	return backlog
+}
+
+// LiveQueryStatus returns the live queries currently running with their reconnects since
+// startup, ordered by table.
+func (m *LiveQueryManager) LiveQueryStatus() []*domain.LiveQueryStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	status := make([]*domain.LiveQueryStatus, 0, len(m.activeQueries))
+	for key, state := range m.activeQueries {
+		status = append(status, &domain.LiveQueryStatus{
+			Namespace:  state.tableID.Namespace,
+			Database:   state.tableID.Database,
+			Table:      state.tableID.Table,
+			LiveID:     state.liveID,
+			Reconnects: m.reconnects[key],
+			Backlog:    len(state.queue),
+		})
+	}
+
+	slices.SortFunc(status, func(a, b *domain.LiveQueryStatus) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Database, b.Database),
+			cmp.Compare(a.Table, b.Table),
+		)
+	})
+
+	return status
+}
+
+// LiveQueryTotals returns operation counts accumulated since startup.
+// Unlike LiveQueryInfo it does not reconcile live queries and can be called by any reader.
+func (m *LiveQueryManager) LiveQueryTotals() []*domain.TableOperationMetrics {
+	return m.accumulator.Totals()
+}
+
+// Start launches the schema watch when a schema poll interval is configured.
+func (m *LiveQueryManager) Start() {
+	if m.schemaPollInterval <= 0 {
+		return
+	}
+
+	m.wg.Add(1)
+
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.schemaPollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				m.pollSchema()
+			}
+		}
+	}()
+}
+
+// Stop kills all live queries and waits for them to end. Their connections must be closed
+// after Stop returns, or the live queries see them closing and reconnect.
 func (m *LiveQueryManager) Stop() {
-	slog.Info("Stopping live query manager")
+	m.logger.Info("Stopping live query manager")
 	m.cancel()
 	m.wg.Wait()
-	slog.Info("Live query manager stopped")
+	m.logger.Info("Live query manager stopped")
 }
 
 // reconcileQueries updates active queries to match desired table list.
+// Tables found by the schema watch are kept until the desired list includes them.
 func (m *LiveQueryManager) reconcileQueries(desiredTables []domain.TableIdentifier) {
+	m.restartReconnectedQueries()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.desiredTables = desiredTables
+
 	desired := make(map[string]domain.TableIdentifier)
 	for _, table := range desiredTables {
 		desired[table.String()] = table
+		delete(m.discoveredTables, table.String())
 	}
 
+	for tableKey, table := range m.discoveredTables {
+		desired[tableKey] = table
+	}
+
+	m.capDesiredLocked(desired)
+
 	for tableKey, state := range m.activeQueries {
 		if _, exists := desired[tableKey]; !exists {
-			slog.Info("Stopping live query for removed table", "table", tableKey)
+			m.logger.Info("Stopping live query for removed table", "table", tableKey)
 			state.cancelCtx()
 			delete(m.activeQueries, tableKey)
 		}
@@ -96,16 +300,213 @@ func (m *LiveQueryManager) reconcileQueries(desiredTables []domain.TableIdentifi
 
 	for tableKey, tableID := range desired {
 		if _, exists := m.activeQueries[tableKey]; !exists {
-			slog.Info("Starting live query for new table", "table", tableKey)
+			m.logger.Info("Starting live query for new table", "table", tableKey)
 			m.wg.Add(1)
 			go m.manageLiveQuery(tableID)
 		}
 	}
 }
 
+// capDesiredLocked removes the tables beyond maxTables from desired, keeping those
+// matching earlier include patterns (caller must hold lock).
+func (m *LiveQueryManager) capDesiredLocked(desired map[string]domain.TableIdentifier) {
+	skipped := 0
+	if m.maxTables > 0 && len(desired) > m.maxTables {
+		keys := slices.SortedFunc(maps.Keys(desired), func(a, b string) int {
+			return cmp.Or(
+				cmp.Compare(m.filter.Priority(desired[a]), m.filter.Priority(desired[b])),
+				cmp.Compare(a, b),
+			)
+		})
+
+		for _, tableKey := range keys[m.maxTables:] {
+			delete(desired, tableKey)
+		}
+		skipped = len(keys) - m.maxTables
+	}
+
+	if skipped > 0 && skipped != m.skippedTables {
+		m.logger.Warn("Live query table limit reached, skipping tables",
+			"max_tables", m.maxTables,
+			"skipped", skipped)
+	}
+	m.skippedTables = skipped
+}
+
+// restartReconnectedQueries recreates live queries whose connection was re-established
+// since they were registered, as their live IDs are unknown to the new connection.
+// Getting the connections makes the connection manager replace closed ones.
+func (m *LiveQueryManager) restartReconnectedQueries() {
+	generations, ok := m.connManager.(ConnectionGenerations)
+	if !ok {
+		return
+	}
+
+	m.mu.RLock()
+	states := slices.Collect(maps.Values(m.activeQueries))
+	m.mu.RUnlock()
+
+	checked := make(map[[2]string]struct{})
+	for _, state := range states {
+		database := [2]string{state.tableID.Namespace, state.tableID.Database}
+		if _, ok := checked[database]; !ok {
+			checked[database] = struct{}{}
+
+			if _, err := m.connManager.Get(m.ctx, database[0], database[1]); err != nil {
+				m.logger.Warn("Failed to check live query connection",
+					"namespace", database[0],
+					"database", database[1],
+					"error", err)
+				continue
+			}
+		}
+
+		if generations.Generation(database[0], database[1]) != state.generation {
+			m.logger.Info("Restarting live query after reconnection", "table", state.tableID.String())
+			state.restart.Store(true)
+			state.cancelCtx()
+		}
+	}
+}
+
+// pollSchema lists the tables of every database with monitored tables, and of every
+// database of the server the filter may monitor, and starts live queries on new tables
+// matching the filter. Discovered tables that have since been removed from their database
+// are forgotten.
+func (m *LiveQueryManager) pollSchema() {
+	m.mu.RLock()
+	current := m.desiredTables
+	m.mu.RUnlock()
+
+	known := make(map[string]struct{}, len(current))
+	databases := make(map[[2]string]struct{})
+	for _, table := range current {
+		known[table.String()] = struct{}{}
+		databases[[2]string{table.Namespace, table.Database}] = struct{}{}
+	}
+
+	for _, database := range m.listDatabases() {
+		databases[database] = struct{}{}
+	}
+
+	if len(databases) == 0 {
+		return
+	}
+
+	polled := make(map[[2]string]struct{}, len(databases))
+	found := make(map[string]domain.TableIdentifier)
+	for database := range databases {
+		tables, err := m.listTables(database[0], database[1])
+		if err != nil {
+			m.logger.Warn("Failed to poll database schema",
+				"namespace", database[0],
+				"database", database[1],
+				"error", err)
+			continue
+		}
+
+		polled[database] = struct{}{}
+		for _, tableID := range m.filter.FilterTables(tables) {
+			if _, exists := known[tableID.String()]; !exists {
+				found[tableID.String()] = tableID
+			}
+		}
+	}
+
+	m.mu.Lock()
+	changed := false
+	for tableKey, tableID := range m.discoveredTables {
+		_, dbPolled := polled[[2]string{tableID.Namespace, tableID.Database}]
+		if _, exists := found[tableKey]; dbPolled && !exists {
+			delete(m.discoveredTables, tableKey)
+			changed = true
+		}
+	}
+	for tableKey, tableID := range found {
+		if _, exists := m.discoveredTables[tableKey]; !exists {
+			m.logger.Debug("Schema watch found new table", "table", tableKey)
+			m.discoveredTables[tableKey] = tableID
+			changed = true
+		}
+	}
+	desiredTables := m.desiredTables
+	m.mu.Unlock()
+
+	if changed {
+		m.reconcileQueries(desiredTables)
+	}
+}
+
+// listDatabases returns the databases of the server the filter may monitor, or none
+// without a database lister.
+func (m *LiveQueryManager) listDatabases() [][2]string {
+	if m.databases == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(m.ctx, m.schemaPollInterval)
+	defer cancel()
+
+	byNamespace, err := m.databases.Databases(ctx)
+	if err != nil {
+		m.logger.Warn("Failed to list databases for the schema watch", "error", err)
+		return nil
+	}
+
+	var databases [][2]string
+	for namespace, names := range byNamespace {
+		for _, database := range names {
+			if m.filter.MayMonitorDatabase(namespace, database) {
+				databases = append(databases, [2]string{namespace, database})
+			}
+		}
+	}
+
+	return databases
+}
+
+// listTables returns the tables defined in the given database.
+func (m *LiveQueryManager) listTables(namespace, database string) ([]*domain.TableInfo, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, m.schemaPollInterval)
+	defer cancel()
+
+	db, err := m.connManager.Querier(ctx, namespace, database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	results, err := tracedQuery[*databaseInfo](ctx, db, "INFO FOR DB", nil)
+	if err != nil {
+		return nil, fmt.Errorf("INFO FOR DATABASE query failed: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return nil, errors.New("INFO FOR DATABASE returned no results")
+	}
+
+	dbResult := (*results)[0]
+	if dbResult.Status != "OK" {
+		return nil, fmt.Errorf("INFO FOR DATABASE returned %s status: %w", dbResult.Status, queryStatus(dbResult.Error))
+	}
+
+	tables := make([]*domain.TableInfo, 0, len(dbResult.Result.Tables))
+	for name := range dbResult.Result.Tables {
+		tables = append(tables, &domain.TableInfo{
+			Name:      name,
+			Database:  database,
+			Namespace: namespace,
+		})
+	}
+
+	return tables, nil
+}
+
 // manageLiveQuery manages a single live query with reconnection.
 func (m *LiveQueryManager) manageLiveQuery(tableID domain.TableIdentifier) {
 	defer m.wg.Done()
+	defer m.accumulator.ForgetProcessing(tableID)
+
+	logger := m.logger.With("table", tableID.String())
 
 	attempts := 0
 	for {
@@ -117,12 +518,14 @@ func (m *LiveQueryManager) manageLiveQuery(tableID domain.TableIdentifier) {
 
 		attempts++
 		if attempts > m.maxReconnectAttempts {
-			slog.Error("Max reconnection attempts reached", "table", tableID.String())
+			logger.Error("Max reconnection attempts reached")
+			m.removeActive(tableID)
 			return
 		}
 
 		if attempts > 1 {
-			slog.Info("Reconnecting live query", "table", tableID.String(), "attempt", attempts)
+			logger.Info("Reconnecting live query", "attempt", attempts)
+			m.countReconnect(tableID)
 			select {
 			case <-m.ctx.Done():
 				return
@@ -130,27 +533,62 @@ func (m *LiveQueryManager) manageLiveQuery(tableID domain.TableIdentifier) {
 			}
 		}
 
-		if err := m.runLiveQuery(tableID); err != nil {
-			slog.Error("Live query error", "table", tableID.String(), "error", err)
+		if err := m.runLiveQuery(tableID, logger); err != nil {
+			if errors.Is(err, errLiveQueryRestart) && m.isActive(tableID) {
+				m.countReconnect(tableID)
+				attempts = 0
+				continue
+			}
+
+			logger.Error("Live query error", "error", err)
+
+			if !domain.Retryable(err) {
+				logger.Error("Live query failed permanently, not reconnecting", "reason", domain.ErrorReason(err))
+				m.removeActive(tableID)
+				return
+			}
 
 			if m.ctx.Err() != nil {
-				m.mu.Lock()
-				delete(m.activeQueries, tableID.String())
-				m.mu.Unlock()
+				m.removeActive(tableID)
 				return
 			}
 			continue
 		}
 
-		m.mu.Lock()
-		delete(m.activeQueries, tableID.String())
-		m.mu.Unlock()
+		m.removeActive(tableID)
 		return
 	}
 }
 
+// removeActive forgets the live query of tableID once its goroutine gives up, so the next
+// reconciliation starts it again while the table is still wanted.
+func (m *LiveQueryManager) removeActive(tableID domain.TableIdentifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.activeQueries, tableID.String())
+}
+
+// countReconnect records that the live query of tableID is recreated.
+func (m *LiveQueryManager) countReconnect(tableID domain.TableIdentifier) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.reconnects[tableID.String()]++
+}
+
+// isActive reports whether a live query for tableID is still wanted, i.e. was not
+// removed by reconcileQueries.
+func (m *LiveQueryManager) isActive(tableID domain.TableIdentifier) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, active := m.activeQueries[tableID.String()]
+	return active && m.ctx.Err() == nil
+}
+
 // runLiveQuery executes a single live query.
-func (m *LiveQueryManager) runLiveQuery(tableID domain.TableIdentifier) error {
+func (m *LiveQueryManager) runLiveQuery(tableID domain.TableIdentifier, logger *slog.Logger) error {
 	ctx, cancel := context.WithCancel(m.ctx)
 	defer cancel()
 
@@ -159,25 +597,29 @@ func (m *LiveQueryManager) runLiveQuery(tableID domain.TableIdentifier) error {
 		return fmt.Errorf("failed to get connection: %w", err)
 	}
 
-	live, err := sdk.Live(ctx, db, models.Table(tableID.Table), false)
+	var generation uint64
+	if generations, ok := m.connManager.(ConnectionGenerations); ok {
+		generation = generations.Generation(tableID.Namespace, tableID.Database)
+	}
+
+	live, err := sdk.Live(ctx, db, models.Table(tableID.Table), !m.detectOperationType)
 	if err != nil {
 		return fmt.Errorf("failed to create live query: %w", err)
 	}
 
 	liveID := live.String()
-	slog.Info("Live query registered",
-		"namespace", tableID.Namespace,
-		"database", tableID.Database,
-		"table", tableID.Table,
-		"live_id", liveID)
+	logger.Info("Live query registered", "live_id", liveID)
+
+	state := &liveQueryState{
+		tableID:    tableID,
+		db:         db,
+		liveID:     liveID,
+		generation: generation,
+		cancelCtx:  cancel,
+	}
 
 	m.mu.Lock()
-	m.activeQueries[tableID.String()] = &liveQueryState{
-		tableID:   tableID,
-		db:        db,
-		liveID:    liveID,
-		cancelCtx: cancel,
-	}
+	m.activeQueries[tableID.String()] = state
 	m.mu.Unlock()
 
 	notifications, err := db.LiveNotifications(liveID)
@@ -189,24 +631,134 @@ func (m *LiveQueryManager) runLiveQuery(tableID domain.TableIdentifier) error {
 		return errors.New("notifications channel is nil")
 	}
 
+	queue := make(chan receivedNotification, liveNotificationQueueSize)
+	go forwardNotifications(ctx, notifications, queue)
+
+	m.mu.Lock()
+	state.queue = queue
+	m.mu.Unlock()
+
+	sampler := m.samplerFor(tableID)
+
 	for {
 		select {
 		case <-ctx.Done():
+			if state.restart.Load() {
+				return errLiveQueryRestart
+			}
+			m.kill(db, liveID, logger)
 			return nil
 
-		case notification, ok := <-notifications:
+		case received, ok := <-queue:
 			if !ok {
 				return errors.New("notifications channel closed")
 			}
-			m.processNotification(tableID, notification)
+			m.processNotification(tableID, received.notification, sampler, logger)
+			m.accumulator.ObserveProcessing(tableID, time.Since(received.received))
+		}
+	}
+}
+
+// kill ends the live query liveID on the server, so it stops sending notifications over
+// the connection, which stays open for other queries until the connection manager closes it.
+func (m *LiveQueryManager) kill(db *sdk.DB, liveID string, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), liveQueryKillTimeout)
+	defer cancel()
+
+	if err := sdk.Kill(ctx, db, liveID); err != nil {
+		logger.Warn("Failed to kill live query", "live_id", liveID, "error", err)
+	}
+}
+
+// forwardNotifications moves notifications into queue as they arrive, recording when they
+// were received, and closes queue when notifications is closed.
+func forwardNotifications(ctx context.Context, notifications <-chan sconn.Notification, queue chan<- receivedNotification) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case notification, ok := <-notifications:
+			if !ok {
+				close(queue)
+				return
+			}
+
+			select {
+			case queue <- receivedNotification{notification: notification, received: time.Now()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// notificationSampler selects the fraction rate of the notifications of each action of a
+// table for classification, accumulating rate per notification and classifying one
+// whenever a whole notification has accumulated. The first notification of each action
+// is always classified.
+type notificationSampler struct {
+	rate   float64
+	credit map[domain.OperationAction]float64
+	// last holds the operation type last classified for each action.
+	last map[domain.OperationAction]domain.OperationType
+}
+
+func newNotificationSampler(rate float64) *notificationSampler {
+	return &notificationSampler{
+		rate:   rate,
+		credit: make(map[domain.OperationAction]float64),
+		last:   make(map[domain.OperationAction]domain.OperationType),
+	}
+}
+
+// sample reports whether the next notification with action is classified.
+func (s *notificationSampler) sample(action domain.OperationAction) bool {
+	credit, ok := s.credit[action]
+	if !ok {
+		credit = 1 - s.rate
+	}
+
+	// The tolerance keeps rounding errors of the sum from delaying a sample.
+	credit += s.rate
+	if credit >= 1-1e-9 {
+		s.credit[action] = credit - 1
+		return true
+	}
+
+	s.credit[action] = credit
+	return false
+}
+
+// lastType returns the operation type last classified for action.
+func (s *notificationSampler) lastType(action domain.OperationAction) domain.OperationType {
+	if opType, ok := s.last[action]; ok {
+		return opType
+	}
+
+	return domain.OperationTypeUnknown
+}
+
+// samplerFor returns the sampler of the first sampling whose pattern matches tableID,
+// classifying every notification when none matches.
+func (m *LiveQueryManager) samplerFor(tableID domain.TableIdentifier) *notificationSampler {
+	for _, sampling := range m.sampling {
+		if matched, _ := path.Match(sampling.Pattern, tableID.String()); matched {
+			return newNotificationSampler(sampling.Rate)
 		}
 	}
+
+	return newNotificationSampler(1)
 }
 
-// processNotification handles a live query notification.
+// processNotification handles a live query notification. With operation type detection,
+// only notifications selected by sampler are decoded and classified; the others count
+// under the operation type last classified for their action.
 func (m *LiveQueryManager) processNotification(
 	tableID domain.TableIdentifier,
 	notification sconn.Notification,
+	sampler *notificationSampler,
+	logger *slog.Logger,
 ) {
 	var action domain.OperationAction
 	switch notification.Action {
@@ -218,150 +770,210 @@ func (m *LiveQueryManager) processNotification(
 		action = domain.ActionDelete
 	default:
 		action = domain.ActionUnknown
-		slog.Warn("Unknown action type",
-			"action", notification.Action,
-			"table", tableID.String(),
-		)
+		logger.Warn("Unknown action type", "action", notification.Action)
+		m.accumulator.RecordNotification(tableID, domain.NotificationUnknownAction)
+		return
+	}
+
+	if !m.detectOperationType {
+		m.accumulator.Record(tableID, "", action, 1)
+		m.accumulator.RecordNotification(tableID, domain.NotificationProcessed)
+		return
+	}
+
+	if !sampler.sample(action) {
+		m.accumulator.Record(tableID, sampler.lastType(action), action, 1)
+		m.accumulator.RecordNotification(tableID, domain.NotificationSampledOut)
 		return
 	}
 
 	opType := domain.OperationTypeUnknown
-	switch res := notification.Result.(type) {
-	case map[string]any:
-		opType = m.detector.DetectFromRecord(res)
-	case nil:
-		slog.Debug("Live notification with nil result",
-			"table", tableID.String(),
-			"action", notification.Action,
-		)
-	default:
-		slog.Warn("Unexpected live notification result type",
-			"type", fmt.Sprintf("%T", res),
-			"table", tableID.String(),
+	result := domain.NotificationProcessed
+	if notification.Result == nil {
+		logger.Debug("Live notification with nil result", "action", notification.Action)
+		result = domain.NotificationNilResult
+	} else if record, err := decodeRecord(notification.Result); err != nil {
+		logger.Warn("Failed to decode live notification result",
+			"type", fmt.Sprintf("%T", notification.Result),
 			"action", notification.Action,
+			"error", err,
 		)
+		result = domain.NotificationDecodeError
+	} else {
+		opType = m.classifier.Classify(tableID, record)
 	}
 
-	m.accumulator.Record(tableID, opType, action)
+	sampler.last[action] = opType
+	m.accumulator.Record(tableID, opType, action, 1)
+	m.accumulator.RecordNotification(tableID, result)
 
-	slog.Debug("Operation recorded",
-		"namespace", tableID.Namespace,
-		"database", tableID.Database,
-		"table", tableID.Table,
+	logger.Debug("Operation recorded",
 		"action", action,
 		"operation_type", opType,
 	)
 }
 
-// OperationTypeDetector analyzes record data to determine operation type.
-type OperationTypeDetector struct{}
-
-// NewOperationTypeDetector creates a new detector.
-func NewOperationTypeDetector() *OperationTypeDetector {
-	return &OperationTypeDetector{}
-}
-
-// DetectFromRecord analyzes a record's structure to determine operation type.
-func (d *OperationTypeDetector) DetectFromRecord(record any) domain.OperationType {
-	if record == nil {
-		return domain.OperationTypeUnknown
-	}
-
-	recordMap, ok := record.(map[string]any)
-	if !ok {
-		return domain.OperationTypeUnknown
-	}
-
-	if d.isGraphRecord(recordMap) {
-		return domain.OperationTypeGraph
-	}
+// recordDecMode decodes re-marshaled notification results with string-keyed maps.
+var recordDecMode, _ = cbor.DecOptions{
+	DefaultMapType: reflect.TypeOf(map[string]any(nil)),
+}.DecMode()
 
-	if d.isKeyValueRecord(recordMap) {
-		return domain.OperationTypeKeyValue
+// decodeRecord converts a live notification result into a string-keyed map with nested
+// maps normalized to map[string]any. Results arriving as CBOR maps with untyped keys are
+// converted directly; structs and other SDK types are re-marshaled through CBOR.
+func decodeRecord(result any) (map[string]any, error) {
+	switch res := result.(type) {
+	case map[string]any:
+		return normalizeMap(res), nil
+	case map[any]any:
+		return normalizeMap(res), nil
 	}
 
-	if d.isRelationalRecord(recordMap) {
-		return domain.OperationTypeRelational
+	data, err := cbor.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-marshal %T: %w", result, err)
 	}
 
-	return domain.OperationTypeDocument
-}
-
-// isGraphRecord checks if record has graph edge characteristics.
-func (d *OperationTypeDetector) isGraphRecord(record map[string]any) bool {
-	hasIn := false
-	hasOut := false
-
-	for key := range record {
-		if key == "in" {
-			hasIn = true
-		}
-		if key == "out" {
-			hasOut = true
-		}
+	var record map[string]any
+	if err := recordDecMode.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("%T is not a record: %w", result, err)
 	}
 
-	return hasIn && hasOut
+	return record, nil
 }
 
-// isKeyValueRecord checks if record has key-value characteristics.
-func (d *OperationTypeDetector) isKeyValueRecord(record map[string]any) bool {
-	fieldCount := 0
-	for key := range record {
-		if key != "id" {
-			fieldCount++
-		}
+// normalizeMap returns a copy of m with string keys and normalized nested values.
+func normalizeMap[K comparable](m map[K]any) map[string]any {
+	normalized := make(map[string]any, len(m))
+	for key, value := range m {
+		normalized[fmt.Sprint(key)] = normalizeValue(value)
 	}
 
-	return fieldCount <= 2 && fieldCount > 0
+	return normalized
 }
 
-// isRelationalRecord checks if record has relational characteristics.
-func (d *OperationTypeDetector) isRelationalRecord(record map[string]any) bool {
-	scalarCount := 0
-	complexCount := 0
-
-	for key, value := range record {
-		if key == "id" {
-			continue
-		}
-
-		switch value.(type) {
-		case map[string]any, []any:
-			complexCount++
-		default:
-			scalarCount++
+// normalizeValue converts nested CBOR maps and arrays to map[string]any and []any.
+func normalizeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		return normalizeMap(v)
+	case map[any]any:
+		return normalizeMap(v)
+	case []any:
+		normalized := make([]any, len(v))
+		for i, item := range v {
+			normalized[i] = normalizeValue(item)
 		}
+		return normalized
+	default:
+		return value
 	}
-
-	return scalarCount >= 3 && complexCount <= 1
 }
 
-// OperationAccumulator thread-safely accumulates operation counts.
+// OperationAccumulator thread-safely accumulates operation counts and notification
+// results since startup, and the notification processing latencies of the tables with a
+// live query.
 type OperationAccumulator struct {
-	metrics map[string]*domain.TableOperationMetrics
-	mu      sync.RWMutex
+	totals        map[string]*domain.TableOperationMetrics
+	notifications map[string]*domain.TableNotificationCount
+	processing    *prometheus.HistogramVec
+	mu            sync.RWMutex
 }
 
 // NewOperationAccumulator creates a new accumulator.
 func NewOperationAccumulator() *OperationAccumulator {
 	return &OperationAccumulator{
-		metrics: make(map[string]*domain.TableOperationMetrics),
+		totals:        make(map[string]*domain.TableOperationMetrics),
+		notifications: make(map[string]*domain.TableNotificationCount),
+		processing: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: domain.Namespace,
+			Subsystem: "live_query",
+			Name:      "notification_processing_seconds",
+			Help:      "Time from receiving a live notification to having handled it, including time queued behind earlier notifications",
+			Buckets:   notificationProcessingBuckets,
+		}, []string{"namespace", "database", "table"}),
 	}
 }
 
-// Record records an operation.
+// Record records count operations.
 func (a *OperationAccumulator) Record(
 	tableID domain.TableIdentifier,
 	opType domain.OperationType,
 	action domain.OperationAction,
+	count int64,
 ) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
 	key := makeKey(tableID, opType)
 
-	metrics, exists := a.metrics[key]
+	incrementOperation(a.totals, key, tableID, opType, action, count)
+}
+
+// RecordNotification records the handling result of a live notification.
+func (a *OperationAccumulator) RecordNotification(tableID domain.TableIdentifier, result domain.LiveNotificationResult) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := tableID.String() + ":" + string(result)
+
+	count, exists := a.notifications[key]
+	if !exists {
+		count = &domain.TableNotificationCount{
+			Namespace: tableID.Namespace,
+			Database:  tableID.Database,
+			Table:     tableID.Table,
+			Result:    result,
+		}
+		a.notifications[key] = count
+	}
+
+	count.Count++
+}
+
+// NotificationTotals returns copies of the notification counts recorded since startup.
+func (a *OperationAccumulator) NotificationTotals() []*domain.TableNotificationCount {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make([]*domain.TableNotificationCount, 0, len(a.notifications))
+	for _, count := range a.notifications {
+		countCopy := *count
+		result = append(result, &countCopy)
+	}
+
+	return result
+}
+
+// ObserveProcessing records the time it took to handle a notification of tableID.
+func (a *OperationAccumulator) ObserveProcessing(tableID domain.TableIdentifier, d time.Duration) {
+	a.processing.WithLabelValues(tableID.Namespace, tableID.Database, tableID.Table).Observe(d.Seconds())
+}
+
+// ForgetProcessing drops the notification processing latencies of tableID, whose live
+// query stopped.
+func (a *OperationAccumulator) ForgetProcessing(tableID domain.TableIdentifier) {
+	a.processing.DeleteLabelValues(tableID.Namespace, tableID.Database, tableID.Table)
+}
+
+// Totals returns the operation counts accumulated since startup without clearing anything.
+func (a *OperationAccumulator) Totals() []*domain.TableOperationMetrics {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return copyOperationMetrics(a.totals)
+}
+
+// incrementOperation adds count to the counter for action in the given metrics map.
+func incrementOperation(
+	metricsMap map[string]*domain.TableOperationMetrics,
+	key string,
+	tableID domain.TableIdentifier,
+	opType domain.OperationType,
+	action domain.OperationAction,
+	count int64,
+) {
+	metrics, exists := metricsMap[key]
 	if !exists {
 		metrics = &domain.TableOperationMetrics{
 			Namespace:     tableID.Namespace,
@@ -369,40 +981,27 @@ func (a *OperationAccumulator) Record(
 			Table:         tableID.Table,
 			OperationType: opType,
 		}
-		a.metrics[key] = metrics
+		metricsMap[key] = metrics
 	}
 
 	switch action {
 	case domain.ActionCreate:
-		metrics.Creates++
+		metrics.Creates += count
 	case domain.ActionUpdate:
-		metrics.Updates++
+		metrics.Updates += count
 	case domain.ActionDelete:
-		metrics.Deletes++
+		metrics.Deletes += count
 	}
 }
 
-// GetAndClear returns all metrics and clears the accumulator.
-func (a *OperationAccumulator) GetAndClear() []*domain.TableOperationMetrics {
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	result := make([]*domain.TableOperationMetrics, 0, len(a.metrics))
-	for _, m := range a.metrics {
-		metricsCopy := &domain.TableOperationMetrics{
-			Namespace:     m.Namespace,
-			Database:      m.Database,
-			Table:         m.Table,
-			OperationType: m.OperationType,
-			Creates:       m.Creates,
-			Updates:       m.Updates,
-			Deletes:       m.Deletes,
-		}
-		result = append(result, metricsCopy)
+// copyOperationMetrics returns copies of all entries in the given metrics map.
+func copyOperationMetrics(metricsMap map[string]*domain.TableOperationMetrics) []*domain.TableOperationMetrics {
+	result := make([]*domain.TableOperationMetrics, 0, len(metricsMap))
+	for _, m := range metricsMap {
+		metricsCopy := *m
+		result = append(result, &metricsCopy)
 	}
 
-	a.metrics = make(map[string]*domain.TableOperationMetrics)
-
 	return result
 }
 
@@ -0,0 +1,60 @@
+package surrealdb
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// prewarmParallelism bounds the connections established at once while pre-warming, so a
+// restart does not flood the server with sign-ins.
+const prewarmParallelism = 8
+
+// PrewarmConnections establishes the root connection of provider and one per database,
+// so the first scrape after startup does not pay for the connect and sign-in round
+// trips. Failures are logged and left for the collectors to retry.
+func PrewarmConnections(ctx context.Context, provider QuerierProvider, databases []*domain.DatabaseInfo) {
+	start := time.Now()
+
+	if _, err := provider.Querier(ctx, "", ""); err != nil {
+		slog.Warn("Failed to pre-establish root connection", "error", err)
+		return
+	}
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+
+	semaphore := make(chan struct{}, prewarmParallelism)
+	for _, database := range databases {
+		wg.Add(1)
+		go func(namespace, name string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			if _, err := provider.Querier(ctx, namespace, name); err != nil {
+				slog.Debug("Failed to pre-establish connection",
+					"namespace", namespace,
+					"database", name,
+					"error", err)
+
+				mu.Lock()
+				failed++
+				mu.Unlock()
+			}
+		}(database.Namespace, database.Name)
+	}
+	wg.Wait()
+
+	slog.Info("Connections pre-established",
+		"databases", len(databases),
+		"failed", failed,
+		"duration", time.Since(start))
+}
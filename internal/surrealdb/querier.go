@@ -0,0 +1,108 @@
+package surrealdb
+
+import (
+	"context"
+
+	"github.com/fxamacker/cbor/v2"
+	sdk "github.com/surrealdb/surrealdb.go"
+	"github.com/surrealdb/surrealdb.go/pkg/connection"
+)
+
+// Querier runs SurrealQL queries on one connection. Readers only query through a Querier,
+// so they can be exercised against canned INFO responses of different SurrealDB versions
+// instead of a live database.
+type Querier interface {
+	// Query runs sql and returns the result of every statement undecoded. Like sdk.Query,
+	// it returns the results together with the joined errors of failed statements.
+	Query(ctx context.Context, sql string, vars map[string]any) ([]RawQueryResult, error)
+	// Unmarshal decodes a statement result returned by Query into v.
+	Unmarshal(data []byte, v any) error
+	// Version returns the version of the server.
+	Version(ctx context.Context) (string, error)
+}
+
+// QuerierProvider returns the Querier for a namespace and database, or for the root
+// level when both are empty.
+type QuerierProvider interface {
+	Querier(ctx context.Context, ns, db string) (Querier, error)
+}
+
+// RawQueryResult is the result of one statement before its value is decoded.
+type RawQueryResult struct {
+	Status string
+	Time   string
+	Result []byte
+	Error  *sdk.QueryError
+}
+
+// sdkQuerier is the Querier of an SDK connection.
+type sdkQuerier struct {
+	db      *sdk.DB
+	conn    connection.Connection
+	target  string
+	limiter *QueryLimiter
+}
+
+// newSDKQuerier creates the Querier of db using the codec of conn, admitting queries
+// through limiter. target names the namespace and database of the connection in query
+// traces.
+func newSDKQuerier(db *sdk.DB, conn connection.Connection, ns, database string, limiter *QueryLimiter) *sdkQuerier {
+	target := "root"
+	if ns != "" {
+		target = ns + "/" + database
+	}
+
+	return &sdkQuerier{db: db, conn: conn, target: target, limiter: limiter}
+}
+
+// Query implements Querier.
+func (q *sdkQuerier) Query(ctx context.Context, sql string, vars map[string]any) ([]RawQueryResult, error) {
+	if err := q.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	results, err := sdk.Query[cbor.RawMessage](ctx, q.db, sql, vars)
+	if results == nil {
+		return nil, err
+	}
+
+	raw := make([]RawQueryResult, len(*results))
+	for i, result := range *results {
+		raw[i] = RawQueryResult{
+			Status: result.Status,
+			Time:   result.Time,
+			Result: result.Result,
+			Error:  result.Error,
+		}
+	}
+
+	return raw, err
+}
+
+// Unmarshal implements Querier.
+func (q *sdkQuerier) Unmarshal(data []byte, v any) error {
+	return q.conn.GetUnmarshaler().Unmarshal(data, v)
+}
+
+// Version implements Querier.
+func (q *sdkQuerier) Version(ctx context.Context) (string, error) {
+	if err := q.limiter.wait(ctx); err != nil {
+		return "", err
+	}
+
+	v, err := q.db.Version(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return v.Version, nil
+}
+
+// querierTarget returns the "namespace/database" of q for query traces.
+func querierTarget(q Querier) string {
+	if s, ok := q.(*sdkQuerier); ok {
+		return s.target
+	}
+
+	return ""
+}
@@ -0,0 +1,117 @@
+package surrealdb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	sdk "github.com/surrealdb/surrealdb.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracer logs issued SurrealQL queries and warns about slow ones.
+type queryTracer struct {
+	traceQueries  bool
+	slowThreshold time.Duration
+	logger        *slog.Logger
+}
+
+var (
+	tracer atomic.Pointer[queryTracer]
+
+	otelTracer = otel.Tracer("github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealdb")
+)
+
+// ConfigureQueryTracing enables logging of every query at debug level when traceQueries
+// is true, and a warning for queries slower than slowThreshold (0 disables the warning).
+func ConfigureQueryTracing(traceQueries bool, slowThreshold time.Duration, logger *slog.Logger) {
+	tracer.Store(&queryTracer{
+		traceQueries:  traceQueries,
+		slowThreshold: slowThreshold,
+		logger:        logger,
+	})
+}
+
+// tracedQuery executes a SurrealQL query in an OpenTelemetry span, logs it according
+// to the query tracing configuration and decodes the statement results into T. Like
+// sdk.Query, it returns the results together with the errors of failed statements.
+func tracedQuery[T any](ctx context.Context, db Querier, sql string, vars map[string]any) (*[]sdk.QueryResult[T], error) {
+	targetName := querierTarget(db)
+
+	ctx, span := otelTracer.Start(ctx, "surrealdb.query",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system.name", "surrealdb"),
+			attribute.String("db.namespace", targetName),
+			attribute.String("db.query.text", sql),
+		),
+	)
+	defer span.End()
+
+	start := time.Now()
+	raw, err := db.Query(ctx, sql, vars)
+	err = classifyQueryError(ctx, err)
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	if t := tracer.Load(); t != nil {
+		t.trace(targetName, sql, time.Since(start), err)
+	}
+
+	if raw == nil {
+		return nil, err
+	}
+
+	results, decodeErr := decodeQueryResults[T](db, raw)
+	if decodeErr != nil {
+		return nil, decodeErr
+	}
+
+	return results, err
+}
+
+// decodeQueryResults decodes the results of successful statements into T. Failed
+// statements keep their error and the zero value of T.
+func decodeQueryResults[T any](db Querier, raw []RawQueryResult) (*[]sdk.QueryResult[T], error) {
+	results := make([]sdk.QueryResult[T], len(raw))
+	for i, r := range raw {
+		results[i] = sdk.QueryResult[T]{Status: r.Status, Time: r.Time, Error: r.Error}
+
+		if r.Error == nil && r.Result != nil {
+			if err := db.Unmarshal(r.Result, &results[i].Result); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal result: %w", err)
+			}
+		}
+	}
+
+	return &results, nil
+}
+
+func (t *queryTracer) trace(target, sql string, duration time.Duration, err error) {
+	attrs := []any{
+		"target", target,
+		"duration", duration,
+		"query", sql,
+	}
+
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+
+	if t.slowThreshold > 0 && duration >= t.slowThreshold {
+		t.logger.Warn("Slow SurrealDB query", append(attrs, "threshold", t.slowThreshold)...)
+		return
+	}
+
+	if t.traceQueries {
+		t.logger.Debug("SurrealDB query", attrs...)
+	}
+}
@@ -0,0 +1,90 @@
+package surrealdb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// QueryLimiter is a token bucket shared by every query the exporter sends, so the
+// exporter stays within a query budget however many collectors, databases and
+// connections it queries.
+type QueryLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	throttled atomic.Uint64
+	waited    atomic.Int64
+}
+
+// NewQueryLimiter creates the limiter of limit, to be passed to every connection manager
+// of an exporter, or returns nil when limit is disabled.
+func NewQueryLimiter(limit domain.QueryRateLimit) *QueryLimiter {
+	if limit.QueriesPerSecond <= 0 {
+		return nil
+	}
+
+	return &QueryLimiter{
+		rate:   limit.QueriesPerSecond,
+		burst:  float64(max(limit.Burst, 1)),
+		tokens: float64(max(limit.Burst, 1)),
+		last:   time.Now(),
+	}
+}
+
+// ThrottleStats returns the queries delayed by the limiter and the time they waited.
+func (l *QueryLimiter) ThrottleStats() domain.QueryThrottleStats {
+	return domain.QueryThrottleStats{
+		Throttled: l.throttled.Load(),
+		Wait:      time.Duration(l.waited.Load()),
+	}
+}
+
+// wait takes a token, waiting until one is available. Tokens are taken in advance, so
+// concurrent waiters are admitted in order at the configured rate. A nil limiter admits
+// every query at once.
+func (l *QueryLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+
+	l.throttled.Add(1)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		l.waited.Add(int64(delay))
+		return nil
+
+	case <-ctx.Done():
+		l.waited.Add(int64(time.Since(now)))
+
+		// Give the token back, so abandoned queries do not delay the next ones.
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+
+		return fmt.Errorf("waiting for the query rate limit: %w", ctx.Err())
+	}
+}
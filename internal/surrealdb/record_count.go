@@ -4,11 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"log/slog"
+	"path"
 	"sync"
 	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
-	sdk "github.com/surrealdb/surrealdb.go"
 )
 
 type recordCountResult struct {
@@ -16,21 +17,46 @@ type recordCountResult struct {
 }
 
 type recordCountReader struct {
-	conn ConnectionManager
+	conn       QuerierProvider
+	partitions []domain.RecordCountPartitioning
+
+	flight flightGroup[*domain.RecordCountMetrics]
 }
 
-func NewRecordCountReader(conn ConnectionManager) (*recordCountReader, error) {
+// NewRecordCountReader creates a reader counting the records of tables matching one of
+// partitions in record ID ranges, and of all other tables with a single query.
+func NewRecordCountReader(
+	conn QuerierProvider,
+	partitions []domain.RecordCountPartitioning,
+) (*recordCountReader, error) {
 	if conn == nil {
 		return nil, errors.New("conn argument cannot be nil")
 	}
 
-	return &recordCountReader{conn: conn}, nil
+	return &recordCountReader{conn: conn, partitions: partitions}, nil
 }
 
 // RecordCount retrieves record counts for the provided tables in parallel.
+// On partial failure the counts that succeeded are returned together with the error.
+// Concurrent calls for the same set of tables share a single backend fetch.
 func (r *recordCountReader) RecordCount(
 	ctx context.Context,
 	tables []*domain.TableInfo,
+) (*domain.RecordCountMetrics, error) {
+	tableIDs := make([]domain.TableIdentifier, len(tables))
+	for i, table := range tables {
+		tableIDs[i] = domain.TableIdentifier{Namespace: table.Namespace, Database: table.Database, Table: table.Name}
+	}
+
+	return r.flight.do(tableIDsKey(tableIDs), func() (*domain.RecordCountMetrics, error) {
+		return r.recordCount(ctx, tables)
+	})
+}
+
+// recordCount retrieves record counts for the provided tables.
+func (r *recordCountReader) recordCount(
+	ctx context.Context,
+	tables []*domain.TableInfo,
 ) (*domain.RecordCountMetrics, error) {
 	start := time.Now()
 
@@ -42,14 +68,18 @@ func (r *recordCountReader) RecordCount(
 	}
 
 	tableCounts, err := r.fetchRecordCountsParallel(ctx, tables)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch record counts: %w", err)
-	}
 
-	return &domain.RecordCountMetrics{
+	metrics := &domain.RecordCountMetrics{
 		Tables:         tableCounts,
 		ScrapeDuration: time.Since(start),
-	}, nil
+	}
+
+	if err != nil {
+		// counts of the tables that succeeded are returned along with the error
+		return metrics, fmt.Errorf("failed to fetch record counts: %w", err)
+	}
+
+	return metrics, nil
 }
 
 // fetchRecordCountsParallel retrieves record counts for multiple tables in parallel.
@@ -102,39 +132,149 @@ func (r *recordCountReader) fetchTableRecordCount(
 	ctx context.Context,
 	table *domain.TableInfo,
 ) (*domain.TableRecordCount, error) {
-	db, err := r.conn.Get(ctx, table.Namespace, table.Database)
+	db, err := r.conn.Querier(ctx, table.Namespace, table.Database)
 	if err != nil {
 		return nil, fmt.Errorf("could not get DB connection for %s.%s.%s: %w",
 			table.Namespace, table.Database, table.Name, err)
 	}
 
-	query := fmt.Sprintf("SELECT count() FROM %s GROUP ALL;", table.Name)
-	results, err := sdk.Query[[]*recordCountResult](ctx, db, query, nil)
+	if partitioning, ok := r.partitioningFor(table); ok {
+		return r.fetchPartitionedRecordCount(ctx, db, table, partitioning)
+	}
+
+	query := "SELECT count() FROM type::table($table) GROUP ALL;"
+	vars := map[string]any{"table": table.Name}
+	recordCount, err := queryRecordCount(ctx, db, query, vars)
 	if err != nil {
 		return nil, fmt.Errorf("record count query failed for %s.%s.%s: %w",
 			table.Namespace, table.Database, table.Name, err)
 	}
 
+	return &domain.TableRecordCount{
+		Name:        table.Name,
+		Database:    table.Database,
+		Namespace:   table.Namespace,
+		RecordCount: recordCount,
+		CountedAt:   time.Now(),
+	}, nil
+}
+
+// partitioningFor returns the first partitioning whose pattern matches table.
+func (r *recordCountReader) partitioningFor(table *domain.TableInfo) (domain.RecordCountPartitioning, bool) {
+	key := table.Namespace + ":" + table.Database + ":" + table.Name
+	for _, partitioning := range r.partitions {
+		if matched, _ := path.Match(partitioning.Pattern, key); matched {
+			return partitioning, true
+		}
+	}
+
+	return domain.RecordCountPartitioning{}, false
+}
+
+// fetchPartitionedRecordCount counts the records of table with one query per record ID
+// range. Failed ranges are counted in PartitionsFailed, an error is returned only when
+// all of them fail.
+func (r *recordCountReader) fetchPartitionedRecordCount(
+	ctx context.Context,
+	db Querier,
+	table *domain.TableInfo,
+	partitioning domain.RecordCountPartitioning,
+) (*domain.TableRecordCount, error) {
+	ranges := recordIDRanges(partitioning.Boundaries)
+	counts := make([]int, len(ranges))
+	errs := make([]error, len(ranges))
+
+	semaphore := make(chan struct{}, partitioning.Concurrency)
+	var wg sync.WaitGroup
+
+	for i, idRange := range ranges {
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			query := fmt.Sprintf("SELECT count() FROM %s:%s GROUP ALL;", quoteIdent(table.Name), idRange)
+			counts[i], errs[i] = queryRecordCount(ctx, db, query, nil)
+		})
+	}
+	wg.Wait()
+
+	tableCount := &domain.TableRecordCount{
+		Name:       table.Name,
+		Database:   table.Database,
+		Namespace:  table.Namespace,
+		CountedAt:  time.Now(),
+		Partitions: make([]domain.PartitionRecordCount, 0, len(ranges)),
+	}
+
+	for i, idRange := range ranges {
+		if errs[i] != nil {
+			tableCount.PartitionsFailed++
+			slog.Warn("Record count of a partition failed",
+				"namespace", table.Namespace,
+				"database", table.Database,
+				"table", table.Name,
+				"range", idRange,
+				"error", errs[i])
+			continue
+		}
+
+		tableCount.RecordCount += counts[i]
+		tableCount.Partitions = append(tableCount.Partitions, domain.PartitionRecordCount{
+			Range:       idRange,
+			RecordCount: counts[i],
+		})
+	}
+
+	if tableCount.PartitionsFailed == len(ranges) {
+		return nil, fmt.Errorf("record count queries failed for all partitions of %s.%s.%s: %w",
+			table.Namespace, table.Database, table.Name, errors.Join(errs...))
+	}
+
+	return tableCount, nil
+}
+
+// recordIDRanges returns the SurrealQL record ID ranges split at boundaries, from the
+// first record up to the first boundary to the last boundary up to the last record.
+func recordIDRanges(boundaries []any) []string {
+	literals := make([]string, len(boundaries))
+	for i, boundary := range boundaries {
+		switch value := boundary.(type) {
+		case string:
+			literals[i] = quoteIdent(value)
+		default:
+			literals[i] = fmt.Sprint(value)
+		}
+	}
+
+	ranges := make([]string, 0, len(literals)+1)
+	ranges = append(ranges, ".."+literals[0])
+	for i := 1; i < len(literals); i++ {
+		ranges = append(ranges, literals[i-1]+".."+literals[i])
+	}
+	ranges = append(ranges, literals[len(literals)-1]+"..")
+
+	return ranges
+}
+
+// queryRecordCount runs a SELECT count() ... GROUP ALL query and returns the count.
+func queryRecordCount(ctx context.Context, db Querier, query string, vars map[string]any) (int, error) {
+	results, err := tracedQuery[[]*recordCountResult](ctx, db, query, vars)
+	if err != nil {
+		return 0, err
+	}
+
 	if results == nil || len(*results) == 0 {
-		return nil, fmt.Errorf("record count query returned no results for %s.%s.%s",
-			table.Namespace, table.Database, table.Name)
+		return 0, errors.New("query returned no results")
 	}
 
 	countResult := (*results)[0]
 	if countResult.Status != "OK" {
-		return nil, fmt.Errorf("record count query returned %s status for %s.%s.%s: %w",
-			countResult.Status, table.Namespace, table.Database, table.Name, countResult.Error)
+		return 0, fmt.Errorf("query returned %s status: %w", countResult.Status, queryStatus(countResult.Error))
 	}
 
-	recordCount := 0
-	if len(countResult.Result) != 0 {
-		recordCount = countResult.Result[0].Count
+	if len(countResult.Result) == 0 {
+		return 0, nil
 	}
 
-	return &domain.TableRecordCount{
-		Name:        table.Name,
-		Database:    table.Database,
-		Namespace:   table.Namespace,
-		RecordCount: recordCount,
-	}, nil
+	return countResult.Result[0].Count, nil
 }
@@ -0,0 +1,119 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// relationEdgeParallelism bounds the relation tables counted at once, since each count
+// scans a whole table.
+const relationEdgeParallelism = 4
+
+type relationEdgeResult struct {
+	FromTable string `json:"from_table"`
+	ToTable   string `json:"to_table"`
+	Count     int    `json:"count"`
+}
+
+type relationEdgeReader struct {
+	conn QuerierProvider
+}
+
+// NewRelationEdgeReader creates a reader counting the edges of relation tables by the
+// tables they connect.
+func NewRelationEdgeReader(conn QuerierProvider) (*relationEdgeReader, error) {
+	if conn == nil {
+		return nil, errors.New("conn argument cannot be nil")
+	}
+
+	return &relationEdgeReader{conn: conn}, nil
+}
+
+// RelationEdges counts the edges of the relation tables among tables, grouped by the
+// tables of their in and out records. On partial failure the counts that succeeded are
+// returned together with the error.
+func (r *relationEdgeReader) RelationEdges(
+	ctx context.Context,
+	tables []*domain.TableInfo,
+) ([]*domain.RelationEdgeCount, error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		result []*domain.RelationEdgeCount
+		errs   []error
+	)
+
+	semaphore := make(chan struct{}, relationEdgeParallelism)
+	for _, table := range tables {
+		if table.Definition.Type != domain.TableTypeRelation {
+			continue
+		}
+
+		wg.Go(func() {
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			counts, err := r.fetchRelationEdges(ctx, table)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			result = append(result, counts...)
+		})
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return result, fmt.Errorf("failed to count relation edges: %w", errors.Join(errs...))
+	}
+
+	return result, nil
+}
+
+// fetchRelationEdges counts the edges of one relation table.
+func (r *relationEdgeReader) fetchRelationEdges(
+	ctx context.Context,
+	table *domain.TableInfo,
+) ([]*domain.RelationEdgeCount, error) {
+	db, err := r.conn.Querier(ctx, table.Namespace, table.Database)
+	if err != nil {
+		return nil, fmt.Errorf("could not get DB connection for %s.%s.%s: %w",
+			table.Namespace, table.Database, table.Name, err)
+	}
+
+	query := "SELECT record::tb(in) AS from_table, record::tb(out) AS to_table, count() AS count " +
+		"FROM type::table($table) GROUP BY from_table, to_table;"
+	results, err := tracedQuery[[]*relationEdgeResult](ctx, db, query, map[string]any{"table": table.Name})
+	if err == nil && (results == nil || len(*results) == 0) {
+		err = errors.New("query returned no results")
+	}
+	if err == nil && (*results)[0].Status != "OK" {
+		err = fmt.Errorf("query returned %s status: %w", (*results)[0].Status, queryStatus((*results)[0].Error))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("relation edge query failed for %s.%s.%s: %w",
+			table.Namespace, table.Database, table.Name, err)
+	}
+
+	counts := make([]*domain.RelationEdgeCount, 0, len((*results)[0].Result))
+	for _, row := range (*results)[0].Result {
+		counts = append(counts, &domain.RelationEdgeCount{
+			Namespace: table.Namespace,
+			Database:  table.Database,
+			Relation:  table.Name,
+			FromTable: row.FromTable,
+			ToTable:   row.ToTable,
+			Count:     row.Count,
+		})
+	}
+
+	return counts, nil
+}
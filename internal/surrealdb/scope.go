@@ -0,0 +1,50 @@
+package surrealdb
+
+import (
+	"slices"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// scopeNamespaces returns the namespaces of scope in order.
+func scopeNamespaces(scope []domain.ScopeEntry) []string {
+	namespaces := make([]string, 0, len(scope))
+	for _, entry := range scope {
+		if !slices.Contains(namespaces, entry.Namespace) {
+			namespaces = append(namespaces, entry.Namespace)
+		}
+	}
+	slices.Sort(namespaces)
+
+	return namespaces
+}
+
+// scopeDatabases returns the databases of namespace listed in scope. It returns false
+// when scope covers the whole namespace, whose databases INFO FOR NS lists.
+func scopeDatabases(scope []domain.ScopeEntry, namespace string) ([]string, bool) {
+	var databases []string
+	for _, entry := range scope {
+		if entry.Namespace != namespace {
+			continue
+		}
+
+		if entry.Database == "" {
+			return nil, false
+		}
+
+		databases = append(databases, entry.Database)
+	}
+
+	return databases, len(databases) > 0
+}
+
+// namespaceConnection returns the namespace of the connection reading INFO FOR NS of
+// namespace: the root connection, or a connection signed in to the namespace within a
+// scope, as users without root access cannot switch namespaces.
+func namespaceConnection(scope []domain.ScopeEntry, namespace string) string {
+	if len(scope) == 0 {
+		return ""
+	}
+
+	return namespace
+}
@@ -0,0 +1,67 @@
+package surrealdb
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// flightGroup deduplicates concurrent calls with the same key: while a call is in
+// flight, later callers wait for it and share its result instead of querying again.
+// Shared results must be treated as read-only.
+type flightGroup[T any] struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall[T]
+}
+
+type flightCall[T any] struct {
+	wg  sync.WaitGroup
+	val T
+	err error
+}
+
+// do executes fn once for all concurrent callers using the same key.
+func (g *flightGroup[T]) do(key string, fn func() (T, error)) (T, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall[T])
+	}
+
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		call.wg.Wait()
+
+		return call.val, call.err
+	}
+
+	call := &flightCall[T]{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+
+		call.wg.Done()
+	}()
+
+	call.val, call.err = fn()
+
+	return call.val, call.err
+}
+
+// tableIDsKey builds an order-independent flight key for a set of tables.
+func tableIDsKey(tableIDs []domain.TableIdentifier) string {
+	keys := make([]string, len(tableIDs))
+	for i, id := range tableIDs {
+		keys[i] = id.String()
+	}
+
+	slices.Sort(keys)
+
+	return strings.Join(keys, ",")
+}
@@ -0,0 +1,210 @@
+package surrealdb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+const (
+	// statsEventName is the single event that feeds a stats side table.
+	statsEventName = "exporter_stats"
+
+	// statsEventChecksumPrefix marks the checksum stored in the event COMMENT.
+	statsEventChecksumPrefix = "exporter-checksum:"
+)
+
+// legacyStatsEventNames are per-action events created by older exporter versions.
+var legacyStatsEventNames = []string{"stats_create", "stats_update", "stats_delete"}
+
+// ensureStatsEvent makes sure the stats event on the target table matches the expected
+// definition. Definitions are compared by checksum, so the event is only redefined when
+// missing or when the exporter's detection logic or stats strategy has changed. With
+// deltaRecords the event appends delta records to the stats table instead of updating
+// the stats record.
+func ensureStatsEvent(
+	ctx context.Context,
+	logger *slog.Logger,
+	db Querier,
+	tableName, statsTableName, opTypeExpr string,
+	deltaRecords bool,
+) error {
+	body := statsEventBodyFor(statsTableName, opTypeExpr, deltaRecords)
+
+	events, err := fetchTableEvents(ctx, db, tableName)
+	if err != nil {
+		return err
+	}
+
+	if statsEventCurrent(events, body) {
+		return nil
+	}
+
+	for _, query := range statsEventStatements(tableName, body, events, supportsDefineOverwrite(ctx, logger, db)) {
+		if _, err = tracedQuery[any](ctx, db, query, nil); err != nil {
+			return fmt.Errorf("failed to define stats event, %q failed: %w", query, err)
+		}
+	}
+
+	if _, exists := events[statsEventName]; exists {
+		logger.Info("Stats event redefined", "table", tableName, "checksum", statsEventChecksum(body))
+	}
+
+	return nil
+}
+
+// statsEventCurrent reports whether events contain the stats event with the checksum of
+// body.
+func statsEventCurrent(events map[string]string, body string) bool {
+	definition, exists := events[statsEventName]
+
+	return exists && strings.Contains(definition, statsEventChecksum(body))
+}
+
+// statsEventStatements returns the statements replacing the stats event of a table with
+// the given events by one running body: the legacy events are removed, and an outdated
+// stats event is removed first when the server has no DEFINE ... OVERWRITE.
+func statsEventStatements(tableName, body string, events map[string]string, overwrite bool) []string {
+	var statements []string
+	for _, legacyName := range legacyStatsEventNames {
+		if _, ok := events[legacyName]; ok {
+			statements = append(statements,
+				fmt.Sprintf("REMOVE EVENT %s ON TABLE %s", quoteIdent(legacyName), quoteIdent(tableName)))
+		}
+	}
+
+	if _, exists := events[statsEventName]; exists && !overwrite {
+		statements = append(statements,
+			fmt.Sprintf("REMOVE EVENT %s ON TABLE %s", quoteIdent(statsEventName), quoteIdent(tableName)))
+	}
+
+	return append(statements, statsEventDefinition(tableName, body, statsEventChecksum(body), overwrite))
+}
+
+// fetchTableEvents returns the event definitions of a table keyed by event name.
+func fetchTableEvents(ctx context.Context, db Querier, tableName string) (map[string]string, error) {
+	query := fmt.Sprintf("INFO FOR TABLE %s", quoteIdent(tableName))
+	results, err := tracedQuery[*tableInfo](ctx, db, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("INFO FOR TABLE query failed: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 || (*results)[0].Result == nil {
+		return nil, errors.New("INFO FOR TABLE returned no results")
+	}
+
+	events := make(map[string]string, len((*results)[0].Result.Events))
+	for name, definition := range (*results)[0].Result.Events {
+		events[name] = fmt.Sprint(definition)
+	}
+
+	return events, nil
+}
+
+// statsEventDefinition builds the DEFINE EVENT statement for the stats event.
+func statsEventDefinition(tableName, body, checksum string, overwrite bool) string {
+	modifier := ""
+	if overwrite {
+		modifier = "OVERWRITE "
+	}
+
+	return fmt.Sprintf(`DEFINE EVENT %s%s ON TABLE %s
+		WHEN $event IN ["CREATE", "UPDATE", "DELETE"] THEN %s
+		COMMENT "%s%s";`,
+		modifier, quoteIdent(statsEventName), quoteIdent(tableName), body, statsEventChecksumPrefix, checksum)
+}
+
+// statsEventBodyFor returns the stats event body of the counter or delta strategy.
+func statsEventBodyFor(statsTableName, opTypeExpr string, deltaRecords bool) string {
+	if deltaRecords {
+		return statsDeltaEventBody(statsTableName, opTypeExpr)
+	}
+
+	return statsEventBody(statsTableName, opTypeExpr)
+}
+
+// statsEventBody builds the event body that classifies the changed record using
+// opTypeExpr and increments the matching counter in the stats table.
+func statsEventBody(statsTableName, opTypeExpr string) string {
+	var assignments strings.Builder
+	for _, action := range []string{"create", "update", "delete"} {
+		for _, opType := range domain.ClassifiableOperationTypes {
+			fmt.Fprintf(&assignments,
+				"\t\t\t%s_%s += IF $event = \"%s\" AND $op_type = \"%s\" THEN 1 ELSE 0 END,\n",
+				action, statsColumnSuffix(opType), strings.ToUpper(action), opType)
+		}
+	}
+
+	return fmt.Sprintf(`{
+			LET $record = IF $event = "DELETE" THEN $before ELSE $after END;
+			LET $op_type = %s;
+			UPDATE %s SET
+%s			last_update = time::now();
+		}`, opTypeExpr, recordID(statsTableName, "stats"), assignments.String())
+}
+
+// statsDeltaEventBody builds the event body that classifies the changed record using
+// opTypeExpr and appends a delta record for it next to the stats record, so concurrent
+// writes do not contend on the stats record.
+func statsDeltaEventBody(statsTableName, opTypeExpr string) string {
+	return fmt.Sprintf(`{
+			LET $record = IF $event = "DELETE" THEN $before ELSE $after END;
+			CREATE %s SET action = $event, op_type = %s;
+		}`, quoteIdent(statsTableName), opTypeExpr)
+}
+
+// statsDeltaCompaction builds the transaction that adds the delta records of a stats
+// table to the counters of its stats record and deletes them.
+func statsDeltaCompaction(statsTableName string) string {
+	var assignments strings.Builder
+	for _, action := range []string{"create", "update", "delete"} {
+		for _, opType := range domain.ClassifiableOperationTypes {
+			fmt.Fprintf(&assignments,
+				"			%s_%s += math::sum($counts[WHERE action = \"%s\" AND op_type = \"%s\"].count),\n",
+				action, statsColumnSuffix(opType), strings.ToUpper(action), opType)
+		}
+	}
+
+	return fmt.Sprintf(`BEGIN TRANSACTION;
+		LET $counts = (SELECT action, op_type, count() AS count FROM %[1]s WHERE id != %[2]s GROUP BY action, op_type);
+		IF array::len($counts) > 0 THEN
+			(UPDATE %[2]s SET
+%[3]s			last_update = time::now())
+		END;
+		DELETE %[1]s WHERE id != %[2]s;
+		COMMIT TRANSACTION;`, quoteIdent(statsTableName), recordID(statsTableName, "stats"), assignments.String())
+}
+
+// statsColumnSuffix returns the stats table column suffix for an operation type.
+func statsColumnSuffix(opType domain.OperationType) string {
+	if opType == domain.OperationTypeKeyValue {
+		return "kv"
+	}
+
+	return string(opType)
+}
+
+// statsEventChecksum returns a short checksum identifying an event body.
+func statsEventChecksum(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:8])
+}
+
+// supportsDefineOverwrite reports whether the connected server accepts DEFINE ... OVERWRITE.
+func supportsDefineOverwrite(ctx context.Context, logger *slog.Logger, db Querier) bool {
+	v, err := db.Version(ctx)
+	if err != nil {
+		logger.Debug("Unable to determine SurrealDB version, assuming no OVERWRITE support", "error", err)
+		return false
+	}
+
+	parsed, ok := parseServerVersion(v)
+
+	return ok && parsed.atLeast(featureMinVersions[domain.FeatureDefineOverwrite])
+}
@@ -0,0 +1,166 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// orphanScanTimeout bounds the startup scan for orphan stats tables.
+const orphanScanTimeout = 5 * time.Minute
+
+// Start removes stats tables left behind by previous runs in the background when orphan
+// removal is enabled. Stats tables created by this process are handled by reconcileTables.
+func (m *StatsTableManager) Start() {
+	if !m.removeOrphanTables {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(m.ctx, orphanScanTimeout)
+		defer cancel()
+
+		if err := m.removeOrphansFromPreviousRuns(ctx); err != nil {
+			m.logger.Warn("Failed to scan for orphan stats tables", "error", err)
+		}
+	}()
+}
+
+// removeOrphansFromPreviousRuns scans every database, or those of the scope, for stats
+// tables whose target table no longer exists and removes them. Only tables whose stats
+// record names the expected target table are considered, so user tables sharing the
+// prefix are left alone.
+func (m *StatsTableManager) removeOrphansFromPreviousRuns(ctx context.Context) error {
+	namespaces, err := m.orphanScanNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, namespace := range namespaces {
+		databases, err := m.orphanScanDatabases(ctx, namespace)
+		if err != nil {
+			m.logger.Warn("Failed to list databases for orphan stats tables", "namespace", namespace, "error", err)
+			continue
+		}
+
+		for _, database := range databases {
+			if err = m.removeDatabaseOrphans(ctx, namespace, database); err != nil {
+				m.logger.Warn("Failed to remove orphan stats tables",
+					"namespace", namespace,
+					"database", database,
+					"error", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// orphanScanNamespaces returns the namespaces of the scope, or of the server listed by
+// INFO FOR ROOT.
+func (m *StatsTableManager) orphanScanNamespaces(ctx context.Context) ([]string, error) {
+	if len(m.scope) > 0 {
+		return scopeNamespaces(m.scope), nil
+	}
+
+	common, err := m.connManager.Querier(ctx, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	rootResults, err := tracedQuery[*rootInfo](ctx, common, "INFO FOR ROOT", nil)
+	if err != nil {
+		return nil, fmt.Errorf("INFO FOR ROOT query failed: %w", err)
+	}
+
+	if rootResults == nil || len(*rootResults) == 0 || (*rootResults)[0].Result == nil {
+		return nil, errors.New("INFO FOR ROOT returned no results")
+	}
+
+	return slices.Sorted(maps.Keys((*rootResults)[0].Result.Namespaces)), nil
+}
+
+// orphanScanDatabases returns the databases of namespace listed in the scope, or by
+// INFO FOR NS.
+func (m *StatsTableManager) orphanScanDatabases(ctx context.Context, namespace string) ([]string, error) {
+	if databases, ok := scopeDatabases(m.scope, namespace); ok {
+		return databases, nil
+	}
+
+	db, err := m.connManager.Querier(ctx, namespaceConnection(m.scope, namespace), "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	query := fmt.Sprintf("USE NS %s; INFO FOR NS;", quoteIdent(namespace))
+	nsResults, err := tracedQuery[*namespaceInfo](ctx, db, query, nil)
+	if err != nil {
+		return nil, fmt.Errorf("INFO FOR NAMESPACE query failed: %w", err)
+	}
+
+	if nsResults == nil || len(*nsResults) < 2 || (*nsResults)[1].Result == nil {
+		return nil, errors.New("INFO FOR NAMESPACE returned no results")
+	}
+
+	return slices.Sorted(maps.Keys((*nsResults)[1].Result.Databases)), nil
+}
+
+// removeDatabaseOrphans removes the orphan stats tables of one database.
+func (m *StatsTableManager) removeDatabaseOrphans(ctx context.Context, namespace, database string) error {
+	db, err := m.connManager.Querier(ctx, namespace, database)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	results, err := tracedQuery[*databaseInfo](ctx, db, "INFO FOR DB", nil)
+	if err != nil {
+		return fmt.Errorf("INFO FOR DATABASE query failed: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 || (*results)[0].Result == nil {
+		return errors.New("INFO FOR DATABASE returned no results")
+	}
+
+	tables := (*results)[0].Result.Tables
+	for name := range tables {
+		target, ok := strings.CutPrefix(name, m.sideTablePrefix)
+		if !ok || target == "" {
+			continue
+		}
+
+		if _, exists := tables[target]; exists {
+			continue
+		}
+
+		query := fmt.Sprintf("SELECT VALUE target_table FROM %s", recordID(name, "stats"))
+		targets, err := tracedQuery[[]string](ctx, db, query, nil)
+		if err != nil || targets == nil || len(*targets) == 0 || !slices.Contains((*targets)[0].Result, target) {
+			continue
+		}
+
+		state := &statsTableState{
+			targetTableID:  domain.TableIdentifier{Namespace: namespace, Database: database, Table: target},
+			statsTableName: name,
+		}
+
+		if m.dryRun {
+			m.mu.Lock()
+			m.planLocked(domain.StatsTableActionRemove, state.targetTableID, name, statsTableRemoval(state))
+			m.mu.Unlock()
+			continue
+		}
+
+		m.logger.Info("Removing orphan stats table from a previous run", "table", state.targetTableID.String())
+		if err = m.removeStatsTable(state); err != nil {
+			m.logger.Error("Failed to remove orphan stats table", "table", state.targetTableID.String(), "error", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package surrealdb
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// StatsTablePlan returns the actions a dry run reconciliation would take, sorted by
+// table and action. It is empty unless dry run is enabled.
+func (m *StatsTableManager) StatsTablePlan() []*domain.StatsTableAction {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	plan := make([]*domain.StatsTableAction, 0, len(m.plan))
+	for _, action := range m.plan {
+		actionCopy := *action
+		plan = append(plan, &actionCopy)
+	}
+
+	slices.SortFunc(plan, func(a, b *domain.StatsTableAction) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Database, b.Database),
+			cmp.Compare(a.Table, b.Table),
+			cmp.Compare(a.Action, b.Action),
+		)
+	})
+
+	return plan
+}
+
+// planTablesLocked plans the changes reconciliation would make to the stats tables of
+// desired instead of making them: creating missing stats tables and migrating the schema
+// and event of outdated ones. Planned changes of tables no longer desired or up to date
+// are dropped (caller must hold lock).
+func (m *StatsTableManager) planTablesLocked(desired map[string]domain.TableIdentifier) {
+	for key, action := range m.plan {
+		tableKey := domain.TableIdentifier{Namespace: action.Namespace, Database: action.Database, Table: action.Table}.String()
+		if _, exists := desired[tableKey]; action.Action != domain.StatsTableActionRemove && !exists {
+			delete(m.plan, key)
+		}
+	}
+
+	for _, tableID := range desired {
+		action, statements, err := m.plannedChanges(tableID)
+		if err != nil {
+			m.logger.Error("Failed to plan stats table changes", "table", tableID.String(), "error", err)
+			continue
+		}
+
+		for _, other := range []string{domain.StatsTableActionCreate, domain.StatsTableActionMigrate} {
+			if other != action {
+				delete(m.plan, other+":"+tableID.String())
+			}
+		}
+
+		if action != "" {
+			m.planLocked(action, tableID, m.getStatsTableName(tableID.Table), statements)
+		}
+	}
+}
+
+// planLocked records an action of a dry run, logging it when it was not planned before
+// or its statements changed (caller must hold lock).
+func (m *StatsTableManager) planLocked(action string, tableID domain.TableIdentifier, statsTableName string, statements []string) {
+	key := action + ":" + tableID.String()
+	if planned, exists := m.plan[key]; exists && slices.Equal(planned.Statements, statements) {
+		return
+	}
+
+	m.plan[key] = &domain.StatsTableAction{
+		Action:     action,
+		Namespace:  tableID.Namespace,
+		Database:   tableID.Database,
+		Table:      tableID.Table,
+		StatsTable: statsTableName,
+		Statements: statements,
+		PlannedAt:  time.Now(),
+	}
+
+	m.logger.Info("Stats table action planned (dry run)",
+		"action", action,
+		"table", tableID.String(),
+		"stats_table", statsTableName,
+		"statements", statements)
+}
+
+// plannedChanges compares the stats table and stats event of a table with the current
+// definitions and returns the statements createStatsTable would run: a create action for
+// a missing stats table, a migrate action for an outdated schema or event, and no action
+// when both are up to date.
+func (m *StatsTableManager) plannedChanges(tableID domain.TableIdentifier) (string, []string, error) {
+	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
+	defer cancel()
+
+	db, err := m.connManager.Querier(ctx, tableID.Namespace, tableID.Database)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	statsTableName := m.getStatsTableName(tableID.Table)
+
+	definition, exists, err := fetchTableDefinition(ctx, db, statsTableName)
+	if err != nil {
+		return "", nil, err
+	}
+
+	events, err := fetchTableEvents(ctx, db, tableID.Table)
+	if err != nil {
+		return "", nil, err
+	}
+
+	body := statsEventBodyFor(statsTableName, m.classifier.SurrealQL(tableID, "$record"), m.deltaRecords)
+
+	schemaCurrent := statsTableSchemaCurrent(statsTableName, definition, exists)
+	eventCurrent := statsEventCurrent(events, body)
+	if schemaCurrent && eventCurrent {
+		return "", nil, nil
+	}
+
+	overwrite := supportsDefineOverwrite(ctx, m.logger, db)
+
+	var statements []string
+	if !schemaCurrent {
+		statements = append(statements, statsTableSchemaStatements(statsTableName, overwrite)...)
+	}
+
+	if !exists {
+		statements = append(statements,
+			"LET $target_table = "+quoteString(tableID.Table),
+			statsRecordCreation(statsTableName),
+		)
+	}
+
+	if !eventCurrent {
+		statements = append(statements, statsEventStatements(tableID.Table, body, events, overwrite)...)
+	}
+
+	if !exists {
+		return domain.StatsTableActionCreate, statements, nil
+	}
+
+	return domain.StatsTableActionMigrate, statements, nil
+}
@@ -0,0 +1,110 @@
+package surrealdb
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// fakeClassifier classifies every record as a document.
+type fakeClassifier struct{}
+
+func (fakeClassifier) Classify(domain.TableIdentifier, map[string]any) domain.OperationType {
+	return domain.OperationTypeDocument
+}
+
+func (fakeClassifier) SurrealQL(domain.TableIdentifier, string) string {
+	return `"document"`
+}
+
+func TestStatsTablePlan(t *testing.T) {
+	tableID := domain.TableIdentifier{Namespace: "shop", Database: "app", Table: "user"}
+	body := statsEventBodyFor("_stats_user", fakeClassifier{}.SurrealQL(tableID, "$record"), false)
+
+	currentTable := statsTableDefinition("_stats_user", "", statsTableSchemaChecksum("_stats_user"))
+	currentEvent := statsEventDefinition("user", body, statsEventChecksum(body), false)
+
+	tests := []struct {
+		name           string
+		statsTable     string
+		event          string
+		wantAction     string
+		wantStatements []string
+	}{
+		{
+			name:           "missing stats table",
+			wantAction:     domain.StatsTableActionCreate,
+			wantStatements: []string{"DEFINE TABLE OVERWRITE", "LET $target_table", "DEFINE EVENT OVERWRITE"},
+		},
+		{
+			name:       "up to date",
+			statsTable: currentTable,
+			event:      currentEvent,
+		},
+		{
+			name:           "outdated schema",
+			statsTable:     "DEFINE TABLE _stats_user TYPE NORMAL SCHEMALESS PERMISSIONS NONE",
+			event:          currentEvent,
+			wantAction:     domain.StatsTableActionMigrate,
+			wantStatements: []string{"DEFINE TABLE OVERWRITE", "DEFINE FIELD OVERWRITE"},
+		},
+		{
+			name:           "outdated event",
+			statsTable:     currentTable,
+			event:          `DEFINE EVENT exporter_stats ON user WHEN true THEN {} COMMENT "exporter-checksum:0"`,
+			wantAction:     domain.StatsTableActionMigrate,
+			wantStatements: []string{"DEFINE EVENT OVERWRITE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tables := map[string]any{"user": "DEFINE TABLE user TYPE NORMAL SCHEMALESS PERMISSIONS NONE"}
+			if tt.statsTable != "" {
+				tables["_stats_user"] = tt.statsTable
+			}
+
+			events := map[string]any{}
+			if tt.event != "" {
+				events[statsEventName] = tt.event
+			}
+
+			server := &fakeServer{version: "surrealdb-2.2.1", responses: map[string]any{
+				"shop/app INFO FOR DB":           map[string]any{"tables": tables},
+				"shop/app INFO FOR TABLE ⟨user⟩": map[string]any{"events": events},
+			}}
+
+			manager := NewStatsTableManager(server, fakeClassifier{}, false, nil, "_stats_", false, true,
+				slog.New(slog.NewTextHandler(io.Discard, nil)))
+			defer manager.Stop()
+
+			manager.reconcileTables([]domain.TableIdentifier{tableID})
+
+			plan := manager.StatsTablePlan()
+			if tt.wantAction == "" {
+				if len(plan) != 0 {
+					t.Fatalf("plan = %v, want no actions", plan)
+				}
+				return
+			}
+
+			if len(plan) != 1 || plan[0].Action != tt.wantAction {
+				t.Fatalf("plan = %v, want one %s action", plan, tt.wantAction)
+			}
+
+			statements := strings.Join(plan[0].Statements, "\n")
+			for _, want := range tt.wantStatements {
+				if !strings.Contains(statements, want) {
+					t.Errorf("statements do not contain %q:\n%s", want, statements)
+				}
+			}
+
+			if tt.wantAction == domain.StatsTableActionMigrate && strings.Contains(statements, "LET $target_table") {
+				t.Error("migrating an existing stats table should not create its stats record")
+			}
+		})
+	}
+}
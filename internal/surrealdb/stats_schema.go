@@ -0,0 +1,155 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	sdk "github.com/surrealdb/surrealdb.go"
+)
+
+// statsTableFields are the fields of a stats table with their types. Fields are optional
+// because the stats record and delta records share the table.
+var statsTableFields = func() [][2]string {
+	fields := [][2]string{{"target_table", "option<string>"}}
+	for _, action := range []string{"create", "update", "delete"} {
+		for _, opType := range domain.ClassifiableOperationTypes {
+			fields = append(fields, [2]string{action + "_" + statsColumnSuffix(opType), "option<int>"})
+		}
+	}
+
+	return append(fields,
+		[2]string{"last_update", "option<datetime>"},
+		[2]string{"created_at", "option<datetime>"},
+		[2]string{"action", "option<string>"},
+		[2]string{"op_type", "option<string>"},
+	)
+}()
+
+// ensureStatsTableSchema defines a stats table as SCHEMAFULL with typed fields and
+// PERMISSIONS NONE, so record users cannot read or tamper with the counters; events and
+// system users are not restricted by table permissions. Like stats events, the definition
+// carries a checksum, so existing schemaless stats tables and tables defined by older
+// exporter versions are migrated in place while their records are kept.
+func ensureStatsTableSchema(ctx context.Context, logger *slog.Logger, db Querier, statsTableName string) error {
+	definition, exists, err := fetchTableDefinition(ctx, db, statsTableName)
+	if err != nil {
+		return err
+	}
+
+	if statsTableSchemaCurrent(statsTableName, definition, exists) {
+		return nil
+	}
+
+	overwrite := supportsDefineOverwrite(ctx, logger, db)
+
+	err = defineStatsTableSchema(ctx, db, statsTableSchemaStatements(statsTableName, overwrite))
+	if err != nil && exists && !overwrite {
+		// Without a known server version OVERWRITE is not used, but servers requiring it
+		// reject redefining the existing table.
+		if !isAlreadyExists(err) {
+			return fmt.Errorf("failed to migrate stats table %s without DEFINE ... OVERWRITE: %w", statsTableName, err)
+		}
+
+		logger.Info("Stats table already exists, migrating it with DEFINE ... OVERWRITE", "stats_table", statsTableName)
+		err = defineStatsTableSchema(ctx, db, statsTableSchemaStatements(statsTableName, true))
+	}
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		logger.Info("Stats table schema migrated", "stats_table", statsTableName, "checksum", statsTableSchemaChecksum(statsTableName))
+	}
+
+	return nil
+}
+
+// defineStatsTableSchema runs the statements defining the schema of a stats table and
+// returns the error of the first failing statement.
+func defineStatsTableSchema(ctx context.Context, db Querier, queries []string) error {
+	results, err := queryBatch[any](ctx, db, queries)
+	if err != nil {
+		return fmt.Errorf("failed to define stats table schema: %w", err)
+	}
+
+	for i, result := range results {
+		if result.Status != "OK" {
+			return fmt.Errorf("%q returned %s status: %w", queries[i], result.Status, queryStatus(result.Error))
+		}
+	}
+
+	return nil
+}
+
+// isAlreadyExists reports whether err is SurrealDB rejecting the definition of an
+// existing resource.
+func isAlreadyExists(err error) bool {
+	var queryErr *sdk.QueryError
+
+	return errors.As(err, &queryErr) && strings.Contains(queryErr.Message, "already exists")
+}
+
+// statsTableSchemaCurrent reports whether a stats table with the given definition exists
+// and carries the checksum of the current schema.
+func statsTableSchemaCurrent(statsTableName, definition string, exists bool) bool {
+	return exists && strings.Contains(definition, statsTableSchemaChecksum(statsTableName))
+}
+
+// statsTableSchemaStatements returns the statements defining the schema of a stats table,
+// replacing an existing schema with DEFINE ... OVERWRITE when overwrite is set.
+func statsTableSchemaStatements(statsTableName string, overwrite bool) []string {
+	modifier := ""
+	if overwrite {
+		modifier = "OVERWRITE "
+	}
+
+	return append([]string{statsTableDefinition(statsTableName, modifier, statsTableSchemaChecksum(statsTableName))},
+		statsTableSchema(statsTableName, modifier)...)
+}
+
+// statsTableSchemaChecksum returns the checksum identifying the schema of a stats table.
+func statsTableSchemaChecksum(statsTableName string) string {
+	return statsEventChecksum(strings.Join(statsTableSchema(statsTableName, ""), "\n"))
+}
+
+// statsTableDefinition builds the DEFINE TABLE statement of a stats table using modifier,
+// either "" or "OVERWRITE ".
+func statsTableDefinition(statsTableName, modifier, checksum string) string {
+	return fmt.Sprintf(`DEFINE TABLE %s%s SCHEMAFULL PERMISSIONS NONE COMMENT "%s%s"`,
+		modifier, quoteIdent(statsTableName), statsEventChecksumPrefix, checksum)
+}
+
+// statsTableSchema returns the DEFINE FIELD statements of a stats table using modifier,
+// either "" or "OVERWRITE ".
+func statsTableSchema(statsTableName, modifier string) []string {
+	statements := make([]string, 0, len(statsTableFields))
+	for _, field := range statsTableFields {
+		statements = append(statements, fmt.Sprintf("DEFINE FIELD %s%s ON TABLE %s TYPE %s PERMISSIONS NONE",
+			modifier, quoteIdent(field[0]), quoteIdent(statsTableName), field[1]))
+	}
+
+	return statements
+}
+
+// fetchTableDefinition returns the DEFINE TABLE statement of a table and whether it exists.
+func fetchTableDefinition(ctx context.Context, db Querier, tableName string) (string, bool, error) {
+	results, err := tracedQuery[*databaseInfo](ctx, db, "INFO FOR DB", nil)
+	if err != nil {
+		return "", false, fmt.Errorf("INFO FOR DATABASE query failed: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 || (*results)[0].Result == nil {
+		return "", false, errors.New("INFO FOR DATABASE returned no results")
+	}
+
+	definition, exists := (*results)[0].Result.Tables[tableName]
+	if !exists {
+		return "", false, nil
+	}
+
+	return fmt.Sprint(definition), true, nil
+}
@@ -0,0 +1,66 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestEnsureStatsTableSchemaUnknownVersion(t *testing.T) {
+	tests := []struct {
+		name          string
+		rejection     string
+		wantErr       bool
+		wantOverwrite bool
+	}{
+		{
+			name:          "already exists",
+			rejection:     "The table '_stats_user' already exists",
+			wantErr:       false,
+			wantOverwrite: true,
+		},
+		{
+			name:          "other error",
+			rejection:     "Permission denied",
+			wantErr:       true,
+			wantOverwrite: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeServer{
+				responses: map[string]any{
+					"shop/app INFO FOR DB": map[string]any{"tables": map[string]any{
+						"_stats_user": "DEFINE TABLE _stats_user TYPE NORMAL SCHEMALESS PERMISSIONS NONE",
+					}},
+				},
+				define: func(statement string) error {
+					if !strings.Contains(statement, "OVERWRITE") {
+						return errors.New(tt.rejection)
+					}
+
+					return nil
+				},
+			}
+
+			db, err := server.Querier(context.Background(), "shop", "app")
+			if err != nil {
+				t.Fatalf("failed to get querier: %v", err)
+			}
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			err = ensureStatsTableSchema(context.Background(), logger, db, "_stats_user")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error: %t", err, tt.wantErr)
+			}
+
+			if ran := server.ran("shop/app DEFINE TABLE OVERWRITE"); ran != tt.wantOverwrite {
+				t.Errorf("DEFINE TABLE OVERWRITE run = %t, want %t", ran, tt.wantOverwrite)
+			}
+		})
+	}
+}
@@ -1,15 +1,15 @@
 package surrealdb
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"log/slog"
-	"strings"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
-	sdk "github.com/surrealdb/surrealdb.go"
 )
 
 // statsRecord represents a record from the stats table.
@@ -28,17 +28,26 @@ type statsRecord struct {
 	DeleteGraph      int64     `json:"delete_graph"`
 	DeleteDocument   int64     `json:"delete_document"`
 	LastUpdate       time.Time `json:"last_update"`
+	CreatedAt        time.Time `json:"created_at"`
 }
 
 // StatsTableManager manages side tables for collecting operation statistics.
 type StatsTableManager struct {
-	connManager        ConnectionManager
+	connManager        QuerierProvider
+	classifier         OperationClassifier
 	removeOrphanTables bool
+	scope              []domain.ScopeEntry
 	sideTablePrefix    string
+	deltaRecords       bool
+	dryRun             bool
+	logger             *slog.Logger
 
 	activeTables map[string]*statsTableState
+	plan         map[string]*domain.StatsTableAction
 	mu           sync.RWMutex
 
+	flight flightGroup[[]*domain.StatsTableData]
+
 	ctx    context.Context
 	cancel context.CancelFunc
 }
@@ -50,26 +59,46 @@ type statsTableState struct {
 }
 
 // NewStatsTableManager creates a new stats table manager.
+// With deltaRecords, stats events append one delta record per change to the stats table
+// instead of updating the stats record, and the deltas are folded into the stats record
+// when the stats are queried.
+// With dryRun, reconciliation only plans and logs the changes it would make, see
+// StatsTablePlan.
+// A non-empty scope limits the orphan table scan to its namespaces and databases.
 func NewStatsTableManager(
-	connManager ConnectionManager,
+	connManager QuerierProvider,
+	classifier OperationClassifier,
 	removeOrphanTables bool,
+	scope []domain.ScopeEntry,
 	sideTablePrefix string,
+	deltaRecords bool,
+	dryRun bool,
+	logger *slog.Logger,
 ) *StatsTableManager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &StatsTableManager{
 		connManager:        connManager,
+		classifier:         classifier,
 		removeOrphanTables: removeOrphanTables,
+		scope:              scope,
 		sideTablePrefix:    sideTablePrefix,
+		deltaRecords:       deltaRecords,
+		dryRun:             dryRun,
+		logger:             logger,
 		activeTables:       make(map[string]*statsTableState),
+		plan:               make(map[string]*domain.StatsTableAction),
 		ctx:                ctx,
 		cancel:             cancel,
 	}
 }
 
 // StatsTableInfo returns stats from all side tables and reconciles tables.
+// Concurrent calls for the same set of tables share a single backend fetch.
 func (m *StatsTableManager) StatsTableInfo(tableIDs []domain.TableIdentifier) ([]*domain.StatsTableData, error) {
-	statsData, err := m.queryAllStatsTables(tableIDs)
+	statsData, err := m.flight.do(tableIDsKey(tableIDs), func() ([]*domain.StatsTableData, error) {
+		return m.queryAllStatsTables(tableIDs)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to query stats tables: %w", err)
 	}
@@ -79,88 +108,144 @@ func (m *StatsTableManager) StatsTableInfo(tableIDs []domain.TableIdentifier) ([
 	return statsData, nil
 }
 
+// ManagedStatsTables returns the stats tables maintained by the manager, ordered by table.
+func (m *StatsTableManager) ManagedStatsTables() []*domain.ManagedStatsTable {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tables := make([]*domain.ManagedStatsTable, 0, len(m.activeTables))
+	for _, state := range m.activeTables {
+		tables = append(tables, &domain.ManagedStatsTable{
+			Namespace:  state.targetTableID.Namespace,
+			Database:   state.targetTableID.Database,
+			Table:      state.targetTableID.Table,
+			StatsTable: state.statsTableName,
+		})
+	}
+
+	slices.SortFunc(tables, func(a, b *domain.ManagedStatsTable) int {
+		return cmp.Or(
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Database, b.Database),
+			cmp.Compare(a.Table, b.Table),
+		)
+	})
+
+	return tables
+}
+
 // Stop gracefully shuts down the manager.
 func (m *StatsTableManager) Stop() {
-	slog.Info("Stopping stats table manager")
+	m.logger.Info("Stopping stats table manager")
 	m.cancel()
-	slog.Info("Stats table manager stopped")
+	m.logger.Info("Stats table manager stopped")
 }
 
-// queryAllStatsTables queries all stats tables for the given table IDs.
+// queryAllStatsTables queries all stats tables for the given table IDs, sending the
+// queries for the tables of each database as a single batch.
 func (m *StatsTableManager) queryAllStatsTables(tableIDs []domain.TableIdentifier) ([]*domain.StatsTableData, error) {
+	byDatabase := make(map[string][]domain.TableIdentifier)
+	for _, tableID := range tableIDs {
+		key := tableID.Namespace + ":" + tableID.Database
+		byDatabase[key] = append(byDatabase[key], tableID)
+	}
+
 	var result []*domain.StatsTableData
 	var mu sync.Mutex
 	var wg sync.WaitGroup
-	errChan := make(chan error, len(tableIDs))
 
-	for _, tableID := range tableIDs {
-		wg.Add(1)
-		go func(tid domain.TableIdentifier) {
-			defer wg.Done()
-
-			data, err := m.queryStatsTable(tid)
+	for _, databaseTables := range byDatabase {
+		wg.Go(func() {
+			data, err := m.queryDatabaseStatsTables(databaseTables)
 			if err != nil {
-				errChan <- fmt.Errorf("failed to query stats for %s: %w", tid.String(), err)
+				m.logger.Warn("Error querying stats tables",
+					"namespace", databaseTables[0].Namespace,
+					"database", databaseTables[0].Database,
+					"error", err)
 				return
 			}
 
-			if data != nil {
-				mu.Lock()
-				result = append(result, data)
-				mu.Unlock()
-			}
-		}(tableID)
+			mu.Lock()
+			result = append(result, data...)
+			mu.Unlock()
+		})
 	}
 
 	wg.Wait()
-	close(errChan)
-
-	for err := range errChan {
-		slog.Warn("Error querying stats table", "error", err)
-	}
 
 	return result, nil
 }
 
-// queryStatsTable queries a single stats table.
-func (m *StatsTableManager) queryStatsTable(tableID domain.TableIdentifier) (*domain.StatsTableData, error) {
+// queryDatabaseStatsTables queries the stats tables of tables in one database.
+// Tables whose stats table cannot be read yet are skipped.
+func (m *StatsTableManager) queryDatabaseStatsTables(tableIDs []domain.TableIdentifier) ([]*domain.StatsTableData, error) {
 	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
 	defer cancel()
 
-	db, err := m.connManager.Get(ctx, tableID.Namespace, tableID.Database)
+	db, err := m.connManager.Querier(ctx, tableIDs[0].Namespace, tableIDs[0].Database)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get connection: %w", err)
 	}
 
-	statsTableName := m.getStatsTableName(tableID.Table)
-
-	query := fmt.Sprintf("SELECT * FROM %s LIMIT 1", statsTableName)
-	results, err := sdk.Query[[]*statsRecord](ctx, db, query, nil)
-	if err != nil {
-		slog.Debug("Stats table query failed", "table", tableID.String(), "error", err)
-		return nil, nil
+	if m.deltaRecords && !m.dryRun {
+		m.compactDeltas(ctx, db, tableIDs)
 	}
 
-	if results == nil || len(*results) == 0 {
-		return nil, nil
+	statements := make([]string, len(tableIDs))
+	for i, tableID := range tableIDs {
+		statements[i] = fmt.Sprintf("SELECT * FROM %s", recordID(m.getStatsTableName(tableID.Table), "stats"))
 	}
 
-	queryResult := (*results)[0]
-	if queryResult.Status != "OK" {
-		slog.Debug("Stats table query returned non-OK status",
-			"table", tableID.String(),
-			"status", queryResult.Status,
-			"error", queryResult.Error)
-		return nil, nil
+	results, err := queryBatch[[]*statsRecord](ctx, db, statements)
+	if err != nil {
+		return nil, err
 	}
 
-	if queryResult.Result == nil || len(queryResult.Result) == 0 {
-		return nil, nil
+	data := make([]*domain.StatsTableData, 0, len(tableIDs))
+	for i, queryResult := range results {
+		if queryResult.Status != "OK" {
+			m.logger.Debug("Stats table query returned non-OK status",
+				"table", tableIDs[i].String(),
+				"status", queryResult.Status,
+				"error", queryResult.Error)
+			continue
+		}
+
+		if len(queryResult.Result) == 0 {
+			continue
+		}
+
+		data = append(data, statsTableData(tableIDs[i], queryResult.Result[0]))
 	}
 
-	record := queryResult.Result[0]
+	return data, nil
+}
 
-	data := &domain.StatsTableData{
+// compactDeltas folds the delta records of the given tables into their stats records.
+// Tables whose deltas cannot be compacted keep them for the next attempt.
+func (m *StatsTableManager) compactDeltas(ctx context.Context, db Querier, tableIDs []domain.TableIdentifier) {
+	for _, tableID := range tableIDs {
+		query := statsDeltaCompaction(m.getStatsTableName(tableID.Table))
+
+		results, err := tracedQuery[any](ctx, db, query, nil)
+		if err == nil && results != nil {
+			for _, result := range *results {
+				if result.Status != "OK" {
+					err = fmt.Errorf("compaction returned %s status: %w", result.Status, queryStatus(result.Error))
+					break
+				}
+			}
+		}
+
+		if err != nil {
+			m.logger.Warn("Failed to compact stats deltas", "table", tableID.String(), "error", err)
+		}
+	}
+}
+
+// statsTableData converts a stats record of a table to its domain representation.
+func statsTableData(tableID domain.TableIdentifier, record *statsRecord) *domain.StatsTableData {
+	return &domain.StatsTableData{
 		Namespace:        tableID.Namespace,
 		Database:         tableID.Database,
 		Table:            tableID.Table,
@@ -177,12 +262,12 @@ func (m *StatsTableManager) queryStatsTable(tableID domain.TableIdentifier) (*do
 		DeleteGraph:      record.DeleteGraph,
 		DeleteDocument:   record.DeleteDocument,
 		LastUpdate:       record.LastUpdate,
+		CreatedAt:        record.CreatedAt,
 	}
-
-	return data, nil
 }
 
-// reconcileTables creates new stats tables and removes orphans.
+// reconcileTables creates new stats tables and removes orphans, or plans doing so in
+// dry run mode.
 func (m *StatsTableManager) reconcileTables(desiredTables []domain.TableIdentifier) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -192,12 +277,17 @@ func (m *StatsTableManager) reconcileTables(desiredTables []domain.TableIdentifi
 		desired[table.String()] = table
 	}
 
+	if m.dryRun {
+		m.planTablesLocked(desired)
+		return
+	}
+
 	if m.removeOrphanTables {
 		for tableKey, state := range m.activeTables {
 			if _, exists := desired[tableKey]; !exists {
-				slog.Info("Removing orphan stats table", "table", tableKey)
+				m.logger.Info("Removing orphan stats table", "table", tableKey)
 				if err := m.removeStatsTable(state); err != nil {
-					slog.Error("Failed to remove orphan stats table", "table", tableKey, "error", err)
+					m.logger.Error("Failed to remove orphan stats table", "table", tableKey, "error", err)
 				}
 				delete(m.activeTables, tableKey)
 			}
@@ -206,9 +296,9 @@ func (m *StatsTableManager) reconcileTables(desiredTables []domain.TableIdentifi
 
 	for tableKey, tableID := range desired {
 		if _, exists := m.activeTables[tableKey]; !exists {
-			slog.Info("Creating stats table for new table", "table", tableKey)
+			m.logger.Info("Creating stats table for new table", "table", tableKey)
 			if err := m.createStatsTable(tableID); err != nil {
-				slog.Error("Failed to create stats table", "table", tableKey, "error", err)
+				m.logger.Error("Failed to create stats table", "table", tableKey, "error", err)
 				continue
 			}
 
@@ -221,39 +311,26 @@ func (m *StatsTableManager) reconcileTables(desiredTables []domain.TableIdentifi
 	}
 }
 
-// createStatsTable creates a side stats table and sets up events.
+// createStatsTable creates a side stats table with its schema and sets up its event.
 func (m *StatsTableManager) createStatsTable(tableID domain.TableIdentifier) error {
 	ctx, cancel := context.WithTimeout(m.ctx, 30*time.Second)
 	defer cancel()
 
-	db, err := m.connManager.Get(ctx, tableID.Namespace, tableID.Database)
+	db, err := m.connManager.Querier(ctx, tableID.Namespace, tableID.Database)
 	if err != nil {
 		return fmt.Errorf("failed to get connection: %w", err)
 	}
 
 	statsTableName := m.getStatsTableName(tableID.Table)
 
-	createTableQuery := fmt.Sprintf(`
-	IF !record::exists(%[1]s:stats) THEN
-		CREATE %[1]s:stats SET
-			target_table = "%[2]s",
-			create_relational = 0,
-			create_kv = 0,
-			create_graph = 0,
-			create_document = 0,
-			update_relational = 0,
-			update_kv = 0,
-			update_graph = 0,
-			update_document = 0,
-			delete_relational = 0,
-			delete_kv = 0,
-			delete_graph = 0,
-			delete_document = 0,
-			last_update = time::now()
-	END;
-    `, statsTableName, tableID.Table)
+	if err = ensureStatsTableSchema(ctx, m.logger, db, statsTableName); err != nil {
+		return err
+	}
+
+	createTableQuery := statsRecordCreation(statsTableName)
 
-	results, err := sdk.Query[any](ctx, db, createTableQuery, nil)
+	vars := map[string]any{"target_table": tableID.Table}
+	results, err := tracedQuery[any](ctx, db, createTableQuery, vars)
 	if err != nil {
 		return fmt.Errorf("failed to create stats table: %w", err)
 	}
@@ -261,98 +338,16 @@ func (m *StatsTableManager) createStatsTable(tableID domain.TableIdentifier) err
 	if results != nil && len(*results) > 0 {
 		result := (*results)[0]
 		if result.Status != "OK" {
-			return fmt.Errorf("create stats table returned %s status: %w", result.Status, result.Error)
-		}
-	}
-
-	createEventQuery := fmt.Sprintf(`
-		DEFINE EVENT stats_create ON TABLE %s WHEN $event = "CREATE" THEN {
-			LET $op_type = IF $after.in AND $after.out THEN "graph"
-				ELSE IF $after.keys().len() <= 3 THEN "kv"
-				ELSE IF $after.values().flatten().len() = $after.values().len() 
-					AND $after.keys().len() >= 4 THEN "relational"
-				ELSE "document"
-			END;
-			UPDATE %s:stats SET
-				create_relational += IF $op_type = "relational" THEN 1 ELSE 0 END,
-				create_kv += IF $op_type = "kv" THEN 1 ELSE 0 END,
-				create_graph += IF $op_type = "graph" THEN 1 ELSE 0 END,
-				create_document += IF $op_type = "document" THEN 1 ELSE 0 END,
-				last_update = time::now()
-		};
-	`, tableID.Table, statsTableName)
-
-	results, err = sdk.Query[any](ctx, db, createEventQuery, nil)
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
-		return fmt.Errorf("failed to create CREATE event: %w", err)
-	}
-
-	if results != nil && len(*results) > 0 {
-		result := (*results)[0]
-		if result.Status != "OK" && !strings.Contains(result.Error.Error(), "already exists") {
-			return fmt.Errorf("create CREATE event returned %s status: %w", result.Status, result.Error)
-		}
-	}
-
-	updateEventQuery := fmt.Sprintf(`
-		DEFINE EVENT stats_update ON TABLE %s WHEN $event = "UPDATE" THEN {
-			LET $op_type = IF $after.in AND $after.out THEN "graph"
-				ELSE IF $after.keys().len() <= 3 THEN "kv"
-				ELSE IF $after.values().flatten().len() = $after.values().len() 
-					AND $after.keys().len() >= 4 THEN "relational"
-				ELSE "document"
-			END;
-			UPDATE %s:stats SET
-				update_relational += IF $op_type = "relational" THEN 1 ELSE 0 END,
-				update_kv += IF $op_type = "kv" THEN 1 ELSE 0 END,
-				update_graph += IF $op_type = "graph" THEN 1 ELSE 0 END,
-				update_document += IF $op_type = "document" THEN 1 ELSE 0 END,
-				last_update = time::now()
-		};
-	`, tableID.Table, statsTableName)
-
-	results, err = sdk.Query[any](ctx, db, updateEventQuery, nil)
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
-		return fmt.Errorf("failed to create UPDATE event: %w", err)
-	}
-
-	if results != nil && len(*results) > 0 {
-		result := (*results)[0]
-		if result.Status != "OK" && !strings.Contains(result.Error.Error(), "already exists") {
-			return fmt.Errorf("create UPDATE event returned %s status: %w", result.Status, result.Error)
+			return fmt.Errorf("create stats table returned %s status: %w", result.Status, queryStatus(result.Error))
 		}
 	}
 
-	deleteEventQuery := fmt.Sprintf(`
-		DEFINE EVENT stats_delete ON TABLE %s WHEN $event = "DELETE" THEN {
-			LET $op_type = IF $before.in AND $before.out THEN "graph"
-				ELSE IF $before.keys().len() <= 3 THEN "kv"
-				ELSE IF $before.values().flatten().len() = $before.values().len() 
-					AND $before.keys().len() >= 4 THEN "relational"
-				ELSE "document"
-			END;
-			UPDATE %s:stats SET
-				delete_relational += IF $op_type = "relational" THEN 1 ELSE 0 END,
-				delete_kv += IF $op_type = "kv" THEN 1 ELSE 0 END,
-				delete_graph += IF $op_type = "graph" THEN 1 ELSE 0 END,
-				delete_document += IF $op_type = "document" THEN 1 ELSE 0 END,
-				last_update = time::now()
-		};
-	`, tableID.Table, statsTableName)
-
-	results, err = sdk.Query[any](ctx, db, deleteEventQuery, nil)
-	if err != nil && !strings.Contains(err.Error(), "already exists") {
-		return fmt.Errorf("failed to create DELETE event: %w", err)
+	opTypeExpr := m.classifier.SurrealQL(tableID, "$record")
+	if err = ensureStatsEvent(ctx, m.logger, db, tableID.Table, statsTableName, opTypeExpr, m.deltaRecords); err != nil {
+		return fmt.Errorf("failed to define stats event: %w", err)
 	}
 
-	if results != nil && len(*results) > 0 {
-		result := (*results)[0]
-		if result.Status != "OK" && !strings.Contains(result.Error.Error(), "already exists") {
-			return fmt.Errorf("create DELETE event returned %s status: %w", result.Status, result.Error)
-		}
-	}
-
-	slog.Info("Stats table created successfully",
+	m.logger.Info("Stats table created successfully",
 		"namespace", tableID.Namespace,
 		"database", tableID.Database,
 		"table", tableID.Table,
@@ -361,35 +356,73 @@ func (m *StatsTableManager) createStatsTable(tableID domain.TableIdentifier) err
 	return nil
 }
 
-// removeStatsTable removes a stats table and its events.
+// statsRecordCreation builds the query creating the stats record of a stats table with
+// zeroed counters, expecting the target table name in $target_table.
+func statsRecordCreation(statsTableName string) string {
+	return fmt.Sprintf(`
+	IF !record::exists(%[1]s) THEN
+		CREATE %[1]s SET
+			target_table = $target_table,
+			create_relational = 0,
+			create_kv = 0,
+			create_graph = 0,
+			create_document = 0,
+			update_relational = 0,
+			update_kv = 0,
+			update_graph = 0,
+			update_document = 0,
+			delete_relational = 0,
+			delete_kv = 0,
+			delete_graph = 0,
+			delete_document = 0,
+			last_update = time::now(),
+			created_at = time::now()
+	END;
+    `, recordID(statsTableName, "stats"))
+}
+
+// statsTableRemoval returns the statements removing a stats table and its events,
+// including legacy ones.
+func statsTableRemoval(state *statsTableState) []string {
+	eventNames := append([]string{statsEventName}, legacyStatsEventNames...)
+
+	statements := make([]string, 0, len(eventNames)+1)
+	for _, eventName := range eventNames {
+		statements = append(statements, fmt.Sprintf("REMOVE EVENT %s ON TABLE %s",
+			quoteIdent(eventName), quoteIdent(state.targetTableID.Table)))
+	}
+
+	// REMOVE TABLE drops the schema defined by ensureStatsTableSchema along with the records.
+	return append(statements, fmt.Sprintf("REMOVE TABLE %s", quoteIdent(state.statsTableName)))
+}
+
+// removeStatsTable removes a stats table and its events, including legacy ones.
 func (m *StatsTableManager) removeStatsTable(state *statsTableState) error {
 	ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
 	defer cancel()
 
-	db, err := m.connManager.Get(ctx, state.targetTableID.Namespace, state.targetTableID.Database)
+	db, err := m.connManager.Querier(ctx, state.targetTableID.Namespace, state.targetTableID.Database)
 	if err != nil {
 		return fmt.Errorf("failed to get connection: %w", err)
 	}
 
-	eventNames := []string{"stats_create", "stats_update", "stats_delete"}
-	for _, eventName := range eventNames {
-		query := fmt.Sprintf("REMOVE EVENT %s ON TABLE %s", eventName, state.targetTableID.Table)
-		results, err := sdk.Query[any](ctx, db, query, nil)
+	statements := statsTableRemoval(state)
+	for _, query := range statements[:len(statements)-1] {
+		results, err := tracedQuery[any](ctx, db, query, nil)
 		if err != nil {
-			slog.Warn("Failed to remove event", "event", eventName, "error", err)
+			m.logger.Debug("Failed to remove event", "query", query, "error", err)
 		} else if results != nil && len(*results) > 0 {
 			result := (*results)[0]
 			if result.Status != "OK" {
-				slog.Warn("Remove event returned non-OK status",
-					"event", eventName,
+				m.logger.Warn("Remove event returned non-OK status",
+					"query", query,
 					"status", result.Status,
 					"error", result.Error)
 			}
 		}
 	}
 
-	query := fmt.Sprintf("DELETE %s", state.statsTableName)
-	results, err := sdk.Query[any](ctx, db, query, nil)
+	results, err := tracedQuery[any](ctx, db, statements[len(statements)-1], nil)
 	if err != nil {
 		return fmt.Errorf("failed to remove stats table: %w", err)
 	}
@@ -397,11 +430,11 @@ func (m *StatsTableManager) removeStatsTable(state *statsTableState) error {
 	if results != nil && len(*results) > 0 {
 		result := (*results)[0]
 		if result.Status != "OK" {
-			return fmt.Errorf("delete stats table returned %s status: %w", result.Status, result.Error)
+			return fmt.Errorf("remove stats table returned %s status: %w", result.Status, queryStatus(result.Error))
 		}
 	}
 
-	slog.Info("Stats table removed", "table", state.targetTableID.String())
+	m.logger.Info("Stats table removed", "table", state.targetTableID.String())
 	return nil
 }
 
@@ -0,0 +1,62 @@
+package surrealdb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	sdk "github.com/surrealdb/surrealdb.go"
+)
+
+var (
+	identEscaper  = strings.NewReplacer(`\`, `\\`, "⟩", `\⟩`)
+	stringEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+)
+
+// quoteIdent wraps a SurrealQL identifier (namespace, database, table, index, event)
+// in ⟨⟩ brackets so names with spaces, reserved words or special characters are
+// always parsed as a single identifier and cannot alter the surrounding statement.
+func quoteIdent(name string) string {
+	return "⟨" + identEscaper.Replace(name) + "⟩"
+}
+
+// quoteString returns value as a double quoted SurrealQL string literal, for statements
+// that are shown or run without bound variables.
+func quoteString(value string) string {
+	return `"` + stringEscaper.Replace(value) + `"`
+}
+
+// recordID builds a record identifier for the given table and literal key.
+// The key must be a constant controlled by the exporter.
+func recordID(table, key string) string {
+	return quoteIdent(table) + ":" + key
+}
+
+// maxBatchStatements caps the number of statements sent in a single multi-statement query.
+const maxBatchStatements = 200
+
+// queryBatch executes statements as multi-statement queries (chunked by maxBatchStatements)
+// and returns exactly one result per statement, in order. Errors of individual statements
+// are reported through the Status and Error fields of their result.
+func queryBatch[T any](ctx context.Context, db Querier, statements []string) ([]sdk.QueryResult[T], error) {
+	results := make([]sdk.QueryResult[T], 0, len(statements))
+
+	for start := 0; start < len(statements); start += maxBatchStatements {
+		end := min(start+maxBatchStatements, len(statements))
+		chunk := statements[start:end]
+
+		chunkResults, err := tracedQuery[T](ctx, db, strings.Join(chunk, ";\n")+";", nil)
+		if chunkResults == nil {
+			return nil, fmt.Errorf("batch query failed: %w", err)
+		}
+
+		if len(*chunkResults) != len(chunk) {
+			return nil, fmt.Errorf("batch query returned %d results for %d statements",
+				len(*chunkResults), len(chunk))
+		}
+
+		results = append(results, *chunkResults...)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,167 @@
+package surrealdb
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+var (
+	// tableQuotedRegex matches strings and escaped identifiers of a DEFINE TABLE statement,
+	// such as comments and view conditions, so their content is not taken for clauses.
+	tableQuotedRegex = regexp.MustCompile("'(?:[^'\\\\]|\\\\.)*'|\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`|⟨[^⟩]*⟩")
+
+	tableTypeRegex       = regexp.MustCompile(`(?i)\bTYPE\s+(ANY|NORMAL|RELATION)\b`)
+	tableSchemafullRegex = regexp.MustCompile(`(?i)\bSCHEMAFULL\b`)
+	tableDropRegex       = regexp.MustCompile(`(?i)\bDROP\b`)
+	tableChangefeedRegex = regexp.MustCompile(`(?i)\bCHANGEFEED\s+([0-9a-zµ]+)(\s+INCLUDE\s+ORIGINAL)?\b`)
+
+	indexVectorRegex     = regexp.MustCompile(`(?i)\b(HNSW|MTREE)\s+DIMENSION\b`)
+	indexParameterRegex  = regexp.MustCompile(`(?i)\b(DIMENSION|CAPACITY|EFC|M0|M)\s+([0-9]+)\b`)
+	indexDistanceRegex   = regexp.MustCompile(`(?i)\bDIST\s+([A-Z]+)\b`)
+	indexVectorTypeRegex = regexp.MustCompile(`(?i)\bTYPE\s+([A-Z0-9]+)\b`)
+
+	// surrealDurationRegex matches one component of a SurrealDB duration, such as 1w or 12h.
+	surrealDurationRegex = regexp.MustCompile(`([0-9]+)(ns|us|µs|ms|s|m|h|d|w|y)`)
+)
+
+// surrealDurationUnits holds the length of the SurrealDB duration units.
+var surrealDurationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// parseTableDefinition returns the settings of the DEFINE TABLE statement INFO FOR DB
+// reports for a table. Tables without an explicit type are of type any.
+func parseTableDefinition(statement any) domain.TableDefinition {
+	definition, _ := statement.(string)
+	definition = tableQuotedRegex.ReplaceAllString(definition, "''")
+
+	// The statement starts with DEFINE TABLE and the table name.
+	if fields := strings.Fields(definition); len(fields) > 3 {
+		definition = strings.Join(fields[3:], " ")
+	} else {
+		definition = ""
+	}
+
+	// The view query and permissions, e.g. a WHERE on a field named drop, do not
+	// configure the table.
+	clauses := wordsBefore(definition, "AS", "PERMISSIONS")
+
+	result := domain.TableDefinition{
+		Type:       domain.TableTypeAny,
+		Schemafull: tableSchemafullRegex.MatchString(clauses),
+		Drop:       tableDropRegex.MatchString(clauses),
+	}
+
+	if match := tableTypeRegex.FindStringSubmatch(clauses); match != nil {
+		result.Type = strings.ToLower(match[1])
+	}
+
+	if match := tableChangefeedRegex.FindStringSubmatch(definition); match != nil {
+		result.Changefeed = true
+		result.ChangefeedRetention = parseSurrealDuration(match[1])
+		result.ChangefeedOriginal = match[2] != ""
+	}
+
+	return result
+}
+
+// parseVectorIndexDefinition returns the settings of the DEFINE INDEX statement INFO FOR
+// TABLE reports for an index, or nil when it is not an HNSW or MTREE vector index.
+func parseVectorIndexDefinition(statement any) *domain.VectorIndexDefinition {
+	definition, _ := statement.(string)
+	definition = tableQuotedRegex.ReplaceAllString(definition, "''")
+
+	// The index type follows the indexed fields, whose names could look like clauses,
+	// and is always followed by the dimension.
+	location := indexVectorRegex.FindStringSubmatchIndex(definition)
+	if location == nil {
+		return nil
+	}
+
+	clauses := definition[location[2]:]
+	result := &domain.VectorIndexDefinition{
+		Type: strings.ToLower(definition[location[2]:location[3]]),
+	}
+
+	for _, match := range indexParameterRegex.FindAllStringSubmatch(clauses, -1) {
+		value, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+
+		switch strings.ToUpper(match[1]) {
+		case "DIMENSION":
+			result.Dimension = value
+		case "CAPACITY":
+			result.Capacity = value
+		case "EFC":
+			result.EFConstruction = value
+		case "M":
+			result.M = value
+		case "M0":
+			result.M0 = value
+		}
+	}
+
+	if match := indexDistanceRegex.FindStringSubmatch(clauses); match != nil {
+		result.Distance = strings.ToLower(match[1])
+	}
+
+	if match := indexVectorTypeRegex.FindStringSubmatch(clauses); match != nil {
+		result.VectorType = strings.ToLower(match[1])
+	}
+
+	return result
+}
+
+// wordsBefore returns s up to the first of keywords, matched case insensitively as whole
+// words.
+func wordsBefore(s string, keywords ...string) string {
+	fields := strings.Fields(s)
+	for i, field := range fields {
+		for _, keyword := range keywords {
+			if strings.EqualFold(field, keyword) {
+				return strings.Join(fields[:i], " ")
+			}
+		}
+	}
+
+	return s
+}
+
+// parseSurrealDuration parses a SurrealDB duration such as 1w2d, returning 0 when it is
+// not one.
+func parseSurrealDuration(s string) time.Duration {
+	matches := surrealDurationRegex.FindAllStringSubmatch(s, -1)
+
+	var total time.Duration
+	matched := 0
+	for _, match := range matches {
+		value, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return 0
+		}
+
+		total += time.Duration(value) * surrealDurationUnits[match[2]]
+		matched += len(match[0])
+	}
+
+	if matched != len(s) {
+		return 0
+	}
+
+	return total
+}
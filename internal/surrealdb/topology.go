@@ -0,0 +1,52 @@
+package surrealdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+)
+
+// cloudHostSuffix is the host suffix of SurrealDB Cloud instances.
+const cloudHostSuffix = ".surreal.cloud"
+
+// DetectTopology infers the deployment mode of the server from its endpoint and the nodes
+// listed by INFO FOR ROOT. The storage engine is not reported by the server and is left
+// empty, as is the mode of a server the user has no root access to.
+func DetectTopology(ctx context.Context, conn QuerierProvider, cfg Config) (domain.Topology, error) {
+	if u, err := url.Parse(cfg.SurrealURL()); err == nil && strings.HasSuffix(u.Hostname(), cloudHostSuffix) {
+		return domain.Topology{DeploymentMode: "cloud"}, nil
+	}
+
+	if cfg.SurrealUserLevel() != domain.AuthLevelRoot {
+		return domain.Topology{}, nil
+	}
+
+	db, err := conn.Querier(ctx, "", "")
+	if err != nil {
+		return domain.Topology{}, fmt.Errorf("could not get DB connection: %w", err)
+	}
+
+	results, err := tracedQuery[*rootInfo](ctx, db, "INFO FOR ROOT", nil)
+	if err != nil {
+		return domain.Topology{}, fmt.Errorf("INFO FOR ROOT query failed: %w", err)
+	}
+
+	if results == nil || len(*results) == 0 {
+		return domain.Topology{}, errors.New("INFO FOR ROOT returned no results")
+	}
+
+	rootResult := (*results)[0]
+	if rootResult.Status != "OK" {
+		return domain.Topology{}, fmt.Errorf("INFO FOR ROOT returned %s status: %w", rootResult.Status, queryStatus(rootResult.Error))
+	}
+
+	if rootResult.Result != nil && len(rootResult.Result.Nodes) > 1 {
+		return domain.Topology{DeploymentMode: "distributed"}, nil
+	}
+
+	return domain.Topology{DeploymentMode: "single"}, nil
+}
@@ -0,0 +1,75 @@
+package surrealdb
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache is a concurrency-safe key/value cache with a fixed time-to-live.
+// A zero TTL disables caching: get always misses and set is a no-op.
+type ttlCache[T any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]ttlCacheEntry[T]
+	// nextSweep is when set next drops the expired entries; sweeping at most once per TTL
+	// keeps set O(1) amortized while bounding the entries to those set within two TTLs.
+	nextSweep time.Time
+}
+
+type ttlCacheEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+func newTTLCache[T any](ttl time.Duration) *ttlCache[T] {
+	return &ttlCache[T]{
+		ttl:     ttl,
+		entries: make(map[string]ttlCacheEntry[T]),
+	}
+}
+
+// get returns the cached value for key if present and not expired.
+func (c *ttlCache[T]) get(key string) (T, bool) {
+	var zero T
+	if c.ttl <= 0 {
+		return zero, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return zero, false
+	}
+
+	return entry.value, true
+}
+
+// set stores value for key and drops the expired entries once per TTL.
+func (c *ttlCache[T]) set(key string, value T) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.After(c.nextSweep) {
+		for k, entry := range c.entries {
+			if now.After(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.nextSweep = now.Add(c.ttl)
+	}
+
+	c.entries[key] = ttlCacheEntry[T]{value: value, expiresAt: now.Add(c.ttl)}
+}
@@ -8,7 +8,7 @@ import (
 )
 
 type versionReader struct {
-	conn ConnectionManager
+	conn QuerierProvider
 
 	mu            sync.RWMutex
 	cachedVersion string
@@ -16,7 +16,7 @@ type versionReader struct {
 	cacheDuration time.Duration
 }
 
-func NewVersionReader(conn ConnectionManager) (*versionReader, error) {
+func NewVersionReader(conn QuerierProvider) (*versionReader, error) {
 	if conn == nil {
 		return nil, errors.New("conn argument cannot be nil")
 	}
@@ -43,7 +43,7 @@ func (r *versionReader) Version(ctx context.Context) (string, error) {
 		return r.cachedVersion, nil
 	}
 
-	db, err := r.conn.Get(ctx, "", "")
+	db, err := r.conn.Querier(ctx, "", "")
 	if err != nil {
 		return "", err
 	}
@@ -53,7 +53,7 @@ func (r *versionReader) Version(ctx context.Context) (string, error) {
 		return "", err
 	}
 
-	r.cachedVersion = v.Version
+	r.cachedVersion = v
 	r.cacheTime = time.Now()
 
 	return r.cachedVersion, nil
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// scrapes holds the spans of the scrapes in flight, so collections, whose Collect carries
+// no context, can be traced as part of the scrape that triggered them.
+var scrapes = struct {
+	mu    sync.Mutex
+	next  uint64
+	spans map[uint64]trace.SpanContext
+}{spans: make(map[uint64]trace.SpanContext)}
+
+// StartScrape records the span of ctx as a scrape in flight until the returned function
+// is called.
+func StartScrape(ctx context.Context) func() {
+	spanContext := trace.SpanContextFromContext(ctx)
+	if !spanContext.IsValid() {
+		return func() {}
+	}
+
+	scrapes.mu.Lock()
+	id := scrapes.next
+	scrapes.next++
+	scrapes.spans[id] = spanContext
+	scrapes.mu.Unlock()
+
+	return func() {
+		scrapes.mu.Lock()
+		delete(scrapes.spans, id)
+		scrapes.mu.Unlock()
+	}
+}
+
+// CollectContext returns the context and options a collection span starts with. The
+// span is a child of the scrape in flight; while scrapes overlap, which one triggered
+// the collection is unknown, so the span starts a new trace linked to all of them.
+func CollectContext() (context.Context, []trace.SpanStartOption) {
+	scrapes.mu.Lock()
+	defer scrapes.mu.Unlock()
+
+	switch len(scrapes.spans) {
+	case 0:
+		return context.Background(), nil
+
+	case 1:
+		for _, spanContext := range scrapes.spans {
+			return trace.ContextWithSpanContext(context.Background(), spanContext), nil
+		}
+	}
+
+	links := make([]trace.Link, 0, len(scrapes.spans))
+	for _, spanContext := range scrapes.spans {
+		links = append(links, trace.Link{SpanContext: spanContext})
+	}
+
+	return context.Background(), []trace.SpanStartOption{trace.WithNewRoot(), trace.WithLinks(links...)}
+}
@@ -0,0 +1,148 @@
+// Package surrealexporter embeds the SurrealDB collectors of the exporter in another Go
+// service, instead of running the exporter as a sidecar:
+//
+//	exporter, err := surrealexporter.NewExporter(
+//		surrealexporter.WithConfigFile("surrealdb-exporter.yaml"),
+//		surrealexporter.WithCollector("relation_edges", true),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	defer exporter.Close()
+//
+//	prometheus.MustRegister(exporter)
+//
+// The exporter is configured with the configuration file of the exporter binary. The
+// settings of its HTTP server, push, logging, tracing, OTLP receiver and telemetry (go,
+// process) collectors are ignored; the embedding service owns those. Each exporter rate limits its
+// own queries with surrealdb.rate_limit.
+package surrealexporter
+
+import (
+	"log/slog"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/config"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/exporter"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/registry"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/proto"
+)
+
+// Option configures an Exporter.
+type Option func(*options)
+
+type options struct {
+	configFile string
+	configYAML string
+	strict     bool
+	collectors map[string]bool
+}
+
+// WithConfigFile reads the configuration from an exporter configuration file.
+func WithConfigFile(path string) Option {
+	return func(o *options) {
+		o.configFile = path
+	}
+}
+
+// WithConfigYAML applies YAML configuration on top of the configuration file, like the
+// --config.inline flag of the exporter.
+func WithConfigYAML(yaml string) Option {
+	return func(o *options) {
+		o.configYAML = yaml
+	}
+}
+
+// WithStrictConfig fails NewExporter on unknown configuration keys and on values that
+// would otherwise be corrected with a warning.
+func WithStrictConfig() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithCollector enables or disables a collector by name, e.g. "record_count", overriding
+// the configuration.
+func WithCollector(name string, enabled bool) Option {
+	return func(o *options) {
+		o.collectors[name] = enabled
+	}
+}
+
+// Exporter is a prometheus.Collector exporting the metrics of the enabled SurrealDB
+// collectors. It is unchecked: Describe sends no descriptors, as the exported metrics
+// depend on the schema of the monitored databases.
+type Exporter struct {
+	exporter *exporter.Exporter
+	gatherer prometheus.Gatherer
+}
+
+var _ prometheus.Collector = (*Exporter)(nil)
+
+// NewExporter connects to SurrealDB and builds the enabled collectors. Without options
+// the exporter defaults apply, connecting to SurrealDB on localhost. Close must be called
+// to stop the background refreshes and live queries of the collectors.
+func NewExporter(opts ...Option) (*Exporter, error) {
+	o := &options{collectors: make(map[string]bool)}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	cfg, err := config.Load(o.configFile, o.configYAML, o.strict, config.Overrides{Collectors: o.collectors})
+	if err != nil {
+		return nil, err
+	}
+
+	exp, err := exporter.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Exporter{
+		exporter: exp,
+		gatherer: registry.WithRenames(cfg, registry.WithLabelSanitization(cfg, exp.Metrics)),
+	}, nil
+}
+
+// Describe sends no descriptors, registering the Exporter as an unchecked collector.
+func (e *Exporter) Describe(chan<- *prometheus.Desc) {}
+
+// Collect gathers the enabled collectors and sends their metrics.
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		slog.Warn("SurrealDB metrics gathered with errors", "error", err)
+	}
+
+	for _, family := range families {
+		desc := prometheus.NewDesc(family.GetName(), family.GetHelp(), nil, nil)
+		for _, metric := range family.GetMetric() {
+			ch <- gatheredMetric{desc: desc, metric: metric}
+		}
+	}
+}
+
+// Close stops the background refreshes, live queries and connection reaping.
+func (e *Exporter) Close() {
+	if e.exporter != nil {
+		e.exporter.Close()
+		e.exporter = nil
+	}
+}
+
+// gatheredMetric passes a gathered metric on to the registry of the embedding service.
+type gatheredMetric struct {
+	desc   *prometheus.Desc
+	metric *dto.Metric
+}
+
+func (m gatheredMetric) Desc() *prometheus.Desc {
+	return m.desc
+}
+
+func (m gatheredMetric) Write(out *dto.Metric) error {
+	proto.Reset(out)
+	proto.Merge(out, m.metric)
+	return nil
+}
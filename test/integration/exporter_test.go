@@ -0,0 +1,191 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/config"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/engine"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/registry"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealcollectors"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/surrealdb"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// exporterConfig is the configuration the exporter under test is loaded with; the
+// SurrealDB address is filled in from the container.
+const exporterConfig = `
+surrealdb:
+  scheme: ws
+  host: %HOST%
+  port: %PORT%
+  username: root
+  password: root
+  cluster_name: integration
+  storage_engine: memory
+  deployment_mode: single
+  auto_detect: false
+collectors:
+  record_count:
+    enabled: true
+    tables:
+      include: ["*:*:*"]
+  live_query:
+    enabled: false
+  stats_table:
+    enabled: false
+  open_telemetry:
+    enabled: false
+  go:
+    enabled: false
+  process:
+    enabled: false
+logging:
+  level: warn
+`
+
+// writeConfig writes exporterConfig for the SurrealDB container and returns its path.
+func writeConfig(t *testing.T) string {
+	t.Helper()
+
+	host, port, _ := strings.Cut(surrealDBAddress, ":")
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	content := strings.NewReplacer("%HOST%", host, "%PORT%", port).Replace(exporterConfig)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	return path
+}
+
+// newExporter wires the collectors like cmd/exporter against the SurrealDB container and
+// serves their metrics from a test server.
+func newExporter(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	cfg, err := config.Load(writeConfig(t), "", false, config.Overrides{})
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	connManager := surrealdb.NewMultiConnectionManager(cfg, nil)
+
+	versionReader, err := surrealdb.NewVersionReader(connManager)
+	if err != nil {
+		t.Fatalf("failed to create version reader: %v", err)
+	}
+
+	infoReader, err := surrealdb.NewInfoReader(cfg, connManager)
+	if err != nil {
+		t.Fatalf("failed to create info reader: %v", err)
+	}
+
+	recordCountReader, err := surrealdb.NewRecordCountReader(connManager, cfg.RecordCountPartitions())
+	if err != nil {
+		t.Fatalf("failed to create record count reader: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.SurrealTimeout())
+	info, err := infoReader.Info(ctx)
+	cancel()
+	if err != nil {
+		t.Fatalf("failed to read info: %v", err)
+	}
+
+	tableCache := surrealcollectors.NewTableCache()
+	tableCache.SetTables(info.AllTables())
+
+	gatherer, _, _, err := registry.New(cfg, surrealcollectors.Dependencies{
+		Config:            cfg,
+		VersionReader:     versionReader,
+		InfoMetricsReader: infoReader,
+		RecordCountReader: recordCountReader,
+		RecordCountFilter: engine.NewTableFilter(cfg.RecordCountIncludePatterns(), cfg.RecordCountExcludePatterns()),
+		TableCache:        tableCache,
+	})
+	if err != nil {
+		t.Fatalf("failed to create registry: %v", err)
+	}
+
+	server := httptest.NewServer(promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// scrape fetches /metrics from the exporter and parses the metric families.
+func scrape(t *testing.T, server *httptest.Server) map[string]*dto.MetricFamily {
+	t.Helper()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("scrape failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("scrape returned %s", resp.Status)
+	}
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to parse metrics: %v", err)
+	}
+
+	return families
+}
+
+// value returns the value of the metric of family name having all of labels, failing
+// the test when there is none.
+func value(t *testing.T, families map[string]*dto.MetricFamily, name string, labels map[string]string) float64 {
+	t.Helper()
+
+	family, ok := families[name]
+	if !ok {
+		t.Fatalf("metric %s not exported", name)
+	}
+
+	for _, metric := range family.GetMetric() {
+		if !hasLabels(metric, labels) {
+			continue
+		}
+
+		switch {
+		case metric.GetGauge() != nil:
+			return metric.GetGauge().GetValue()
+		case metric.GetCounter() != nil:
+			return metric.GetCounter().GetValue()
+		case metric.GetUntyped() != nil:
+			return metric.GetUntyped().GetValue()
+		}
+	}
+
+	t.Fatalf("metric %s%v not exported", name, labels)
+	return 0
+}
+
+// hasLabels reports whether metric has every label of labels with the same value.
+func hasLabels(metric *dto.Metric, labels map[string]string) bool {
+	matched := 0
+	for _, pair := range metric.GetLabel() {
+		if expected, ok := labels[pair.GetName()]; ok {
+			if pair.GetValue() != expected {
+				return false
+			}
+			matched++
+		}
+	}
+
+	return matched == len(labels)
+}
@@ -0,0 +1,107 @@
+//go:build integration
+
+// Package integration runs the exporter's collectors against a real SurrealDB started in
+// Docker and asserts on the scraped /metrics output.
+//
+// Run with:
+//
+//	go test -tags integration ./test/integration/...
+//
+// SURREALDB_VERSION selects the SurrealDB image tag (default defaultSurrealDBVersion), so
+// INFO parsing can be checked against every supported server version.
+package integration
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+)
+
+const (
+	defaultSurrealDBVersion = "v2.3.7"
+	surrealDBImage          = "surrealdb/surrealdb"
+	surrealDBUser           = "root"
+	surrealDBPass           = "root"
+
+	containerExpiry = 10 * time.Minute
+	startupTimeout  = 2 * time.Minute
+)
+
+// surrealDBAddress is the host:port of the SurrealDB container shared by all tests.
+var surrealDBAddress string
+
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	version := os.Getenv("SURREALDB_VERSION")
+	if version == "" {
+		version = defaultSurrealDBVersion
+	}
+
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Printf("Failed to connect to Docker: %v", err)
+		return 1
+	}
+	pool.MaxWait = startupTimeout
+
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: surrealDBImage,
+		Tag:        version,
+		Cmd:        []string{"start", "--user", surrealDBUser, "--pass", surrealDBPass, "memory"},
+	}, func(config *docker.HostConfig) {
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+	})
+	if err != nil {
+		log.Printf("Failed to start SurrealDB %s: %v", version, err)
+		return 1
+	}
+
+	defer func() {
+		if err := pool.Purge(resource); err != nil {
+			log.Printf("Failed to remove SurrealDB container: %v", err)
+		}
+	}()
+
+	// Removes the container even when the test binary is killed before the deferred purge.
+	if err := resource.Expire(uint(containerExpiry.Seconds())); err != nil {
+		log.Printf("Failed to set SurrealDB container expiry: %v", err)
+		return 1
+	}
+
+	surrealDBAddress = resource.GetHostPort("8000/tcp")
+
+	err = pool.Retry(func() error {
+		resp, err := http.Get(fmt.Sprintf("http://%s/health", surrealDBAddress))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("health check returned %s", resp.Status)
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Printf("SurrealDB %s did not become healthy: %v", version, err)
+		return 1
+	}
+
+	if err := seed(); err != nil {
+		log.Printf("Failed to seed SurrealDB: %v", err)
+		return 1
+	}
+
+	return m.Run()
+}
@@ -0,0 +1,64 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+)
+
+func TestBuildInfo(t *testing.T) {
+	families := scrape(t, newExporter(t))
+
+	family, ok := families["surrealdb_build_info"]
+	if !ok || len(family.GetMetric()) != 1 {
+		t.Fatalf("expected one surrealdb_build_info series, got %v", family)
+	}
+
+	if got := value(t, families, "surrealdb_build_info", map[string]string{"cluster": "integration"}); got != 1 {
+		t.Errorf("surrealdb_build_info = %v, want 1", got)
+	}
+}
+
+func TestInfoMetrics(t *testing.T) {
+	families := scrape(t, newExporter(t))
+
+	database := map[string]string{"namespace": seedNamespace, "database": seedDatabase}
+	table := func(name string) map[string]string {
+		return map[string]string{"namespace": seedNamespace, "database": seedDatabase, "table": name}
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   float64
+	}{
+		{"surrealdb_namespace_databases", map[string]string{"namespace": seedNamespace}, 2},
+		{"surrealdb_database_tables", database, 2},
+		{"surrealdb_table_fields", table("product"), 2},
+		{"surrealdb_table_indexes", table("product"), 1},
+		{"surrealdb_table_fields", table("purchase"), 0},
+		{"surrealdb_table_indexes", table("purchase"), 0},
+	}
+
+	for _, tt := range tests {
+		if got := value(t, families, tt.name, tt.labels); got != tt.want {
+			t.Errorf("%s%v = %v, want %v", tt.name, tt.labels, got, tt.want)
+		}
+	}
+}
+
+func TestRecordCount(t *testing.T) {
+	families := scrape(t, newExporter(t))
+
+	tests := map[string]float64{
+		"product":  3,
+		"purchase": 2,
+	}
+
+	for table, want := range tests {
+		labels := map[string]string{"namespace": seedNamespace, "database": seedDatabase, "table": table}
+		if got := value(t, families, "surrealdb_table_record_count", labels); got != want {
+			t.Errorf("surrealdb_table_record_count%v = %v, want %v", labels, got, want)
+		}
+	}
+}
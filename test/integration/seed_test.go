@@ -0,0 +1,70 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/surrealdb/surrealdb.go"
+)
+
+const (
+	seedNamespace = "integration"
+	seedDatabase  = "shop"
+
+	seedTimeout = 30 * time.Second
+)
+
+// seedStatements define the schema and records the tests assert on: namespace integration
+// with databases shop and empty, shop holding tables product (2 fields, 1 index, 3 records)
+// and purchase (schemaless, 2 records).
+var seedStatements = []string{
+	"DEFINE NAMESPACE integration",
+	"USE NS integration",
+	"DEFINE DATABASE shop",
+	"DEFINE DATABASE empty",
+	"USE NS integration DB shop",
+	"DEFINE TABLE product SCHEMAFULL",
+	"DEFINE FIELD name ON TABLE product TYPE string",
+	"DEFINE FIELD price ON TABLE product TYPE number",
+	"DEFINE INDEX product_name ON TABLE product FIELDS name",
+	"CREATE product:1 SET name = 'keyboard', price = 49",
+	"CREATE product:2 SET name = 'mouse', price = 19",
+	"CREATE product:3 SET name = 'monitor', price = 199",
+	"DEFINE TABLE purchase SCHEMALESS",
+	"CREATE purchase:1 SET product = product:1, quantity = 2",
+	"CREATE purchase:2 SET product = product:3, quantity = 1",
+}
+
+// seed connects to the SurrealDB container as root and runs seedStatements.
+func seed() error {
+	ctx, cancel := context.WithTimeout(context.Background(), seedTimeout)
+	defer cancel()
+
+	db, err := sdk.FromEndpointURLString(ctx, "ws://"+surrealDBAddress)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer db.Close(ctx)
+
+	if _, err := db.SignIn(ctx, &sdk.Auth{Username: surrealDBUser, Password: surrealDBPass}); err != nil {
+		return fmt.Errorf("failed to sign in: %w", err)
+	}
+
+	// One query, so the USE statements apply to the statements following them.
+	results, err := sdk.Query[any](ctx, db, strings.Join(seedStatements, ";\n"), nil)
+	if err != nil {
+		return fmt.Errorf("seed query failed: %w", err)
+	}
+
+	for i, result := range *results {
+		if result.Status != "OK" {
+			return fmt.Errorf("%q returned %s status: %w", seedStatements[i], result.Status, result.Error)
+		}
+	}
+
+	return nil
+}
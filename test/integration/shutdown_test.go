@@ -0,0 +1,208 @@
+//go:build integration
+
+package integration
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/api"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/config"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/converter"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/domain"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/exporter"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/processor"
+	"github.com/asaphin/surrealdb-prometheus-exporter/internal/registry"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// liveQueryConfig enables live queries on the seeded tables on top of exporterConfig,
+// started by the schema poll rather than by a scrape.
+const liveQueryConfig = `
+collectors:
+  live_query:
+    enabled: true
+    tables:
+      include: ["integration:shop:*"]
+    schema_poll_interval: 200ms
+    reconnect_delay: 100ms
+`
+
+const (
+	// shutdownWait bounds the waits for background work in the shutdown tests.
+	shutdownWait = 10 * time.Second
+	// drainCheck is how long Shutdown must keep blocking on a scrape in flight.
+	drainCheck = 200 * time.Millisecond
+)
+
+// TestShutdownOrdering shuts the exporter down like cmd/exporter: the HTTP server drains
+// the scrapes in flight while the connections are still open, then the live queries are
+// stopped and only then the connections closed, so no live query reconnects.
+func TestShutdownOrdering(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t), liveQueryConfig, false, config.Overrides{})
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	exp, err := exporter.New(cfg)
+	if err != nil {
+		t.Fatalf("failed to create exporter: %v", err)
+	}
+
+	waitFor(t, "live queries on the seeded tables", func() bool {
+		active, _ := exp.LiveQuery.LiveQueryTables()
+		return active == 2
+	})
+
+	// The scrape blocks in the gatherer until released, so it is in flight when the
+	// server shuts down.
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	gatherer := prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		close(entered)
+		<-release
+		return exp.Metrics.Gather()
+	})
+
+	server, err := api.NewPrometheusServer(cfg, gatherer)
+	if err != nil {
+		t.Fatalf("failed to create HTTP server: %v", err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	go server.Serve(listener)
+
+	type scrapeResult struct {
+		families map[string]*dto.MetricFamily
+		err      error
+	}
+	scraped := make(chan scrapeResult, 1)
+	go func() {
+		resp, err := http.Get("http://" + listener.Addr().String() + cfg.MetricsPath())
+		if err != nil {
+			scraped <- scrapeResult{err: err}
+			return
+		}
+		defer resp.Body.Close()
+
+		parser := expfmt.NewTextParser(model.UTF8Validation)
+		families, err := parser.TextToMetricFamilies(resp.Body)
+		scraped <- scrapeResult{families: families, err: err}
+	}()
+
+	<-entered
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownWait)
+		defer cancel()
+		shutdown <- server.Shutdown(ctx)
+	}()
+
+	select {
+	case err := <-shutdown:
+		t.Fatalf("HTTP server shut down with a scrape in flight: %v", err)
+	case <-time.After(drainCheck):
+	}
+
+	close(release)
+
+	result := <-scraped
+	if result.err != nil {
+		t.Fatalf("scrape in flight failed: %v", result.err)
+	}
+	if got := value(t, result.families, "surrealdb_database_tables",
+		map[string]string{"namespace": seedNamespace, "database": seedDatabase}); got != 2 {
+		t.Errorf("surrealdb_database_tables scraped while draining = %v, want 2", got)
+	}
+
+	if err := <-shutdown; err != nil {
+		t.Fatalf("failed to drain HTTP server: %v", err)
+	}
+
+	exp.Close()
+
+	if active, _ := exp.LiveQuery.LiveQueryTables(); active != 0 {
+		t.Errorf("%d live queries active after Close, want 0", active)
+	}
+
+	// Live queries seeing their connections close would reconnect after reconnect_delay.
+	time.Sleep(drainCheck)
+	if status := exp.StatusSources().Connections.ConnectionStatus(); len(status) != 0 {
+		t.Errorf("%d connections open after Close, want 0: %v", len(status), status)
+	}
+}
+
+// TestBatchProcessorStopFlushes checks that stopping the OTLP batch processor, as the OTLP
+// receiver does once its gRPC server stopped gracefully, converts the metrics still queued.
+func TestBatchProcessorStopFlushes(t *testing.T) {
+	cfg, err := config.Load(writeConfig(t), "", false, config.Overrides{})
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	otlpRegistry := prometheus.NewRegistry()
+	proc := processor.NewBatchProcessor(
+		converter.NewConverter(cfg, otlpRegistry, registry.MetricNames{}, slog.Default()),
+		100,
+		time.Hour,
+		domain.QueueLimits{},
+		otlpRegistry,
+		nil,
+		slog.Default(),
+	)
+
+	err = proc.Process(context.Background(), domain.MetricBatch{
+		Metrics: []domain.Metric{{
+			Name:      "shutdown_queued",
+			Type:      domain.MetricTypeGauge,
+			Value:     42,
+			Labels:    map[string]string{},
+			Timestamp: time.Now(),
+		}},
+		ReceivedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to queue batch: %v", err)
+	}
+
+	if err := proc.Stop(); err != nil {
+		t.Fatalf("failed to stop batch processor: %v", err)
+	}
+
+	families, err := otlpRegistry.Gather()
+	if err != nil {
+		t.Fatalf("failed to gather converted metrics: %v", err)
+	}
+
+	for _, family := range families {
+		if strings.HasSuffix(family.GetName(), "shutdown_queued") {
+			return
+		}
+	}
+	t.Errorf("queued metric not converted on Stop, gathered %d families", len(families))
+}
+
+// waitFor polls condition until it holds, failing the test after shutdownWait.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(shutdownWait)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}